@@ -6,8 +6,10 @@ import (
 	"GoVM/chapter2-class/classpath"
 	"GoVM/chapter5-instructions/base"
 	"GoVM/chapter5-instructions"
+	"GoVM/native/java/lang"
 	"strings"
 	"fmt"
+	"os"
 )
 
 type JVM struct {
@@ -18,7 +20,22 @@ type JVM struct {
 
 func newJVM(cmd *Cmd) *JVM {
 	cp := classpath.Parse(cmd.XjreOption, cmd.cpOption)
-	classLoader := heap.NewClassLoader(cp, cmd.verboseClassFlag)
+	heap.InitHeap(cmd.xmsOption, cmd.xmxOption)
+	heap.GetHeap().SetYoungSize(cmd.xmnOption)
+	if cmd.printGCFlag {
+		heap.GetHeap().SetGCLog(os.Stdout)
+	}
+	lang.SetAssertionsEnabled(cmd.assertionsEnabled())
+	classLoader := heap.NewClassLoader(nil, cp, cmd.verboseClassFlag, cmd.verifyFlag())
+	if cmd.verifyNoneFlag {
+		heap.DisableMethodVerification()
+	}
+	if cmd.coverageFlag {
+		chapter5_instructions.EnableCoverage()
+	}
+	if cmd.checkMonitorsFlag {
+		chapter4_rtdt.EnableMonitorCheck()
+	}
 	return &JVM{
 		cmd:                cmd,
 		classLoader:        classLoader,
@@ -29,6 +46,9 @@ func newJVM(cmd *Cmd) *JVM {
 func (self *JVM) start() {
 	self.initVM()
 	self.execMain()
+	if self.cmd.coverageFlag {
+		chapter5_instructions.DumpCoverage(os.Stdout)
+	}
 }
 
 func (self *JVM) initVM() {
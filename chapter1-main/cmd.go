@@ -8,6 +8,8 @@ import (
 	"GoVM/chapter3-cf/classfile"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -16,9 +18,29 @@ type Cmd struct {
 	versionFlag      bool
 	verboseClassFlag bool
 	verboseInstFlag  bool
+	//记录执行过的字节码，退出时输出
+	coverageFlag     bool
+	//-Xverify:all 链接之前验证class的结构，默认不验证；
+	//-Xverify:none 连方法第一次执行前的操作数栈深度验证也关掉
+	verifyAllFlag    bool
+	verifyNoneFlag   bool
+	//-Xcheck:monitors，检查每个栈帧的monitorenter/monitorexit是否配对
+	checkMonitorsFlag bool
+	//-XX:+PrintGC 和 -verbose:gc，每次GC输出一行日志
+	printGCFlag      bool
+	//-ea 和 -da，默认不打开断言
+	enableAssertionsFlag  bool
+	disableAssertionsFlag bool
 	//classpath option
 	cpOption         string
 	XjreOption       string
+	//-dump <class>，只打印class结构，不执行
+	dumpOption       string
+	//-Xms<size> -Xmx<size> -Xmn<size>，单位是字节，0表示没有指定
+	xmsOption        uint64
+	xmxOption        uint64
+	//新生代的大小，没有指定时不分代
+	xmnOption        uint64
 	class            string
 	args             []string
 }
@@ -35,10 +57,21 @@ func parseCmd() *Cmd {
 	flag.BoolVar(&cmd.verboseClassFlag, "verbose", false, "enable verbose output")
 	flag.BoolVar(&cmd.verboseClassFlag, "verbose:class", false, "enable verbose output")
 	flag.BoolVar(&cmd.verboseInstFlag, "verbose:inst", false, "enable verbose output")
+	flag.BoolVar(&cmd.coverageFlag, "Xcoverage", false, "dump executed bytecode pcs at exit")
+	flag.BoolVar(&cmd.verifyAllFlag, "Xverify:all", false, "verify all classes before linking")
+	flag.BoolVar(&cmd.verifyNoneFlag, "Xverify:none", false, "disable all verification, including the stack depth check before a method first runs")
+	flag.BoolVar(&cmd.checkMonitorsFlag, "Xcheck:monitors", false, "check monitorenter/monitorexit balance per frame")
+	flag.BoolVar(&cmd.printGCFlag, "XX:+PrintGC", false, "print a message at each garbage collection")
+	flag.BoolVar(&cmd.printGCFlag, "verbose:gc", false, "print a message at each garbage collection")
+	flag.BoolVar(&cmd.enableAssertionsFlag, "ea", false, "enable assertions")
+	flag.BoolVar(&cmd.enableAssertionsFlag, "enableassertions", false, "enable assertions")
+	flag.BoolVar(&cmd.disableAssertionsFlag, "da", false, "disable assertions (default)")
+	flag.BoolVar(&cmd.disableAssertionsFlag, "disableassertions", false, "disable assertions (default)")
 	flag.StringVar(&cmd.cpOption, "classpath", "", "class path")
 	flag.StringVar(&cmd.cpOption, "cp", "", "equals classpath")
 	flag.StringVar(&cmd.XjreOption, "Xjre", "", "path to jre")
-	flag.Parse()
+	flag.StringVar(&cmd.dumpOption, "dump", "", "print the structure of a class and exit")
+	flag.CommandLine.Parse(cmd.parseHeapOptions(os.Args[1:]))
 
 	args := flag.Args()
 	if len(args) > 0 {
@@ -48,6 +81,88 @@ func parseCmd() *Cmd {
 	return cmd
 }
 
+/**
+	flag包不支持 -Xmx64m 这种参数名和值连在一起的写法，所以在flag.Parse之前先把它们挑出来
+	只看主类名之前的参数，主类后面的都是传给java程序的
+	返回剩下的参数交给flag包处理
+ */
+func (self *Cmd) parseHeapOptions(args []string) []string {
+	rest := make([]string, 0, len(args))
+	i := 0
+	for ; i < len(args) && strings.HasPrefix(args[i], "-"); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "-Xms"):
+			self.xmsOption = mustParseMemorySize(arg, "initial")
+		case strings.HasPrefix(arg, "-Xmx"):
+			self.xmxOption = mustParseMemorySize(arg, "maximum")
+		case strings.HasPrefix(arg, "-Xmn"):
+			self.xmnOption = mustParseMemorySize(arg, "young generation")
+		case arg == "-classpath" || arg == "-cp" || arg == "-Xjre" || arg == "-dump":
+			//带值的参数，值原样留给flag包
+			rest = append(rest, arg)
+			if i + 1 < len(args) {
+				i++
+				rest = append(rest, args[i])
+			}
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	if self.xmsOption > 0 && self.xmxOption > 0 && self.xmsOption > self.xmxOption {
+		fmt.Println("Initial heap size set to a larger value than the maximum heap size")
+		os.Exit(1)
+	}
+	//主类名和它后面的参数
+	return append(rest, args[i:]...)
+}
+
+func mustParseMemorySize(arg, kind string) uint64 {
+	size, err := parseMemorySize(arg[len("-Xmx"):])
+	if err != nil {
+		fmt.Printf("Invalid %s heap size: %s\n", kind, arg)
+		os.Exit(1)
+	}
+	return size
+}
+
+/**
+	解析 64m 1g 512k 1024 这样的大小，后缀不区分大小写，没有后缀时单位是字节
+ */
+func parseMemorySize(s string) (uint64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+	multiplier := uint64(1)
+	switch s[len(s) - 1] {
+	case 'k', 'K':
+		multiplier = 1 << 10
+	case 'm', 'M':
+		multiplier = 1 << 20
+	case 'g', 'G':
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		s = s[:len(s) - 1]
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxUint64 / multiplier {
+		return 0, fmt.Errorf("size overflow: %s", s)
+	}
+	return n * multiplier, nil
+}
+
+func (self *Cmd) verifyFlag() bool {
+	return self.verifyAllFlag && !self.verifyNoneFlag
+}
+
+func (self *Cmd) assertionsEnabled() bool {
+	return self.enableAssertionsFlag && !self.disableAssertionsFlag
+}
+
 func printUsage() {
 	fmt.Printf("Usage: %s [-options] class [args...] \n", os.Args[0])
 }
@@ -56,6 +171,8 @@ func main() {
 	cmd := parseCmd()
 	if cmd.versionFlag {
 		fmt.Println("version 0.0.1")
+	} else if cmd.dumpOption != "" {
+		dumpClass(cmd, os.Stdout)
 	} else if cmd.helpFlag || cmd.class == "" {
 		printUsage()
 	} else {
@@ -67,7 +184,7 @@ func main() {
 func startJVM(cmd *Cmd) {
 	//第六节测试代码
 	cp := classpath.Parse(cmd.XjreOption, cmd.cpOption)
-	classLoader := heap.NewClassLoader(cp, cmd.verboseClassFlag)
+	classLoader := heap.NewClassLoader(nil, cp, cmd.verboseClassFlag, cmd.verifyFlag())
 
 	className := strings.Replace(cmd.class, ".", "/", -1)
 	mainClass := classLoader.LoadClass(className)
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"GoVM/chapter6-obj/heap"
+	"GoVM/internal/testjdk"
+	"reflect"
+	"testing"
+)
+
+func TestParseMemorySize(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want uint64
+	}{
+		{"1024", 1024},
+		{"512k", 512 << 10},
+		{"64m", 64 << 20},
+		{"64M", 64 << 20},
+		{"2g", 2 << 30},
+	} {
+		if got, err := parseMemorySize(test.s); err != nil || got != test.want {
+			t.Errorf("parseMemorySize(%q) = %d, %v, want %d", test.s, got, err, test.want)
+		}
+	}
+	for _, s := range []string{"", "m", "64x", "-1m", "99999999999g"} {
+		if _, err := parseMemorySize(s); err == nil {
+			t.Errorf("parseMemorySize(%q) did not fail", s)
+		}
+	}
+}
+
+func TestXmxSetsTheHeapLimit(t *testing.T) {
+	cmd := &Cmd{XjreOption: testjdk.JRE(t), cpOption: t.TempDir()}
+	rest := cmd.parseHeapOptions([]string{"-Xms16m", "-cp", "classes", "-Xmx64m", "Main", "-Xmx1g"})
+
+	//主类后面的参数是传给java程序的，原样保留
+	if want := []string{"-cp", "classes", "Main", "-Xmx1g"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("remaining args = %q, want %q", rest, want)
+	}
+	if cmd.xmxOption != 64 * 1024 * 1024 || cmd.xmsOption != 16 * 1024 * 1024 {
+		t.Fatalf("xms = %d, xmx = %d", cmd.xmsOption, cmd.xmxOption)
+	}
+
+	t.Cleanup(func() {
+		heap.InitHeap(0, 0)
+	})
+	newJVM(cmd)
+	if got := heap.GetHeap().MaxMemory(); got != 64 * 1024 * 1024 {
+		t.Errorf("MaxMemory() = %d, want %d", got, 64 * 1024 * 1024)
+	}
+}
+
+func TestXmnEnablesTheYoungGeneration(t *testing.T) {
+	cmd := &Cmd{XjreOption: testjdk.JRE(t), cpOption: t.TempDir()}
+	cmd.parseHeapOptions([]string{"-Xmn4m", "Main"})
+	if cmd.xmnOption != 4 * 1024 * 1024 {
+		t.Fatalf("xmn = %d", cmd.xmnOption)
+	}
+
+	t.Cleanup(func() {
+		heap.InitHeap(0, 0)
+	})
+	newJVM(cmd)
+	if got := heap.GetHeap().YoungSize(); got != 4 * 1024 * 1024 {
+		t.Errorf("YoungSize() = %d, want %d", got, 4 * 1024 * 1024)
+	}
+}
+
+func TestAssertionFlags(t *testing.T) {
+	for _, test := range []struct {
+		ea, da bool
+		want   bool
+	}{
+		{false, false, false},
+		{true, false, true},
+		{false, true, false},
+		{true, true, false},
+	} {
+		cmd := &Cmd{enableAssertionsFlag: test.ea, disableAssertionsFlag: test.da}
+		if got := cmd.assertionsEnabled(); got != test.want {
+			t.Errorf("-ea %v -da %v: assertionsEnabled() = %v, want %v", test.ea, test.da, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"GoVM/chapter2-class/classpath"
+	"GoVM/chapter3-cf/classfile"
+	"GoVM/chapter5-instructions"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/**
+	-dump <class> 模式：类似javap，打印class的结构之后退出，不执行main方法
+ */
+func dumpClass(cmd *Cmd, w io.Writer) {
+	cp := classpath.Parse(cmd.XjreOption, cmd.cpOption)
+	className := strings.Replace(cmd.dumpOption, ".", "/", -1)
+	cf := loadClass(className, cp)
+
+	fmt.Fprintf(w, "class %s\n", cf.ClassName())
+	fmt.Fprintf(w, "  version: %v.%v\n", cf.MajorVersion(), cf.MinorVersion())
+	fmt.Fprintf(w, "  access flags: 0x%04x\n", cf.AccessFlags())
+	fmt.Fprintf(w, "  super class: %v\n", cf.SuperClassName())
+	fmt.Fprintf(w, "  interfaces: %v\n", cf.InterfaceNames())
+
+	fmt.Fprintln(w, "Constant pool:")
+	cf.ConstantPool().Dump(w)
+
+	fmt.Fprintf(w, "Fields (%d):\n", len(cf.Fields()))
+	for _, f := range cf.Fields() {
+		fmt.Fprintf(w, "  0x%04x %s %s\n", f.AccessFlags(), f.Name(), f.Descriptor())
+	}
+
+	fmt.Fprintf(w, "Methods (%d):\n", len(cf.Methods()))
+	for _, m := range cf.Methods() {
+		dumpMethod(m, w)
+	}
+}
+
+func dumpMethod(m *chapter3_cf.MemberInfo, w io.Writer) {
+	fmt.Fprintf(w, "  0x%04x %s%s\n", m.AccessFlags(), m.Name(), m.Descriptor())
+	codeAttr := m.CodeAttribute()
+	if codeAttr == nil {
+		return
+	}
+	fmt.Fprintf(w, "    Code: stack=%d, locals=%d, length=%d\n",
+		codeAttr.MaxStack(), codeAttr.MaxLocals(), len(codeAttr.Code()))
+	chapter5_instructions.Disassemble(w, codeAttr.Code())
+}
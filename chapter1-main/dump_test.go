@@ -0,0 +1,50 @@
+package main
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/testjdk"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+/**
+	public class Hello implements Runnable {
+		private int count;
+		public void run() { count = count + 1; }
+		static String greet() { return "hello"; }
+	}
+ */
+func TestDumpPrintsClassStructure(t *testing.T) {
+	class := New("dump/Hello", "java/lang/Object", "java/lang/Runnable")
+	class.Field(ACC_PRIVATE, "count", "I")
+	class.Method(ACC_PUBLIC, "run", "()V").Code(3, 1).
+		Op(ALOAD_0).Op(ALOAD_0).Getfield("dump/Hello", "count", "I").Op(ICONST_1).Op(IADD).
+		Putfield("dump/Hello", "count", "I").Op(RETURN)
+	class.Method(ACC_STATIC, "greet", "()Ljava/lang/String;").Code(1, 0).LdcString("hello").Op(ARETURN)
+	dir := t.TempDir()
+	if err := testjdk.WriteClasses(dir, class); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	dumpClass(&Cmd{XjreOption: testjdk.JRE(t), cpOption: dir, dumpOption: "dump.Hello"}, &out)
+	for _, want := range []string{
+		"class dump/Hello\n",
+		"  super class: java/lang/Object\n",
+		"  interfaces: [java/lang/Runnable]\n",
+		"Constant pool:\n",
+		"Utf8               hello\n",
+		"// dump/Hello.count:I\n",
+		"Fields (1):\n  0x0002 count I\n",
+		"Methods (2):\n  0x0001 run()V\n    Code: stack=3, locals=1, length=",
+		"     0: aload_0\n",
+		"     2: getfield #6\n     5: iconst_1\n",
+		"  0x0008 greet()Ljava/lang/String;\n",
+		": areturn\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dump output is missing %q", want)
+		}
+	}
+}
@@ -26,7 +26,14 @@ func (self *Classpath) parseBootAndExtClasspath(jreOption string) {
 
 	//jre/lib/*
 	jreLibPath := filepath.Join(jreDir, "lib", "*")
-	self.bootClasspath = newWildcardEntry(jreLibPath)
+	bootClasspath := newWildcardEntry(jreLibPath)
+
+	//exploded 构建的 jre/modules/<module>/...
+	modulesPath := filepath.Join(jreDir, "modules")
+	if isDir(modulesPath) {
+		bootClasspath = append(bootClasspath, newModuleDirEntry(modulesPath))
+	}
+	self.bootClasspath = bootClasspath
 
 	//jre/lib/ext/*
 	jreExtPath := filepath.Join(jreDir, "lib", "ext", "*")
@@ -74,6 +81,19 @@ func (self *Classpath) ReadClass(className string) ([]byte, Entry, error) {
 	return self.userClasspath.readClass(className)
 }
 
+/**
+	和ReadClass一样的顺序查找资源文件，name不需要加.class后缀
+ */
+func (self *Classpath) ReadResource(name string) ([]byte, error) {
+	if data, err := self.bootClasspath.readResource(name); err == nil {
+		return data, nil
+	}
+	if data, err := self.extClasspath.readResource(name); err == nil {
+		return data, nil
+	}
+	return self.userClasspath.readResource(name)
+}
+
 func (self *Classpath) String() string {
 	return self.userClasspath.String()
 }
@@ -89,3 +109,11 @@ func exists(path string) bool {
 	}
 	return true
 }
+
+/**
+	判断路径是否是一个目录。Java 9+ 的 lib/modules 是jimage文件，不是目录
+ */
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,57 @@
+package classpath
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeJar(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadResourceFromDirAndJar(t *testing.T) {
+	dir, jar := t.TempDir(), filepath.Join(t.TempDir(), "app.jar")
+	writeFiles(t, dir, map[string]string{"config/app.properties": "from=dir"})
+	writeJar(t, jar, map[string]string{"config/jar.txt": "from=jar", "config/app.properties": "shadowed"})
+
+	for _, test := range []struct {
+		entry Entry
+		name  string
+		want  string
+	}{
+		{newDirEntry(dir), "config/app.properties", "from=dir"},
+		{newZipEntry(jar), "config/jar.txt", "from=jar"},
+	} {
+		if data, err := test.entry.readResource(test.name); err != nil || string(data) != test.want {
+			t.Errorf("%s: readResource(%s) = %q, %v, want %q", test.entry, test.name, data, err, test.want)
+		}
+		if _, err := test.entry.readResource("config/missing.txt"); err == nil {
+			t.Errorf("%s: readResource of a missing file did not fail", test.entry)
+		}
+	}
+
+	//类路径上按顺序查找，先找到的为准
+	cp := Parse(t.TempDir(), dir + pathListSeparator + jar)
+	for name, want := range map[string]string{"config/app.properties": "from=dir", "config/jar.txt": "from=jar"} {
+		if data, err := cp.ReadResource(name); err != nil || string(data) != want {
+			t.Errorf("ReadResource(%s) = %q, %v, want %q", name, data, err, want)
+		}
+	}
+}
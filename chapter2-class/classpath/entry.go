@@ -13,6 +13,8 @@ const pathListSeparator = string(os.PathListSeparator)
  */
 type Entry interface {
 	readClass(className string) ([]byte, Entry, error)
+	//读取类路径上的非class文件，name用/分隔，比如 config/app.properties
+	readResource(name string) ([]byte, error)
 	String() string
 }
 
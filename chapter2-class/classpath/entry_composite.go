@@ -20,6 +20,15 @@ func (self CompositeEntry) readClass(className string) ([]byte, Entry, error) {
 	return nil, nil, errors.New("class not found: " + className)
 }
 
+func (self CompositeEntry) readResource(name string) ([]byte, error) {
+	for _, entry := range self {
+		if data, err := entry.readResource(name); err == nil {
+			return data, nil
+		}
+	}
+	return nil, errors.New("resource not found: " + name)
+}
+
 func (self CompositeEntry) String() string {
 	strs := make([]string, len(self))
 	for i, entry := range self {
@@ -25,6 +25,10 @@ func (self *DirEntry) readClass(className string) ([]byte, Entry, error) {
 	return data, self, err
 }
 
+func (self *DirEntry) readResource(name string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(self.absDir, filepath.FromSlash(name)))
+}
+
 func (self *DirEntry) String() string {
 	return self.absDir
 }
\ No newline at end of file
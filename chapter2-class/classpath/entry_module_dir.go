@@ -0,0 +1,59 @@
+package classpath
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+)
+
+/**
+	Java 9+ exploded 构建的模块目录，比如 jdk/modules
+	根目录下每个子目录是一个模块（java.base, java.logging ...），模块目录里面才是包目录
+	modules 按模块名排序，查找顺序是确定的
+ */
+type ModuleDirEntry struct {
+	absDir  string
+	modules []*DirEntry
+}
+
+func newModuleDirEntry(path string) *ModuleDirEntry {
+	absDir, err := filepath.Abs(path)
+	if err != nil {
+		panic(err)
+	}
+	//ReadDir 返回的结果已经按文件名排好序了
+	infos, err := ioutil.ReadDir(absDir)
+	if err != nil {
+		panic(err)
+	}
+	entry := &ModuleDirEntry{absDir: absDir}
+	for _, info := range infos {
+		if info.IsDir() {
+			entry.modules = append(entry.modules, newDirEntry(filepath.Join(absDir, info.Name())))
+		}
+	}
+	return entry
+}
+
+func (self *ModuleDirEntry) readClass(className string) ([]byte, Entry, error) {
+	for _, module := range self.modules {
+		data, from, err := module.readClass(className)
+		if err == nil {
+			return data, from, nil
+		}
+	}
+	return nil, nil, errors.New("class not found: " + className)
+}
+
+func (self *ModuleDirEntry) readResource(name string) ([]byte, error) {
+	for _, module := range self.modules {
+		if data, err := module.readResource(name); err == nil {
+			return data, nil
+		}
+	}
+	return nil, errors.New("resource not found: " + name)
+}
+
+func (self *ModuleDirEntry) String() string {
+	return self.absDir
+}
@@ -0,0 +1,62 @@
+package classpath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestModuleDirEntryFindsClassInSecondModule(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"java.alpha/alpha/A.class":    "A",
+		"java.alpha/shared/S.class":   "S from alpha",
+		"java.beta/beta/Target.class": "Target",
+		"java.beta/shared/S.class":    "S from beta",
+		//模块根目录下的普通文件不是模块
+		"README": "",
+	})
+	entry := newModuleDirEntry(root)
+	if len(entry.modules) != 2 {
+		t.Fatalf("found %d modules, want 2", len(entry.modules))
+	}
+
+	data, from, err := entry.readClass("beta/Target.class")
+	if err != nil || string(data) != "Target" {
+		t.Fatalf("readClass(beta/Target) = %q, %v", data, err)
+	}
+	if want := filepath.Join(root, "java.beta"); from.String() != want {
+		t.Errorf("Target came from %s, want %s", from, want)
+	}
+	//两个模块里都有时，按模块名排序取第一个
+	if data, _, _ := entry.readClass("shared/S.class"); string(data) != "S from alpha" {
+		t.Errorf("shared/S = %q, want the copy in java.alpha", data)
+	}
+	if _, _, err := entry.readClass("missing/M.class"); err == nil {
+		t.Error("readClass of a missing class did not fail")
+	}
+}
+
+func TestParseAddsExplodedModulesToBootClasspath(t *testing.T) {
+	jre := t.TempDir()
+	writeFiles(t, jre, map[string]string{
+		"lib/ext/.keep":                            "",
+		"modules/java.base/java/lang/Object.class": "Object",
+	})
+	cp := Parse(jre, t.TempDir())
+	if data, _, err := cp.ReadClass("java/lang/Object"); err != nil || string(data) != "Object" {
+		t.Errorf("ReadClass(java/lang/Object) = %q, %v", data, err)
+	}
+}
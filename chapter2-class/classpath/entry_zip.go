@@ -12,30 +12,34 @@ type ZipEntry struct {
 }
 
 func (self *ZipEntry) readClass(className string) ([]byte, Entry, error) {
+	data, err := self.readResource(className)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, self, nil
+}
+
+func (self *ZipEntry) readResource(name string) ([]byte, error) {
 	//打开压缩文件,错误直接返回
 	r, err := zip.OpenReader(self.absPath)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer r.Close()
 
 	for _, f := range r.File {
-		if f.Name == className {
+		if f.Name == name {
 			//打开一个ReadCloser，用来获取文件的内容
 			rc, err := f.Open()
 			if err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 			defer rc.Close()
 			//这里用readAll，从Reader中
-			data, err := ioutil.ReadAll(rc)
-			if err != nil {
-				return nil, nil, err
-			}
-			return data, self, err
+			return ioutil.ReadAll(rc)
 		}
 	}
-	return nil, nil, errors.New("class not found: " + className)
+	return nil, errors.New("not found: " + name)
 }
 
 func (self *ZipEntry) String() string {
@@ -0,0 +1,46 @@
+package chapter3_cf
+
+/**
+	只出现在ClassFile结构中，记录invokedynamic指令用到的引导方法
+	BOOTSTRAP_METHODS_ATTRIBUTE {
+		u2 attribute_name_index;
+		u4 attribute_length;
+		u2 num_bootstrap_methods;
+		{
+			u2 bootstrap_method_ref; -> 指向一个CONSTANT_MethodHandle_info
+			u2 num_bootstrap_arguments;
+			u2 bootstrap_arguments[num_bootstrap_arguments]; -> 指向可以被ldc加载的常量
+		} bootstrap_methods[num_bootstrap_methods];
+	}
+ */
+type BootstrapMethodsAttribute struct {
+	bootstrapMethods []*BootstrapMethod
+}
+
+type BootstrapMethod struct {
+	bootstrapMethodRef uint16
+	bootstrapArguments []uint16
+}
+
+func (self *BootstrapMethodsAttribute) readInfo(reader *ClassReader) {
+	numBootstrapMethods := reader.readUint16()
+	self.bootstrapMethods = make([]*BootstrapMethod, numBootstrapMethods)
+	for i := range self.bootstrapMethods {
+		self.bootstrapMethods[i] = &BootstrapMethod{
+			bootstrapMethodRef:        reader.readUint16(),
+			bootstrapArguments:        reader.readUint16s(),
+		}
+	}
+}
+
+func (self *BootstrapMethodsAttribute) BootstrapMethods() []*BootstrapMethod {
+	return self.bootstrapMethods
+}
+
+func (self *BootstrapMethod) BootstrapMethodRef() uint16 {
+	return self.bootstrapMethodRef
+}
+
+func (self *BootstrapMethod) BootstrapArguments() []uint16 {
+	return self.bootstrapArguments
+}
@@ -80,6 +80,29 @@ func (self *CodeAttribute) LineNumberTableAttribute() *LineNumberTableAttribute
 	return nil
 }
 
+/**
+	jvms允许一个Code属性里有多个LineNumberTable，顺序也没有要求
+ */
+func (self *CodeAttribute) LineNumberTableAttributes() []*LineNumberTableAttribute {
+	var attrs []*LineNumberTableAttribute
+	for _, attrInfo := range self.attributes {
+		if attr, ok := attrInfo.(*LineNumberTableAttribute); ok {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+func (self *CodeAttribute) StackMapTableAttribute() *StackMapTableAttribute {
+	for _, attrInfo := range self.attributes {
+		switch attrInfo.(type) {
+		case *StackMapTableAttribute:
+			return attrInfo.(*StackMapTableAttribute)
+		}
+	}
+	return nil
+}
+
 /**
 	异常表
  */
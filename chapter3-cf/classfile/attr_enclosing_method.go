@@ -0,0 +1,32 @@
+package chapter3_cf
+
+/**
+	局部类和匿名类才有这个属性，指出包含它的类和方法
+	ENCLOSING_METHOD_ATTRIBUTE {
+		u2 attribute_name_index;
+		u4 attribute_length; -> 必须是4
+		u2 class_index;
+		u2 method_index; -> 不在方法里面（比如在字段初始化表达式里）时为0
+	}
+ */
+type EnclosingMethodAttribute struct {
+	cp          ConstantPool
+	classIndex  uint16
+	methodIndex uint16
+}
+
+func (self *EnclosingMethodAttribute) readInfo(reader *ClassReader) {
+	self.classIndex = reader.readUint16()
+	self.methodIndex = reader.readUint16()
+}
+
+func (self *EnclosingMethodAttribute) ClassName() string {
+	return self.cp.getClassName(self.classIndex)
+}
+
+func (self *EnclosingMethodAttribute) MethodNameAndDescriptor() (string, string) {
+	if self.methodIndex > 0 {
+		return self.cp.getNameAndType(self.methodIndex)
+	}
+	return "", ""
+}
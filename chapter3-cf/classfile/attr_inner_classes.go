@@ -0,0 +1,53 @@
+package chapter3_cf
+
+/**
+	记录嵌套类的信息
+	INNER_CLASSES_ATTRIBUTE {
+		u2 attribute_name_index;
+		u4 attribute_length;
+		u2 number_of_classes;
+		{
+			u2 inner_class_info_index;
+			u2 outer_class_info_index; -> 不是成员类（局部类、匿名类）时为0
+			u2 inner_name_index; -> 匿名类为0
+			u2 inner_class_access_flags;
+		} classes[number_of_classes];
+	}
+ */
+type InnerClassesAttribute struct {
+	cp      ConstantPool
+	classes []*InnerClassInfo
+}
+
+type InnerClassInfo struct {
+	innerClassInfoIndex   uint16
+	outerClassInfoIndex   uint16
+	innerNameIndex        uint16
+	innerClassAccessFlags uint16
+}
+
+func (self *InnerClassesAttribute) readInfo(reader *ClassReader) {
+	numberOfClasses := reader.readUint16()
+	self.classes = make([]*InnerClassInfo, numberOfClasses)
+	for i := range self.classes {
+		self.classes[i] = &InnerClassInfo{
+			innerClassInfoIndex:   reader.readUint16(),
+			outerClassInfoIndex:   reader.readUint16(),
+			innerNameIndex:        reader.readUint16(),
+			innerClassAccessFlags: reader.readUint16(),
+		}
+	}
+}
+
+/**
+	查找某个成员类的外部类的名字，不是成员类返回空字符串
+ */
+func (self *InnerClassesAttribute) OuterClassName(innerClassName string) string {
+	for _, info := range self.classes {
+		if info.innerClassInfoIndex != 0 && info.outerClassInfoIndex != 0 &&
+			self.cp.getClassName(info.innerClassInfoIndex) == innerClassName {
+			return self.cp.getClassName(info.outerClassInfoIndex)
+		}
+	}
+	return ""
+}
@@ -48,4 +48,16 @@ func (self *LineNumberTableAttribute) GetLineNumber(pc int) int {
 		}
 	}
 	return -1
-}
\ No newline at end of file
+}
+
+func (self *LineNumberTableAttribute) Entries() []*LineNumberTableEntry {
+	return self.lineNumberTable
+}
+
+func (self *LineNumberTableEntry) StartPc() uint16 {
+	return self.startPc
+}
+
+func (self *LineNumberTableEntry) LineNumber() uint16 {
+	return self.lineNumber
+}
@@ -0,0 +1,22 @@
+package chapter3_cf
+
+/**
+	可以出现在ClassFile、field_info和method_info结构中，记录泛型的类型信息
+	SIGNATURE_ATTRIBUTE {
+		u2 attribute_name_index;
+		u4 attribute_length; -> 必须是2
+		u2 signature_index; -> 指向一个UTF8常量，比如 (Ljava/util/List<Ljava/lang/String;>;)V
+	}
+ */
+type SignatureAttribute struct {
+	cp             ConstantPool
+	signatureIndex uint16
+}
+
+func (self *SignatureAttribute) readInfo(reader *ClassReader) {
+	self.signatureIndex = reader.readUint16()
+}
+
+func (self *SignatureAttribute) Signature() string {
+	return self.cp.getUtf8(self.signatureIndex)
+}
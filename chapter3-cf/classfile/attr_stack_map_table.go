@@ -0,0 +1,155 @@
+package chapter3_cf
+
+import "fmt"
+
+/**
+	StackMapTable属性，供类型检查验证器使用
+	STACK_MAP_TABLE_ATTRIBUTE {
+		u2 attribute_name_index;
+		u4 attribute_length;
+		u2 number_of_entries;
+		stack_map_frame entries[number_of_entries];
+	}
+	只有开启验证时才用得到，所以这里先保存原始数据，需要的时候再解析
+ */
+type StackMapTableAttribute struct {
+	length uint32
+	info   []byte
+}
+
+func (self *StackMapTableAttribute) readInfo(reader *ClassReader) {
+	self.info = reader.readBytes(self.length)
+}
+
+/**
+	verification_type_info的tag
+ */
+const (
+	ITEM_Top = 0
+	ITEM_Integer = 1
+	ITEM_Float = 2
+	ITEM_Double = 3
+	ITEM_Long = 4
+	ITEM_Null = 5
+	ITEM_UninitializedThis = 6
+	ITEM_Object = 7
+	ITEM_Uninitialized = 8
+)
+
+/**
+	verification_type_info，Object 带一个常量池索引，Uninitialized 带创建对象的new指令的偏移量
+ */
+type VerificationType struct {
+	tag   uint8
+	index uint16
+}
+
+func (self VerificationType) Tag() uint8 {
+	return self.tag
+}
+
+/**
+	Object 的常量池索引，或者 Uninitialized 的new指令偏移量
+ */
+func (self VerificationType) Index() uint16 {
+	return self.index
+}
+
+/**
+	一帧：它对应的字节码偏移量，以及这个位置上操作数栈里的类型
+	局部变量表的部分（chop、append、full_frame的locals）只解析不保存
+ */
+type StackMapFrame struct {
+	offset int
+	stack  []VerificationType
+}
+
+func (self StackMapFrame) Offset() int {
+	return self.offset
+}
+
+func (self StackMapFrame) Stack() []VerificationType {
+	return self.stack
+}
+
+/**
+	解析出每一个stack_map_frame
+	第一帧的偏移量就是offset_delta，后面每一帧是 上一帧偏移量 + offset_delta + 1
+ */
+func (self *StackMapTableAttribute) Frames() (frames []StackMapFrame, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			frames = nil
+			err = fmt.Errorf("malformed StackMapTable: %v", r)
+		}
+	}()
+
+	reader := &ClassReader{self.info}
+	count := reader.readUint16()
+	frames = make([]StackMapFrame, count)
+	offset := -1
+	for i := range frames {
+		offsetDelta, stack := readStackMapFrame(reader)
+		offset += int(offsetDelta) + 1
+		frames[i] = StackMapFrame{offset, stack}
+	}
+	if len(reader.data) != 0 {
+		panic("trailing bytes")
+	}
+	return frames, nil
+}
+
+/**
+	读取一个stack_map_frame，返回它的offset_delta和操作数栈
+	frame_type:
+		0-63      same_frame                                  栈为空
+		64-127    same_locals_1_stack_item_frame              栈里一项
+		247       same_locals_1_stack_item_frame_extended     栈里一项
+		248-250   chop_frame                                  栈为空
+		251       same_frame_extended                         栈为空
+		252-254   append_frame                                栈为空
+		255       full_frame
+ */
+func readStackMapFrame(reader *ClassReader) (uint16, []VerificationType) {
+	frameType := reader.readUint8()
+	switch {
+	case frameType <= 63:
+		return uint16(frameType), nil
+	case frameType <= 127:
+		return uint16(frameType - 64), readVerificationTypes(reader, 1)
+	case frameType == 247:
+		offsetDelta := reader.readUint16()
+		return offsetDelta, readVerificationTypes(reader, 1)
+	case frameType >= 248 && frameType <= 251:
+		return reader.readUint16(), nil
+	case frameType >= 252 && frameType <= 254:
+		offsetDelta := reader.readUint16()
+		readVerificationTypes(reader, int(frameType) - 251)
+		return offsetDelta, nil
+	case frameType == 255:
+		offsetDelta := reader.readUint16()
+		readVerificationTypes(reader, int(reader.readUint16()))
+		return offsetDelta, readVerificationTypes(reader, int(reader.readUint16()))
+	default:
+		panic(fmt.Sprintf("reserved frame type %d", frameType))
+	}
+}
+
+/**
+	verification_type_info: 0-6 没有附加数据，7和8后面是u2
+ */
+func readVerificationTypes(reader *ClassReader, count int) []VerificationType {
+	types := make([]VerificationType, count)
+	for i := range types {
+		tag := reader.readUint8()
+		switch {
+		case tag <= ITEM_UninitializedThis:
+			types[i] = VerificationType{tag: tag}
+		case tag == ITEM_Object || tag == ITEM_Uninitialized:
+			types[i] = VerificationType{tag, reader.readUint16()}
+		default:
+			panic(fmt.Sprintf("bad verification type tag %d", tag))
+		}
+	}
+	return types
+}
@@ -38,18 +38,28 @@ func readAttribute(reader *ClassReader, cp ConstantPool) AttributeInfo {
 
 func newAttributeInfo(attrName string, attrLen uint32, cp ConstantPool) AttributeInfo {
 	switch attrName {
+	case "BootstrapMethods":
+		return &BootstrapMethodsAttribute{}
 	case "Code":
 		return &CodeAttribute{cp:	cp}
 	case "ConstantValue":
 		return &ConstantValueAttribute{}
 	case "Deprecated":
 		return &DeprecatedAttribute{}
+	case "EnclosingMethod":
+		return &EnclosingMethodAttribute{cp: cp}
 	case "Exceptions":
 		return &ExceptionsAttribute{}
+	case "InnerClasses":
+		return &InnerClassesAttribute{cp: cp}
 	case "LineNumberTable":
 		return &LineNumberTableAttribute{}
 	//case "LocalVariableTable":
 	//	return &LocalVariableTableAttribute{}
+	case "StackMapTable":
+		return &StackMapTableAttribute{length: attrLen}
+	case "Signature":
+		return &SignatureAttribute{cp: cp}
 	case "SourceFile":
 		return &SourceFileAttribute{cp:	cp}
 	case "Synthetic":
@@ -33,11 +33,15 @@ func Parse(classData []byte) (cf *ClassFile, err error) {
 	return
 }
 
+//java9的module-info.class的access_flags
+const accModule = 0x8000
+
 func (this *ClassFile) read(reader *ClassReader) {
 	this.readAndCheckMagic(reader)
-	this.readAndCheckVersion(reader)
+	this.readVersion(reader)
 	this.constantPool = readConstantPool(reader)
 	this.accessFlags = reader.readUint16()
+	this.checkVersion()
 	this.thisClass = reader.readUint16()
 	this.superClass = reader.readUint16()
 	this.interfaces = reader.readUint16s()
@@ -56,12 +60,20 @@ func (this *ClassFile) readAndCheckMagic(reader *ClassReader) {
 	}
 }
 
-/**
-	检查字节码版本
- */
-func (this *ClassFile) readAndCheckVersion(reader *ClassReader) {
+func (this *ClassFile) readVersion(reader *ClassReader) {
 	this.minorVersion = reader.readUint16()
 	this.majorVersion = reader.readUint16()
+}
+
+/**
+	检查字节码版本，虚拟机只支持到java8（52）。
+	module-info.class（access_flags带ACC_MODULE）没有字节码，只是要能解析，所以不限制版本，
+	因此版本要等读完常量池和access_flags之后再检查
+ */
+func (this *ClassFile) checkVersion() {
+	if this.accessFlags & accModule != 0 && this.majorVersion >= 53 {
+		return
+	}
 	switch this.majorVersion {
 	case 45:
 		return
@@ -128,4 +140,44 @@ func (self *ClassFile) SourceFileAttribute() *SourceFileAttribute {
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (self *ClassFile) SignatureAttribute() *SignatureAttribute {
+	for _, attrInfo := range self.attributes {
+		switch attrInfo.(type) {
+		case *SignatureAttribute:
+			return attrInfo.(*SignatureAttribute)
+		}
+	}
+	return nil
+}
+
+func (self *ClassFile) InnerClassesAttribute() *InnerClassesAttribute {
+	for _, attrInfo := range self.attributes {
+		switch attrInfo.(type) {
+		case *InnerClassesAttribute:
+			return attrInfo.(*InnerClassesAttribute)
+		}
+	}
+	return nil
+}
+
+func (self *ClassFile) EnclosingMethodAttribute() *EnclosingMethodAttribute {
+	for _, attrInfo := range self.attributes {
+		switch attrInfo.(type) {
+		case *EnclosingMethodAttribute:
+			return attrInfo.(*EnclosingMethodAttribute)
+		}
+	}
+	return nil
+}
+
+func (self *ClassFile) BootstrapMethodsAttribute() *BootstrapMethodsAttribute {
+	for _, attrInfo := range self.attributes {
+		switch attrInfo.(type) {
+		case *BootstrapMethodsAttribute:
+			return attrInfo.(*BootstrapMethodsAttribute)
+		}
+	}
+	return nil
+}
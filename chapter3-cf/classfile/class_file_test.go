@@ -0,0 +1,84 @@
+package chapter3_cf
+
+import (
+	"GoVM/internal/classgen"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+/**
+	javac编出来的module-info.class的样子：
+		module com.example { exports com/example/api; }
+	版本53，access_flags是ACC_MODULE，super_class是0，Module属性里引用了CONSTANT_Module和CONSTANT_Package
+ */
+func moduleInfo() (class *classgen.Class, module, pkg uint16) {
+	class = classgen.New("module-info", "")
+	class.SetAccessFlags(classgen.ACC_MODULE).SetMajorVersion(53)
+	module = class.RawConstant(classgen.CONSTANT_Module, u2(class.Utf8("com.example")))
+	pkg = class.RawConstant(classgen.CONSTANT_Package, u2(class.Utf8("com/example/api")))
+	//Module属性：名字、flags、版本，requires 0个，exports 1个，opens、uses、provides 0个
+	class.Attribute("Module", u2(module, 0, 0, 0, 1, pkg, 0, 0, 0, 0, 0))
+	return
+}
+
+func u2(values ...uint16) []byte {
+	var b []byte
+	for _, v := range values {
+		b = append(b, byte(v >> 8), byte(v))
+	}
+	return b
+}
+
+func TestParseModuleInfo(t *testing.T) {
+	class, module, pkg := moduleInfo()
+	cf, err := Parse(class.Bytes())
+	if err != nil {
+		t.Fatalf("Parse(module-info.class) failed: %v", err)
+	}
+	if cf.MajorVersion() != 53 || cf.ClassName() != "module-info" || cf.SuperClassName() != "" {
+		t.Errorf("parsed version %d, class %q, super %q", cf.MajorVersion(), cf.ClassName(), cf.SuperClassName())
+	}
+
+	cp := cf.ConstantPool()
+	if info, ok := cp.GetConstantInfo(module).(*ConstantModuleInfo); !ok || info.Name() != "com.example" {
+		t.Errorf("constant #%d = %#v, want Module com.example", module, cp.GetConstantInfo(module))
+	}
+	if info, ok := cp.GetConstantInfo(pkg).(*ConstantPackageInfo); !ok || info.Name() != "com/example/api" {
+		t.Errorf("constant #%d = %#v, want Package com/example/api", pkg, cp.GetConstantInfo(pkg))
+	}
+
+	var dump bytes.Buffer
+	cp.Dump(&dump)
+	for _, want := range []string{"= Module", "// com.example", "= Package", "// com/example/api"} {
+		if !strings.Contains(dump.String(), want) {
+			t.Errorf("constant pool dump has no %q:\n%s", want, dump.String())
+		}
+	}
+}
+
+/**
+	只有module-info不限制版本，普通的类还是只支持到java8
+ */
+func TestVersionCheck(t *testing.T) {
+	for _, test := range []struct {
+		flags   uint16
+		version uint16
+		ok      bool
+	}{
+		{classgen.ACC_MODULE, 53, true},
+		{classgen.ACC_MODULE, 61, true},
+		{classgen.ACC_PUBLIC | classgen.ACC_SUPER, 52, true},
+		{classgen.ACC_PUBLIC | classgen.ACC_SUPER, 53, false},
+	} {
+		class := classgen.New("version/C", "java/lang/Object")
+		class.SetAccessFlags(test.flags).SetMajorVersion(test.version)
+		_, err := Parse(class.Bytes())
+		if (err == nil) != test.ok {
+			t.Errorf("flags %#x, version %d: err = %v, want ok = %v", test.flags, test.version, err, test.ok)
+		}
+		if err != nil && err.Error() != "java.lang.UnsupportedClassVersionError!" {
+			t.Errorf("flags %#x, version %d: err = %v", test.flags, test.version, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package chapter3_cf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+/**
+	仿照javap -v的格式输出常量池，比如：
+	   #1 = Methodref          #6.#15         // java/lang/Object."<init>":()V
+ */
+func (self ConstantPool) Dump(w io.Writer) {
+	for i := 1; i < len(self); i++ {
+		if self[i] == nil {
+			//long和double后面的那个位置
+			continue
+		}
+		kind, args, comment := self.describe(self[i])
+		line := fmt.Sprintf("%5s = %-18s %s", "#" + strconv.Itoa(i), kind, args)
+		if comment != "" {
+			line = fmt.Sprintf("%-40s // %s", line, comment)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func (self ConstantPool) describe(cpInfo ConstantInfo) (kind, args, comment string) {
+	switch info := cpInfo.(type) {
+	case *ConstantUtf8Info:
+		return "Utf8", info.str, ""
+	case *ConstantIntegerInfo:
+		return "Integer", strconv.Itoa(int(info.val)), ""
+	case *ConstantFloatInfo:
+		return "Float", fmt.Sprintf("%vf", info.val), ""
+	case *ConstantLongInfo:
+		return "Long", fmt.Sprintf("%dl", info.val), ""
+	case *ConstantDoubleInfo:
+		return "Double", fmt.Sprintf("%vd", info.val), ""
+	case *ConstantStringInfo:
+		return "String", fmt.Sprintf("#%d", info.stringIndex), info.String()
+	case *ConstantClassInfo:
+		return "Class", fmt.Sprintf("#%d", info.nameIndex), info.Name()
+	case *ConstantNameAndTypeInfo:
+		return "NameAndType", fmt.Sprintf("#%d:#%d", info.nameIndex, info.descriptorIndex),
+			self.getUtf8(info.nameIndex) + ":" + self.getUtf8(info.descriptorIndex)
+	case *ConstantFieldrefInfo:
+		return "Fieldref", memberRefArgs(&info.ConstantMemberrefInfo), memberRefComment(&info.ConstantMemberrefInfo)
+	case *ConstantMethodrefInfo:
+		return "Methodref", memberRefArgs(&info.ConstantMemberrefInfo), memberRefComment(&info.ConstantMemberrefInfo)
+	case *ConstantInterfaceMethodrefInfo:
+		return "InterfaceMethodref", memberRefArgs(&info.ConstantMemberrefInfo), memberRefComment(&info.ConstantMemberrefInfo)
+	case *ConstantMethodTypeInfo:
+		return "MethodType", fmt.Sprintf("#%d", info.descriptorIndex), self.getUtf8(info.descriptorIndex)
+	case *ConstantMethodHandleInfo:
+		return "MethodHandle", fmt.Sprintf("%d:#%d", info.referenceKind, info.referenceIndex), ""
+	case *ConstantInvokeDynamicInfo:
+		name, descriptor := self.getNameAndType(info.nameAndTypeIndex)
+		return "InvokeDynamic", fmt.Sprintf("#%d:#%d", info.bootstrapMethodAttrIndex, info.nameAndTypeIndex),
+			name + ":" + descriptor
+	case *ConstantModuleInfo:
+		return "Module", fmt.Sprintf("#%d", info.nameIndex), info.Name()
+	case *ConstantPackageInfo:
+		return "Package", fmt.Sprintf("#%d", info.nameIndex), info.Name()
+	default:
+		return fmt.Sprintf("%T", info), "", ""
+	}
+}
+
+func memberRefArgs(info *ConstantMemberrefInfo) string {
+	return fmt.Sprintf("#%d.#%d", info.classIndex, info.nameAndTypeIndex)
+}
+
+func memberRefComment(info *ConstantMemberrefInfo) string {
+	name, descriptor := info.NameAndDescriptor()
+	return info.ClassName() + "." + name + ":" + descriptor
+}
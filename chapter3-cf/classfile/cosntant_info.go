@@ -22,6 +22,8 @@ const (
 	CONSTANT_MethodHandle = 15
 	CONSTANT_MethodType = 16
 	CONSTANT_InvokeDynamic = 18
+	CONSTANT_Module = 19
+	CONSTANT_Package = 20
 )
 
 /**
@@ -82,11 +84,15 @@ func newConstantInfo(tag uint8, cp ConstantPool) ConstantInfo {
 	case CONSTANT_NameAndType:
 		return &ConstantNameAndTypeInfo{}
 	case CONSTANT_MethodType:
-		return &ConstantMethodTypeInfo{}
+		return &ConstantMethodTypeInfo{cp:	cp}
 	case CONSTANT_MethodHandle:
 		return &ConstantMethodHandleInfo{}
 	case CONSTANT_InvokeDynamic:
-		return &ConstantInvokeDynamicInfo{}
+		return &ConstantInvokeDynamicInfo{cp:	cp}
+	case CONSTANT_Module:
+		return &ConstantModuleInfo{cp:	cp}
+	case CONSTANT_Package:
+		return &ConstantPackageInfo{cp:	cp}
 	default:
 		panic("java.lang.ClassFormatError: constant pool tag!")
 	}
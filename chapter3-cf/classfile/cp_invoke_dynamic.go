@@ -19,17 +19,19 @@ type ConstantMethodHandleInfo struct {
 	}
 */
 type ConstantMethodTypeInfo struct {
+	cp              ConstantPool
 	descriptorIndex uint16
 }
 
 /*
 	CONSTANT_InvokeDynamic_info {
 	    u1 tag;
-	    u2 bootstrap_method_attr_index;
+	    u2 bootstrap_method_attr_index; -> BootstrapMethods属性里的下标
 	    u2 name_and_type_index;
 	}
 */
 type ConstantInvokeDynamicInfo struct {
+	cp                       ConstantPool
 	bootstrapMethodAttrIndex uint16
 	nameAndTypeIndex         uint16
 }
@@ -46,4 +48,24 @@ func (self *ConstantMethodTypeInfo) readInfo(reader *ClassReader) {
 func (self *ConstantInvokeDynamicInfo) readInfo(reader *ClassReader) {
 	self.bootstrapMethodAttrIndex = reader.readUint16()
 	self.nameAndTypeIndex = reader.readUint16()
-}
\ No newline at end of file
+}
+
+func (self *ConstantMethodHandleInfo) ReferenceKind() uint8 {
+	return self.referenceKind
+}
+
+func (self *ConstantMethodHandleInfo) ReferenceIndex() uint16 {
+	return self.referenceIndex
+}
+
+func (self *ConstantMethodTypeInfo) Descriptor() string {
+	return self.cp.getUtf8(self.descriptorIndex)
+}
+
+func (self *ConstantInvokeDynamicInfo) BootstrapMethodAttrIndex() uint16 {
+	return self.bootstrapMethodAttrIndex
+}
+
+func (self *ConstantInvokeDynamicInfo) NameAndDescriptor() (string, string) {
+	return self.cp.getNameAndType(self.nameAndTypeIndex)
+}
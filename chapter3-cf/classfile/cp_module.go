@@ -0,0 +1,41 @@
+package chapter3_cf
+
+/**
+	java9开始，只在module-info.class里出现
+	CONSTANT_MODULE_INFO {
+		u1 tag;
+		u2 name_index;
+	}
+	CONSTANT_PACKAGE_INFO {
+		u1 tag;
+		u2 name_index;
+	}
+ */
+type ConstantModuleInfo struct {
+	cp        ConstantPool
+	nameIndex uint16
+}
+
+func (self *ConstantModuleInfo) readInfo(reader *ClassReader) {
+	self.nameIndex = reader.readUint16()
+}
+
+func (self *ConstantModuleInfo) Name() string {
+	return self.cp.getUtf8(self.nameIndex)
+}
+
+/**
+	包名是内部形式，比如 java/lang
+ */
+type ConstantPackageInfo struct {
+	cp        ConstantPool
+	nameIndex uint16
+}
+
+func (self *ConstantPackageInfo) readInfo(reader *ClassReader) {
+	self.nameIndex = reader.readUint16()
+}
+
+func (self *ConstantPackageInfo) Name() string {
+	return self.cp.getUtf8(self.nameIndex)
+}
@@ -66,4 +66,24 @@ func (this *MemberInfo) ConstantValueAttribute() *ConstantValueAttribute {
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func (this *MemberInfo) ExceptionsAttribute() *ExceptionsAttribute {
+	for _, attrInfo := range this.attributes {
+		switch attrInfo.(type) {
+		case *ExceptionsAttribute :
+			return attrInfo.(*ExceptionsAttribute)
+		}
+	}
+	return nil
+}
+
+func (this *MemberInfo) SignatureAttribute() *SignatureAttribute {
+	for _, attrInfo := range this.attributes {
+		switch attrInfo.(type) {
+		case *SignatureAttribute :
+			return attrInfo.(*SignatureAttribute)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package chapter4_rtdt
+
+import (
+	"GoVM/chapter6-obj/heap"
+	"fmt"
+	"os"
+)
+
+/**
+	异常发生时java虚拟机栈中一帧的信息，存放在异常对象的extra字段里
+ */
+type StackTraceElement struct {
+	fileName   string
+	className  string
+	methodName string
+	lineNumber int
+}
+
+func newStackTraceElement(frame *Frame) *StackTraceElement {
+	method := frame.Method()
+	class := method.Class()
+
+	return &StackTraceElement{
+		fileName:	class.SourceFile(),
+		className:	class.JavaName(),
+		methodName:	method.Name(),
+		lineNumber:	method.GetLineNumber(frame.NextPC() - 1),
+	}
+}
+
+func (self *StackTraceElement) FileName() string {
+	return self.fileName
+}
+
+func (self *StackTraceElement) ClassName() string {
+	return self.className
+}
+
+func (self *StackTraceElement) MethodName() string {
+	return self.methodName
+}
+
+func (self *StackTraceElement) LineNumber() int {
+	return self.lineNumber
+}
+
+func (self *StackTraceElement) String() string {
+	return fmt.Sprintf("%s.%s(%s:%d)",
+		self.className, self.methodName, self.fileName, self.lineNumber)
+}
+
+/**
+	从栈顶开始跳过skip帧，记录剩下每一帧的信息
+ */
+func (self *Thread) StackTrace(skip int) []*StackTraceElement {
+	frames := self.GetFrames()[skip:]
+
+	stes := make([]*StackTraceElement, len(frames))
+	for i, frame := range frames {
+		stes[i] = newStackTraceElement(frame)
+	}
+	return stes
+}
+
+/**
+	给本地方法用的：创建异常对象，记录栈信息，然后和athrow一样开始查找异常处理器
+	调用之后当前帧可能已经被弹出了，本地方法要直接返回，不能再往操作数栈里压返回值
+ */
+func (self *Frame) ThrowException(className, message string) {
+	loader := self.method.Class().Loader()
+	ex := heap.NewException(loader.LoadClass(className), message)
+	ex.SetExtra(self.thread.StackTrace(0))
+	self.thread.ThrowException(ex)
+}
+
+/**
+	athrow和本地方法抛出的异常都在这里处理，找不到异常处理器就打印栈信息，虚拟机栈清空之后解释器也就停止了
+ */
+func (self *Thread) ThrowException(ex *heap.Object) {
+	if !self.findAndGotoExceptionHandler(ex) {
+		self.handleUncaughtException(ex)
+	}
+}
+
+/**
+	从当前帧开始寻找方法的异常处理表，如果找不到，弹出栈帧继续寻找
+	如果找到了，跳转到异常处理之前，先把栈帧的操作数栈清空，然后把异常对象引用推入栈顶
+
+ */
+func (self *Thread) findAndGotoExceptionHandler(ex *heap.Object) bool {
+	for {
+		frame := self.CurrentFrame()
+		pc := frame.NextPC() - 1
+
+		handlerPc := frame.Method().FindExceptionHandler(ex.Class(), pc)
+		//异常处理器可能就在pc 0处，所以要用 >= 0 判断
+		//同步块的monitorexit处理器执行完之后会再次athrow，从新的pc重新查找，
+		//这样嵌套的synchronized块会由内向外依次释放锁
+		if handlerPc >= 0 {
+			stack := frame.OperandStack()
+			stack.Clear()
+			stack.PushRef(ex)
+			frame.SetNextPC(handlerPc)
+			return true
+		}
+
+		self.unwindFrame()
+		if self.IsStackEmpty() {
+			break
+		}
+	}
+	return false
+}
+
+/**
+	把虚拟机栈清空，把异常信息打印到标准错误
+	异常对象的extra字段中，存放的就是java虚拟机栈信息
+ */
+func (self *Thread) handleUncaughtException(ex *heap.Object) {
+	self.ClearStack()
+
+	msg := "Exception in thread \"" + self.Name() + "\" " + ex.Class().JavaName()
+	if jMsg := ex.GetRefVar("detailMessage", "Ljava/lang/String;"); jMsg != nil {
+		msg += ": " + heap.GoString(jMsg)
+	}
+	fmt.Fprintln(os.Stderr, msg)
+
+	stes, _ := ex.Extra().([]*StackTraceElement)
+	for _, ste := range stes {
+		fmt.Fprintln(os.Stderr, "\tat " + ste.String())
+	}
+}
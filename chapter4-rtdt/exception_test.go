@@ -0,0 +1,52 @@
+package chapter4_rtdt
+
+import (
+	"GoVM/chapter2-class/classpath"
+	"GoVM/chapter6-obj/heap"
+	"GoVM/internal/classgen"
+	"GoVM/internal/testjdk"
+	"testing"
+)
+
+func newTestLoader(t *testing.T, classes ...*classgen.Class) *heap.ClassLoader {
+	jre := testjdk.JRE(t)
+	boot := heap.NewClassLoader(nil, classpath.Parse(jre, jre), false, false)
+	return heap.NewClassLoader(boot, testjdk.Classpath(t, classes...), false, false)
+}
+
+/**
+	异常处理器在pc 0：方法开始时栈是空的，处理器开始时栈里有异常对象，通不过栈深度的验证，
+	所以像 -Xverify:none 一样关掉验证，也不执行字节码，直接在athrow之后的位置抛异常
+ */
+func TestThrowExceptionFindsHandlerAtPCZero(t *testing.T) {
+	class := classgen.New("ex/Zero", "java/lang/Object")
+	class.Method(classgen.ACC_STATIC, "run", "()V").Code(2, 0).
+		Label("start").
+		Op(classgen.POP).
+		New("java/lang/RuntimeException").Op(classgen.DUP).
+		Invokespecial("java/lang/RuntimeException", "<init>", "()V").
+		Op(classgen.ATHROW).
+		Label("end").Op(classgen.RETURN).
+		Catch("start", "end", "start", "java/lang/RuntimeException")
+	heap.DisableMethodVerification()
+	loader := newTestLoader(t, class)
+	method := loader.LoadClass("ex/Zero").GetStaticMethod("run", "()V")
+
+	thread := NewThread()
+	frame := thread.NewFrame(method)
+	thread.PushFrame(frame)
+	//athrow在pc 8，执行时nextPC已经是9
+	frame.SetNextPC(9)
+	ex := loader.LoadClass("java/lang/RuntimeException").NewObject()
+	thread.ThrowException(ex)
+
+	if thread.IsStackEmpty() || thread.CurrentFrame() != frame {
+		t.Fatal("handler at pc 0 was not found")
+	}
+	if frame.NextPC() != 0 {
+		t.Errorf("nextPC = %d, want 0", frame.NextPC())
+	}
+	if top := frame.OperandStack().PopRef(); top != ex {
+		t.Error("the exception is not on top of the handler's stack")
+	}
+}
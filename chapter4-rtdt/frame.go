@@ -1,6 +1,9 @@
 package chapter4_rtdt
 
-import "GoVM/chapter6-obj/heap"
+import (
+	"fmt"
+	"GoVM/chapter6-obj/heap"
+)
 
 /**
 	栈帧
@@ -16,9 +19,28 @@ type Frame struct {
 	thread       *Thread
 	method       *heap.Method
 	nextPC       int
+	//这个栈帧里monitorenter还没有monitorexit的次数，只在 -Xcheck:monitors 时记录
+	heldMonitors int
+	//synchronized方法进入的监视器（this或者类对象），栈帧弹出时退出
+	methodMonitor *heap.Object
+	//这一帧正常返回时完成初始化的类：<clinit>所属的类，以及在等这个<clinit>、自己没有<clinit>的类
+	pendingInits []*heap.Class
+}
+
+/**
+	-Xcheck:monitors，方法正常返回时还持有自己进入的监视器，就抛出IllegalMonitorStateException
+	用来发现不配对的monitorenter/monitorexit字节码。因为异常退出时不检查，javac生成的异常处理器会释放监视器，
+	没有处理器的话异常本身就已经说明了问题
+ */
+var checkMonitors = false
+
+func EnableMonitorCheck() {
+	checkMonitors = true
 }
 
 func newFrame(thread *Thread, method *heap.Method) *Frame {
+	//方法第一次执行之前验证操作数栈深度
+	method.EnsureVerified()
 	return &Frame{
 		thread:        thread,
 		localVars:        newLocalVars(method.MaxLocals()),
@@ -56,4 +78,55 @@ func (self *Frame) SetNextPC(pc int) {
 
 func (self *Frame) Thread() *Thread {
 	return self.thread
-}
\ No newline at end of file
+}
+
+/**
+	synchronized方法的参数放进局部变量表之后调用：实例方法进入this的监视器，静态方法进入类对象的监视器
+	不管方法是正常返回还是因为异常退出，PopFrame都会退出这个监视器
+ */
+func (self *Frame) EnterMethodMonitor() {
+	if !self.method.IsSynchronized() {
+		return
+	}
+	if self.method.IsStatic() {
+		self.methodMonitor = self.method.Class().JClass()
+	} else {
+		self.methodMonitor = self.localVars.GetThis()
+	}
+	self.methodMonitor.EnterMonitor()
+}
+
+/**
+	这一帧正常返回时class完成初始化，因为异常弹出时class初始化失败
+ */
+func (self *Frame) InitOnReturn(class *heap.Class) {
+	self.pendingInits = append(self.pendingInits, class)
+}
+
+func (self *Frame) RecordMonitorEnter() {
+	if checkMonitors {
+		self.heldMonitors++
+	}
+}
+
+func (self *Frame) RecordMonitorExit() {
+	if checkMonitors && self.heldMonitors > 0 {
+		self.heldMonitors--
+	}
+}
+
+/**
+	xRETURN指令在弹出栈帧之前调用，返回false表示已经在当前帧抛出了IllegalMonitorStateException，指令要直接返回
+	抛出之后计数清零，异常处理器里再次返回时不会重复报告
+ */
+func (self *Frame) CheckMonitorBalance() bool {
+	if checkMonitors && self.heldMonitors > 0 {
+		method := self.method
+		msg := fmt.Sprintf("%s.%s%s exited with %d unreleased monitor(s)",
+			method.Class().JavaName(), method.Name(), method.Descriptor(), self.heldMonitors)
+		self.heldMonitors = 0
+		self.ThrowException("java/lang/IllegalMonitorStateException", msg)
+		return false
+	}
+	return true
+}
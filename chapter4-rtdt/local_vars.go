@@ -80,4 +80,11 @@ func (self LocalVars) SetSlot(index uint, slot heap.Slot) {
 
 func (self LocalVars) GetSlot(index uint) heap.Slot {
 	return self[index]
-}
\ No newline at end of file
+}
+
+/**
+	ret用，返回地址是astore从操作数栈整个搬过来的slot
+ */
+func (self LocalVars) GetReturnAddress(index uint) int {
+	return int(self[index].Num)
+}
@@ -1,6 +1,7 @@
 package chapter4_rtdt
 
 import (
+	"fmt"
 	"math"
 	"GoVM/chapter6-obj/heap"
 )
@@ -12,13 +13,32 @@ type OperandStack struct {
 	slots []heap.Slot
 }
 
+/**
+	maxStack 为0时也返回一个空栈，这样对它的push同样会经过溢出检查
+ */
 func newOperandStack(maxStack uint) *OperandStack {
-	if maxStack > 0 {
-		return &OperandStack{
-			slots:        make([]heap.Slot, maxStack),
-		}
+	return &OperandStack{
+		slots:        make([]heap.Slot, maxStack),
+	}
+}
+
+/**
+	压入n个slot之前检查是否会超过方法声明的maxStack。
+	class文件声明的maxStack偏小时，抛出VerifyError，而不是让Go的切片越界
+ */
+func (self *OperandStack) checkOverflow(n uint) {
+	if self.size + n > uint(len(self.slots)) {
+		panic(fmt.Sprintf("java.lang.VerifyError: Operand stack overflow (max stack %d)", len(self.slots)))
+	}
+}
+
+/**
+	一次弹出n个slot之前检查栈里是否有这么多，不够时抛出VerifyError
+ */
+func (self *OperandStack) checkUnderflow(n uint) {
+	if n > self.size {
+		panic(fmt.Sprintf("java.lang.VerifyError: Operand stack underflow (popping %d of %d)", n, self.size))
 	}
-	return nil
 }
 
 func (self *OperandStack) Clear() {
@@ -51,6 +71,7 @@ func (self *OperandStack) PopBoolean() bool {
 
 //int操作
 func (self *OperandStack) PushInt(val int32) {
+	self.checkOverflow(1)
 	self.slots[self.size].Num = val
 	self.size++
 }
@@ -62,6 +83,7 @@ func (self *OperandStack) PopInt() int32 {
 
 //float
 func (self *OperandStack) PushFloat(val float32) {
+	self.checkOverflow(1)
 	bits := math.Float32bits(val)
 	self.slots[self.size].Num = int32(bits)
 	self.size++
@@ -75,6 +97,7 @@ func (self *OperandStack) PopFloat() float32 {
 
 //long变量入栈时，要拆成两个int。弹出时也弹两个int
 func (self *OperandStack) PushLong(val int64) {
+	self.checkOverflow(2)
 	self.slots[self.size].Num = int32(val)
 	self.slots[self.size + 1].Num = int32(val >> 32)
 	self.size += 2
@@ -100,6 +123,7 @@ func (self *OperandStack) PopDouble() float64 {
 
 //引用类型
 func (self *OperandStack) PushRef(ref *heap.Object) {
+	self.checkOverflow(1)
 	self.slots[self.size].Ref = ref
 	self.size++
 }
@@ -111,8 +135,16 @@ func (self *OperandStack) PopRef() *heap.Object {
 	return ref
 }
 
+//returnAddress，jsr/jsr_w压入的返回地址。和int一样放在Num里，Ref为nil
+func (self *OperandStack) PushReturnAddress(pc int) {
+	self.checkOverflow(1)
+	self.slots[self.size] = heap.Slot{Num: int32(pc)}
+	self.size++
+}
+
 //Slot
 func (self *OperandStack) PushSlot(slot heap.Slot) {
+	self.checkOverflow(1)
 	self.slots[self.size] = slot
 	self.size++
 }
@@ -120,4 +152,27 @@ func (self *OperandStack) PushSlot(slot heap.Slot) {
 func (self *OperandStack) PopSlot() heap.Slot {
 	self.size--
 	return self.slots[self.size]
+}
+
+/**
+	一次弹出栈顶的n个slot，返回的顺序和入栈顺序相同（最先入栈的在下标0）
+	返回的是新分配的拷贝，只给需要自己保存这些slot的地方用（比如invokedynamic捕获的参数）
+ */
+func (self *OperandStack) PopSlots(n uint) heap.Slots {
+	slots := make(heap.Slots, n)
+	self.PopSlotsTo(slots, n)
+	return slots
+}
+
+/**
+	弹出栈顶的n个slot，按入栈顺序直接拷贝到dst开头，不分配内存。方法调用时用它把参数放进新栈帧的局部变量表
+	栈上对应位置的引用会被清掉，否则GC会以为它们还活着
+ */
+func (self *OperandStack) PopSlotsTo(dst heap.Slots, n uint) {
+	self.checkUnderflow(n)
+	self.size -= n
+	dst.CopyFrom(0, self.slots, self.size, n)
+	for i := self.size; i < self.size + n; i++ {
+		self.slots[i].Ref = nil
+	}
 }
\ No newline at end of file
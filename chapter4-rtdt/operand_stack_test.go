@@ -0,0 +1,93 @@
+package chapter4_rtdt
+
+import (
+	"GoVM/chapter6-obj/heap"
+	"strings"
+	"testing"
+)
+
+func pushPanic(push func()) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	push()
+	return nil
+}
+
+func TestOperandStackOverflowIsVerifyError(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		maxStack uint
+		push     func(*OperandStack)
+	}{
+		{"int", 1, func(s *OperandStack) { s.PushInt(1); s.PushInt(2) }},
+		{"ref", 1, func(s *OperandStack) { s.PushRef(nil); s.PushRef(nil) }},
+		//long占两个slot，只剩一个时也不能压入
+		{"long", 2, func(s *OperandStack) { s.PushInt(1); s.PushLong(2) }},
+		{"double", 1, func(s *OperandStack) { s.PushDouble(1) }},
+		{"empty", 0, func(s *OperandStack) { s.PushFloat(1) }},
+	} {
+		stack := newOperandStack(test.maxStack)
+		r := pushPanic(func() { test.push(stack) })
+		if msg, _ := r.(string); !strings.HasPrefix(msg, "java.lang.VerifyError: Operand stack overflow") {
+			t.Errorf("%s: panic = %v, want a VerifyError", test.name, r)
+		}
+	}
+
+	stack := newOperandStack(3)
+	if r := pushPanic(func() { stack.PushLong(1); stack.PushInt(2) }); r != nil {
+		t.Errorf("pushing exactly maxStack slots panicked: %v", r)
+	}
+}
+
+func TestPopSlotsKeepsPushOrder(t *testing.T) {
+	a, b := &heap.Object{}, &heap.Object{}
+	stack := newOperandStack(6)
+	stack.PushRef(a)
+	stack.PushInt(3)
+	stack.PushLong(-2)
+	stack.PushRef(b)
+
+	slots := stack.PopSlots(4)
+	if stack.size != 1 || stack.slots[0].Ref != a {
+		t.Fatalf("stack after PopSlots(4): size %d", stack.size)
+	}
+	if slots.GetInt(0) != 3 || slots.GetLong(1) != -2 || slots.GetRef(3) != b {
+		t.Errorf("PopSlots(4) = %v, want [3, -2, b] in push order", slots)
+	}
+	//弹出的位置不能再引用对象，否则GC会把它们当成还活着
+	for i := 1; i < len(stack.slots); i++ {
+		if stack.slots[i].Ref != nil {
+			t.Errorf("popped slot %d still holds a ref", i)
+		}
+	}
+}
+
+func TestPopSlotsToCopiesWithoutAllocating(t *testing.T) {
+	a := &heap.Object{}
+	stack := newOperandStack(4)
+	locals := newLocalVars(4)
+	allocs := testing.AllocsPerRun(100, func() {
+		stack.PushInt(7)
+		stack.PushRef(a)
+		stack.PopSlotsTo(heap.Slots(locals), 2)
+	})
+	if allocs != 0 {
+		t.Errorf("PopSlotsTo allocated %v times per call", allocs)
+	}
+	if stack.size != 0 || locals.GetInt(0) != 7 || locals.GetRef(1) != a {
+		t.Errorf("after PopSlotsTo: size %d, locals %v", stack.size, locals)
+	}
+	if stack.slots[1].Ref != nil {
+		t.Error("popped slot still holds a ref")
+	}
+
+	stack.PushInt(1)
+	r := pushPanic(func() { stack.PopSlotsTo(heap.Slots(locals), 2) })
+	if msg, _ := r.(string); !strings.HasPrefix(msg, "java.lang.VerifyError: Operand stack underflow") {
+		t.Errorf("popping 2 of 1 slots: panic = %v, want a VerifyError", r)
+	}
+	if stack.size != 1 {
+		t.Errorf("underflow changed the stack size to %d", stack.size)
+	}
+}
@@ -9,6 +9,8 @@ type Thread struct {
 	pc    int
 	// java虚拟机栈指针
 	stack *Stack
+	// 对应的java.lang.Thread对象，第一次调用Thread.currentThread()时才创建
+	jThread *heap.Object
 }
 
 func NewThread() *Thread {
@@ -44,14 +46,50 @@ func (self *Thread) PushFrame(frame *Frame) {
 	self.stack.push(frame)
 }
 
+/**
+	方法正常返回（xRETURN指令）时弹出栈帧，等这一帧的类完成初始化
+ */
 func (self *Thread) PopFrame() *Frame {
-	return self.stack.pop()
+	frame := self.popFrame()
+	for _, class := range frame.pendingInits {
+		class.FinishInit()
+	}
+	return frame
+}
+
+/**
+	异常没有在这一帧处理时弹出栈帧，等这一帧的类初始化失败
+ */
+func (self *Thread) unwindFrame() {
+	frame := self.popFrame()
+	for _, class := range frame.pendingInits {
+		class.FailInit()
+	}
+}
+
+func (self *Thread) popFrame() *Frame {
+	frame := self.stack.pop()
+	if frame.methodMonitor != nil {
+		frame.methodMonitor.ExitMonitor()
+	}
+	return frame
 }
 
 func (self *Thread) TopFrame() *Frame {
 	return self.stack._top
 }
 
+/**
+	压在frame上面的第一帧，frame为nil时是栈底的那一帧，frame上面没有帧时返回nil
+ */
+func (self *Thread) FrameAbove(frame *Frame) *Frame {
+	var above *Frame
+	for f := self.stack._top; f != nil && f != frame; f = f.lower {
+		above = f
+	}
+	return above
+}
+
 func (self *Thread) CurrentFrame() *Frame {
 	return self.stack.top()
 }
@@ -59,3 +97,45 @@ func (self *Thread) CurrentFrame() *Frame {
 func (self *Thread) NewFrame(method *heap.Method) *Frame {
 	return newFrame(self, method)
 }
+
+func (self *Thread) JThread() *heap.Object {
+	return self.jThread
+}
+
+func (self *Thread) SetJThread(jThread *heap.Object) {
+	self.jThread = jThread
+}
+
+/**
+	线程名从java.lang.Thread对象的name字段（char[]）读取
+	还没有创建Thread对象时，只可能是主线程
+ */
+func (self *Thread) Name() string {
+	if self.jThread != nil {
+		if jName := self.jThread.GetRefVar("name", "[C"); jName != nil {
+			return heap.GoStringFromChars(jName)
+		}
+	}
+	return "main"
+}
+
+/**
+	GC的根：每一帧的局部变量表和操作数栈（只看栈顶以下的部分）里的引用，以及线程自己的java.lang.Thread对象
+ */
+func (self *Thread) GCRoots() []*heap.Object {
+	roots := []*heap.Object{self.jThread}
+	for _, frame := range self.GetFrames() {
+		for _, slot := range frame.localVars {
+			if slot.Ref != nil {
+				roots = append(roots, slot.Ref)
+			}
+		}
+		stack := frame.operandStack
+		for _, slot := range stack.slots[:stack.size] {
+			if slot.Ref != nil {
+				roots = append(roots, slot.Ref)
+			}
+		}
+	}
+	return roots
+}
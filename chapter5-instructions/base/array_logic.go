@@ -0,0 +1,31 @@
+package base
+
+import (
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+	"fmt"
+)
+
+/**
+	xaload和xastore共用的检查，检查不通过时抛出的异常java代码可以捕获
+	返回false表示已经抛出了异常，指令要直接返回
+ */
+func CheckNotNil(frame *chapter4_rtdt.Frame, ref *heap.Object) bool {
+	if ref == nil {
+		frame.ThrowException("java/lang/NullPointerException", "")
+		return false
+	}
+	return true
+}
+
+/**
+	和新版本的JDK一样，信息里带上下标和数组长度
+ */
+func CheckIndex(frame *chapter4_rtdt.Frame, arrLen int, index int32) bool {
+	if index < 0 || index >= int32(arrLen) {
+		frame.ThrowException("java/lang/ArrayIndexOutOfBoundsException",
+			fmt.Sprintf("Index %d out of bounds for length %d", index, arrLen))
+		return false
+	}
+	return true
+}
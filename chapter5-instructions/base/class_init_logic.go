@@ -5,31 +5,129 @@ import (
 	"GoVM/chapter6-obj/heap"
 )
 
+/**
+	getstatic、putstatic、new、invokestatic用到的类要先初始化，返回true时指令可以继续执行
+	返回false时：要么压入了<clinit>的栈帧，这条指令等它们返回之后重新执行；要么类初始化失败，已经抛出了NoClassDefFoundError
+ */
+func CheckInitialized(frame *chapter4_rtdt.Frame, class *heap.Class) bool {
+	if class.InitStarted() && !class.InitFailed() {
+		return true
+	}
+	InitClass(frame.Thread(), class)
+	//抛出异常时当前帧可能已经跳到了异常处理器，不能再回退pc
+	if !class.InitFailed() {
+		frame.RevertNextPC()
+	}
+	return false
+}
+
+/**
+	初始化已经开始（包括<clinit>正在执行中又触发了自己的初始化）的类直接返回，所以可以重复调用
+	栈是后进先出的，所以先压入自己的<clinit>，再倒着压入超接口的，最后是超类的，
+	执行顺序是 超类 -> 超接口（按声明的顺序） -> 自己
+	压入的栈帧都正常返回之后类才初始化完成，其中任何一个因为异常退出，类就初始化失败。
+	类（或者要先初始化的超类、超接口）已经初始化失败时，在当前帧抛出NoClassDefFoundError
+ */
 func InitClass(thread *chapter4_rtdt.Thread, class *heap.Class) {
+	if dependencyFailed(class) {
+		class.StartInit()
+		class.FailInit()
+		thread.CurrentFrame().ThrowException("java/lang/NoClassDefFoundError",
+			"Could not initialize class " + class.JavaName())
+		return
+	}
+	if class.InitStarted() {
+		return
+	}
 	class.StartInit()
+	below := thread.TopFrame()
 	scheduleClinit(thread, class)
+	initSuperInterfaces(thread, class)
 	initSuperClass(thread, class)
+	if class.GetClinitMethod() == nil {
+		//没有<clinit>方法的类，等超类和超接口的<clinit>都返回之后才算初始化完成
+		if first := thread.FrameAbove(below); first != nil {
+			first.InitOnReturn(class)
+		} else {
+			class.FinishInit()
+		}
+	}
 }
 
 /**
-	调用clinit
+	类自己，或者初始化时要先初始化的超类、有default方法的超接口，是不是已经初始化失败了
+ */
+func dependencyFailed(class *heap.Class) bool {
+	if class.InitFailed() {
+		return true
+	}
+	if class.InitStarted() || class.IsInterface() {
+		return false
+	}
+	if superClass := class.SuperClass(); superClass != nil && dependencyFailed(superClass) {
+		return true
+	}
+	for _, iface := range defaultInterfaces(class.Interfaces(), nil, map[*heap.Class]bool{}) {
+		if iface.InitFailed() {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+	压入<clinit>的栈帧，它正常返回时类完成初始化
  */
 func scheduleClinit(thread *chapter4_rtdt.Thread, class *heap.Class) {
 	clinit := class.GetClinitMethod()
 	if clinit != nil {
 		newFrame := thread.NewFrame(clinit)
+		newFrame.InitOnReturn(class)
 		thread.PushFrame(newFrame)
 	}
 }
 
 /**
 	递归的初始化父类
+	接口的初始化不会触发超接口的初始化，所以接口直接跳过。
+	比如getstatic读取接口中的常量时，字段解析出来的是声明该字段的接口，只初始化这一个接口就够了
  */
 func initSuperClass(thread *chapter4_rtdt.Thread, class *heap.Class) {
 	if !class.IsInterface() {
 		superClass := class.SuperClass()
-		if superClass != nil && !superClass.InitStarted() {
+		if superClass != nil {
 			InitClass(thread, superClass)
 		}
 	}
+}
+
+/**
+	jvms 5.5：类初始化时，声明了default方法的超接口（直接的和间接的）也要初始化，没有default方法的不用
+	接口的初始化不会压入别的<clinit>，所以倒着调用InitClass，执行的时候就是defaultInterfaces给出的顺序
+ */
+func initSuperInterfaces(thread *chapter4_rtdt.Thread, class *heap.Class) {
+	if !class.IsInterface() {
+		ifaces := defaultInterfaces(class.Interfaces(), nil, map[*heap.Class]bool{})
+		for i := len(ifaces) - 1; i >= 0; i-- {
+			InitClass(thread, ifaces[i])
+		}
+	}
+}
+
+/**
+	按声明的顺序递归地列出超接口，每个接口的超接口排在它自己前面，只留下有default方法的
+	比如 class C implements I, J，J extends K，顺序是 I K J
+ */
+func defaultInterfaces(ifaces []*heap.Class, result []*heap.Class, seen map[*heap.Class]bool) []*heap.Class {
+	for _, iface := range ifaces {
+		if seen[iface] {
+			continue
+		}
+		seen[iface] = true
+		result = defaultInterfaces(iface.Interfaces(), result, seen)
+		if iface.HasDefaultMethods() {
+			result = append(result, iface)
+		}
+	}
+	return result
 }
\ No newline at end of file
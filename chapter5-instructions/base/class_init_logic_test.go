@@ -0,0 +1,238 @@
+package base_test
+
+import (
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter5-instructions/base"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	每个<clinit>调用 Log.add(n)，log = log * 10 + n，最后从log的各位数字读出执行顺序
+ */
+func logClass() *Class {
+	log := New("init/Log", "java/lang/Object")
+	log.Field(ACC_STATIC, "log", "I")
+	log.Method(ACC_PUBLIC|ACC_STATIC, "add", "(I)V").Code(2, 1).
+		Getstatic("init/Log", "log", "I").Iconst(10).Op(IMUL).Op(ILOAD_0).Op(IADD).
+		Putstatic("init/Log", "log", "I").Op(RETURN)
+	log.Method(ACC_PUBLIC|ACC_STATIC, "get", "()I").Code(1, 0).
+		Getstatic("init/Log", "log", "I").Op(IRETURN)
+	return log
+}
+
+func clinit(class *Class, n int32) *Code {
+	return class.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		Iconst(n).Invokestatic("init/Log", "add", "(I)V")
+}
+
+/**
+	超类、有default方法的超接口和自己的<clinit>
+		class Super { static { Log.add(1); } }
+		interface WithDefault { static { Log.add(3); } default void hello() {} }
+		interface Plain { static { Log.add(4); } }                       //没有default方法，不会被初始化
+		class Sub extends Super implements WithDefault, Plain {
+			static int x;
+			static { Log.add(2); x = read(); }                             //<clinit>里又触发了自己的初始化
+			static int read() { return x + 5; }
+		}
+		class Bare { static int y; }                                     //没有<clinit>
+ */
+func newInitOrderVM(t *testing.T) *jvmtest.VM {
+	log := logClass()
+
+	super := New("init/Super", "java/lang/Object")
+	clinit(super, 1).Op(RETURN)
+	withDefault := NewInterface("init/WithDefault")
+	clinit(withDefault, 3).Op(RETURN)
+	withDefault.Method(ACC_PUBLIC, "hello", "()V").Code(0, 1).Op(RETURN)
+	plain := NewInterface("init/Plain")
+	clinit(plain, 4).Op(RETURN)
+
+	sub := New("init/Sub", "init/Super", "init/WithDefault", "init/Plain")
+	sub.Field(ACC_STATIC, "x", "I")
+	clinit(sub, 2).Invokestatic("init/Sub", "read", "()I").Putstatic("init/Sub", "x", "I").Op(RETURN)
+	sub.Method(ACC_PUBLIC|ACC_STATIC, "read", "()I").Code(2, 0).
+		Getstatic("init/Sub", "x", "I").Iconst(5).Op(IADD).Op(IRETURN)
+
+	bare := New("init/Bare", "java/lang/Object")
+	bare.Field(ACC_STATIC, "y", "I")
+	bare.Method(ACC_PUBLIC|ACC_STATIC, "read", "()I").Code(1, 0).
+		Getstatic("init/Bare", "y", "I").Op(IRETURN)
+	return jvmtest.New(t, log, super, withDefault, plain, sub, bare)
+}
+
+func TestInitClassRunsSuperclassAndDefaultInterfacesFirst(t *testing.T) {
+	vm := newInitOrderVM(t)
+
+	//<clinit>里的read()读到的x还是0，所以x = 5，这里再加5
+	if got := vm.Call("init/Sub", "read", "()I").Int(); got != 10 {
+		t.Errorf("Sub.read() = %d, want 10", got)
+	}
+	if got := vm.Call("init/Log", "get", "()I").Int(); got != 132 {
+		t.Errorf("<clinit> order = %d, want 132 (Super, WithDefault, Sub)", got)
+	}
+	if vm.Class("init/Plain").InitStarted() {
+		t.Error("superinterface without default methods was initialized")
+	}
+
+	//再次使用不会重新初始化
+	vm.Call("init/Sub", "read", "()I")
+	if got := vm.Call("init/Log", "get", "()I").Int(); got != 132 {
+		t.Errorf("<clinit> ran again: log = %d", got)
+	}
+
+	bare := vm.Class("init/Bare")
+	vm.Call("init/Bare", "read", "()I")
+	if !bare.InitStarted() || !bare.InitFinished() {
+		t.Error("class without <clinit> was not marked initialized")
+	}
+}
+
+/**
+	超接口按声明的顺序初始化，间接的超接口排在继承它的接口前面，一个接口只初始化一次
+		interface First { static { Log.add(5); } default void a() {} }
+		interface Parent { static { Log.add(6); } default void b() {} }
+		interface Middle extends Parent { static { Log.add(9); } }        //自己没有default方法，不初始化
+		interface Second extends Parent { static { Log.add(7); } default void c() {} }
+		class Multi implements First, Middle, Second { static { Log.add(8); } static int read() { return 0; } }
+ */
+func TestInitClassRunsIndirectDefaultInterfacesInDeclarationOrder(t *testing.T) {
+	iface := func(name string, n int32, hasDefault bool, supers ...string) *Class {
+		class := NewInterface(name, supers...)
+		clinit(class, n).Op(RETURN)
+		if hasDefault {
+			class.Method(ACC_PUBLIC, "m" + name[len("init/"):], "()V").Code(0, 1).Op(RETURN)
+		}
+		return class
+	}
+
+	multi := New("init/Multi", "java/lang/Object", "init/First", "init/Middle", "init/Second")
+	clinit(multi, 8).Op(RETURN)
+	multi.Method(ACC_PUBLIC|ACC_STATIC, "read", "()I").Code(1, 0).Op(ICONST_0).Op(IRETURN)
+	vm := jvmtest.New(t, logClass(), multi,
+		iface("init/First", 5, true),
+		iface("init/Parent", 6, true),
+		iface("init/Middle", 9, false, "init/Parent"),
+		iface("init/Second", 7, true, "init/Parent"))
+
+	vm.Call("init/Multi", "read", "()I")
+	if got := vm.Call("init/Log", "get", "()I").Int(); got != 5678 {
+		t.Errorf("<clinit> order = %d, want 5678 (First, Parent, Second, Multi)", got)
+	}
+	if vm.Class("init/Middle").InitStarted() {
+		t.Error("superinterface without default methods was initialized")
+	}
+}
+
+/**
+	子类的<clinit>读超类的静态字段，要读到超类<clinit>赋的值
+		class Base { static int seed; static { seed = 41; } }
+		class Derived extends Base {
+			static int copy, inherited;
+			static { copy = Base.seed + 1; inherited = Derived.seed; }  //通过子类引用继承来的字段
+		}
+ */
+func TestSubclassInitializerSeesSuperclassStatics(t *testing.T) {
+	base := New("init/Base", "java/lang/Object")
+	base.Field(ACC_STATIC, "seed", "I")
+	base.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		Iconst(41).Putstatic("init/Base", "seed", "I").Op(RETURN)
+	derived := New("init/Derived", "init/Base")
+	derived.Field(ACC_STATIC, "copy", "I")
+	derived.Field(ACC_STATIC, "inherited", "I")
+	derived.Method(ACC_STATIC, "<clinit>", "()V").Code(2, 0).
+		Getstatic("init/Base", "seed", "I").Op(ICONST_1).Op(IADD).Putstatic("init/Derived", "copy", "I").
+		Getstatic("init/Derived", "seed", "I").Putstatic("init/Derived", "inherited", "I").Op(RETURN)
+	for _, name := range []string{"copy", "inherited"} {
+		derived.Method(ACC_PUBLIC|ACC_STATIC, name, "()I").Code(1, 0).
+			Getstatic("init/Derived", name, "I").Op(IRETURN)
+	}
+	vm := jvmtest.New(t, base, derived)
+
+	if got := vm.Call("init/Derived", "copy", "()I").Int(); got != 42 {
+		t.Errorf("Derived.copy = %d, want 42", got)
+	}
+	if got := vm.Call("init/Derived", "inherited", "()I").Int(); got != 41 {
+		t.Errorf("Derived.inherited = %d, want 41", got)
+	}
+	if !vm.Class("init/Base").InitFinished() {
+		t.Error("Base is not initialized")
+	}
+}
+
+/**
+	<clinit>因为异常退出，类初始化失败，以后再用到它（或者它的子类）都抛出NoClassDefFoundError，<clinit>不会再执行
+		class Broken { static int x; static { Log.add(1); throw new RuntimeException(); } }
+		class Leaf extends Broken { static int y; }                     //没有<clinit>，要等Broken的<clinit>
+		class Other extends Broken { static int z; }                    //Broken失败之后才第一次用到
+		class Reader { static int x() { return Broken.x; } ... }
+ */
+func TestInitClassFailsWhenClinitThrows(t *testing.T) {
+	broken := New("init/Broken", "java/lang/Object")
+	broken.Field(ACC_STATIC, "x", "I")
+	broken.Method(ACC_STATIC, "<clinit>", "()V").Code(2, 0).
+		Iconst(1).Invokestatic("init/Log", "add", "(I)V").
+		New("java/lang/RuntimeException").Op(DUP).
+		Invokespecial("java/lang/RuntimeException", "<init>", "()V").Op(ATHROW)
+	leaf := New("init/Leaf", "init/Broken")
+	leaf.Field(ACC_STATIC, "y", "I")
+	other := New("init/Other", "init/Broken")
+	other.Field(ACC_STATIC, "z", "I")
+
+	reader := New("init/Reader", "java/lang/Object")
+	for _, field := range []struct{ class, name string }{{"init/Broken", "x"}, {"init/Leaf", "y"}, {"init/Other", "z"}} {
+		reader.Method(ACC_PUBLIC|ACC_STATIC, field.name, "()I").Code(1, 0).
+			Getstatic(field.class, field.name, "I").Op(IRETURN)
+	}
+	vm := jvmtest.New(t, logClass(), broken, leaf, other, reader)
+
+	vm.Call("init/Reader", "y", "()I").Throws("java/lang/RuntimeException")
+	for _, name := range []string{"init/Broken", "init/Leaf"} {
+		if class := vm.Class(name); !class.InitFailed() || class.InitFinished() {
+			t.Errorf("%s: failed = %v, finished = %v, want failed", name, class.InitFailed(), class.InitFinished())
+		}
+	}
+
+	for _, test := range []struct {
+		method, class string
+	}{
+		{"x", "init.Broken"},
+		{"y", "init.Leaf"},
+		{"z", "init.Other"},
+	} {
+		msg := vm.Call("init/Reader", test.method, "()I").Throws("java/lang/NoClassDefFoundError")
+		if want := "Could not initialize class " + test.class; msg != want {
+			t.Errorf("Reader.%s(): message = %q, want %q", test.method, msg, want)
+		}
+	}
+	if got := vm.Call("init/Log", "get", "()I").Int(); got != 1 {
+		t.Errorf("log = %d, want 1 (Broken.<clinit> ran once)", got)
+	}
+}
+
+/**
+	没有<clinit>的类要等超类的<clinit>返回之后才初始化完成
+		class Top { static { Log.add(1); } }
+		class Middle extends Top { static int y; }                      //没有<clinit>
+ */
+func TestClassWithoutClinitWaitsForSuperclass(t *testing.T) {
+	top := New("init/Top", "java/lang/Object")
+	top.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		Iconst(1).Invokestatic("init/Log", "add", "(I)V").Op(RETURN)
+	middle := New("init/Middle", "init/Top")
+	middle.Field(ACC_STATIC, "y", "I")
+	vm := jvmtest.New(t, logClass(), top, middle)
+
+	class := vm.Class("init/Middle")
+	vm.Run(func(thread *chapter4_rtdt.Thread) {
+		base.InitClass(thread, class)
+		if class.InitFinished() {
+			t.Error("Middle finished before Top.<clinit> ran")
+		}
+	})
+	if !class.InitFinished() || !vm.Class("init/Top").InitFinished() {
+		t.Error("Middle is not initialized after Top.<clinit> returned")
+	}
+}
@@ -13,18 +13,62 @@ import (
 	2.假设实际的参数占用 n 个位置，一次把这 n 个变量从调用者的操作数栈中弹出，放进调用方法的局部变量表中
  */
 func InvokeMethod(invokerFrame *chapter4_rtdt.Frame, method *heap.Method) {
+	checkInvokable(method)
+
 	//创建一个新的栈帧，并且压入线程的栈顶
 	thread := invokerFrame.Thread()
 	newFrame := thread.NewFrame(method)
 	thread.PushFrame(newFrame)
 
-	argSlotSlot := int(method.ArgSlotCount())
-	if argSlotSlot > 0 {
-		for i := argSlotSlot - 1; i >= 0; i-- {
-			//调用方操作数栈中弹出参数
-			slot := invokerFrame.OperandStack().PopSlot()
-			//放到方法栈帧的局部变量表中
-			newFrame.LocalVars().SetSlot(uint(i), slot)
-		}
+	argSlotCount := method.ArgSlotCount()
+	if argSlotCount > 0 {
+		//调用方操作数栈中弹出参数，放到方法栈帧的局部变量表中
+		invokerFrame.OperandStack().PopSlotsTo(heap.Slots(newFrame.LocalVars()), argSlotCount)
+	}
+	newFrame.EnterMethodMonitor()
+}
+
+/**
+	调用之前确认方法有字节码可以执行：
+		抽象方法，抛出AbstractMethodError
+		本地方法，字节码是注入的 0xfe + 返回指令，invokenative会去本地方法注册表里找实现
+		其他方法都是解释执行的，必须有Code属性，否则解释器会去执行空的字节码
+ */
+func checkInvokable(method *heap.Method) {
+	if method.IsAbstract() {
+		panic("java.lang.AbstractMethodError: " + method.Class().JavaName() + "." + method.Name() + method.Descriptor())
+	}
+	if !method.HasCode() {
+		//和HotSpot的信息一样
+		panic("java.lang.ClassFormatError: Absent Code attribute in method that is not native or abstract in class file " +
+			method.Class().Name())
+	}
+}
+
+/**
+	解析时检查的是解析出来的方法，真正调用的是按对象的实际类型选出来的方法，
+	选出来的方法如果比解析出来的方法访问权限更小（比如子类用字节码把public方法改成了包私有的），
+	还要检查调用方能不能访问它，不能访问就抛出IllegalAccessError
+ */
+func CheckSelectedMethodAccess(currentClass *heap.Class, resolvedMethod, selectedMethod *heap.Method) {
+	if selectedMethod == resolvedMethod || accessLevel(selectedMethod) >= accessLevel(resolvedMethod) {
+		return
+	}
+	if !heap.CanAccess(selectedMethod.AccessFlags(), selectedMethod.Class(), currentClass) {
+		panic("java.lang.IllegalAccessError: tried to access method " + selectedMethod.Class().JavaName() + "." +
+			selectedMethod.Name() + selectedMethod.Descriptor() + " from class " + currentClass.JavaName())
+	}
+}
+
+// private < 包私有 < protected < public
+func accessLevel(method *heap.Method) int {
+	switch {
+	case method.IsPublic():
+		return 3
+	case method.IsProtected():
+		return 2
+	case method.IsPrivate():
+		return 0
 	}
+	return 1
 }
@@ -0,0 +1,64 @@
+package base_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/chapter4-rtdt"
+	"GoVM/internal/jvmtest"
+	"GoVM/native"
+	"testing"
+)
+
+/**
+	三个方法都通过 invokestatic 调用，走同一个 InvokeMethod：
+		static native int nativeTwice(int x);   //本地方法注册表里的实现
+		static abstract int abstractTwice(int x);
+		static int normalTwice(int x) { return x + x; }
+		static int noCode(int x);               //不是本地方法也不是抽象方法，却没有Code属性
+ */
+func TestInvokeMethodRoutesByMethodKind(t *testing.T) {
+	native.Register("invoke/Kinds", "nativeTwice", "(I)I", func(frame *chapter4_rtdt.Frame) {
+		frame.OperandStack().PushInt(frame.LocalVars().GetInt(0) * 2)
+	})
+	class := New("invoke/Kinds", "java/lang/Object")
+	class.Method(ACC_STATIC|ACC_NATIVE, "nativeTwice", "(I)I")
+	class.Method(ACC_STATIC|ACC_ABSTRACT, "abstractTwice", "(I)I")
+	class.Method(ACC_STATIC, "normalTwice", "(I)I").Code(2, 1).
+		Op(ILOAD_0).Op(ILOAD_0).Op(IADD).Op(IRETURN)
+	class.Method(ACC_STATIC, "noCode", "(I)I")
+	for _, name := range []string{"nativeTwice", "abstractTwice", "normalTwice", "noCode"} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, "call_"+name, "(I)I").Code(1, 1).
+			Op(ILOAD_0).Invokestatic("invoke/Kinds", name, "(I)I").Op(IRETURN)
+	}
+	vm := jvmtest.New(t, class)
+
+	kinds := vm.Class("invoke/Kinds")
+	for _, test := range []struct {
+		name    string
+		hasCode bool
+	}{
+		//本地方法的字节码是加载时注入的
+		{"nativeTwice", true},
+		{"abstractTwice", false},
+		{"normalTwice", true},
+		{"noCode", false},
+	} {
+		if got := kinds.GetStaticMethod(test.name, "(I)I").HasCode(); got != test.hasCode {
+			t.Errorf("%s.HasCode() = %v, want %v", test.name, got, test.hasCode)
+		}
+	}
+
+	for _, name := range []string{"call_nativeTwice", "call_normalTwice"} {
+		if got := vm.Call("invoke/Kinds", name, "(I)I", int32(21)).Int(); got != 42 {
+			t.Errorf("%s(21) = %d, want 42", name, got)
+		}
+	}
+	for _, test := range []struct{ name, want string }{
+		{"call_abstractTwice", "java.lang.AbstractMethodError: invoke.Kinds.abstractTwice(I)I"},
+		{"call_noCode", "java.lang.ClassFormatError: Absent Code attribute in method that is not native or abstract in class file invoke/Kinds"},
+	} {
+		var result *jvmtest.Result
+		if r := vm.CallPanic(&result, "invoke/Kinds", test.name, "(I)I", int32(21)); r != test.want {
+			t.Errorf("%s(21) panicked with %v, want %q", test.name, r, test.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package comparisons_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+var (
+	ifConds   = map[string]byte{"ifeq": IFEQ, "ifne": IFNE, "iflt": IFLT, "ifge": IFGE, "ifgt": IFGT, "ifle": IFLE}
+	ifIcmpOps = map[string]byte{"if_icmpeq": IF_ICMPEQ, "if_icmpne": IF_ICMPNE, "if_icmplt": IF_ICMPLT,
+		"if_icmpge": IF_ICMPGE, "if_icmpgt": IF_ICMPGT, "if_icmple": IF_ICMPLE}
+)
+
+/**
+	每个跳转指令一个方法：条件成立时返回true
+		static boolean ifeq(int a) { return a == 0; }
+		static boolean if_icmpeq(int a, int b) { return a == b; }
+	以及用向后跳转实现的循环
+		static int sumDown(int n) { int s = 0; do { s += n; } while (--n > 0); return s; }
+		static int countUp(int n) { int i = 0; do { i++; } while (i < n); return i; }
+ */
+func newBranchVM(t *testing.T) *jvmtest.VM {
+	class := New("cmp/Branches", "java/lang/Object")
+	for name, opcode := range ifConds {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(I)Z").Code(1, 1).
+			Op(ILOAD_0).Branch(opcode, "taken").
+			Op(ICONST_0).Op(IRETURN).
+			Label("taken").Op(ICONST_1).Op(IRETURN)
+	}
+	for name, opcode := range ifIcmpOps {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(II)Z").Code(2, 2).
+			Op(ILOAD_0).Op(ILOAD_1).Branch(opcode, "taken").
+			Op(ICONST_0).Op(IRETURN).
+			Label("taken").Op(ICONST_1).Op(IRETURN)
+	}
+	class.Method(ACC_PUBLIC|ACC_STATIC, "sumDown", "(I)I").Code(2, 2).
+		Op(ICONST_0).Op(ISTORE_1).
+		Label("loop").
+		Op(ILOAD_1).Op(ILOAD_0).Op(IADD).Op(ISTORE_1).
+		Op(IINC, 0, 0xff).
+		Op(ILOAD_0).Branch(IFGT, "loop").
+		Op(ILOAD_1).Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "countUp", "(I)I").Code(2, 2).
+		Op(ICONST_0).Op(ISTORE_1).
+		Label("loop").
+		Op(IINC, 1, 1).
+		Op(ILOAD_1).Op(ILOAD_0).Branch(IF_ICMPLT, "loop").
+		Op(ILOAD_1).Op(IRETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestIfCond(t *testing.T) {
+	vm := newBranchVM(t)
+	for _, test := range []struct {
+		name string
+		a    int32
+		want bool
+	}{
+		{"ifeq", 0, true}, {"ifeq", 1, false},
+		{"ifne", 0, false}, {"ifne", -1, true},
+		{"iflt", -1, true}, {"iflt", 0, false},
+		{"ifge", 0, true}, {"ifge", -5, false},
+		{"ifgt", 1, true}, {"ifgt", 0, false},
+		{"ifle", 0, true}, {"ifle", 7, false},
+	} {
+		if got := vm.Call("cmp/Branches", test.name, "(I)Z", test.a).Bool(); got != test.want {
+			t.Errorf("%s(%d) = %v, want %v", test.name, test.a, got, test.want)
+		}
+	}
+}
+
+func TestIfIcmp(t *testing.T) {
+	vm := newBranchVM(t)
+	for _, test := range []struct {
+		name string
+		a, b int32
+		want bool
+	}{
+		{"if_icmpeq", 3, 3, true}, {"if_icmpeq", 3, 4, false},
+		{"if_icmpne", 3, 4, true}, {"if_icmpne", -2, -2, false},
+		{"if_icmplt", -2, 1, true}, {"if_icmplt", 1, 1, false},
+		{"if_icmpge", 1, 1, true}, {"if_icmpge", -3, 2, false},
+		{"if_icmpgt", 2, -3, true}, {"if_icmpgt", 2, 2, false},
+		{"if_icmple", 2, 2, true}, {"if_icmple", 5, -5, false},
+	} {
+		if got := vm.Call("cmp/Branches", test.name, "(II)Z", test.a, test.b).Bool(); got != test.want {
+			t.Errorf("%s(%d, %d) = %v, want %v", test.name, test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestBackwardBranchLoops(t *testing.T) {
+	vm := newBranchVM(t)
+	if got := vm.Call("cmp/Branches", "sumDown", "(I)I", int32(100)).Int(); got != 5050 {
+		t.Errorf("sumDown(100) = %d, want 5050", got)
+	}
+	if got := vm.Call("cmp/Branches", "countUp", "(I)I", int32(10)).Int(); got != 10 {
+		t.Errorf("countUp(10) = %d, want 10", got)
+	}
+}
@@ -0,0 +1,82 @@
+package constants_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"fmt"
+	"math"
+	"testing"
+)
+
+/**
+	class Literals { static String hello() { return "ldc literal"; } static boolean same() { return "ldc literal" == "ldc literal"; } }
+	class Other { static String hello() { return "ldc literal"; } }  //另一个类的常量池
+ */
+func TestLdcStringLiteralsAreIdentical(t *testing.T) {
+	var classes []*Class
+	for _, name := range []string{"consts/Literals", "consts/Other"} {
+		class := New(name, "java/lang/Object")
+		class.Method(ACC_PUBLIC|ACC_STATIC, "hello", "()Ljava/lang/String;").Code(1, 0).
+			LdcString("ldc literal").Op(ARETURN)
+		classes = append(classes, class)
+	}
+	classes[0].Method(ACC_PUBLIC|ACC_STATIC, "same", "()Z").Code(2, 0).
+		LdcString("ldc literal").LdcString("ldc literal").Branch(IF_ACMPNE, "different").
+		Op(ICONST_1).Op(IRETURN).
+		Label("different").Op(ICONST_0).Op(IRETURN)
+	vm := jvmtest.New(t, classes...)
+
+	if !vm.Call("consts/Literals", "same", "()Z").Bool() {
+		t.Error(`"ldc literal" == "ldc literal" is false`)
+	}
+	first := vm.Call("consts/Literals", "hello", "()Ljava/lang/String;").Ref()
+	if vm.Call("consts/Literals", "hello", "()Ljava/lang/String;").Ref() != first {
+		t.Error("two executions of one ldc returned different strings")
+	}
+	if vm.Call("consts/Other", "hello", "()Ljava/lang/String;").Ref() != first {
+		t.Error("the same literal in another class is a different string")
+	}
+	if vm.String("ldc literal") != first {
+		t.Error("the literal is not the interned string")
+	}
+}
+
+var (
+	ldc2Longs   = []int64{math.MaxInt64, math.MinInt64, 0x0123456789abcdef, -2}
+	ldc2Doubles = []float64{math.SmallestNonzeroFloat64, math.MaxFloat64, -0.5, math.Copysign(0, -1)}
+)
+
+/**
+	每个常量一个方法：static long longN() { return <常量>; }  static double doubleN() { return <常量>; }
+	以及 static int below() { int x = 7; long l = Long.MAX_VALUE; return x; }：ldc2_w正好压入两个slot，pop2之后7还在栈顶
+ */
+func newLdc2VM(t *testing.T) *jvmtest.VM {
+	class := New("consts/Wide", "java/lang/Object")
+	for i, val := range ldc2Longs {
+		class.Method(ACC_PUBLIC|ACC_STATIC, fmt.Sprint("long", i), "()J").Code(2, 0).Lconst(val).Op(LRETURN)
+	}
+	for i, val := range ldc2Doubles {
+		class.Method(ACC_PUBLIC|ACC_STATIC, fmt.Sprint("double", i), "()D").Code(2, 0).Dconst(val).Op(DRETURN)
+	}
+	class.Method(ACC_PUBLIC|ACC_STATIC, "below", "()I").Code(3, 0).
+		Iconst(7).Lconst(math.MaxInt64).Op(POP2).Op(IRETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestLdc2wPushesFull64BitValues(t *testing.T) {
+	vm := newLdc2VM(t)
+	for i, want := range ldc2Longs {
+		if got := vm.Call("consts/Wide", fmt.Sprint("long", i), "()J").Long(); got != want {
+			t.Errorf("ldc2_w %#x = %#x", want, got)
+		}
+	}
+	//按位比较，-0.0和0.0也要区分开
+	for i, want := range ldc2Doubles {
+		if got := vm.Call("consts/Wide", fmt.Sprint("double", i), "()D").Double(); math.Float64bits(got) != math.Float64bits(want) {
+			t.Errorf("ldc2_w %v = %v", want, got)
+		}
+	}
+	if got := vm.Call("consts/Wide", "below", "()I").Int(); got != 7 {
+		t.Errorf("below() = %d, want 7", got)
+	}
+}
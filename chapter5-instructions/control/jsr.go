@@ -0,0 +1,31 @@
+package control
+
+import (
+	"GoVM/chapter5-instructions/base"
+	"GoVM/chapter4-rtdt"
+)
+
+/**
+	jsr把下一条指令的地址（returnAddress）压栈，然后跳到子程序。
+	子程序一般先用astore把返回地址存进局部变量，最后用ret跳回来。
+	returnAddress既不是int也不是引用，java代码里没法直接操作它
+ */
+type JSR struct {
+	base.BranchInstruction
+}
+
+func (self *JSR) Execute(frame *chapter4_rtdt.Frame) {
+	frame.OperandStack().PushReturnAddress(frame.NextPC())
+	base.Branch(frame, self.Offset)
+}
+
+/**
+	从局部变量表读出returnAddress，跳回jsr的下一条指令。局部变量超过255时要用wide ret
+ */
+type RET struct {
+	base.Index8Instruction
+}
+
+func (self *RET) Execute(frame *chapter4_rtdt.Frame) {
+	frame.SetNextPC(frame.LocalVars().GetReturnAddress(self.Index))
+}
@@ -0,0 +1,45 @@
+package control_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	老版本javac编译finally的方式：子程序把返回地址存进局部变量，执行完用ret跳回去
+		static int twice(int n) { int acc = 0; jsr add; jsr_w add; return acc; }
+		add: astore 300; acc += n; ret 300        //下标超过255，要用wide
+	以及下标小的 astore_2 / ret 2
+ */
+func newJsrVM(t *testing.T) *jvmtest.VM {
+	class := New("ctrl/Subroutines", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "twiceWide", "(I)I").Code(2, 301).
+		Op(ICONST_0).Op(ISTORE_1).
+		Branch(JSR, "add").
+		Branch(JSR_W, "add").
+		Op(ILOAD_1).Op(IRETURN).
+		Label("add").
+		Op(WIDE, ASTORE, 0x01, 0x2c).
+		Op(ILOAD_1).Op(ILOAD_0).Op(IADD).Op(ISTORE_1).
+		Op(WIDE, RET, 0x01, 0x2c)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "twice", "(I)I").Code(2, 3).
+		Op(ICONST_0).Op(ISTORE_1).
+		Branch(JSR, "add").
+		Branch(JSR, "add").
+		Op(ILOAD_1).Op(IRETURN).
+		Label("add").
+		Op(ASTORE_2).
+		Op(ILOAD_1).Op(ILOAD_0).Op(IADD).Op(ISTORE_1).
+		Op(RET, 2)
+	return jvmtest.New(t, class)
+}
+
+func TestJsrRet(t *testing.T) {
+	vm := newJsrVM(t)
+	for _, method := range []string{"twice", "twiceWide"} {
+		if got := vm.Call("ctrl/Subroutines", method, "(I)I", int32(21)).Int(); got != 42 {
+			t.Errorf("%s(21) = %d, want 42", method, got)
+		}
+	}
+}
@@ -21,11 +21,22 @@ func (self *LOOKUP_SWITCH) FetchOperands(reader *base.BytecodeReader) {
 	self.matchOffsets = reader.ReadInt32s(self.npairs * 2)
 }
 
+/**
+	class文件里的 match-offset 对是按match升序排好的（jvms 6.5 lookupswitch），所以可以二分查找
+	找不到就跳到default
+ */
 func (self *LOOKUP_SWITCH) Execute(frame *chapter4_rtdt.Frame) {
 	key := frame.OperandStack().PopInt()
-	for i := int32(0); i < self.npairs * 2; i += 2 {
-		if self.matchOffsets[i] == key {
-			offset := self.matchOffsets[i + 1]
+	low, high := int32(0), self.npairs - 1
+	for low <= high {
+		mid := int32(uint32(low + high) >> 1)
+		match := self.matchOffsets[mid * 2]
+		if match < key {
+			low = mid + 1
+		} else if match > key {
+			high = mid - 1
+		} else {
+			offset := self.matchOffsets[mid * 2 + 1]
 			base.Branch(frame, int(offset))
 			return
 		}
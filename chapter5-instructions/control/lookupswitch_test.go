@@ -0,0 +1,80 @@
+package control_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"fmt"
+	"math"
+	"testing"
+)
+
+/**
+	static int lookup(int key) { switch (key) { case keys[i]: return i; default: return -1; } }
+	keys 里有 Integer.MIN_VALUE 和 Integer.MAX_VALUE，中间是间隔7的负数和正数
+	static int empty(int key) 的lookupswitch没有任何case
+ */
+func lookupKeys() []int32 {
+	keys := []int32{math.MinInt32}
+	for i := int32(0); i < 200; i++ {
+		keys = append(keys, i * 7 - 500)
+	}
+	return append(keys, math.MaxInt32)
+}
+
+func newLookupVM(t *testing.T, keys []int32) *jvmtest.VM {
+	class := New("control/Lookup", "java/lang/Object")
+	labels := make([]string, len(keys))
+	for i := range keys {
+		labels[i] = fmt.Sprint("case", i)
+	}
+	code := class.Method(ACC_PUBLIC|ACC_STATIC, "lookup", "(I)I").Code(1, 1).
+		Op(ILOAD_0).LookupSwitch("default", keys, labels)
+	for i, label := range labels {
+		code.Label(label).Iconst(int32(i)).Op(IRETURN)
+	}
+	code.Label("default").Op(ICONST_M1).Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "empty", "(I)I").Code(1, 1).
+		Op(ILOAD_0).LookupSwitch("default", nil, nil).
+		Label("default").Op(ICONST_M1).Op(IRETURN)
+	return jvmtest.New(t, class)
+}
+
+func linearLookup(keys []int32, key int32) int32 {
+	for i, k := range keys {
+		if k == key {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+func TestLookupswitchMatchesLinearSearch(t *testing.T) {
+	keys := lookupKeys()
+	vm := newLookupVM(t, keys)
+	probes := []int32{math.MinInt32 + 1, math.MaxInt32 - 1, -501, 0, 1}
+	for _, k := range keys {
+		probes = append(probes, k, k + 1)
+	}
+	for _, key := range probes {
+		want := linearLookup(keys, key)
+		if got := vm.Call("control/Lookup", "lookup", "(I)I", key).Int(); got != want {
+			t.Errorf("lookup(%d) = %d, want %d", key, got, want)
+		}
+	}
+	//两端的case
+	if got := vm.Call("control/Lookup", "lookup", "(I)I", int32(math.MinInt32)).Int(); got != 0 {
+		t.Errorf("lookup(MIN_VALUE) = %d, want 0", got)
+	}
+	if got := vm.Call("control/Lookup", "lookup", "(I)I", int32(math.MaxInt32)).Int(); got != int32(len(keys) - 1) {
+		t.Errorf("lookup(MAX_VALUE) = %d, want %d", got, len(keys) - 1)
+	}
+}
+
+func TestLookupswitchWithoutPairs(t *testing.T) {
+	vm := newLookupVM(t, lookupKeys())
+	for _, key := range []int32{0, math.MinInt32, math.MaxInt32} {
+		if got := vm.Call("control/Lookup", "empty", "(I)I", key).Int(); got != -1 {
+			t.Errorf("empty(%d) = %d, want the default", key, got)
+		}
+	}
+}
@@ -10,6 +10,10 @@ type RETURN struct {
 }
 
 func (self *RETURN) Execute(frame *chapter4_rtdt.Frame) {
+	//-Xcheck:monitors 时还持有监视器的方法不能正常返回
+	if !frame.CheckMonitorBalance() {
+		return
+	}
 	frame.Thread().PopFrame()
 }
 
@@ -18,6 +22,9 @@ type ARETURN struct {
 }
 
 func (self *ARETURN) Execute(frame *chapter4_rtdt.Frame) {
+	if !frame.CheckMonitorBalance() {
+		return
+	}
 	thread := frame.Thread()
 	currentFrame := thread.PopFrame()
 	invokerFrame := thread.TopFrame()
@@ -30,6 +37,9 @@ type DRETURN struct {
 }
 
 func (self *DRETURN) Execute(frame *chapter4_rtdt.Frame) {
+	if !frame.CheckMonitorBalance() {
+		return
+	}
 	thread := frame.Thread()
 	currentFrame := thread.PopFrame()
 	invokerFrame := thread.TopFrame()
@@ -42,6 +52,9 @@ type FRETURN struct {
 }
 
 func (self *FRETURN) Execute(frame *chapter4_rtdt.Frame) {
+	if !frame.CheckMonitorBalance() {
+		return
+	}
 	thread := frame.Thread()
 	currentFrame := thread.PopFrame()
 	invokerFrame := thread.TopFrame()
@@ -54,6 +67,9 @@ type IRETURN struct {
 }
 
 func (self *IRETURN) Execute(frame *chapter4_rtdt.Frame) {
+	if !frame.CheckMonitorBalance() {
+		return
+	}
 	thread := frame.Thread()
 	currentFrame := thread.PopFrame()
 	//调用方为当前线程最上面的栈
@@ -69,6 +85,9 @@ type LRETURN struct {
 }
 
 func (self *LRETURN) Execute(frame *chapter4_rtdt.Frame) {
+	if !frame.CheckMonitorBalance() {
+		return
+	}
 	thread := frame.Thread()
 	currentFrame := thread.PopFrame()
 	invokerFrame := thread.TopFrame()
@@ -23,7 +23,7 @@ type D2I struct {
 func (self *D2I) Execute(frame *chapter4_rtdt.Frame) {
 	stack := frame.OperandStack()
 	d := stack.PopDouble()
-	i := int32(d)
+	i := d2i(d)
 	stack.PushInt(i)
 }
 
@@ -34,6 +34,6 @@ type D2L struct {
 func (self *D2L) Execute(frame *chapter4_rtdt.Frame) {
 	stack := frame.OperandStack()
 	d := stack.PopDouble()
-	l := int64(d)
+	l := d2l(d)
 	stack.PushLong(l)
 }
\ No newline at end of file
@@ -25,7 +25,7 @@ type F2I struct {
 func (self *F2I) Execute(frame *chapter4_rtdt.Frame) {
 	stack := frame.OperandStack()
 	f := stack.PopFloat()
-	i := int32(f)
+	i := d2i(float64(f))
 	stack.PushInt(i)
 }
 
@@ -37,6 +37,6 @@ type F2L struct {
 func (self *F2L) Execute(frame *chapter4_rtdt.Frame) {
 	stack := frame.OperandStack()
 	f := stack.PopFloat()
-	l := int64(f)
+	l := d2l(float64(f))
 	stack.PushLong(l)
 }
\ No newline at end of file
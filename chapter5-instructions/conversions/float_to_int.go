@@ -0,0 +1,35 @@
+package conversions
+
+import "math"
+
+/**
+	jvms 2.8.3：浮点数转整数向0取整，NaN转成0，超出范围的取最大值或最小值。
+	go里超出范围的浮点数转整数，结果是和平台相关的，所以要先判断
+	float转成double是精确的，所以f2i、f2l也用这两个函数
+ */
+func d2i(d float64) int32 {
+	switch {
+	case math.IsNaN(d):
+		return 0
+	case d >= math.MaxInt32:
+		return math.MaxInt32
+	case d <= math.MinInt32:
+		return math.MinInt32
+	}
+	return int32(d)
+}
+
+/**
+	2^63不能用int64表示，所以用 >= 比较（float64(math.MaxInt64) 正好就是2^63）
+ */
+func d2l(d float64) int64 {
+	switch {
+	case math.IsNaN(d):
+		return 0
+	case d >= math.MaxInt64:
+		return math.MaxInt64
+	case d <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(d)
+}
@@ -0,0 +1,94 @@
+package conversions_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"math"
+	"testing"
+)
+
+/**
+	static int f2i(float f) { return (int) f; }  以及 f2l、d2i、d2l
+ */
+func newFloatToIntVM(t *testing.T) *jvmtest.VM {
+	class := New("conv/Truncate", "java/lang/Object")
+	for _, m := range []struct {
+		name, descriptor string
+		load, op, ret    byte
+	}{
+		{"f2i", "(F)I", FLOAD_0, F2I, IRETURN},
+		{"f2l", "(F)J", FLOAD_0, F2L, LRETURN},
+		{"d2i", "(D)I", DLOAD_0, D2I, IRETURN},
+		{"d2l", "(D)J", DLOAD_0, D2L, LRETURN},
+	} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, m.name, m.descriptor).Code(2, 2).
+			Op(m.load).Op(m.op).Op(m.ret)
+	}
+	return jvmtest.New(t, class)
+}
+
+func TestFloatToIntClampsAndTruncates(t *testing.T) {
+	vm := newFloatToIntVM(t)
+	nan, inf := math.NaN(), math.Inf(1)
+	for _, test := range []struct {
+		d        float64
+		wantInt  int32
+		wantLong int64
+	}{
+		{inf, math.MaxInt32, math.MaxInt64},
+		{-inf, math.MinInt32, math.MinInt64},
+		{nan, 0, 0},
+		//向0取整
+		{2.9, 2, 2},
+		{-2.9, -2, -2},
+		{-0.5, 0, 0},
+		//超出int但没超出long
+		{1e10, math.MaxInt32, 10000000000},
+		{-1e10, math.MinInt32, -10000000000},
+		//超出long
+		{1e19, math.MaxInt32, math.MaxInt64},
+		{-1e19, math.MinInt32, math.MinInt64},
+	} {
+		if got := vm.Call("conv/Truncate", "d2i", "(D)I", test.d).Int(); got != test.wantInt {
+			t.Errorf("d2i(%v) = %d, want %d", test.d, got, test.wantInt)
+		}
+		if got := vm.Call("conv/Truncate", "d2l", "(D)J", test.d).Long(); got != test.wantLong {
+			t.Errorf("d2l(%v) = %d, want %d", test.d, got, test.wantLong)
+		}
+		//这些值转成float之后结果不变
+		f := float32(test.d)
+		if got := vm.Call("conv/Truncate", "f2i", "(F)I", f).Int(); got != test.wantInt {
+			t.Errorf("f2i(%v) = %d, want %d", f, got, test.wantInt)
+		}
+		if got := vm.Call("conv/Truncate", "f2l", "(F)J", f).Long(); got != test.wantLong {
+			t.Errorf("f2l(%v) = %d, want %d", f, got, test.wantLong)
+		}
+	}
+}
+
+/**
+	正好在边界上的值：2^31和2^63是第一个超出范围的，-2^31和-2^63正好能表示
+ */
+func TestFloatToIntAtTheBoundaries(t *testing.T) {
+	vm := newFloatToIntVM(t)
+	if got := vm.Call("conv/Truncate", "d2i", "(D)I", float64(1 << 31)).Int(); got != math.MaxInt32 {
+		t.Errorf("d2i(2^31) = %d", got)
+	}
+	if got := vm.Call("conv/Truncate", "d2i", "(D)I", float64(math.MaxInt32)).Int(); got != math.MaxInt32 {
+		t.Errorf("d2i(2^31 - 1) = %d", got)
+	}
+	if got := vm.Call("conv/Truncate", "d2i", "(D)I", float64(math.MinInt32)).Int(); got != math.MinInt32 {
+		t.Errorf("d2i(-2^31) = %d", got)
+	}
+	if got := vm.Call("conv/Truncate", "d2l", "(D)J", math.Ldexp(1, 63)).Long(); got != math.MaxInt64 {
+		t.Errorf("d2l(2^63) = %d", got)
+	}
+	if got := vm.Call("conv/Truncate", "d2l", "(D)J", math.Ldexp(-1, 63)).Long(); got != math.MinInt64 {
+		t.Errorf("d2l(-2^63) = %d", got)
+	}
+	//2^63以下最大的double
+	const below = math.MaxInt64 - 1023
+	if got := vm.Call("conv/Truncate", "d2l", "(D)J", math.Nextafter(math.Ldexp(1, 63), 0)).Long(); got != below {
+		t.Errorf("d2l(2^63 - 1024) = %d, want %d", got, int64(below))
+	}
+}
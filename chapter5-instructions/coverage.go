@@ -0,0 +1,76 @@
+package chapter5_instructions
+
+import (
+	"GoVM/chapter6-obj/heap"
+	"fmt"
+	"io"
+	"sort"
+)
+
+/**
+	字节码覆盖率收集器
+	记录每个方法中执行过的指令的pc，退出时可以配合LineNumberTable把pc映射回源码行号
+ */
+type Coverage struct {
+	methods map[*heap.Method]map[int]bool
+}
+
+//为nil时表示没有开启覆盖率收集
+var coverage *Coverage
+
+func EnableCoverage() {
+	coverage = &Coverage{
+		methods: make(map[*heap.Method]map[int]bool),
+	}
+}
+
+func (self *Coverage) record(method *heap.Method, pc int) {
+	pcs, ok := self.methods[method]
+	if !ok {
+		pcs = make(map[int]bool)
+		self.methods[method] = pcs
+	}
+	pcs[pc] = true
+}
+
+/**
+	某个方法执行过的所有pc，按从小到大排序
+ */
+func (self *Coverage) ExecutedPCs(method *heap.Method) []int {
+	pcs := make([]int, 0, len(self.methods[method]))
+	for pc := range self.methods[method] {
+		pcs = append(pcs, pc)
+	}
+	sort.Ints(pcs)
+	return pcs
+}
+
+/**
+	输出格式：
+	java/lang/Object.<init>()V
+	    pc:    0 line: 37
+ */
+func DumpCoverage(w io.Writer) {
+	if coverage == nil {
+		return
+	}
+
+	methods := make([]*heap.Method, 0, len(coverage.methods))
+	for method := range coverage.methods {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methodKey(methods[i]) < methodKey(methods[j])
+	})
+
+	for _, method := range methods {
+		fmt.Fprintln(w, methodKey(method))
+		for _, pc := range coverage.ExecutedPCs(method) {
+			fmt.Fprintf(w, "    pc: %4d line: %d\n", pc, method.GetLineNumber(pc))
+		}
+	}
+}
+
+func methodKey(method *heap.Method) string {
+	return method.Class().Name() + "." + method.Name() + method.Descriptor()
+}
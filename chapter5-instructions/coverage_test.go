@@ -0,0 +1,61 @@
+package chapter5_instructions
+
+import (
+	"GoVM/internal/classgen"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func enableTestCoverage(t *testing.T) {
+	EnableCoverage()
+	t.Cleanup(func() {
+		coverage = nil
+	})
+}
+
+/**
+	static int sign(int x) { if (x > 0) return 1; return -1; }
+	pc: 0 iload_0, 1 ifle 6, 4 iconst_1, 5 ireturn, 6 iconst_m1, 7 ireturn
+ */
+func TestCoverageOmitsTheBranchNotTaken(t *testing.T) {
+	class := classgen.New("cov/Sign", "java/lang/Object")
+	class.Method(classgen.ACC_STATIC, "sign", "(I)I").Code(1, 1).
+		Line(3).Op(classgen.ILOAD_0).Branch(classgen.IFLE, "negative").
+		Line(4).Op(classgen.ICONST_1).Op(classgen.IRETURN).
+		Label("negative").Line(5).Op(classgen.ICONST_M1).Op(classgen.IRETURN)
+	method := loadTestClass(t, class).GetStaticMethod("sign", "(I)I")
+	enableTestCoverage(t)
+
+	if got := runStatic(method, 5); got != 1 {
+		t.Fatalf("sign(5) = %d", got)
+	}
+	if got, want := coverage.ExecutedPCs(method), []int{0, 1, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExecutedPCs = %v, want %v", got, want)
+	}
+
+	var out bytes.Buffer
+	DumpCoverage(&out)
+	dump := out.String()
+	if !strings.Contains(dump, "cov/Sign.sign(I)I\n    pc:    0 line: 3\n") {
+		t.Errorf("dump does not list sign's first pc:\n%s", dump)
+	}
+	if strings.Contains(dump, "line: 5") {
+		t.Errorf("dump lists the branch that did not run:\n%s", dump)
+	}
+
+	//另一边执行过之后两边都有
+	runStatic(method, -5)
+	if got, want := coverage.ExecutedPCs(method), []int{0, 1, 4, 5, 6, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExecutedPCs = %v, want %v", got, want)
+	}
+}
+
+func TestCoverageDisabledByDefault(t *testing.T) {
+	var out bytes.Buffer
+	DumpCoverage(&out)
+	if coverage != nil || out.Len() != 0 {
+		t.Errorf("coverage is collected without EnableCoverage: %q", out.String())
+	}
+}
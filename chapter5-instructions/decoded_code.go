@@ -0,0 +1,48 @@
+package chapter5_instructions
+
+import (
+	"GoVM/chapter5-instructions/base"
+	"GoVM/chapter6-obj/heap"
+)
+
+/**
+	方法字节码解码之后的缓存
+	指令的Execute不会修改指令本身，所以解码出来的指令可以一直复用，
+	不用每次执行都重新解码操作数。insts和nextPCs都按pc索引
+	只在第一次执行到某个pc时才解码，这样方法里没执行到的、虚拟机还不支持的指令也不会报错
+ */
+type decodedCode struct {
+	insts   []base.Instruction
+	nextPCs []int
+}
+
+func getDecodedCode(method *heap.Method) *decodedCode {
+	if code, ok := method.DecodedCode().(*decodedCode); ok {
+		return code
+	}
+	codeLength := len(method.Code())
+	code := &decodedCode{
+		insts:   make([]base.Instruction, codeLength),
+		nextPCs: make([]int, codeLength),
+	}
+	method.SetDecodedCode(code)
+	return code
+}
+
+/**
+	取出pc处的指令和下一条指令的pc，还没解码过就先解码
+ */
+func (self *decodedCode) fetch(reader *base.BytecodeReader, method *heap.Method, pc int) (base.Instruction, int) {
+	if inst := self.insts[pc]; inst != nil {
+		return inst, self.nextPCs[pc]
+	}
+
+	reader.Reset(method.Code(), pc)
+	opcode := reader.ReadUInt8()
+	inst := NewInstruction(opcode)
+	inst.FetchOperands(reader)
+
+	self.insts[pc] = inst
+	self.nextPCs[pc] = reader.PC()
+	return inst, reader.PC()
+}
@@ -0,0 +1,84 @@
+package chapter5_instructions
+
+import (
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter5-instructions/base"
+	"GoVM/chapter6-obj/heap"
+	"GoVM/internal/classgen"
+	"sync"
+	"testing"
+)
+
+const loopIterations = 10000
+
+var (
+	loopOnce   sync.Once
+	loopMethod *heap.Method
+)
+
+/**
+	static void loop() { int sum = 0; for (int i = 0; i < 10000; i++) sum += i; }
+	b.Run会多次调用基准函数，类只加载一次
+ */
+func getLoopMethod(b *testing.B) *heap.Method {
+	loopOnce.Do(func() {
+		loopMethod = newLoopMethod(b)
+	})
+	return loopMethod
+}
+
+func newLoopMethod(b *testing.B) *heap.Method {
+	c := classgen.New("bench/Loop", "java/lang/Object")
+	c.Method(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "loop", "()V").Code(2, 2).
+		Op(classgen.ICONST_0).Op(classgen.ISTORE_0).
+		Op(classgen.ICONST_0).Op(classgen.ISTORE_1).
+		Label("cond").Op(classgen.ILOAD_1).Iconst(loopIterations).Branch(classgen.IF_ICMPGE, "done").
+		Op(classgen.ILOAD_0).Op(classgen.ILOAD_1).Op(classgen.IADD).Op(classgen.ISTORE_0).
+		Op(classgen.IINC, 1, 1).Branch(classgen.GOTO, "cond").
+		Label("done").Op(classgen.RETURN)
+
+	return loadTestClass(b, c).GetStaticMethod("loop", "()V")
+}
+
+/**
+	缓存之前的解释器循环：每条指令都重新解码
+ */
+func loopDecodeEveryTime(thread *chapter4_rtdt.Thread) {
+	reader := &base.BytecodeReader{}
+	for {
+		frame := thread.CurrentFrame()
+		pc := frame.NextPC()
+		thread.SetPC(pc)
+
+		reader.Reset(frame.Method().Code(), pc)
+		inst := NewInstruction(reader.ReadUInt8())
+		inst.FetchOperands(reader)
+		frame.SetNextPC(reader.PC())
+
+		inst.Execute(frame)
+		if thread.IsStackEmpty() {
+			break
+		}
+	}
+}
+
+func benchmarkInterpretLoop(b *testing.B, run func(*chapter4_rtdt.Thread)) {
+	method := getLoopMethod(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		thread := chapter4_rtdt.NewThread()
+		thread.PushFrame(thread.NewFrame(method))
+		run(thread)
+	}
+}
+
+func BenchmarkInterpretLoop(b *testing.B) {
+	b.Run("decode_every_time", func(b *testing.B) {
+		benchmarkInterpretLoop(b, loopDecodeEveryTime)
+	})
+	b.Run("cached_decode", func(b *testing.B) {
+		benchmarkInterpretLoop(b, func(thread *chapter4_rtdt.Thread) {
+			loop(thread, false)
+		})
+	})
+}
@@ -0,0 +1,265 @@
+package chapter5_instructions
+
+import (
+	"GoVM/chapter6-obj/heap"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/**
+	反汇编器，把字节码按 pc: 助记符 操作数 的格式一行一行打印出来，调试解释器时用
+	不走NewInstruction，虚拟机还没实现的指令也能打印；不认识的操作码打印成 <unknown 0xXX>，
+	字节码在操作数中间就结束了打印 <truncated>，都不会panic
+	跳转指令的目标打印成绝对的pc，常量池下标打印成 #index
+ */
+var mnemonics = map[byte]string{
+	0x00: "nop", 0x01: "aconst_null", 0x02: "iconst_m1", 0x03: "iconst_0",
+	0x04: "iconst_1", 0x05: "iconst_2", 0x06: "iconst_3", 0x07: "iconst_4",
+	0x08: "iconst_5", 0x09: "lconst_0", 0x0a: "lconst_1", 0x0b: "fconst_0",
+	0x0c: "fconst_1", 0x0d: "fconst_2", 0x0e: "dconst_0", 0x0f: "dconst_1",
+	0x10: "bipush", 0x11: "sipush", 0x12: "ldc", 0x13: "ldc_w",
+	0x14: "ldc2_w", 0x15: "iload", 0x16: "lload", 0x17: "fload",
+	0x18: "dload", 0x19: "aload", 0x1a: "iload_0", 0x1b: "iload_1",
+	0x1c: "iload_2", 0x1d: "iload_3", 0x1e: "lload_0", 0x1f: "lload_1",
+	0x20: "lload_2", 0x21: "lload_3", 0x22: "fload_0", 0x23: "fload_1",
+	0x24: "fload_2", 0x25: "fload_3", 0x26: "dload_0", 0x27: "dload_1",
+	0x28: "dload_2", 0x29: "dload_3", 0x2a: "aload_0", 0x2b: "aload_1",
+	0x2c: "aload_2", 0x2d: "aload_3", 0x2e: "iaload", 0x2f: "laload",
+	0x30: "faload", 0x31: "daload", 0x32: "aaload", 0x33: "baload",
+	0x34: "caload", 0x35: "saload", 0x36: "istore", 0x37: "lstore",
+	0x38: "fstore", 0x39: "dstore", 0x3a: "astore", 0x3b: "istore_0",
+	0x3c: "istore_1", 0x3d: "istore_2", 0x3e: "istore_3", 0x3f: "lstore_0",
+	0x40: "lstore_1", 0x41: "lstore_2", 0x42: "lstore_3", 0x43: "fstore_0",
+	0x44: "fstore_1", 0x45: "fstore_2", 0x46: "fstore_3", 0x47: "dstore_0",
+	0x48: "dstore_1", 0x49: "dstore_2", 0x4a: "dstore_3", 0x4b: "astore_0",
+	0x4c: "astore_1", 0x4d: "astore_2", 0x4e: "astore_3", 0x4f: "iastore",
+	0x50: "lastore", 0x51: "fastore", 0x52: "dastore", 0x53: "aastore",
+	0x54: "bastore", 0x55: "castore", 0x56: "sastore", 0x57: "pop",
+	0x58: "pop2", 0x59: "dup", 0x5a: "dup_x1", 0x5b: "dup_x2",
+	0x5c: "dup2", 0x5d: "dup2_x1", 0x5e: "dup2_x2", 0x5f: "swap",
+	0x60: "iadd", 0x61: "ladd", 0x62: "fadd", 0x63: "dadd",
+	0x64: "isub", 0x65: "lsub", 0x66: "fsub", 0x67: "dsub",
+	0x68: "imul", 0x69: "lmul", 0x6a: "fmul", 0x6b: "dmul",
+	0x6c: "idiv", 0x6d: "ldiv", 0x6e: "fdiv", 0x6f: "ddiv",
+	0x70: "irem", 0x71: "lrem", 0x72: "frem", 0x73: "drem",
+	0x74: "ineg", 0x75: "lneg", 0x76: "fneg", 0x77: "dneg",
+	0x78: "ishl", 0x79: "lshl", 0x7a: "ishr", 0x7b: "lshr",
+	0x7c: "iushr", 0x7d: "lushr", 0x7e: "iand", 0x7f: "land",
+	0x80: "ior", 0x81: "lor", 0x82: "ixor", 0x83: "lxor",
+	0x84: "iinc", 0x85: "i2l", 0x86: "i2f", 0x87: "i2d",
+	0x88: "l2i", 0x89: "l2f", 0x8a: "l2d", 0x8b: "f2i",
+	0x8c: "f2l", 0x8d: "f2d", 0x8e: "d2i", 0x8f: "d2l",
+	0x90: "d2f", 0x91: "i2b", 0x92: "i2c", 0x93: "i2s",
+	0x94: "lcmp", 0x95: "fcmpl", 0x96: "fcmpg", 0x97: "dcmpl",
+	0x98: "dcmpg", 0x99: "ifeq", 0x9a: "ifne", 0x9b: "iflt",
+	0x9c: "ifge", 0x9d: "ifgt", 0x9e: "ifle", 0x9f: "if_icmpeq",
+	0xa0: "if_icmpne", 0xa1: "if_icmplt", 0xa2: "if_icmpge", 0xa3: "if_icmpgt",
+	0xa4: "if_icmple", 0xa5: "if_acmpeq", 0xa6: "if_acmpne", 0xa7: "goto",
+	0xa8: "jsr", 0xa9: "ret", 0xaa: "tableswitch", 0xab: "lookupswitch",
+	0xac: "ireturn", 0xad: "lreturn", 0xae: "freturn", 0xaf: "dreturn",
+	0xb0: "areturn", 0xb1: "return", 0xb2: "getstatic", 0xb3: "putstatic",
+	0xb4: "getfield", 0xb5: "putfield", 0xb6: "invokevirtual", 0xb7: "invokespecial",
+	0xb8: "invokestatic", 0xb9: "invokeinterface", 0xba: "invokedynamic", 0xbb: "new",
+	0xbc: "newarray", 0xbd: "anewarray", 0xbe: "arraylength", 0xbf: "athrow",
+	0xc0: "checkcast", 0xc1: "instanceof", 0xc2: "monitorenter", 0xc3: "monitorexit",
+	0xc4: "wide", 0xc5: "multianewarray", 0xc6: "ifnull", 0xc7: "ifnonnull",
+	0xc8: "goto_w", 0xc9: "jsr_w", 0xca: "breakpoint", 0xfe: "invokenative",
+	0xff: "impdep2",
+}
+
+//newarray的atype
+var arrayTypeNames = map[uint]string{
+	4: "boolean", 5: "char", 6: "float", 7: "double",
+	8: "byte", 9: "short", 10: "int", 11: "long",
+}
+
+func DisassembleMethod(method *heap.Method) string {
+	var buf bytes.Buffer
+	Disassemble(&buf, method.Code())
+	return buf.String()
+}
+
+func Disassemble(w io.Writer, code []byte) {
+	d := &disassembler{code: code}
+	for d.pc < len(code) {
+		pc := d.pc
+		text, ok := d.next()
+		fmt.Fprintf(w, "%6d: %s\n", pc, text)
+		if !ok {
+			return
+		}
+	}
+}
+
+type disassembler struct {
+	code []byte
+	pc   int
+	//当前指令操作数的读取位置
+	pos  int
+	//读取时越过了字节码的末尾
+	truncated bool
+}
+
+/**
+	返回pc处指令的文字形式，字节码被截断时第二个返回值为false，后面不再继续
+ */
+func (self *disassembler) next() (string, bool) {
+	pc := self.pc
+	opcode := self.code[pc]
+	self.pos = pc + 1
+	name, ok := mnemonics[opcode]
+	if !ok {
+		self.pc = pc + 1
+		return fmt.Sprintf("<unknown 0x%02x>", opcode), true
+	}
+
+	operands := self.operands(opcode, pc)
+	if self.truncated {
+		return name + " <truncated>", false
+	}
+	self.pc = self.pos
+	if operands == "" {
+		return name, true
+	}
+	return name + " " + operands, true
+}
+
+func (self *disassembler) operands(opcode byte, pc int) string {
+	switch {
+	case opcode >= 0x15 && opcode <= 0x19, opcode >= 0x36 && opcode <= 0x3a, opcode == 0xa9: // xload xstore ret
+		return fmt.Sprint(self.u1())
+	case opcode >= 0x99 && opcode <= 0xa8, opcode == 0xc6, opcode == 0xc7: // ifxx goto jsr ifnull ifnonnull
+		return fmt.Sprint(pc + int(self.s2()))
+	}
+
+	switch opcode {
+	case 0x10: // bipush
+		return fmt.Sprint(int8(self.u1()))
+	case 0x11: // sipush
+		return fmt.Sprint(self.s2())
+	case 0x12: // ldc
+		return fmt.Sprintf("#%d", self.u1())
+	case 0x13, 0x14, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6, 0xb7, 0xb8, 0xbb, 0xbd, 0xc0, 0xc1:
+		return fmt.Sprintf("#%d", self.u2())
+	case 0x84: // iinc
+		index := self.u1()
+		return fmt.Sprintf("%d, %d", index, int8(self.u1()))
+	case 0xaa:
+		return self.tableSwitch(pc)
+	case 0xab:
+		return self.lookupSwitch(pc)
+	case 0xb9: // invokeinterface
+		index := self.u2()
+		count := self.u1()
+		self.u1()
+		return fmt.Sprintf("#%d, %d", index, count)
+	case 0xba: // invokedynamic
+		index := self.u2()
+		self.u2()
+		return fmt.Sprintf("#%d, 0", index)
+	case 0xbc: // newarray
+		atype := self.u1()
+		if name, ok := arrayTypeNames[atype]; ok {
+			return name
+		}
+		return fmt.Sprintf("<unknown atype %d>", atype)
+	case 0xc4:
+		return self.wide()
+	case 0xc5: // multianewarray
+		index := self.u2()
+		return fmt.Sprintf("#%d, %d", index, self.u1())
+	case 0xc8, 0xc9: // goto_w jsr_w
+		return fmt.Sprint(pc + int(self.s4()))
+	}
+	return ""
+}
+
+/**
+	wide修饰的是 xload xstore ret（u2的下标） 和 iinc（u2的下标，s2的增量）
+ */
+func (self *disassembler) wide() string {
+	opcode := byte(self.u1())
+	name, ok := mnemonics[opcode]
+	switch {
+	case self.truncated:
+		return ""
+	case opcode == 0x84:
+		index := self.u2()
+		return fmt.Sprintf("iinc %d, %d", index, self.s2())
+	case ok && (opcode >= 0x15 && opcode <= 0x19 || opcode >= 0x36 && opcode <= 0x3a || opcode == 0xa9):
+		return fmt.Sprintf("%s %d", name, self.u2())
+	}
+	return fmt.Sprintf("<unknown 0x%02x>", opcode)
+}
+
+/**
+	操作码后面有0~3字节的填充，让default从4的倍数的地址开始
+ */
+func (self *disassembler) skipPadding() {
+	for self.pos % 4 != 0 {
+		self.u1()
+	}
+}
+
+func (self *disassembler) tableSwitch(pc int) string {
+	self.skipPadding()
+	defaultOffset := self.s4()
+	low := self.s4()
+	high := self.s4()
+	//low > high 或者跳转表比剩下的字节码还长，都当成截断
+	if self.truncated || low > high || int64(high) - int64(low) + 1 > int64(len(self.code) - self.pos) / 4 {
+		self.truncated = true
+		return ""
+	}
+
+	cases := make([]string, 0, high - low + 2)
+	for i := int64(low); i <= int64(high); i++ {
+		cases = append(cases, fmt.Sprintf("%d: %d", i, pc + int(self.s4())))
+	}
+	cases = append(cases, fmt.Sprintf("default: %d", pc + int(defaultOffset)))
+	return fmt.Sprintf("%d..%d { %s }", low, high, strings.Join(cases, ", "))
+}
+
+func (self *disassembler) lookupSwitch(pc int) string {
+	self.skipPadding()
+	defaultOffset := self.s4()
+	npairs := self.s4()
+	if self.truncated || npairs < 0 || int64(npairs) > int64(len(self.code) - self.pos) / 8 {
+		self.truncated = true
+		return ""
+	}
+
+	cases := make([]string, 0, npairs + 1)
+	for i := int32(0); i < npairs; i++ {
+		match := self.s4()
+		cases = append(cases, fmt.Sprintf("%d: %d", match, pc + int(self.s4())))
+	}
+	cases = append(cases, fmt.Sprintf("default: %d", pc + int(defaultOffset)))
+	return fmt.Sprintf("%d { %s }", npairs, strings.Join(cases, ", "))
+}
+
+func (self *disassembler) u1() uint {
+	if self.pos >= len(self.code) {
+		self.truncated = true
+		return 0
+	}
+	b := self.code[self.pos]
+	self.pos++
+	return uint(b)
+}
+
+func (self *disassembler) u2() uint {
+	high := self.u1()
+	return high << 8 | self.u1()
+}
+
+func (self *disassembler) s2() int16 {
+	return int16(self.u2())
+}
+
+func (self *disassembler) s4() int32 {
+	high := self.u2()
+	return int32(high << 16 | self.u2())
+}
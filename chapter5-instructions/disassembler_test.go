@@ -0,0 +1,88 @@
+package chapter5_instructions
+
+import (
+	"GoVM/internal/classgen"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+/**
+	把 pc 和指令拼成Disassemble输出的格式
+ */
+func listing(lines ...interface{}) string {
+	var b strings.Builder
+	for i := 0; i < len(lines); i += 2 {
+		fmt.Fprintf(&b, "%6d: %s\n", lines[i], lines[i + 1])
+	}
+	return b.String()
+}
+
+func TestDisassemble(t *testing.T) {
+	for _, test := range []struct {
+		code []byte
+		want string
+	}{
+		{[]byte{0x10, 0xfb, 0x11, 0x01, 0x2c}, listing(0, "bipush -5", 2, "sipush 300")},
+		{[]byte{0x84, 0x01, 0xff}, listing(0, "iinc 1, -1")},
+		{[]byte{0x12, 0x03, 0xb2, 0x00, 0x07}, listing(0, "ldc #3", 2, "getstatic #7")},
+		{[]byte{0xb9, 0x00, 0x09, 0x02, 0x00}, listing(0, "invokeinterface #9, 2")},
+		{[]byte{0xbc, 0x0a}, listing(0, "newarray int")},
+		//跳转目标是绝对的pc
+		{[]byte{0x00, 0xa7, 0xff, 0xff}, listing(0, "nop", 1, "goto 0")},
+		{[]byte{0x00, 0xc8, 0x00, 0x00, 0x00, 0x05}, listing(0, "nop", 1, "goto_w 6")},
+		{[]byte{0xc4, 0x15, 0x01, 0x2c}, listing(0, "wide iload 300")},
+		{[]byte{0xc4, 0x84, 0x01, 0x2c, 0xff, 0x9c}, listing(0, "wide iinc 300, -100")},
+		//tableswitch在pc 1，后面两个字节的填充
+		{[]byte{
+			0x00, 0xaa, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x1b,
+			0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
+			0x00, 0x00, 0x00, 0x13, 0x00, 0x00, 0x00, 0x17,
+		}, listing(0, "nop", 1, "tableswitch 1..2 { 1: 20, 2: 24, default: 28 }")},
+		{[]byte{
+			0xab, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x1e,
+			0x00, 0x00, 0x00, 0x02,
+			0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x0a,
+			0x00, 0x00, 0x00, 0x64, 0x00, 0x00, 0x00, 0x14,
+		}, listing(0, "lookupswitch 2 { -1: 10, 100: 20, default: 30 }")},
+		//不认识的操作码只占一个字节，后面接着打印
+		{[]byte{0xcb, 0x00}, listing(0, "<unknown 0xcb>", 1, "nop")},
+		{[]byte{0xc4, 0x60}, listing(0, "wide <unknown 0x60>")},
+		//操作数不完整的指令打印之后就停下
+		{[]byte{0x00, 0x11, 0x01}, listing(0, "nop", 1, "sipush <truncated>")},
+		{[]byte{0xaa, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09},
+			listing(0, "tableswitch <truncated>")},
+	} {
+		var out bytes.Buffer
+		Disassemble(&out, test.code)
+		if out.String() != test.want {
+			t.Errorf("Disassemble(% x) =\n%swant\n%s", test.code, out.String(), test.want)
+		}
+	}
+}
+
+/**
+	static int abs(int x) { return x >= 0 ? x : -x; }
+	static native int twice(int x);      //字节码是加载时注入的 invokenative + ireturn
+ */
+func TestDisassembleMethod(t *testing.T) {
+	c := classgen.New("dis/Methods", "java/lang/Object")
+	c.Method(classgen.ACC_STATIC, "abs", "(I)I").Code(1, 1).
+		Op(classgen.ILOAD_0).Branch(classgen.IFGE, "positive").
+		Op(classgen.ILOAD_0).Op(classgen.INEG).Op(classgen.IRETURN).
+		Label("positive").Op(classgen.ILOAD_0).Op(classgen.IRETURN)
+	c.Method(classgen.ACC_STATIC|classgen.ACC_NATIVE, "twice", "(I)I")
+	class := loadTestClass(t, c)
+
+	for _, test := range []struct{ name, want string }{
+		{"abs", listing(0, "iload_0", 1, "ifge 7", 4, "iload_0", 5, "ineg", 6, "ireturn", 7, "iload_0", 8, "ireturn")},
+		{"twice", listing(0, "invokenative", 1, "ireturn")},
+	} {
+		if got := DisassembleMethod(class.GetStaticMethod(test.name, "(I)I")); got != test.want {
+			t.Errorf("DisassembleMethod(%s) =\n%swant\n%s", test.name, got, test.want)
+		}
+	}
+}
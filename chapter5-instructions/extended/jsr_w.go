@@ -0,0 +1,22 @@
+package extended
+
+import (
+	"GoVM/chapter5-instructions/base"
+	"GoVM/chapter4-rtdt"
+)
+
+type JSR_W struct {
+	offset int
+}
+
+/**
+	与jsr唯一的区别在于偏移量从2字节变成了4字节
+ */
+func (self *JSR_W) FetchOperands(reader *base.BytecodeReader) {
+	self.offset = int(reader.ReadInt32())
+}
+
+func (self *JSR_W) Execute(frame *chapter4_rtdt.Frame) {
+	frame.OperandStack().PushReturnAddress(frame.NextPC())
+	base.Branch(frame, self.offset)
+}
@@ -5,6 +5,7 @@ import (
 	"GoVM/chapter5-instructions/loads"
 	"GoVM/chapter5-instructions/stores"
 	"GoVM/chapter5-instructions/math"
+	"GoVM/chapter5-instructions/control"
 	"GoVM/chapter4-rtdt"
 )
 
@@ -68,7 +69,9 @@ func (self *WIDE) FetchOperands(reader *base.BytecodeReader) {
 		inst.Const = int32(reader.ReadInt16())
 		self.modifiedInstruction = inst
 	case 0xa9:                                // ret
-		panic("Unsupported opcode: 0xa9!")
+		inst := &control.RET{}
+		inst.Index = uint(reader.ReadUInt16())
+		self.modifiedInstruction = inst
 	}
 }
 
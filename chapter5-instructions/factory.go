@@ -163,8 +163,8 @@ var (
 	_return = &control.RETURN{}
 	arraylength = &references.ARRAY_LENGTH{}
 	athrow = &references.ATHROW{}
-	// monitorenter  = &MONITOR_ENTER{}
-	// monitorexit   = &MONITOR_EXIT{}
+	monitorenter = &references.MONITOR_ENTER{}
+	monitorexit = &references.MONITOR_EXIT{}
 	invoke_native = &reserved.INVOKE_NATIVE{}
 )
 
@@ -506,10 +506,10 @@ func NewInstruction(opcode byte) base.Instruction {
 		return &comparisons.IF_ACMPNE{}
 	case 0xa7:
 		return &control.GOTO{}
-	// case 0xa8:
-	// 	return &JSR{}
-	// case 0xa9:
-	// 	return &RET{}
+	case 0xa8:
+		return &control.JSR{}
+	case 0xa9:
+		return &control.RET{}
 	case 0xaa:
 		return &control.TABLE_SWITCH{}
 	case 0xab:
@@ -542,8 +542,8 @@ func NewInstruction(opcode byte) base.Instruction {
 		return &references.INVOKE_STATIC{}
 	case 0xb9:
 		return &references.INVOKE_INTERFACE{}
-	// case 0xba:
-	// 	return &INVOKE_DYNAMIC{}
+	case 0xba:
+		return &references.INVOKE_DYNAMIC{}
 	case 0xbb:
 		return &references.NEW{}
 	case 0xbc:
@@ -558,10 +558,10 @@ func NewInstruction(opcode byte) base.Instruction {
 		return &references.CHECK_CAST{}
 	case 0xc1:
 		return &references.INSTANCE_OF{}
-	// case 0xc2:
-	// 	return monitorenter
-	// case 0xc3:
-	// 	return monitorexit
+	case 0xc2:
+		return monitorenter
+	case 0xc3:
+		return monitorexit
 	case 0xc4:
 		return &extended.WIDE{}
 	case 0xc5:
@@ -572,8 +572,8 @@ func NewInstruction(opcode byte) base.Instruction {
 		return &extended.IFNONNULL{}
 	case 0xc8:
 		return &extended.GOTO_W{}
-	// case 0xc9:
-	// 	return &JSR_W{}
+	case 0xc9:
+		return &extended.JSR_W{}
 	// case 0xca: breakpoint
 	case 0xfe:
 		return invoke_native
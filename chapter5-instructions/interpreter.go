@@ -50,21 +50,30 @@ func catchErr(thread *chapter4_rtdt.Thread) {
 
 func loop(thread *chapter4_rtdt.Thread, logInst bool) {
 	reader := &base.BytecodeReader{}
+	jvmHeap := heap.GetHeap()
 	for {
+		//两条指令之间，活着的对象都能从栈帧和静态变量找到，在这里GC是安全的
+		if jvmHeap.GCRequested() {
+			jvmHeap.GC(thread.GCRoots())
+		} else if jvmHeap.MinorGCRequested() {
+			jvmHeap.MinorGC(thread.GCRoots())
+		}
+
 		frame := thread.CurrentFrame()
 		pc := frame.NextPC()
 		thread.SetPC(pc)
 
-		//decode
-		reader.Reset(frame.Method().Code(), pc)
-		opcode := reader.ReadUInt8()
-		inst := NewInstruction(opcode)
-		inst.FetchOperands(reader)
-		frame.SetNextPC(reader.PC())
+		//decode，解码过的指令直接从缓存中取
+		method := frame.Method()
+		inst, nextPC := getDecodedCode(method).fetch(reader, method, pc)
+		frame.SetNextPC(nextPC)
 
 		if (logInst) {
 			logInstruction(frame, inst)
 		}
+		if coverage != nil {
+			coverage.record(frame.Method(), pc)
+		}
 
 		//execute
 		//fmt.Printf("pc : %2d inst:%T %v \n", pc, inst, inst)
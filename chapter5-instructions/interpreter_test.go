@@ -0,0 +1,75 @@
+package chapter5_instructions
+
+import (
+	"GoVM/chapter2-class/classpath"
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+	"GoVM/internal/classgen"
+	"GoVM/internal/testjdk"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	bootOnce   sync.Once
+	bootLoader *heap.ClassLoader
+)
+
+/**
+	这个包的测试不能用jvmtest（它引用了这个包），自己建加载器
+ */
+func testBootLoader(tb testing.TB) *heap.ClassLoader {
+	jre := testjdk.JRE(tb)
+	bootOnce.Do(func() {
+		bootLoader = heap.NewClassLoader(nil, classpath.Parse(jre, jre), false, false)
+	})
+	return bootLoader
+}
+
+/**
+	类里加一个 static void host() { nop; return; } 作为调用方的栈帧
+ */
+func loadTestClass(tb testing.TB, class *classgen.Class) *heap.Class {
+	class.Method(classgen.ACC_STATIC, "host", "()V").Code(1, 0).Op(classgen.NOP).Op(classgen.RETURN)
+	loader := heap.NewClassLoader(testBootLoader(tb), testjdk.Classpath(tb, class), false, false)
+	return loader.LoadClass(class.Name())
+}
+
+/**
+	执行一个参数都是int、返回int的静态方法。调用方从host的return开始执行，被调用的方法返回之后线程就结束了
+ */
+func runStatic(method *heap.Method, args ...int32) int32 {
+	thread := chapter4_rtdt.NewThread()
+	host := thread.NewFrame(method.Class().GetStaticMethod("host", "()V"))
+	host.SetNextPC(1)
+	thread.PushFrame(host)
+	frame := thread.NewFrame(method)
+	for i, arg := range args {
+		frame.LocalVars().SetInt(uint(i), arg)
+	}
+	thread.PushFrame(frame)
+	loop(thread, false)
+	return host.OperandStack().PopInt()
+}
+
+/**
+	static int add() { return 1 + 2; } 需要两个slot，却只声明了一个
+ */
+func TestUnderDeclaredMaxStackIsRejected(t *testing.T) {
+	class := classgen.New("interp/Small", "java/lang/Object")
+	class.Method(classgen.ACC_STATIC, "add", "()I").Code(1, 0).
+		Op(classgen.ICONST_1).Op(classgen.ICONST_2).Op(classgen.IADD).Op(classgen.IRETURN)
+	method := loadTestClass(t, class).GetStaticMethod("add", "()I")
+
+	r := func() (r interface{}) {
+		defer func() {
+			r = recover()
+		}()
+		runStatic(method)
+		return nil
+	}()
+	if msg, _ := r.(string); !strings.HasPrefix(msg, "java.lang.VerifyError:") || !strings.Contains(msg, "stack overflow") {
+		t.Errorf("running add() panicked with %v, want a stack overflow VerifyError", r)
+	}
+}
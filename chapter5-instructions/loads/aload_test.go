@@ -0,0 +1,39 @@
+package loads_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	class Counter {
+		int n;
+		int incr() { return ++this.n; }  //aload_0 是接收者
+		static int run() {
+			Counter c0 = new Counter(); c1 = c0; c2 = c1; c3 = c2; c4 = c3;
+			c4.incr();
+			return c0.incr();
+		}
+	}
+ */
+func TestAloadPushesTheReceiver(t *testing.T) {
+	class := New("loads/Counter", "java/lang/Object").DefaultConstructor()
+	class.Field(0, "n", "I")
+	class.Method(0, "incr", "()I").Code(3, 1).
+		Op(ALOAD_0).Op(DUP).Getfield("loads/Counter", "n", "I").Op(ICONST_1).Op(IADD).
+		Putfield("loads/Counter", "n", "I").
+		Op(ALOAD_0).Getfield("loads/Counter", "n", "I").Op(IRETURN)
+	class.Method(ACC_STATIC, "run", "()I").Code(2, 5).
+		New("loads/Counter").Op(DUP).Invokespecial("loads/Counter", "<init>", "()V").Op(ASTORE_0).
+		Op(ALOAD_0).Op(ASTORE_1).Op(ALOAD_1).Op(ASTORE_2).Op(ALOAD_2).Op(ASTORE_3).
+		Op(ALOAD_3).Op(ASTORE, 4).
+		Op(ALOAD, 4).Invokevirtual("loads/Counter", "incr", "()I").Op(POP).
+		Op(ALOAD_0).Invokevirtual("loads/Counter", "incr", "()I").Op(IRETURN)
+	vm := jvmtest.New(t, class)
+
+	//每个局部变量里都是同一个对象
+	if got := vm.Call("loads/Counter", "run", "()I").Int(); got != 2 {
+		t.Errorf("run() = %d, want 2", got)
+	}
+}
@@ -1,7 +1,6 @@
 package loads
 
 import (
-	"GoVM/chapter6-obj/heap"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter5-instructions/base"
 )
@@ -21,9 +20,13 @@ func (self *AALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	refs := arrRef.Refs()
-	checkIndex(len(refs), index)
+	if !base.CheckIndex(frame, len(refs), index) {
+		return
+	}
 	stack.PushRef(refs[index])
 }
 
@@ -37,9 +40,13 @@ func (self *BALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	bytes := arrRef.Bytes()
-	checkIndex(len(bytes), index)
+	if !base.CheckIndex(frame, len(bytes), index) {
+		return
+	}
 	stack.PushInt(int32(bytes[index]))
 }
 
@@ -53,9 +60,13 @@ func (self *CALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	chars := arrRef.Chars()
-	checkIndex(len(chars), index)
+	if !base.CheckIndex(frame, len(chars), index) {
+		return
+	}
 	stack.PushInt(int32(chars[index]))
 }
 
@@ -69,9 +80,13 @@ func (self *DALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	doubles := arrRef.Doubles()
-	checkIndex(len(doubles), index)
+	if !base.CheckIndex(frame, len(doubles), index) {
+		return
+	}
 	stack.PushDouble(doubles[index])
 }
 
@@ -85,9 +100,13 @@ func (self *FALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	floats := arrRef.Floats()
-	checkIndex(len(floats), index)
+	if !base.CheckIndex(frame, len(floats), index) {
+		return
+	}
 	stack.PushFloat(floats[index])
 }
 
@@ -101,9 +120,13 @@ func (self *IALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	ints := arrRef.Ints()
-	checkIndex(len(ints), index)
+	if !base.CheckIndex(frame, len(ints), index) {
+		return
+	}
 	stack.PushInt(ints[index])
 }
 
@@ -117,9 +140,13 @@ func (self *LALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	longs := arrRef.Longs()
-	checkIndex(len(longs), index)
+	if !base.CheckIndex(frame, len(longs), index) {
+		return
+	}
 	stack.PushLong(longs[index])
 }
 
@@ -133,19 +160,12 @@ func (self *SALOAD) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
-	shorts := arrRef.Shorts()
-	checkIndex(len(shorts), index)
-	stack.PushInt(int32(shorts[index]))
-}
-
-func checkNotNil(ref *heap.Object) {
-	if ref == nil {
-		panic("java.lang.NullPointerException")
+	if !base.CheckNotNil(frame, arrRef) {
+		return
 	}
-}
-func checkIndex(arrLen int, index int32) {
-	if index < 0 || index >= int32(arrLen) {
-		panic("ArrayIndexOutOfBoundsException")
+	shorts := arrRef.Shorts()
+	if !base.CheckIndex(frame, len(shorts), index) {
+		return
 	}
+	stack.PushInt(int32(shorts[index]))
 }
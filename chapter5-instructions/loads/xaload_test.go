@@ -0,0 +1,41 @@
+package loads_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	static int load(int length, int index) { return new int[length][index]; }
+ */
+func TestIaloadOutOfBoundsIsCatchable(t *testing.T) {
+	class := New("loads/Arrays", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "load", "(II)I").Code(2, 2).
+		Op(ILOAD_0).Op(NEWARRAY, T_INT).Op(ILOAD_1).Op(IALOAD).Op(IRETURN)
+	//try { long x = ((long[]) null)[0]; return null; } catch (NullPointerException e) { return "npe"; }
+	class.Method(ACC_PUBLIC|ACC_STATIC, "loadNull", "()Ljava/lang/String;").Code(4, 0).
+		Label("start").Op(ACONST_NULL).Op(ICONST_0).Op(LALOAD).Op(POP2).Op(ACONST_NULL).Op(ARETURN).
+		Label("handler").Op(POP).LdcString("npe").Op(ARETURN).
+		Catch("start", "handler", "handler", "java/lang/NullPointerException")
+	vm := jvmtest.New(t, class)
+
+	if got := vm.Call("loads/Arrays", "load", "(II)I", 3, 2).Int(); got != 0 {
+		t.Errorf("new int[3][2] = %d", got)
+	}
+	for _, test := range []struct {
+		index int
+		want  string
+	}{
+		{3, "Index 3 out of bounds for length 3"},
+		{-1, "Index -1 out of bounds for length 3"},
+	} {
+		msg := vm.Call("loads/Arrays", "load", "(II)I", 3, test.index).Throws("java/lang/ArrayIndexOutOfBoundsException")
+		if msg != test.want {
+			t.Errorf("message = %q, want %q", msg, test.want)
+		}
+	}
+	if got := vm.Call("loads/Arrays", "loadNull", "()Ljava/lang/String;").String(); got != "npe" {
+		t.Errorf("laload on null = %q, want npe", got)
+	}
+}
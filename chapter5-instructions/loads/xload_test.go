@@ -0,0 +1,65 @@
+package loads_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"math"
+	"testing"
+)
+
+var localTypes = []struct {
+	descriptor                      string
+	load, store, load0, store0, ret byte
+}{
+	{"I", ILOAD, ISTORE, ILOAD_0, ISTORE_0, IRETURN},
+	{"J", LLOAD, LSTORE, LLOAD_0, LSTORE_0, LRETURN},
+	{"F", FLOAD, FSTORE, FLOAD_0, FSTORE_0, FRETURN},
+	{"D", DLOAD, DSTORE, DLOAD_0, DSTORE_0, DRETURN},
+}
+
+/**
+	参数在局部变量0里，依次存到1、2、3（xstore_N），再存到5（带u1下标的xstore），
+	最后存到300（wide xstore，u2下标），每存一次就从同一个位置读回来，最后返回
+		static long J(long v) { long a1 = v; long a2 = a1; ... long a300 = a5; return a300; }
+	long和double占两个槽，相邻的下标会互相覆盖，但每次都是读完再写下一个，不影响结果
+ */
+func newLocalsVM(t *testing.T) *jvmtest.VM {
+	class := New("loads/Locals", "java/lang/Object")
+	for _, local := range localTypes {
+		descriptor := "(" + local.descriptor + ")" + local.descriptor
+		code := class.Method(ACC_PUBLIC|ACC_STATIC, local.descriptor, descriptor).Code(2, 302).
+			Op(local.load0)
+		for n := byte(1); n <= 3; n++ {
+			code.Op(local.store0 + n).Op(local.load0 + n)
+		}
+		code.Op(local.store, 5).Op(local.load, 5).
+			Op(WIDE, local.store, 0x01, 0x2c).Op(WIDE, local.load, 0x01, 0x2c).
+			Op(local.ret)
+	}
+	return jvmtest.New(t, class)
+}
+
+func TestLocalsRoundTrip(t *testing.T) {
+	vm := newLocalsVM(t)
+	for _, i := range []int32{0, -1, math.MaxInt32, math.MinInt32, 0x12345678} {
+		if got := vm.Call("loads/Locals", "I", "(I)I", i).Int(); got != i {
+			t.Errorf("int %d came back as %d", i, got)
+		}
+	}
+	//高32位和低32位都有值，两个槽任何一个弄错都会变
+	for _, l := range []int64{-1, math.MinInt64, 0x123456789abcdef0, 1 << 32, 0xffffffff} {
+		if got := vm.Call("loads/Locals", "J", "(J)J", l).Long(); got != l {
+			t.Errorf("long %#x came back as %#x", l, got)
+		}
+	}
+	for _, f := range []float32{1.5, float32(math.Copysign(0, -1)), float32(math.Inf(-1)), math.MaxFloat32} {
+		if got := vm.Call("loads/Locals", "F", "(F)F", f).Float(); math.Float32bits(got) != math.Float32bits(f) {
+			t.Errorf("float %v came back as %v", f, got)
+		}
+	}
+	for _, d := range []float64{math.Pi, math.Copysign(0, -1), math.SmallestNonzeroFloat64, -1e300} {
+		if got := vm.Call("loads/Locals", "D", "(D)D", d).Double(); math.Float64bits(got) != math.Float64bits(d) {
+			t.Errorf("double %v came back as %v", d, got)
+		}
+	}
+}
@@ -12,8 +12,8 @@ type DADD struct {
 
 func (self *DADD) Execute(frame *chapter4_rtdt.Frame) {
 	stack := frame.OperandStack()
-	v1 := stack.PopDouble()
 	v2 := stack.PopDouble()
+	v1 := stack.PopDouble()
 	result := v1 + v2
 	stack.PushDouble(result)
 }
@@ -0,0 +1,64 @@
+package math_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"math"
+	"testing"
+)
+
+/**
+	static double dadd(double a, double b) { return a + b; } 以及dsub、dmul
+	static float fadd(float a, float b) { return a + b; } 以及fsub、fmul
+ */
+func newFloatArithVM(t *testing.T) *jvmtest.VM {
+	class := New("math/FloatArith", "java/lang/Object")
+	for name, opcode := range map[string]byte{"dadd": DADD, "dsub": DSUB, "dmul": DMUL} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(DD)D").Code(4, 4).
+			Op(DLOAD_0).Op(DLOAD_2).Op(opcode).Op(DRETURN)
+	}
+	for name, opcode := range map[string]byte{"fadd": FADD, "fsub": FSUB, "fmul": FMUL} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(FF)F").Code(2, 2).
+			Op(FLOAD_0).Op(FLOAD_1).Op(opcode).Op(FRETURN)
+	}
+	return jvmtest.New(t, class)
+}
+
+/**
+	按位比较，这样+0.0和-0.0能区分开；NaN只要是NaN就行
+ */
+func sameFloat(got, want float64) bool {
+	if math.IsNaN(want) {
+		return math.IsNaN(got)
+	}
+	return math.Float64bits(got) == math.Float64bits(want)
+}
+
+func TestFloatArithmeticSpecialValues(t *testing.T) {
+	vm := newFloatArithVM(t)
+	negZero, inf, nan := math.Copysign(0, -1), math.Inf(1), math.NaN()
+	for _, test := range []struct {
+		op         string
+		a, b, want float64
+	}{
+		{"add", negZero, 0, 0},
+		{"add", negZero, negZero, negZero},
+		{"add", inf, -inf, nan},
+		{"add", nan, 1, nan},
+		{"mul", nan, 2, nan},
+		{"mul", 0, inf, nan},
+		{"mul", negZero, 3, negZero},
+		//操作数的顺序：value1 - value2
+		{"sub", 1, 3, -2},
+		{"sub", 0, 0, 0},
+		{"sub", negZero, 0, negZero},
+	} {
+		if got := vm.Call("math/FloatArith", "d" + test.op, "(DD)D", test.a, test.b).Double(); !sameFloat(got, test.want) {
+			t.Errorf("d%s(%v, %v) = %v, want %v", test.op, test.a, test.b, got, test.want)
+		}
+		got := vm.Call("math/FloatArith", "f" + test.op, "(FF)F", float32(test.a), float32(test.b)).Float()
+		if !sameFloat(float64(got), test.want) {
+			t.Errorf("f%s(%v, %v) = %v, want %v", test.op, test.a, test.b, got, test.want)
+		}
+	}
+}
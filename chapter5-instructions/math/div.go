@@ -5,6 +5,11 @@ import (
 	"GoVM/chapter4-rtdt"
 )
 
+/**
+	浮点数除法遵循IEEE 754，除数为0时不会抛出ArithmeticException：
+	1.0/0.0 = +Infinity，-1.0/0.0 = -Infinity，0.0/0.0 = NaN
+	Go的浮点数除法语义和java一致，直接相除就可以了
+ */
 // Divide double
 type DDIV struct {
 	base.NoOperandsInstruction
@@ -0,0 +1,44 @@
+package math_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"math"
+	"testing"
+)
+
+/**
+	static double ddiv(double a, double b) { return a / b; }
+	static float fdiv(float a, float b) { return a / b; }
+ */
+func newDivVM(t *testing.T) *jvmtest.VM {
+	class := New("math/Div", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "ddiv", "(DD)D").Code(4, 4).
+		Op(DLOAD_0).Op(DLOAD_2).Op(DDIV).Op(DRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "fdiv", "(FF)F").Code(2, 2).
+		Op(FLOAD_0).Op(FLOAD_1).Op(FDIV).Op(FRETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestFloatDivisionByZero(t *testing.T) {
+	vm := newDivVM(t)
+	for _, test := range []struct {
+		a, b  float64
+		check func(float64) bool
+		want  string
+	}{
+		{1, 0, func(r float64) bool { return math.IsInf(r, 1) }, "+Inf"},
+		{-1, 0, func(r float64) bool { return math.IsInf(r, -1) }, "-Inf"},
+		{0, 0, math.IsNaN, "NaN"},
+		{1, math.Copysign(0, -1), func(r float64) bool { return math.IsInf(r, -1) }, "-Inf"},
+	} {
+		//结果是一个值，不会抛出ArithmeticException（Double会让测试失败）
+		if got := vm.Call("math/Div", "ddiv", "(DD)D", test.a, test.b).Double(); !test.check(got) {
+			t.Errorf("ddiv %v / %v = %v, want %s", test.a, test.b, got, test.want)
+		}
+		got := vm.Call("math/Div", "fdiv", "(FF)F", float32(test.a), float32(test.b)).Float()
+		if !test.check(float64(got)) {
+			t.Errorf("fdiv %v / %v = %v, want %s", test.a, test.b, got, test.want)
+		}
+	}
+}
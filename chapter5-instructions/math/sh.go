@@ -8,6 +8,7 @@ import (
 /**
 	位移指令分为左移和右移
 	右移又可以分为算术右移（有符号右移） 和 逻辑右移（无符号右移）
+	java规定int只取位移量的低5位，long只取低6位，所以 1 << 33 == 2，要先 & 0x1f 或 & 0x3f
  */
 type ISHL struct {
 	base.NoOperandsInstruction
@@ -65,7 +66,7 @@ type LSHR struct {
 	base.NoOperandsInstruction
 }
 
-func (self LSHR) Execute(frame *chapter4_rtdt.Frame) {
+func (self *LSHR) Execute(frame *chapter4_rtdt.Frame) {
 	stack := frame.OperandStack()
 	v2 := stack.PopInt()
 	v1 := stack.PopLong()
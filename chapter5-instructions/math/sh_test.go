@@ -0,0 +1,74 @@
+package math_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	static int ishl(int a, int n) { return a << n; } 以及ishr、iushr
+	static long lshl(long a, int n) { return a << n; } 以及lshr、lushr
+ */
+func newShiftVM(t *testing.T) *jvmtest.VM {
+	class := New("math/Shift", "java/lang/Object")
+	for name, opcode := range map[string]byte{"ishl": ISHL, "ishr": ISHR, "iushr": IUSHR} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(II)I").Code(2, 2).
+			Op(ILOAD_0).Op(ILOAD_1).Op(opcode).Op(IRETURN)
+	}
+	for name, opcode := range map[string]byte{"lshl": LSHL, "lshr": LSHR, "lushr": LUSHR} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(JI)J").Code(3, 3).
+			Op(LLOAD_0).Op(ILOAD_2).Op(opcode).Op(LRETURN)
+	}
+	return jvmtest.New(t, class)
+}
+
+func TestIntShiftsMaskTheCount(t *testing.T) {
+	vm := newShiftVM(t)
+	for _, test := range []struct {
+		name       string
+		a, n, want int32
+	}{
+		{"ishl", 1, 31, -1 << 31},
+		{"ishl", 1, 32, 1},
+		{"ishl", 1, 33, 2},
+		{"ishl", 3, -1, -1 << 31},
+		{"ishr", -8, 1, -4},
+		{"ishr", -8, 33, -4},
+		{"ishr", -1, 31, -1},
+		{"iushr", -8, 1, 0x7ffffffc},
+		{"iushr", -1, 28, 0xf},
+		{"iushr", -1, 60, 0xf},
+		{"iushr", -1, 32, -1},
+	} {
+		if got := vm.Call("math/Shift", test.name, "(II)I", test.a, test.n).Int(); got != test.want {
+			t.Errorf("%s %d, %d = %d, want %d", test.name, test.a, test.n, got, test.want)
+		}
+	}
+}
+
+func TestLongShiftsMaskTheCount(t *testing.T) {
+	vm := newShiftVM(t)
+	for _, test := range []struct {
+		name string
+		a    int64
+		n    int32
+		want int64
+	}{
+		{"lshl", 1, 63, -1 << 63},
+		{"lshl", 1, 64, 1},
+		{"lshl", 1, 65, 2},
+		//long的移位数是int，只看低6位，32不会被当成0
+		{"lshl", 1, 32, 1 << 32},
+		{"lshr", -8, 65, -4},
+		{"lshr", -1, 63, -1},
+		{"lushr", -8, 1, 0x7ffffffffffffffc},
+		{"lushr", -1, 60, 0xf},
+		{"lushr", -1, 124, 0xf},
+		{"lushr", -1, 64, -1},
+	} {
+		if got := vm.Call("math/Shift", test.name, "(JI)J", test.a, test.n).Long(); got != test.want {
+			t.Errorf("%s %d, %d = %d, want %d", test.name, test.a, test.n, got, test.want)
+		}
+	}
+}
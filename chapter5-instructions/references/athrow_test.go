@@ -0,0 +1,50 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	static void nested(Object a, Object b) {
+		synchronized (a) { synchronized (b) { throw new RuntimeException(); } }
+	}
+	按javac的样子生成：每个synchronized块有一个捕获所有异常的处理器，先monitorexit再重新athrow
+ */
+func newSyncVM(t *testing.T) *jvmtest.VM {
+	class := New("sync/Blocks", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "nested", "(Ljava/lang/Object;Ljava/lang/Object;)V").Code(2, 6).
+		Op(ALOAD_0).Op(DUP).Op(ASTORE_2).Op(MONITORENTER).
+		Label("outer").
+		Op(ALOAD_1).Op(DUP).Op(ASTORE_3).Op(MONITORENTER).
+		Label("inner").
+		New("java/lang/RuntimeException").Op(DUP).
+		Invokespecial("java/lang/RuntimeException", "<init>", "()V").Op(ATHROW).
+		Label("innerHandler").
+		Op(ASTORE, 4).Op(ALOAD_3).Op(MONITOREXIT).
+		Label("innerRethrow").
+		Op(ALOAD, 4).Op(ATHROW).
+		Label("outerHandler").
+		Op(ASTORE, 5).Op(ALOAD_2).Op(MONITOREXIT).
+		Label("outerRethrow").
+		Op(ALOAD, 5).Op(ATHROW).
+		Catch("inner", "innerHandler", "innerHandler", "").
+		Catch("innerHandler", "innerRethrow", "innerHandler", "").
+		Catch("outer", "outerHandler", "outerHandler", "").
+		Catch("outerHandler", "outerRethrow", "outerHandler", "")
+	return jvmtest.New(t, class)
+}
+
+func TestAthrowReleasesNestedMonitors(t *testing.T) {
+	vm := newSyncVM(t)
+	object := vm.Class("java/lang/Object")
+	a, b := object.NewObject(), object.NewObject()
+
+	vm.Call("sync/Blocks", "nested", "(Ljava/lang/Object;Ljava/lang/Object;)V", a, b).
+		Throws("java/lang/RuntimeException")
+	if a.MonitorCount() != 0 || b.MonitorCount() != 0 {
+		t.Errorf("monitor counts after unwinding: outer %d, inner %d, want 0",
+			a.MonitorCount(), b.MonitorCount())
+	}
+}
@@ -29,6 +29,8 @@ func (self *CHECK_CAST) Execute(frame *chapter4_rtdt.Frame) {
 	classRef := cp.GetConstant(self.Index).(*heap.ClassRef)
 	class := classRef.ResolvedClass()
 	if !ref.IsInstanceOf(class) {
-		panic("java.lang.ClassCastException")
+		//抛出的异常可以被java代码catch住
+		frame.ThrowException("java/lang/ClassCastException",
+			"class " + ref.Class().JavaName() + " cannot be cast to class " + class.JavaName())
 	}
 }
@@ -0,0 +1,105 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"fmt"
+	"testing"
+)
+
+/**
+	interface J {}  interface I extends J {}  class C implements I {}  class D extends C {}
+	static Object castToJ(Object o) { return (J) o; }
+	static boolean isJ(Object o) { return o instanceof J; }
+	static String castMessage(Object o) {
+		try { Object j = (J) o; return null; } catch (ClassCastException e) { return e.getMessage(); }
+	}
+ */
+func newCastVM(t *testing.T) *jvmtest.VM {
+	casts := New("refs/Casts", "java/lang/Object")
+	casts.Method(ACC_PUBLIC|ACC_STATIC, "castToJ", "(Ljava/lang/Object;)Ljava/lang/Object;").Code(1, 1).
+		Op(ALOAD_0).Checkcast("refs/J").Op(ARETURN)
+	casts.Method(ACC_PUBLIC|ACC_STATIC, "isJ", "(Ljava/lang/Object;)Z").Code(1, 1).
+		Op(ALOAD_0).Instanceof("refs/J").Op(IRETURN)
+	casts.Method(ACC_PUBLIC|ACC_STATIC, "castMessage", "(Ljava/lang/Object;)Ljava/lang/String;").Code(1, 2).
+		Label("start").
+		Op(ALOAD_0).Checkcast("refs/J").Op(ASTORE_1).
+		Label("end").
+		Op(ACONST_NULL).Op(ARETURN).
+		Label("handler").
+		Invokevirtual("java/lang/Throwable", "getMessage", "()Ljava/lang/String;").Op(ARETURN).
+		Catch("start", "end", "handler", "java/lang/ClassCastException")
+	return jvmtest.New(t, casts,
+		NewInterface("refs/J"), NewInterface("refs/I", "refs/J"),
+		New("refs/C", "java/lang/Object", "refs/I").DefaultConstructor(),
+		New("refs/D", "refs/C").DefaultConstructor())
+}
+
+func TestCheckcastToTransitiveSuperinterface(t *testing.T) {
+	vm := newCastVM(t)
+	for _, className := range []string{"refs/C", "refs/D"} {
+		obj := vm.Class(className).NewObject()
+		if got := vm.Call("refs/Casts", "castToJ", "(Ljava/lang/Object;)Ljava/lang/Object;", obj).Ref(); got != obj {
+			t.Errorf("(J) %s returned %v", className, got)
+		}
+		if !vm.Call("refs/Casts", "isJ", "(Ljava/lang/Object;)Z", obj).Bool() {
+			t.Errorf("%s instanceof J = false", className)
+		}
+	}
+	if !vm.Class("refs/J").IsAssignableFrom(vm.Class("refs/I")) {
+		t.Error("J is not assignable from I")
+	}
+
+	obj := vm.Class("java/lang/Object").NewObject()
+	vm.Call("refs/Casts", "castToJ", "(Ljava/lang/Object;)Ljava/lang/Object;", obj).
+		Throws("java/lang/ClassCastException")
+	if vm.Call("refs/Casts", "isJ", "(Ljava/lang/Object;)Z", obj).Bool() {
+		t.Error("Object instanceof J = true")
+	}
+}
+
+func TestClassCastExceptionNamesBothClasses(t *testing.T) {
+	vm := newCastVM(t)
+	for _, test := range []struct {
+		obj  interface{}
+		want string
+	}{
+		{vm.Class("java/lang/Object").NewObject(), "class java.lang.Object cannot be cast to class refs.J"},
+		{vm.String("s"), "class java.lang.String cannot be cast to class refs.J"},
+		{vm.Class("[Lrefs/C;").NewArray(1), "class [Lrefs.C; cannot be cast to class refs.J"},
+	} {
+		if got := vm.Call("refs/Casts", "castMessage", "(Ljava/lang/Object;)Ljava/lang/String;", test.obj).String(); got != test.want {
+			t.Errorf("caught message %q, want %q", got, test.want)
+		}
+	}
+	//能转换的就不会进异常处理器
+	if got := vm.Call("refs/Casts", "castMessage", "(Ljava/lang/Object;)Ljava/lang/String;", vm.Class("refs/D").NewObject()).Ref(); got != nil {
+		t.Error("(J) D reached the handler")
+	}
+}
+
+/**
+	对每种目标类型：static Object castN(Object o) { return (T) o; }  static boolean isN(Object o) { return o instanceof T; }
+	refs/Missing 不存在：null的判断在解析类引用之前，所以也不会抛NoClassDefFoundError
+ */
+var nullCastTargets = []string{"[I", "[[D", "[Ljava/lang/String;", "java/lang/Runnable", "java/lang/String", "refs/Missing"}
+
+func TestNullPassesCheckcastAndFailsInstanceof(t *testing.T) {
+	casts := New("refs/NullCasts", "java/lang/Object")
+	for i, target := range nullCastTargets {
+		casts.Method(ACC_PUBLIC|ACC_STATIC, fmt.Sprint("cast", i), "(Ljava/lang/Object;)Ljava/lang/Object;").Code(1, 1).
+			Op(ALOAD_0).Checkcast(target).Op(ARETURN)
+		casts.Method(ACC_PUBLIC|ACC_STATIC, fmt.Sprint("is", i), "(Ljava/lang/Object;)Z").Code(1, 1).
+			Op(ALOAD_0).Instanceof(target).Op(IRETURN)
+	}
+	vm := jvmtest.New(t, casts)
+
+	for i, target := range nullCastTargets {
+		if got := vm.Call("refs/NullCasts", fmt.Sprint("cast", i), "(Ljava/lang/Object;)Ljava/lang/Object;", nil).Ref(); got != nil {
+			t.Errorf("(%s) null = %v", target, got)
+		}
+		if vm.Call("refs/NullCasts", fmt.Sprint("is", i), "(Ljava/lang/Object;)Z", nil).Bool() {
+			t.Errorf("null instanceof %s = true", target)
+		}
+	}
+}
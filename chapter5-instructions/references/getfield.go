@@ -1,6 +1,7 @@
 package references
 
 import (
+	"fmt"
 	"GoVM/chapter5-instructions/base"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
@@ -21,7 +22,8 @@ func (self *GET_FIELD) Execute(frame *chapter4_rtdt.Frame) {
 	stack := frame.OperandStack()
 	ref := stack.PopRef()
 	if ref == nil {
-		panic("java.lang.NullPointerException")
+		throwFieldNPE(frame, "read", field)
+		return
 	}
 
 	descriptor := field.Descriptor()
@@ -42,4 +44,13 @@ func (self *GET_FIELD) Execute(frame *chapter4_rtdt.Frame) {
 	default:
 	// todo
 	}
-}
\ No newline at end of file
+}
+
+/**
+	和新版本的HotSpot一样，在NPE的信息里带上访问的字段名，比如
+	java.lang.NullPointerException: Cannot read field "x"
+	异常可以被java代码捕获，调用之后指令要直接返回
+ */
+func throwFieldNPE(frame *chapter4_rtdt.Frame, action string, field *heap.Field) {
+	frame.ThrowException("java/lang/NullPointerException", fmt.Sprintf("Cannot %s field \"%s\"", action, field.Name()))
+}
@@ -0,0 +1,73 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	try { <access> } catch (NullPointerException e) { return e.getMessage(); }
+ */
+func catchNPE(class *Class, name string, access func(code *Code)) {
+	code := class.Method(ACC_PUBLIC|ACC_STATIC, name, "()Ljava/lang/String;").Code(3, 0)
+	code.Label("start")
+	access(code)
+	code.Op(ACONST_NULL).Op(ARETURN)
+	code.Label("handler").Invokevirtual("java/lang/Throwable", "getMessage", "()Ljava/lang/String;").Op(ARETURN)
+	code.Catch("start", "handler", "handler", "java/lang/NullPointerException")
+}
+
+func TestFieldAccessOnNullThrowsCatchableNPE(t *testing.T) {
+	class := New("refs/Point", "java/lang/Object").DefaultConstructor()
+	class.Field(ACC_PUBLIC, "x", "I")
+	class.Field(ACC_PUBLIC, "next", "Lrefs/Point;")
+	catchNPE(class, "readX", func(code *Code) {
+		code.Op(ACONST_NULL).Getfield("refs/Point", "x", "I").Op(POP)
+	})
+	catchNPE(class, "writeX", func(code *Code) {
+		code.Op(ACONST_NULL).Op(ICONST_1).Putfield("refs/Point", "x", "I")
+	})
+	catchNPE(class, "writeNext", func(code *Code) {
+		code.Op(ACONST_NULL).Op(ACONST_NULL).Putfield("refs/Point", "next", "Lrefs/Point;")
+	})
+	vm := jvmtest.New(t, class)
+
+	for _, test := range []struct{ method, want string }{
+		{"readX", `Cannot read field "x"`},
+		{"writeX", `Cannot assign field "x"`},
+		{"writeNext", `Cannot assign field "next"`},
+	} {
+		if got := vm.Call("refs/Point", test.method, "()Ljava/lang/String;").String(); got != test.want {
+			t.Errorf("%s: message = %q, want %q", test.method, got, test.want)
+		}
+	}
+}
+
+/**
+	class Base { int x = 1; }
+	class Sub extends Base { int x = 2; }
+	static int readBoth() { Sub s = new Sub(); return ((Base) s).x * 10 + s.x; }
+ */
+func TestGetfieldOnShadowedField(t *testing.T) {
+	base := New("refs/Base", "java/lang/Object")
+	base.Field(0, "x", "I")
+	base.Method(0, "<init>", "()V").Code(2, 1).
+		Op(ALOAD_0).Invokespecial("java/lang/Object", "<init>", "()V").
+		Op(ALOAD_0).Op(ICONST_1).Putfield("refs/Base", "x", "I").Op(RETURN)
+	sub := New("refs/Sub", "refs/Base")
+	sub.Field(0, "x", "I")
+	sub.Method(0, "<init>", "()V").Code(2, 1).
+		Op(ALOAD_0).Invokespecial("refs/Base", "<init>", "()V").
+		Op(ALOAD_0).Op(ICONST_2).Putfield("refs/Sub", "x", "I").Op(RETURN)
+	sub.Method(ACC_STATIC, "readBoth", "()I").Code(3, 1).
+		New("refs/Sub").Op(DUP).Invokespecial("refs/Sub", "<init>", "()V").Op(ASTORE_0).
+		Op(ALOAD_0).Getfield("refs/Base", "x", "I").Iconst(10).Op(IMUL).
+		Op(ALOAD_0).Getfield("refs/Sub", "x", "I").Op(IADD).Op(IRETURN)
+	vm := jvmtest.New(t, base, sub)
+
+	//两个x各占一个slot，互不覆盖
+	if got := vm.Call("refs/Sub", "readBoth", "()I").Int(); got != 12 {
+		t.Errorf("Base.x * 10 + Sub.x = %d, want 12", got)
+	}
+}
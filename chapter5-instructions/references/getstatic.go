@@ -16,9 +16,7 @@ func (self *GET_STATIC) Execute(frame *chapter4_rtdt.Frame) {
 
 	field := fieldRef.ResolvedField()
 	class := field.Class()
-	if !class.InitStarted() {
-		frame.RevertNextPC()
-		base.InitClass(frame.Thread(), class)
+	if !base.CheckInitialized(frame, class) {
 		return
 	}
 
@@ -0,0 +1,157 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	static int name() { return className.field; }
+ */
+func staticGetter(class *Class, name, className, field string) {
+	class.Method(ACC_PUBLIC|ACC_STATIC, name, "()I").Code(1, 0).
+		Getstatic(className, field, "I").Op(IRETURN)
+}
+
+/**
+	<clinit>里执行 ++className.counter
+ */
+func countInit(code *Code, className, counter string) *Code {
+	return code.Getstatic(className, counter, "I").Op(ICONST_1).Op(IADD).Putstatic(className, counter, "I")
+}
+
+/**
+	interface Base { int B = <clinit>: ++Log.baseInit, 7; }
+	interface K extends Base { int VALUE = <clinit>: ++Log.kInit, 6 * 7; }
+ */
+func TestGetstaticInitializesOnlyTheDeclaringInterface(t *testing.T) {
+	log := New("refs/Log", "java/lang/Object")
+	log.Field(ACC_PUBLIC|ACC_STATIC, "baseInit", "I")
+	log.Field(ACC_PUBLIC|ACC_STATIC, "kInit", "I")
+
+	base := NewInterface("refs/Base")
+	base.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "B", "I")
+	countInit(base.Method(ACC_STATIC, "<clinit>", "()V").Code(2, 0), "refs/Log", "baseInit").
+		Iconst(7).Putstatic("refs/Base", "B", "I").Op(RETURN)
+
+	k := NewInterface("refs/K", "refs/Base")
+	k.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "VALUE", "I")
+	countInit(k.Method(ACC_STATIC, "<clinit>", "()V").Code(2, 0), "refs/Log", "kInit").
+		Iconst(6).Iconst(7).Op(IMUL).Putstatic("refs/K", "VALUE", "I").Op(RETURN)
+
+	statics := New("refs/Statics", "java/lang/Object")
+	staticGetter(statics, "value", "refs/K", "VALUE")
+	staticGetter(statics, "kInit", "refs/Log", "kInit")
+	staticGetter(statics, "baseInit", "refs/Log", "baseInit")
+	vm := jvmtest.New(t, log, base, k, statics)
+
+	if got := vm.Call("refs/Statics", "value", "()I").Int(); got != 42 {
+		t.Errorf("K.VALUE = %d, want 42", got)
+	}
+	if got := vm.Call("refs/Statics", "kInit", "()I").Int(); got != 1 {
+		t.Errorf("K.<clinit> ran %d times, want 1", got)
+	}
+	//超接口不会因为子接口的初始化而初始化
+	if got := vm.Call("refs/Statics", "baseInit", "()I").Int(); got != 0 {
+		t.Errorf("Base.<clinit> ran %d times, want 0", got)
+	}
+}
+
+/**
+	class Once { static int inits; static int value; static { ++inits; value = 21; } }
+	static int readTwice() { return Once.value + Once.value; }
+ */
+func TestClinitRunsOnceAcrossGetstatics(t *testing.T) {
+	once := New("refs/Once", "java/lang/Object")
+	once.Field(ACC_PUBLIC|ACC_STATIC, "inits", "I")
+	once.Field(ACC_PUBLIC|ACC_STATIC, "value", "I")
+	countInit(once.Method(ACC_STATIC, "<clinit>", "()V").Code(2, 0), "refs/Once", "inits").
+		Iconst(21).Putstatic("refs/Once", "value", "I").Op(RETURN)
+
+	statics := New("refs/OnceReader", "java/lang/Object")
+	statics.Method(ACC_PUBLIC|ACC_STATIC, "readTwice", "()I").Code(2, 0).
+		Getstatic("refs/Once", "value", "I").Getstatic("refs/Once", "value", "I").Op(IADD).Op(IRETURN)
+	staticGetter(statics, "inits", "refs/Once", "inits")
+	vm := jvmtest.New(t, once, statics)
+
+	if vm.Class("refs/Once").InitStarted() {
+		t.Fatal("Once initialized before the first getstatic")
+	}
+	if got := vm.Call("refs/OnceReader", "readTwice", "()I").Int(); got != 42 {
+		t.Errorf("readTwice() = %d, want 42", got)
+	}
+	if !vm.Class("refs/Once").InitFinished() {
+		t.Error("Once is not initialized after its <clinit> returned")
+	}
+	vm.Call("refs/OnceReader", "readTwice", "()I")
+	if got := vm.Call("refs/OnceReader", "inits", "()I").Int(); got != 1 {
+		t.Errorf("<clinit> ran %d times, want 1", got)
+	}
+}
+
+/**
+	interface Consts { int LIMIT = 100; String NAME = "limit"; }  //都在<clinit>里赋值
+	class Impl implements Consts {}
+	class SubImpl extends Impl {}
+	getstatic 的符号引用写的是实现类，字段要到接口里找
+ */
+func TestGetstaticReadsInterfaceConstantThroughClass(t *testing.T) {
+	consts := NewInterface("refs/Consts")
+	consts.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "LIMIT", "I")
+	consts.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "NAME", "Ljava/lang/String;")
+	consts.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		Iconst(100).Putstatic("refs/Consts", "LIMIT", "I").
+		LdcString("limit").Putstatic("refs/Consts", "NAME", "Ljava/lang/String;").Op(RETURN)
+	impl := New("refs/Impl", "java/lang/Object", "refs/Consts")
+	subImpl := New("refs/SubImpl", "refs/Impl")
+
+	statics := New("refs/ConstReader", "java/lang/Object")
+	staticGetter(statics, "viaImpl", "refs/Impl", "LIMIT")
+	staticGetter(statics, "viaSubImpl", "refs/SubImpl", "LIMIT")
+	vm := jvmtest.New(t, consts, impl, subImpl, statics)
+
+	for _, method := range []string{"viaImpl", "viaSubImpl"} {
+		if got := vm.Call("refs/ConstReader", method, "()I").Int(); got != 100 {
+			t.Errorf("%s() = %d, want 100", method, got)
+		}
+	}
+	//GetRefVar这些Go的反射方法也要按同样的顺序找
+	name := vm.Class("refs/SubImpl").GetRefVar("NAME", "Ljava/lang/String;")
+	if name == nil || name != vm.Class("refs/Consts").GetRefVar("NAME", "Ljava/lang/String;") {
+		t.Errorf("SubImpl.NAME through GetRefVar = %v, want the string in Consts", name)
+	}
+}
+
+/**
+	interface J { int CONST = <clinit>: 9; }
+	interface I extends J {}
+	class C implements I {}
+	class D extends C {}
+	常量声明在接口的超接口里，要顺着接口继承往上找
+ */
+func TestGetstaticReadsConstantFromSuperinterfaceOfInterface(t *testing.T) {
+	j := NewInterface("refs/J")
+	j.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "CONST", "I")
+	j.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		Iconst(9).Putstatic("refs/J", "CONST", "I").Op(RETURN)
+	i := NewInterface("refs/I", "refs/J")
+	c := New("refs/C", "java/lang/Object", "refs/I")
+	d := New("refs/D", "refs/C")
+
+	statics := New("refs/DeepReader", "java/lang/Object")
+	staticGetter(statics, "viaC", "refs/C", "CONST")
+	staticGetter(statics, "viaD", "refs/D", "CONST")
+	staticGetter(statics, "viaI", "refs/I", "CONST")
+	vm := jvmtest.New(t, j, i, c, d, statics)
+
+	for _, method := range []string{"viaC", "viaD", "viaI"} {
+		if got := vm.Call("refs/DeepReader", method, "()I").Int(); got != 9 {
+			t.Errorf("%s() = %d, want 9", method, got)
+		}
+	}
+	//getstatic只初始化声明字段的接口
+	if !vm.Class("refs/J").InitFinished() || vm.Class("refs/I").InitStarted() {
+		t.Error("reading J.CONST through C should initialize J and only J")
+	}
+}
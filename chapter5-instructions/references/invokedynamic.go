@@ -0,0 +1,38 @@
+package references
+
+import (
+	"GoVM/chapter5-instructions/base"
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+)
+
+/**
+	invokedynamic后面也跟着 4 字节操作数
+	前两个字节是运行时常量池的索引，指向一个InvokeDynamic常量，后两个字节必须是0
+	第一次执行时通过引导方法解析出调用点，之后同一条指令直接用缓存的调用点
+ */
+type INVOKE_DYNAMIC struct {
+	index uint
+	// zero uint8
+	// zero uint8
+}
+
+func (self *INVOKE_DYNAMIC) FetchOperands(reader *base.BytecodeReader) {
+	self.index = uint(reader.ReadUInt16())
+	reader.ReadUInt8()
+	reader.ReadUInt8()
+}
+
+/**
+	调用点目前都是LambdaMetafactory生成的：弹出lambda捕获的变量，压入实现了函数式接口的对象
+ */
+func (self *INVOKE_DYNAMIC) Execute(frame *chapter4_rtdt.Frame) {
+	method := frame.Method()
+	cp := method.Class().ConstantPool()
+	indyRef := cp.GetConstant(self.index).(*heap.InvokeDynamicRef)
+	callSite := indyRef.ResolvedCallSite(method, frame.Thread().PC())
+
+	stack := frame.OperandStack()
+	captured := stack.PopSlots(callSite.CapturedSlotCount())
+	stack.PushRef(callSite.NewInstance(captured))
+}
@@ -0,0 +1,76 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"strings"
+	"testing"
+)
+
+const REF_invokeStatic = 6
+
+/**
+	interface IntOp { int apply(int x); }
+	class Lambdas {
+		static IntOp adder(int n) { return x -> x + n; }
+		static IntOp otherAdder(int n) { return x -> x + n; } //同一个常量，另一条invokedynamic指令
+		static int apply(IntOp op, int x) { return op.apply(x); }
+		private static int lambda$add(int n, int x) { return x + n; }
+	}
+ */
+func newLambdaVM(t *testing.T) *jvmtest.VM {
+	op := NewInterface("indy/IntOp")
+	op.Method(ACC_PUBLIC|ACC_ABSTRACT, "apply", "(I)I")
+
+	class := New("indy/Lambdas", "java/lang/Object")
+	class.Method(ACC_PRIVATE|ACC_STATIC|ACC_SYNTHETIC, "lambda$add", "(II)I").Code(2, 2).
+		Op(ILOAD_1).Op(ILOAD_0).Op(IADD).Op(IRETURN)
+	metafactory := class.MethodHandle(REF_invokeStatic, class.MethodRef("java/lang/invoke/LambdaMetafactory", "metafactory",
+		"(Ljava/lang/invoke/MethodHandles$Lookup;Ljava/lang/String;Ljava/lang/invoke/MethodType;"+
+			"Ljava/lang/invoke/MethodType;Ljava/lang/invoke/MethodHandle;Ljava/lang/invoke/MethodType;)Ljava/lang/invoke/CallSite;"))
+	bootstrap := class.BootstrapMethod(metafactory, class.MethodType("(I)I"),
+		class.MethodHandle(REF_invokeStatic, class.MethodRef("indy/Lambdas", "lambda$add", "(II)I")), class.MethodType("(I)I"))
+	indy := class.InvokeDynamic(bootstrap, "apply", "(I)Lindy/IntOp;")
+	for _, name := range []string{"adder", "otherAdder"} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(I)Lindy/IntOp;").Code(1, 1).
+			Op(ILOAD_0).Invokedynamic(indy).Op(ARETURN)
+	}
+	class.Method(ACC_PUBLIC|ACC_STATIC, "apply", "(Lindy/IntOp;I)I").Code(2, 2).
+		Op(ALOAD_0).Op(ILOAD_1).Invokeinterface("indy/IntOp", "apply", "(I)I").Op(IRETURN)
+	return jvmtest.New(t, op, class)
+}
+
+/**
+	引导方法每运行一次就合成一个新的lambda类（类名带着递增的编号），
+	所以数一数执行过的invokedynamic产生了几个不同的类，就是引导方法运行的次数
+ */
+func TestInvokedynamicBootstrapsOncePerInstruction(t *testing.T) {
+	vm := newLambdaVM(t)
+	bootstraps := map[string]bool{}
+	call := func(method string, n int32) int32 {
+		op := vm.Call("indy/Lambdas", method, "(I)Lindy/IntOp;", n).Ref()
+		name := op.Class().Name()
+		if !strings.HasPrefix(name, "indy/Lambdas$$Lambda$") {
+			t.Fatalf("lambda class is %s", name)
+		}
+		bootstraps[name] = true
+		return vm.Call("indy/Lambdas", "apply", "(Lindy/IntOp;I)I", op, int32(10)).Int()
+	}
+
+	for n := int32(1); n <= 3; n++ {
+		//调用点是共用的，捕获的变量还是每个对象各自的
+		if got := call("adder", n); got != 10 + n {
+			t.Errorf("adder(%d).apply(10) = %d, want %d", n, got, 10 + n)
+		}
+	}
+	if len(bootstraps) != 1 {
+		t.Fatalf("bootstrap ran %d times for one instruction executed 3 times", len(bootstraps))
+	}
+
+	//共用同一个常量的另一条指令是另一个调用点，要单独链接一次
+	call("otherAdder", 5)
+	call("otherAdder", 6)
+	if len(bootstraps) != 2 {
+		t.Errorf("bootstrap ran %d times for two instructions, want 2", len(bootstraps))
+	}
+}
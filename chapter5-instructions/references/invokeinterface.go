@@ -1,6 +1,7 @@
 package references
 
 import (
+	"fmt"
 	"GoVM/chapter5-instructions/base"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
@@ -37,8 +38,11 @@ func (self *INVOKE_INTERFACE) Execute(frame *chapter4_rtdt.Frame) {
 	if ref == nil {
 		panic("java.lang.NullPointerException")
 	}
-	if !ref.Class().IsImplements(methodRef.ResolvedClass()) {
-		panic("java.lang.IncompatibleClassChangeError")
+	//畸形的字节码可能让没有实现接口的对象走到这里，要在查找方法之前拦下来
+	iface := methodRef.ResolvedClass()
+	if !iface.IsAssignableFrom(ref.Class()) {
+		panic(fmt.Sprintf("java.lang.IncompatibleClassChangeError: Class %s does not implement the requested interface %s",
+			ref.Class().JavaName(), iface.JavaName()))
 	}
 
 	toBeInvoked := heap.LookupMethodInClass(ref.Class(), methodRef.Name(), methodRef.Descriptor())
@@ -0,0 +1,92 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"regexp"
+	"testing"
+)
+
+/**
+	interface Shape {}                         //没有声明toString、hashCode、equals
+	class Square implements Shape { public String toString() { return "square"; } public int hashCode() { return 4; } }
+	class Blob implements Shape {}
+	static String describe(Shape s) { return s.toString(); }  //invokeinterface Shape.toString
+	static int hash(Shape s) { return s.hashCode(); }
+	static boolean same(Shape a, Shape b) { return a.equals(b); }
+ */
+func newShapeVM(t *testing.T) *jvmtest.VM {
+	shape := NewInterface("refs/Shape")
+	square := New("refs/Square", "java/lang/Object", "refs/Shape").DefaultConstructor()
+	square.Method(ACC_PUBLIC, "toString", "()Ljava/lang/String;").Code(1, 1).LdcString("square").Op(ARETURN)
+	square.Method(ACC_PUBLIC, "hashCode", "()I").Code(1, 1).Iconst(4).Op(IRETURN)
+	blob := New("refs/Blob", "java/lang/Object", "refs/Shape").DefaultConstructor()
+
+	class := New("refs/Shapes", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "describe", "(Lrefs/Shape;)Ljava/lang/String;").Code(1, 1).
+		Op(ALOAD_0).Invokeinterface("refs/Shape", "toString", "()Ljava/lang/String;").Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "hash", "(Lrefs/Shape;)I").Code(1, 1).
+		Op(ALOAD_0).Invokeinterface("refs/Shape", "hashCode", "()I").Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "same", "(Lrefs/Shape;Lrefs/Shape;)Z").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Invokeinterface("refs/Shape", "equals", "(Ljava/lang/Object;)Z").Op(IRETURN)
+	return jvmtest.New(t, shape, square, blob, class)
+}
+
+func TestObjectMethodsThroughInterfaceReference(t *testing.T) {
+	vm := newShapeVM(t)
+	square := vm.Class("refs/Square").NewObject()
+	blob := vm.Class("refs/Blob").NewObject()
+
+	//覆盖了的方法用对象自己的
+	if got := vm.Call("refs/Shapes", "describe", "(Lrefs/Shape;)Ljava/lang/String;", square).String(); got != "square" {
+		t.Errorf("Square.toString() = %q, want \"square\"", got)
+	}
+	if got := vm.Call("refs/Shapes", "hash", "(Lrefs/Shape;)I", square).Int(); got != 4 {
+		t.Errorf("Square.hashCode() = %d, want 4", got)
+	}
+	//没有覆盖的用java.lang.Object的
+	got := vm.Call("refs/Shapes", "describe", "(Lrefs/Shape;)Ljava/lang/String;", blob).String()
+	if !regexp.MustCompile(`^refs\.Blob@[0-9a-f]+$`).MatchString(got) {
+		t.Errorf("Blob.toString() = %q, want refs.Blob@<hex>", got)
+	}
+	if !vm.Call("refs/Shapes", "same", "(Lrefs/Shape;Lrefs/Shape;)Z", blob, blob).Bool() ||
+		vm.Call("refs/Shapes", "same", "(Lrefs/Shape;Lrefs/Shape;)Z", blob, square).Bool() {
+		t.Error("Object.equals through the interface is not identity")
+	}
+}
+
+/**
+	interface Sized { int size(); }
+	class Sized3 implements Sized { public int size() { return 3; } }
+	class Unsized { public int size() { return 5; } }  //有同名方法，但是没有实现接口
+	static int size(Object o) { return ((Sized) o).size(); }  //畸形的字节码：少了checkcast
+ */
+func TestInvokeinterfaceOnNonImplementingReceiver(t *testing.T) {
+	sized := NewInterface("refs/Sized")
+	sized.Method(ACC_PUBLIC|ACC_ABSTRACT, "size", "()I")
+	var classes []*Class
+	for _, test := range []struct {
+		name       string
+		interfaces []string
+		size       int32
+	}{{"refs/Sized3", []string{"refs/Sized"}, 3}, {"refs/Unsized", nil, 5}} {
+		class := New(test.name, "java/lang/Object", test.interfaces...).DefaultConstructor()
+		class.Method(ACC_PUBLIC, "size", "()I").Code(1, 1).Iconst(test.size).Op(IRETURN)
+		classes = append(classes, class)
+	}
+	class := New("refs/Sizes", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "size", "(Ljava/lang/Object;)I").Code(1, 1).
+		Op(ALOAD_0).Invokeinterface("refs/Sized", "size", "()I").Op(IRETURN)
+	vm := jvmtest.New(t, append(classes, sized, class)...)
+
+	if got := vm.Call("refs/Sizes", "size", "(Ljava/lang/Object;)I", vm.Class("refs/Sized3").NewObject()).Int(); got != 3 {
+		t.Errorf("Sized3.size() = %d, want 3", got)
+	}
+	//和其他链接错误一样是虚拟机的panic
+	var result *jvmtest.Result
+	r := vm.CallPanic(&result, "refs/Sizes", "size", "(Ljava/lang/Object;)I", vm.Class("refs/Unsized").NewObject())
+	want := "java.lang.IncompatibleClassChangeError: Class refs.Unsized does not implement the requested interface refs.Sized"
+	if r != want {
+		t.Errorf("panic = %v, want %q", r, want)
+	}
+}
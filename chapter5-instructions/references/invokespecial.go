@@ -47,6 +47,7 @@ func (self *INVOKE_SPECIAL) Execute(frame *chapter4_rtdt.Frame) {
 	if toBeInvoked == nil || toBeInvoked.IsAbstract() {
 		panic("java.lang.AbstractMethodError")
 	}
+	base.CheckSelectedMethodAccess(currentClass, resolvedMethod, toBeInvoked)
 
 	base.InvokeMethod(frame, toBeInvoked)
 }
@@ -0,0 +1,60 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	class A { protected int foo() { return 1; } }
+	class B extends A { protected int foo() { return 2; } }
+	class C extends B {
+		protected int foo() { return 3; }
+		int superFoo() { return super.foo(); }     //方法引用是 B.foo
+		int superFooViaA() { return super.foo(); } //方法引用是 A.foo（旧的编译器会这样生成）
+	}
+	B和C在另一个包里时，protected方法的访问检查也要通过
+ */
+func newSuperVM(t *testing.T, otherPackage bool) *jvmtest.VM {
+	pkg := "refs/"
+	if otherPackage {
+		pkg = "refs/sub/"
+	}
+	var classes []*Class
+	for i, name := range []string{"refs/A", pkg + "B", pkg + "C"} {
+		super := "java/lang/Object"
+		if i > 0 {
+			super = classes[i - 1].Name()
+		}
+		class := New(name, super).DefaultConstructor()
+		class.Method(ACC_PROTECTED, "foo", "()I").Code(1, 1).Iconst(int32(i + 1)).Op(IRETURN)
+		classes = append(classes, class)
+	}
+	c := classes[2]
+	c.Method(ACC_PUBLIC, "superFoo", "()I").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(pkg + "B", "foo", "()I").Op(IRETURN)
+	c.Method(ACC_PUBLIC, "superFooViaA", "()I").Code(1, 1).
+		Op(ALOAD_0).Invokespecial("refs/A", "foo", "()I").Op(IRETURN)
+	for _, method := range []string{"superFoo", "superFooViaA"} {
+		c.Method(ACC_PUBLIC|ACC_STATIC, method + "OnNewC", "()I").Code(2, 0).
+			New(c.Name()).Op(DUP).Invokespecial(c.Name(), "<init>", "()V").
+			Invokevirtual(c.Name(), method, "()I").Op(IRETURN)
+	}
+	return jvmtest.New(t, classes...)
+}
+
+func TestInvokespecialSuperSelectsTheMiddleOverride(t *testing.T) {
+	for _, otherPackage := range []bool{false, true} {
+		vm := newSuperVM(t, otherPackage)
+		className := "refs/C"
+		if otherPackage {
+			className = "refs/sub/C"
+		}
+		for _, method := range []string{"superFooOnNewC", "superFooViaAOnNewC"} {
+			if got := vm.Call(className, method, "()I").Int(); got != 2 {
+				t.Errorf("%s.%s() = %d, want B.foo() = 2", className, method, got)
+			}
+		}
+	}
+}
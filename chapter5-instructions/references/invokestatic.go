@@ -22,9 +22,7 @@ func (self *INVOKE_STATIC) Execute(frame *chapter4_rtdt.Frame) {
 	class := resolvedMethod.Class()
 	//先判断类的初始化是否已经开始，如果还没有，需要调用类的初始化方法（clinit）并终止指令执行，先执行类初始化
 	//由于此时指令已经执行到一半了，也就是说当前栈帧的nextPc字段已经指向下一条指令了，所以需要修改nextPC，让它重新指向当前指令。
-	if !class.InitStarted() {
-		frame.RevertNextPC()
-		base.InitClass(frame.Thread(), class)
+	if !base.CheckInitialized(frame, class) {
 		return
 	}
 	base.InvokeMethod(frame, resolvedMethod)
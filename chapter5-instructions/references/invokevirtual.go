@@ -45,7 +45,9 @@ func (self *INVOKE_VIRTUAL) Execute(frame *chapter4_rtdt.Frame) {
 		panic("java.lang.NullPointerException")
 	}
 
+	//数组的clone()看作是public的，比如 int[] a; a.clone() 编译成 invokevirtual [I.clone，不受protected的限制
 	if resolvedMethod.IsProtected() &&
+		!(ref.Class().IsArray() && resolvedMethod.Name() == "clone") &&
 		resolvedMethod.Class().IsSuperClassOf(currentClass) &&
 		resolvedMethod.Class().GetPackageName() != currentClass.GetPackageName() &&
 		ref.Class() != currentClass &&
@@ -58,6 +60,7 @@ func (self *INVOKE_VIRTUAL) Execute(frame *chapter4_rtdt.Frame) {
 	if toBeInvoked == nil || toBeInvoked.IsAbstract() {
 		panic("java.lang.AbstractMethodError")
 	}
+	base.CheckSelectedMethodAccess(currentClass, resolvedMethod, toBeInvoked)
 
 	base.InvokeMethod(frame, toBeInvoked)
 }
@@ -0,0 +1,115 @@
+package references_test
+
+import (
+	"GoVM/chapter6-obj/heap"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+const builderType = "Lrefs/Builder;"
+
+/**
+	class Builder {
+		int count; String name;
+		Builder add(int n) { count += n; return this; }
+		Builder name(String s) { name = s; return this; }
+	}
+	class CountingBuilder extends Builder { Builder name(String s) { super.name(s); return add(100); } }
+	static Builder chain(Builder b) { return b.add(1).name("x").add(2).add(3); }
+	static boolean same(Builder b) { return b.add(1).name("y") == b; }
+ */
+func newBuilderVM(t *testing.T) *jvmtest.VM {
+	builder := New("refs/Builder", "java/lang/Object").DefaultConstructor()
+	builder.Field(ACC_PUBLIC, "count", "I")
+	builder.Field(ACC_PUBLIC, "name", "Ljava/lang/String;")
+	builder.Method(ACC_PUBLIC, "add", "(I)"+builderType).Code(3, 2).
+		Op(ALOAD_0).Op(DUP).Getfield("refs/Builder", "count", "I").Op(ILOAD_1).Op(IADD).
+		Putfield("refs/Builder", "count", "I").Op(ALOAD_0).Op(ARETURN)
+	builder.Method(ACC_PUBLIC, "name", "(Ljava/lang/String;)"+builderType).Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Putfield("refs/Builder", "name", "Ljava/lang/String;").Op(ALOAD_0).Op(ARETURN)
+
+	counting := New("refs/CountingBuilder", "refs/Builder").DefaultConstructor()
+	counting.Method(ACC_PUBLIC, "name", "(Ljava/lang/String;)"+builderType).Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Invokespecial("refs/Builder", "name", "(Ljava/lang/String;)"+builderType).
+		Iconst(100).Invokevirtual("refs/Builder", "add", "(I)"+builderType).Op(ARETURN)
+
+	class := New("refs/Chains", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "chain", "("+builderType+")"+builderType).Code(2, 1).
+		Op(ALOAD_0).
+		Op(ICONST_1).Invokevirtual("refs/Builder", "add", "(I)"+builderType).
+		LdcString("x").Invokevirtual("refs/Builder", "name", "(Ljava/lang/String;)"+builderType).
+		Op(ICONST_2).Invokevirtual("refs/Builder", "add", "(I)"+builderType).
+		Op(ICONST_3).Invokevirtual("refs/Builder", "add", "(I)"+builderType).
+		Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "same", "("+builderType+")Z").Code(2, 1).
+		Op(ALOAD_0).
+		Op(ICONST_1).Invokevirtual("refs/Builder", "add", "(I)"+builderType).
+		LdcString("y").Invokevirtual("refs/Builder", "name", "(Ljava/lang/String;)"+builderType).
+		Op(ALOAD_0).Branch(IF_ACMPNE, "different").
+		Op(ICONST_1).Op(IRETURN).
+		Label("different").Op(ICONST_0).Op(IRETURN)
+	return jvmtest.New(t, builder, counting, class)
+}
+
+func TestBuilderChainKeepsReceiverIdentity(t *testing.T) {
+	vm := newBuilderVM(t)
+	for _, test := range []struct {
+		class string
+		count int32
+	}{
+		{"refs/Builder", 6},
+		//子类覆盖了链中间的方法，后面的调用还是在同一个对象上分派
+		{"refs/CountingBuilder", 106},
+	} {
+		b := vm.Class(test.class).NewObject()
+		got := vm.Call("refs/Chains", "chain", "("+builderType+")"+builderType, b).Ref()
+		if got != b {
+			t.Errorf("%s: chain returned a different object", test.class)
+		}
+		if count := b.GetIntVar("count", "I"); count != test.count {
+			t.Errorf("%s: count = %d, want %d", test.class, count, test.count)
+		}
+		if name := heap.GoString(b.GetRefVar("name", "Ljava/lang/String;")); name != "x" {
+			t.Errorf("%s: name = %q, want \"x\"", test.class, name)
+		}
+		if !vm.Call("refs/Chains", "same", "("+builderType+")Z", b).Bool() {
+			t.Errorf("%s: the chained reference is not == the receiver in Java", test.class)
+		}
+	}
+}
+
+/**
+	javac不允许，但字节码里可以把public方法覆盖成包私有的：
+		package pa; public class Base { public int run() { return 1; } }
+		package pb; public class Narrow extends pa.Base { int run() { return 2; } }   //包私有
+		static int call(Base b) { return b.run(); }   //分别放在 pc/Caller 和 pb/Caller 里
+ */
+func TestInvokevirtualOfInaccessibleOverride(t *testing.T) {
+	base := New("pa/Base", "java/lang/Object").DefaultConstructor()
+	base.Method(ACC_PUBLIC, "run", "()I").Code(1, 1).Op(ICONST_1).Op(IRETURN)
+	narrow := New("pb/Narrow", "pa/Base").DefaultConstructor()
+	narrow.Method(0, "run", "()I").Code(1, 1).Op(ICONST_2).Op(IRETURN)
+	var callers []*Class
+	for _, name := range []string{"pc/Caller", "pb/Caller"} {
+		caller := New(name, "java/lang/Object")
+		caller.Method(ACC_PUBLIC|ACC_STATIC, "call", "(Lpa/Base;)I").Code(1, 1).
+			Op(ALOAD_0).Invokevirtual("pa/Base", "run", "()I").Op(IRETURN)
+		callers = append(callers, caller)
+	}
+	vm := jvmtest.New(t, append(callers, base, narrow)...)
+
+	//选中的就是解析出来的方法，不用再检查
+	if got := vm.Call("pc/Caller", "call", "(Lpa/Base;)I", vm.Class("pa/Base").NewObject()).Int(); got != 1 {
+		t.Errorf("call(new Base()) = %d, want 1", got)
+	}
+	//同一个包里的调用方可以访问包私有的覆盖方法
+	if got := vm.Call("pb/Caller", "call", "(Lpa/Base;)I", vm.Class("pb/Narrow").NewObject()).Int(); got != 2 {
+		t.Errorf("pb/Caller.call(new Narrow()) = %d, want 2", got)
+	}
+	var result *jvmtest.Result
+	r := vm.CallPanic(&result, "pc/Caller", "call", "(Lpa/Base;)I", vm.Class("pb/Narrow").NewObject())
+	if want := "java.lang.IllegalAccessError: tried to access method pb.Narrow.run()I from class pc.Caller"; r != want {
+		t.Errorf("pc/Caller.call(new Narrow()) panicked with %v, want %q", r, want)
+	}
+}
@@ -0,0 +1,43 @@
+package references
+
+import (
+	"GoVM/chapter5-instructions/base"
+	"GoVM/chapter4-rtdt"
+)
+
+/**
+	进入对象的监视器，synchronized块编译之后就是monitorenter和monitorexit
+	虚拟机目前只有一个线程，所以只需要记录重入次数
+ */
+type MONITOR_ENTER struct {
+	base.NoOperandsInstruction
+}
+
+func (self *MONITOR_ENTER) Execute(frame *chapter4_rtdt.Frame) {
+	ref := frame.OperandStack().PopRef()
+	if !base.CheckNotNil(frame, ref) {
+		return
+	}
+	ref.EnterMonitor()
+	frame.RecordMonitorEnter()
+}
+
+/**
+	退出对象的监视器
+ */
+type MONITOR_EXIT struct {
+	base.NoOperandsInstruction
+}
+
+func (self *MONITOR_EXIT) Execute(frame *chapter4_rtdt.Frame) {
+	ref := frame.OperandStack().PopRef()
+	if !base.CheckNotNil(frame, ref) {
+		return
+	}
+	//当前线程没有进入过这个监视器
+	if !ref.ExitMonitor() {
+		frame.ThrowException("java/lang/IllegalMonitorStateException", "current thread is not owner")
+		return
+	}
+	frame.RecordMonitorExit()
+}
@@ -0,0 +1,84 @@
+package references_test
+
+import (
+	"GoVM/chapter4-rtdt"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	javac不会生成这样的字节码：
+		static void leak(Object o) { monitorenter(o); }                       //没有monitorexit就返回
+		static void leakOnThrow(Object o) { monitorenter(o); throw new RuntimeException(); }
+		static void balanced(Object o) { monitorenter(o); monitorexit(o); }
+		static void enterNull() { monitorenter(null); }
+		static void exitNull() { monitorexit(null); }
+		static void exitUnowned(Object o) { monitorexit(o); }
+ */
+func newMonitorVM(t *testing.T) *jvmtest.VM {
+	class := New("sync/Monitors", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "leak", "(Ljava/lang/Object;)V").Code(1, 1).
+		Op(ALOAD_0).Op(MONITORENTER).Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "leakOnThrow", "(Ljava/lang/Object;)V").Code(2, 1).
+		Op(ALOAD_0).Op(MONITORENTER).
+		New("java/lang/RuntimeException").Op(DUP).
+		Invokespecial("java/lang/RuntimeException", "<init>", "()V").Op(ATHROW)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "balanced", "(Ljava/lang/Object;)V").Code(1, 1).
+		Op(ALOAD_0).Op(MONITORENTER).Op(ALOAD_0).Op(MONITOREXIT).Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "enterNull", "()V").Code(1, 0).
+		Op(ACONST_NULL).Op(MONITORENTER).Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "exitNull", "()V").Code(1, 0).
+		Op(ACONST_NULL).Op(MONITOREXIT).Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "exitUnowned", "(Ljava/lang/Object;)V").Code(1, 1).
+		Op(ALOAD_0).Op(MONITOREXIT).Op(RETURN)
+	return jvmtest.New(t, class)
+}
+
+/**
+	null和没有进入过的监视器抛出的异常java代码可以捕获
+ */
+func TestMonitorInstructionsThrowJavaExceptions(t *testing.T) {
+	vm := newMonitorVM(t)
+	object := vm.Class("java/lang/Object")
+
+	for _, test := range []struct {
+		method, descriptor string
+		args               []interface{}
+		exception, message string
+	}{
+		{"enterNull", "()V", nil, "java/lang/NullPointerException", ""},
+		{"exitNull", "()V", nil, "java/lang/NullPointerException", ""},
+		{"exitUnowned", "(Ljava/lang/Object;)V", []interface{}{object.NewObject()},
+			"java/lang/IllegalMonitorStateException", "current thread is not owner"},
+	} {
+		msg := vm.Call("sync/Monitors", test.method, test.descriptor, test.args...).Throws(test.exception)
+		if msg != test.message {
+			t.Errorf("%s: message = %q, want %q", test.method, msg, test.message)
+		}
+	}
+}
+
+/**
+	-Xcheck:monitors 打开之后不能再关，这个包里之后的测试都在检查之下运行
+ */
+func TestXcheckMonitorsFlagsUnreleasedMonitor(t *testing.T) {
+	vm := newMonitorVM(t)
+	object := vm.Class("java/lang/Object")
+
+	//默认不检查
+	vm.Call("sync/Monitors", "leak", "(Ljava/lang/Object;)V", object.NewObject())
+
+	chapter4_rtdt.EnableMonitorCheck()
+	msg := vm.Call("sync/Monitors", "leak", "(Ljava/lang/Object;)V", object.NewObject()).
+		Throws("java/lang/IllegalMonitorStateException")
+	if want := "sync.Monitors.leak(Ljava/lang/Object;)V exited with 1 unreleased monitor(s)"; msg != want {
+		t.Errorf("leak: message = %q, want %q", msg, want)
+	}
+	//因为异常退出时不检查，调用方看到的还是原来的异常
+	vm.Call("sync/Monitors", "leakOnThrow", "(Ljava/lang/Object;)V", object.NewObject()).
+		Throws("java/lang/RuntimeException")
+	if result := vm.Call("sync/Monitors", "balanced", "(Ljava/lang/Object;)V", object.NewObject()); result.Thrown != nil {
+		t.Errorf("balanced monitors threw %s", result.Thrown.Class().Name())
+	}
+}
@@ -24,32 +24,15 @@ func (self *MULTI_ANEW_ARRAY) Execute(frame *chapter4_rtdt.Frame) {
 	arrClass := classRef.ResolvedClass()
 
 	stack := frame.OperandStack()
-	counts := popAndCheckCounts(stack, int(self.dimensions))
-	arr := newMultiDimensionalArray(counts, arrClass)
+	counts := popCounts(stack, int(self.dimensions))
+	arr := arrClass.NewMultiDimensionalArray(counts)
 	stack.PushRef(arr)
 }
 
-func popAndCheckCounts(stack *chapter4_rtdt.OperandStack, dimensions int) []int32 {
+func popCounts(stack *chapter4_rtdt.OperandStack, dimensions int) []int32 {
 	counts := make([]int32, dimensions)
 	for i := dimensions - 1; i >= 0; i-- {
 		counts[i] = stack.PopInt()
-		if counts[i] < 0 {
-			panic("java.lang.NegativeArraySizeException")
-		}
 	}
-
 	return counts
 }
-
-func newMultiDimensionalArray(counts []int32, arrClass *heap.Class) *heap.Object {
-	count := uint(counts[0])
-	arr := arrClass.NewArray(count)
-
-	if len(counts) > 1 {
-		refs := arr.Refs()
-		for i := range refs {
-			refs[i] = newMultiDimensionalArray(counts[1:], arrClass.ComponentClass())
-		}
-	}
-	return arr
-}
\ No newline at end of file
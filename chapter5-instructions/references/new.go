@@ -19,9 +19,7 @@ func (self *NEW) Execute(frame *chapter4_rtdt.Frame) {
 	cp := frame.Method().Class().ConstantPool()
 	classRef := cp.GetConstant(self.Index).(*heap.ClassRef)
 	class := classRef.ResolvedClass()
-	if !class.InitStarted() {
-		frame.RevertNextPC()
-		base.InitClass(frame.Thread(), class)
+	if !base.CheckInitialized(frame, class) {
 		return
 	}
 
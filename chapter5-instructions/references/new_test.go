@@ -0,0 +1,54 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	class Pair {
+		int a; long b;
+		Pair(int a, long b) { this.a = a; this.b = b; }
+		static Pair make(int a, long b) { return new Pair(a, b); }
+		static long sum(int a, long b) { return 100 + new Pair(a, b).a + new Pair(a, b).b; }
+	}
+	sum里100先压栈，构造完成后它要还在原来的位置
+ */
+func newPairVM(t *testing.T) *jvmtest.VM {
+	class := New("refs/Pair", "java/lang/Object")
+	class.Field(0, "a", "I")
+	class.Field(0, "b", "J")
+	class.Method(0, "<init>", "(IJ)V").Code(3, 4).
+		Op(ALOAD_0).Invokespecial("java/lang/Object", "<init>", "()V").
+		Op(ALOAD_0).Op(ILOAD_1).Putfield("refs/Pair", "a", "I").
+		Op(ALOAD_0).Op(LLOAD_2).Putfield("refs/Pair", "b", "J").
+		Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "make", "(IJ)Lrefs/Pair;").Code(5, 3).
+		New("refs/Pair").Op(DUP).Op(ILOAD_0).Op(LLOAD_1).
+		Invokespecial("refs/Pair", "<init>", "(IJ)V").Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "sum", "(IJ)J").Code(8, 3).
+		Iconst(100).
+		New("refs/Pair").Op(DUP).Op(ILOAD_0).Op(LLOAD_1).
+		Invokespecial("refs/Pair", "<init>", "(IJ)V").
+		Getfield("refs/Pair", "a", "I").Op(IADD).Op(I2L).
+		New("refs/Pair").Op(DUP).Op(ILOAD_0).Op(LLOAD_1).
+		Invokespecial("refs/Pair", "<init>", "(IJ)V").
+		Getfield("refs/Pair", "b", "J").Op(LADD).Op(LRETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestNewDupInvokespecialWithArguments(t *testing.T) {
+	vm := newPairVM(t)
+
+	pair := vm.Call("refs/Pair", "make", "(IJ)Lrefs/Pair;", int32(7), int64(1) << 40).Ref()
+	if pair == nil || pair.Class().Name() != "refs/Pair" {
+		t.Fatalf("make(7, 1<<40) = %v, want a Pair", pair)
+	}
+	if a := pair.GetIntVar("a", "I"); a != 7 {
+		t.Errorf("a = %d, want 7", a)
+	}
+	if got := vm.Call("refs/Pair", "sum", "(IJ)J", int32(7), int64(1) << 40).Long(); got != 107 + 1 << 40 {
+		t.Errorf("sum(7, 1<<40) = %d, want %d", got, 107 + 1 << 40)
+	}
+}
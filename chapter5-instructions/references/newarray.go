@@ -1,6 +1,7 @@
 package references
 
 import (
+	"fmt"
 	"GoVM/chapter5-instructions/base"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
@@ -43,25 +44,27 @@ func (self *NEW_ARRAY) Execute(frame *chapter4_rtdt.Frame) {
 	stack.PushRef(arr)
 }
 
+/**
+	atype 到基本类型数组类名的映射，下标就是atype
+	0~3 不是合法的atype，对应空字符串
+ */
+var primitiveArrayClassNames = [...]string{
+	AT_BOOLEAN: "[Z",
+	AT_CHAR:    "[C",
+	AT_FLOAT:   "[F",
+	AT_DOUBLE:  "[D",
+	AT_BYTE:    "[B",
+	AT_SHORT:   "[S",
+	AT_INT:     "[I",
+	AT_LONG:    "[J",
+}
+
+/**
+	atype 只能是 T_BOOLEAN(4) 到 T_LONG(11)，其他值说明字节码本身不合法，抛出VerifyError
+ */
 func getPrimitiveArrayClass(loader *heap.ClassLoader, atype uint8) *heap.Class {
-	switch atype {
-	case AT_BOOLEAN:
-		return loader.LoadClass("[Z")
-	case AT_BYTE:
-		return loader.LoadClass("[B")
-	case AT_CHAR:
-		return loader.LoadClass("[C")
-	case AT_SHORT:
-		return loader.LoadClass("[S")
-	case AT_INT:
-		return loader.LoadClass("[I")
-	case AT_LONG:
-		return loader.LoadClass("[J")
-	case AT_FLOAT:
-		return loader.LoadClass("[F")
-	case AT_DOUBLE:
-		return loader.LoadClass("[D")
-	default:
-		panic("Invalid atype!")
+	if int(atype) >= len(primitiveArrayClassNames) || primitiveArrayClassNames[atype] == "" {
+		panic(fmt.Sprintf("java.lang.VerifyError: Bad newarray type code %d", atype))
 	}
-}
\ No newline at end of file
+	return loader.LoadClass(primitiveArrayClassNames[atype])
+}
@@ -0,0 +1,60 @@
+package references_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var newarrayTypes = []struct {
+	atype     byte
+	className string
+}{
+	{4, "[Z"}, {5, "[C"}, {6, "[F"}, {7, "[D"}, {8, "[B"}, {9, "[S"}, {10, "[I"}, {11, "[J"},
+}
+
+func newarrayMethod(atype byte) string {
+	return fmt.Sprintf("newarray%d", atype)
+}
+
+/**
+	每个atype一个 static Object newarrayN(int n) { newarray atype; }，另外两个atype不合法：3 和 12
+ */
+func newArrayVM(t *testing.T) *jvmtest.VM {
+	class := New("refs/Arrays", "java/lang/Object")
+	atypes := []byte{3, 12}
+	for _, test := range newarrayTypes {
+		atypes = append(atypes, test.atype)
+	}
+	for _, atype := range atypes {
+		class.Method(ACC_PUBLIC|ACC_STATIC, newarrayMethod(atype), "(I)Ljava/lang/Object;").Code(1, 1).
+			Op(ILOAD_0).Op(NEWARRAY, atype).Op(ARETURN)
+	}
+	return jvmtest.New(t, class)
+}
+
+func TestNewarrayTypeCodes(t *testing.T) {
+	vm := newArrayVM(t)
+	for _, test := range newarrayTypes {
+		arr := vm.Call("refs/Arrays", newarrayMethod(test.atype), "(I)Ljava/lang/Object;", int32(3)).Ref()
+		if name := arr.Class().Name(); name != test.className {
+			t.Errorf("newarray %d created %s, want %s", test.atype, name, test.className)
+		}
+		if arr.ArrayLength() != 3 {
+			t.Errorf("newarray %d: length %d, want 3", test.atype, arr.ArrayLength())
+		}
+	}
+}
+
+func TestNewarrayRejectsBadTypeCode(t *testing.T) {
+	vm := newArrayVM(t)
+	for _, atype := range []byte{3, 12} {
+		var result *jvmtest.Result
+		r := vm.CallPanic(&result, "refs/Arrays", newarrayMethod(atype), "(I)Ljava/lang/Object;", int32(1))
+		if msg, _ := r.(string); !strings.HasPrefix(msg, "java.lang.VerifyError:") || !strings.Contains(msg, "type code") {
+			t.Errorf("newarray %d panicked with %v, want a VerifyError", atype, r)
+		}
+	}
+}
@@ -43,37 +43,43 @@ func (self *PUT_FIELD) Execute(frame *chapter4_rtdt.Frame) {
 		val := stack.PopInt()
 		ref := stack.PopRef()
 		if ref == nil {
-			panic("java.lang.NullPointerException")
+			throwFieldNPE(frame, "assign", field)
+			return
 		}
 		ref.Fields().SetInt(slotId, val)
 	case 'F':
 		val := stack.PopFloat()
 		ref := stack.PopRef()
 		if ref == nil {
-			panic("java.lang.NullPointerException")
+			throwFieldNPE(frame, "assign", field)
+			return
 		}
 		ref.Fields().SetFloat(slotId, val)
 	case 'J':
 		val := stack.PopLong()
 		ref := stack.PopRef()
 		if ref == nil {
-			panic("java.lang.NullPointerException")
+			throwFieldNPE(frame, "assign", field)
+			return
 		}
 		ref.Fields().SetLong(slotId, val)
 	case 'D':
 		val := stack.PopDouble()
 		ref := stack.PopRef()
 		if ref == nil {
-			panic("java.lang.NullPointerException")
+			throwFieldNPE(frame, "assign", field)
+			return
 		}
 		ref.Fields().SetDouble(slotId, val)
 	case 'L', '[':
 		val := stack.PopRef()
 		ref := stack.PopRef()
 		if ref == nil {
-			panic("java.lang.NullPointerException")
+			throwFieldNPE(frame, "assign", field)
+			return
 		}
 		ref.Fields().SetRef(slotId, val)
+		ref.WriteBarrier(val)
 	default:
 	// todo
 	}
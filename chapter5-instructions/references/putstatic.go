@@ -20,9 +20,7 @@ func (self *PUT_STATIC) Execute(frame *chapter4_rtdt.Frame) {
 	fieldRef := cp.GetConstant(self.Index).(*heap.FieldRef)
 	field := fieldRef.ResolvedField()
 	class := field.Class()
-	if !class.InitStarted() {
-		frame.RevertNextPC()
-		base.InitClass(frame.Thread(), class)
+	if !base.CheckInitialized(frame, class) {
 		return
 	}
 
@@ -54,7 +52,9 @@ func (self *PUT_STATIC) Execute(frame *chapter4_rtdt.Frame) {
 	case 'D':
 		slots.SetDouble(slotId, stack.PopDouble())
 	case 'L', '[':
-		slots.SetRef(slotId, stack.PopRef())
+		ref := stack.PopRef()
+		slots.SetRef(slotId, ref)
+		class.StaticWriteBarrier(ref)
 	default:
 	// todo
 	}
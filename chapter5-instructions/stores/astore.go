@@ -51,7 +51,10 @@ func (self *ASTORE_3) Execute(frame *chapter4_rtdt.Frame) {
 	_astore(frame, 3)
 }
 
+/**
+	astore还要能存jsr压入的returnAddress，所以整个slot一起搬，不能只取引用
+ */
 func _astore(frame *chapter4_rtdt.Frame, index uint) {
-	val := frame.OperandStack().PopRef()
-	frame.LocalVars().SetRef(index, val)
+	slot := frame.OperandStack().PopSlot()
+	frame.LocalVars().SetSlot(index, slot)
 }
\ No newline at end of file
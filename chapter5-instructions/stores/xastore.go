@@ -3,7 +3,6 @@ package stores
 import (
 	"GoVM/chapter5-instructions/base"
 	"GoVM/chapter4-rtdt"
-	"GoVM/chapter6-obj/heap"
 )
 
 /**
@@ -22,10 +21,15 @@ func (self *AASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	refs := arrRef.Refs()
-	checkIndex(len(refs), index)
+	if !base.CheckIndex(frame, len(refs), index) {
+		return
+	}
 	refs[index] = ref
+	arrRef.WriteBarrier(ref)
 }
 
 // Store into byte or boolean array
@@ -39,9 +43,13 @@ func (self *BASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	bytes := arrRef.Bytes()
-	checkIndex(len(bytes), index)
+	if !base.CheckIndex(frame, len(bytes), index) {
+		return
+	}
 	bytes[index] = int8(val)
 }
 
@@ -56,9 +64,13 @@ func (self *CASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	chars := arrRef.Chars()
-	checkIndex(len(chars), index)
+	if !base.CheckIndex(frame, len(chars), index) {
+		return
+	}
 	chars[index] = uint16(val)
 }
 
@@ -73,9 +85,13 @@ func (self *DASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	doubles := arrRef.Doubles()
-	checkIndex(len(doubles), index)
+	if !base.CheckIndex(frame, len(doubles), index) {
+		return
+	}
 	doubles[index] = float64(val)
 }
 
@@ -90,9 +106,13 @@ func (self *FASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	floats := arrRef.Floats()
-	checkIndex(len(floats), index)
+	if !base.CheckIndex(frame, len(floats), index) {
+		return
+	}
 	floats[index] = float32(val)
 }
 
@@ -107,9 +127,13 @@ func (self *IASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	ints := arrRef.Ints()
-	checkIndex(len(ints), index)
+	if !base.CheckIndex(frame, len(ints), index) {
+		return
+	}
 	ints[index] = int32(val)
 }
 
@@ -124,9 +148,13 @@ func (self *LASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
+	if !base.CheckNotNil(frame, arrRef) {
+		return
+	}
 	longs := arrRef.Longs()
-	checkIndex(len(longs), index)
+	if !base.CheckIndex(frame, len(longs), index) {
+		return
+	}
 	longs[index] = int64(val)
 }
 
@@ -141,19 +169,12 @@ func (self *SASTORE) Execute(frame *chapter4_rtdt.Frame) {
 	index := stack.PopInt()
 	arrRef := stack.PopRef()
 
-	checkNotNil(arrRef)
-	shorts := arrRef.Shorts()
-	checkIndex(len(shorts), index)
-	shorts[index] = int16(val)
-}
-
-func checkNotNil(ref *heap.Object) {
-	if ref == nil {
-		panic("java.lang.NullPointerException")
+	if !base.CheckNotNil(frame, arrRef) {
+		return
 	}
-}
-func checkIndex(arrLen int, index int32) {
-	if index < 0 || index >= int32(arrLen) {
-		panic("ArrayIndexOutOfBoundsException")
+	shorts := arrRef.Shorts()
+	if !base.CheckIndex(frame, len(shorts), index) {
+		return
 	}
+	shorts[index] = int16(val)
 }
@@ -0,0 +1,28 @@
+package stores_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	try { new String[1][index] = null; return null; } catch (ArrayIndexOutOfBoundsException e) { return e.getMessage(); }
+ */
+func TestAastoreOutOfBoundsIsCatchable(t *testing.T) {
+	class := New("stores/Arrays", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "store", "(I)Ljava/lang/String;").Code(3, 1).
+		Label("start").Op(ICONST_1).Anewarray("java/lang/String").Op(ILOAD_0).Op(ACONST_NULL).Op(AASTORE).
+		Op(ACONST_NULL).Op(ARETURN).
+		Label("handler").Invokevirtual("java/lang/Throwable", "getMessage", "()Ljava/lang/String;").Op(ARETURN).
+		Catch("start", "handler", "handler", "java/lang/ArrayIndexOutOfBoundsException")
+	vm := jvmtest.New(t, class)
+
+	if got := vm.Call("stores/Arrays", "store", "(I)Ljava/lang/String;", 0).String(); got != "null" {
+		t.Errorf("store(0) = %q, want no exception", got)
+	}
+	want := "Index 1 out of bounds for length 1"
+	if got := vm.Call("stores/Arrays", "store", "(I)Ljava/lang/String;", 1).String(); got != want {
+		t.Errorf("store(1) message = %q, want %q", got, want)
+	}
+}
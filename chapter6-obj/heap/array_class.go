@@ -9,28 +9,64 @@ func (self *Class) ComponentClass() *Class {
 	return self.loader.LoadClass(componentClassName)
 }
 
+/**
+	各种类型的数组都用对应的go切片存放，make会把每个元素置为零值，正好就是java要求的默认值
+ */
 func (self *Class) NewArray(count uint) *Object {
 	if !self.IsArray() {
 		panic("Not array class: " + self.name)
 	}
+	jvmHeap.alloc(arraySize(self.name, count))
 	switch self.Name() {
 	case "[Z":
-		return &Object{self, make([]int8, count), nil}
+		return self.newArrayObject(make([]int8, count))
 	case "[B":
-		return &Object{self, make([]int8, count), nil}
+		return self.newArrayObject(make([]int8, count))
 	case "[C":
-		return &Object{self, make([]uint16, count), nil}
+		return self.newArrayObject(make([]uint16, count))
 	case "[S":
-		return &Object{self, make([]int16, count), nil}
+		return self.newArrayObject(make([]int16, count))
 	case "[I":
-		return &Object{self, make([]int32, count), nil}
+		return self.newArrayObject(make([]int32, count))
 	case "[J":
-		return &Object{self, make([]int64, count), nil}
+		return self.newArrayObject(make([]int64, count))
 	case "[F":
-		return &Object{self, make([]float32, count), nil}
+		return self.newArrayObject(make([]float32, count))
 	case "[D":
-		return &Object{self, make([]float64, count), nil}
+		return self.newArrayObject(make([]float64, count))
 	default:
-		return &Object{self, make([]*Object, count), nil}
+		return self.newArrayObject(make([]*Object, count))
 	}
 }
+
+func (self *Class) newArrayObject(data interface{}) *Object {
+	obj := &Object{class: self, data: data}
+	jvmHeap.track(obj)
+	return obj
+}
+
+/**
+	multianewarray 用：counts[0]是最外层的长度，每一层的元素都是下一层新分配的数组，
+	元素类型每往里一层去掉一个'['，比如 [[I -> [I -> I。最里层的元素是零值
+	按照jvms，要在分配之前检查所有的长度，任何一个是负数都抛出NegativeArraySizeException
+ */
+func (self *Class) NewMultiDimensionalArray(counts []int32) *Object {
+	for _, count := range counts {
+		if count < 0 {
+			panic("java.lang.NegativeArraySizeException")
+		}
+	}
+	return self.newMultiDimensionalArray(counts)
+}
+
+func (self *Class) newMultiDimensionalArray(counts []int32) *Object {
+	arr := self.NewArray(uint(counts[0]))
+	if len(counts) > 1 {
+		componentClass := self.ComponentClass()
+		refs := arr.Refs()
+		for i := range refs {
+			refs[i] = componentClass.newMultiDimensionalArray(counts[1:])
+		}
+	}
+	return arr
+}
@@ -0,0 +1,55 @@
+package heap
+
+import "testing"
+
+func TestNewMultiDimensionalArray(t *testing.T) {
+	loader := testBootLoader(t)
+	arrClass := loader.LoadClass("[[[I")
+
+	arr := arrClass.NewMultiDimensionalArray([]int32{2, 3, 4})
+	if arr.Class() != arrClass || arr.ArrayLength() != 2 {
+		t.Fatalf("outer array is %s[%d]", arr.Class().Name(), arr.ArrayLength())
+	}
+	seen := map[*Object]bool{}
+	for _, middle := range arr.Refs() {
+		if middle.Class().Name() != "[[I" || middle.ArrayLength() != 3 {
+			t.Fatalf("middle array is %s[%d], want [[I[3]", middle.Class().Name(), middle.ArrayLength())
+		}
+		for _, inner := range middle.Refs() {
+			if inner.Class().Name() != "[I" || len(inner.Ints()) != 4 {
+				t.Fatalf("inner array is %s[%d], want [I[4]", inner.Class().Name(), inner.ArrayLength())
+			}
+			for _, v := range inner.Ints() {
+				if v != 0 {
+					t.Errorf("element = %d, want 0", v)
+				}
+			}
+			seen[inner] = true
+		}
+	}
+	if len(seen) != 6 {
+		t.Errorf("%d distinct inner arrays, want 6", len(seen))
+	}
+
+	//new int[2][3][]：没有给出长度的那一层是null
+	arr = arrClass.NewMultiDimensionalArray([]int32{2, 3})
+	if inner := arr.Refs()[1].Refs()[2]; inner != nil {
+		t.Errorf("unsized dimension holds %s", inner.Class().Name())
+	}
+	//长度为0的一层下面什么都不分配
+	arr = arrClass.NewMultiDimensionalArray([]int32{2, 0, 5})
+	if n := arr.Refs()[0].ArrayLength(); n != 0 {
+		t.Errorf("middle array length = %d, want 0", n)
+	}
+}
+
+func TestNewMultiDimensionalArrayChecksAllCountsFirst(t *testing.T) {
+	arrClass := testBootLoader(t).LoadClass("[[I")
+	//外层长度是0，用不到第二层的长度，也要检查
+	for _, counts := range [][]int32{{-1, 2}, {0, -1}} {
+		r := catchPanic(func() { arrClass.NewMultiDimensionalArray(counts) })
+		if r != "java.lang.NegativeArraySizeException" {
+			t.Errorf("counts %v: panic = %v, want NegativeArraySizeException", counts, r)
+		}
+	}
+}
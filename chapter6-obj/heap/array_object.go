@@ -1,38 +1,69 @@
 package heap
 
+import "fmt"
+
 /**
 	将使用int8型来标识boolean类型
+	元素类型和数组类不匹配时panic，信息里带上实际的类名，方便找出是哪条指令用错了
  */
 func (self *Object) Bytes() []int8 {
-	return self.data.([]int8)
+	if data, ok := self.data.([]int8); ok {
+		return data
+	}
+	panic(self.notArrayOf("byte"))
 }
 
 func (self *Object) Shorts() []int16 {
-	return self.data.([]int16)
+	if data, ok := self.data.([]int16); ok {
+		return data
+	}
+	panic(self.notArrayOf("short"))
 }
 
 func (self *Object) Ints() []int32 {
-	return self.data.([]int32)
+	if data, ok := self.data.([]int32); ok {
+		return data
+	}
+	panic(self.notArrayOf("int"))
 }
 
 func (self *Object) Longs() []int64 {
-	return self.data.([]int64)
+	if data, ok := self.data.([]int64); ok {
+		return data
+	}
+	panic(self.notArrayOf("long"))
 }
 
 func (self *Object) Chars() []uint16 {
-	return self.data.([]uint16)
+	if data, ok := self.data.([]uint16); ok {
+		return data
+	}
+	panic(self.notArrayOf("char"))
 }
 
 func (self *Object) Floats() []float32 {
-	return self.data.([]float32)
+	if data, ok := self.data.([]float32); ok {
+		return data
+	}
+	panic(self.notArrayOf("float"))
 }
 
 func (self *Object) Doubles() []float64 {
-	return self.data.([]float64)
+	if data, ok := self.data.([]float64); ok {
+		return data
+	}
+	panic(self.notArrayOf("double"))
 }
 
 func (self *Object) Refs() []*Object {
-	return self.data.([]*Object)
+	if data, ok := self.data.([]*Object); ok {
+		return data
+	}
+	panic(self.notArrayOf("reference"))
+}
+
+func (self *Object) notArrayOf(elementType string) string {
+	return fmt.Sprintf("%s is not an array of %s", self.class.name, elementType)
 }
 
 func ArrayCopy(src, dest *Object, srcPos, dstPos, length int32) {
@@ -69,6 +100,9 @@ func ArrayCopy(src, dest *Object, srcPos, dstPos, length int32) {
 		_src := src.data.([]*Object)[srcPos : srcPos + length]
 		_dst := dest.data.([]*Object)[dstPos : dstPos + length]
 		copy(_dst, _src)
+		for _, ref := range _src {
+			dest.WriteBarrier(ref)
+		}
 	default:
 		panic("Not array!")
 	}
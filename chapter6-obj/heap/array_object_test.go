@@ -0,0 +1,65 @@
+package heap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/**
+	每种数组类对应的访问方法，boolean和byte都用[]int8
+ */
+var arrayAccessors = []struct {
+	class    string
+	accessor string
+	data     func(*Object) interface{}
+}{
+	{"[Z", "Bytes", func(o *Object) interface{} { return o.Bytes() }},
+	{"[B", "Bytes", func(o *Object) interface{} { return o.Bytes() }},
+	{"[S", "Shorts", func(o *Object) interface{} { return o.Shorts() }},
+	{"[I", "Ints", func(o *Object) interface{} { return o.Ints() }},
+	{"[J", "Longs", func(o *Object) interface{} { return o.Longs() }},
+	{"[C", "Chars", func(o *Object) interface{} { return o.Chars() }},
+	{"[F", "Floats", func(o *Object) interface{} { return o.Floats() }},
+	{"[D", "Doubles", func(o *Object) interface{} { return o.Doubles() }},
+	{"[Ljava/lang/Object;", "Refs", func(o *Object) interface{} { return o.Refs() }},
+}
+
+func TestTypedArrayAccessors(t *testing.T) {
+	loader := testBootLoader(t)
+	for _, array := range arrayAccessors {
+		arr := loader.LoadClass(array.class).NewArray(3)
+		if n := arr.ArrayLength(); n != 3 {
+			t.Errorf("%s: ArrayLength() = %d, want 3", array.class, n)
+		}
+		if n := reflect.ValueOf(array.data(arr)).Len(); n != 3 {
+			t.Errorf("%s.%s() has %d elements, want 3", array.class, array.accessor, n)
+		}
+		//其他类型的访问方法都panic，信息里有数组的类名
+		for _, other := range arrayAccessors {
+			if other.accessor == array.accessor {
+				continue
+			}
+			msg, _ := catchPanic(func() { other.data(arr) }).(string)
+			if want := array.class + " is not an array of "; !strings.HasPrefix(msg, want) {
+				t.Errorf("%s.%s(): panic = %q, want %q...", array.class, other.accessor, msg, want)
+			}
+		}
+	}
+}
+
+func TestNewObjectOnArrayClassAllocatesEmptyArray(t *testing.T) {
+	loader := testBootLoader(t)
+	for _, array := range arrayAccessors {
+		arr := loader.LoadClass(array.class).NewObject()
+		if n := arr.ArrayLength(); n != 0 {
+			t.Errorf("%s.NewObject() has length %d, want 0", array.class, n)
+		}
+		array.data(arr)
+	}
+	//不是数组的对象没有数组访问方法
+	msg, _ := catchPanic(func() { loader.LoadClass("java/lang/Object").NewObject().Ints() }).(string)
+	if msg != "java/lang/Object is not an array of int" {
+		t.Errorf("Ints() on a plain object: panic = %q", msg)
+	}
+}
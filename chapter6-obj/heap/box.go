@@ -0,0 +1,129 @@
+package heap
+
+import "fmt"
+
+/**
+	包装类和它的value字段的描述符
+ */
+var wrapperValueDescriptors = map[string]string{
+	"java/lang/Boolean":   "Z",
+	"java/lang/Byte":      "B",
+	"java/lang/Character": "C",
+	"java/lang/Short":     "S",
+	"java/lang/Integer":   "I",
+	"java/lang/Long":      "J",
+	"java/lang/Float":     "F",
+	"java/lang/Double":    "D",
+}
+
+/**
+	把go的值装箱成包装类对象，和其他本地方法创建的对象一样，不调用构造函数（也不走valueOf的缓存），直接给value字段赋值
+	boolean、byte、char、short、int在局部变量表里都占一个int的slot，所以这几种都可以传int32，会按字段的宽度截断；
+	long和double占两个slot
+ */
+func Box(loader *ClassLoader, value interface{}, wrapperClassName string) *Object {
+	descriptor := wrapperValueDescriptor(wrapperClassName)
+	obj := loader.LoadClass(wrapperClassName).NewObject()
+	slots := obj.Fields()
+	slotId := obj.class.getField("value", descriptor, false).slotId
+
+	switch descriptor {
+	case "J":
+		val, ok := value.(int64)
+		if !ok {
+			val = int64(boxedInt(value, wrapperClassName))
+		}
+		slots.SetLong(slotId, val)
+	case "F":
+		val, ok := value.(float32)
+		if !ok {
+			panic(boxTypeError(value, wrapperClassName))
+		}
+		slots.SetFloat(slotId, val)
+	case "D":
+		val, ok := value.(float64)
+		if !ok {
+			panic(boxTypeError(value, wrapperClassName))
+		}
+		slots.SetDouble(slotId, val)
+	default:
+		slots.SetInt(slotId, truncateInt(boxedInt(value, wrapperClassName), descriptor))
+	}
+	return obj
+}
+
+/**
+	拆箱，返回值的go类型和字段类型对应：Integer -> int32，Character -> uint16，Boolean -> bool ...
+ */
+func Unbox(obj *Object) interface{} {
+	if obj == nil {
+		panic("java.lang.NullPointerException")
+	}
+	descriptor := wrapperValueDescriptor(obj.class.name)
+	slots := obj.Fields()
+	slotId := obj.class.getField("value", descriptor, false).slotId
+
+	switch descriptor {
+	case "Z":
+		return slots.GetInt(slotId) != 0
+	case "B":
+		return int8(slots.GetInt(slotId))
+	case "C":
+		return uint16(slots.GetInt(slotId))
+	case "S":
+		return int16(slots.GetInt(slotId))
+	case "J":
+		return slots.GetLong(slotId)
+	case "F":
+		return slots.GetFloat(slotId)
+	case "D":
+		return slots.GetDouble(slotId)
+	default:
+		return slots.GetInt(slotId)
+	}
+}
+
+func wrapperValueDescriptor(className string) string {
+	descriptor, ok := wrapperValueDescriptors[className]
+	if !ok {
+		panic("Not a primitive wrapper class: " + className)
+	}
+	return descriptor
+}
+
+func boxedInt(value interface{}, wrapperClassName string) int32 {
+	switch val := value.(type) {
+	case int32:
+		return val
+	case int16:
+		return int32(val)
+	case int8:
+		return int32(val)
+	case uint16:
+		return int32(val)
+	case bool:
+		if val {
+			return 1
+		}
+		return 0
+	}
+	panic(boxTypeError(value, wrapperClassName))
+}
+
+func truncateInt(val int32, descriptor string) int32 {
+	switch descriptor {
+	case "Z":
+		return val & 1
+	case "B":
+		return int32(int8(val))
+	case "C":
+		return int32(uint16(val))
+	case "S":
+		return int32(int16(val))
+	}
+	return val
+}
+
+func boxTypeError(value interface{}, wrapperClassName string) string {
+	return fmt.Sprintf("Cannot box %T as %s", value, wrapperClassName)
+}
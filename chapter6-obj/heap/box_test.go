@@ -0,0 +1,79 @@
+package heap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBoxUnboxRoundTrip(t *testing.T) {
+	loader := newTestLoader(t)
+	for _, test := range []struct {
+		wrapper     string
+		value, want interface{}
+	}{
+		{"java/lang/Integer", int32(math.MinInt32), int32(math.MinInt32)},
+		{"java/lang/Long", int64(math.MaxInt64), int64(math.MaxInt64)},
+		{"java/lang/Float", float32(-1.5), float32(-1.5)},
+		{"java/lang/Double", math.Inf(1), math.Inf(1)},
+		{"java/lang/Boolean", true, true},
+		{"java/lang/Byte", int8(-128), int8(-128)},
+		{"java/lang/Short", int16(-12345), int16(-12345)},
+		{"java/lang/Character", uint16(0xffff), uint16(0xffff)},
+		//int32可以装进任何一个int宽度的包装类，按字段宽度截断
+		{"java/lang/Byte", int32(0x1ff), int8(-1)},
+		{"java/lang/Short", int32(0x18000), int16(math.MinInt16)},
+		{"java/lang/Character", int32(-1), uint16(0xffff)},
+		{"java/lang/Boolean", int32(3), true},
+		{"java/lang/Boolean", int32(2), false},
+		//Long也接受int宽度的值
+		{"java/lang/Long", int32(-7), int64(-7)},
+	} {
+		obj := Box(loader, test.value, test.wrapper)
+		if obj.Class().Name() != test.wrapper {
+			t.Errorf("Box(%v, %s) allocated a %s", test.value, test.wrapper, obj.Class().Name())
+		}
+		if got := Unbox(obj); got != test.want {
+			t.Errorf("Unbox(Box(%T %v, %s)) = %T %v, want %T %v",
+				test.value, test.value, test.wrapper, got, got, test.want, test.want)
+		}
+	}
+}
+
+func TestBoxWritesTheValueField(t *testing.T) {
+	loader := newTestLoader(t)
+	long := Box(loader, int64(-1) << 40, "java/lang/Long")
+	field := long.Class().getField("value", "J", false)
+	if got := long.Fields().GetLong(field.SlotId()); got != -1 << 40 {
+		t.Errorf("Long.value = %d, want %d", got, int64(-1) << 40)
+	}
+	char := Box(loader, uint16('字'), "java/lang/Character")
+	field = char.Class().getField("value", "C", false)
+	if got := char.Fields().GetInt(field.SlotId()); got != '字' {
+		t.Errorf("Character.value = %#x, want %#x", got, '字')
+	}
+}
+
+func TestBoxUnboxPanics(t *testing.T) {
+	loader := newTestLoader(t)
+	for _, test := range []struct {
+		name string
+		f    func()
+		want string
+	}{
+		{"unknown wrapper", func() { Box(loader, int32(1), "java/lang/String") },
+			"Not a primitive wrapper class: java/lang/String"},
+		{"float as Integer", func() { Box(loader, float32(1), "java/lang/Integer") },
+			"Cannot box float32 as java/lang/Integer"},
+		{"int64 as Double", func() { Box(loader, int64(1), "java/lang/Double") },
+			"Cannot box int64 as java/lang/Double"},
+		{"float64 as Float", func() { Box(loader, 1.0, "java/lang/Float") },
+			"Cannot box float64 as java/lang/Float"},
+		{"unbox String", func() { Unbox(JString(loader, "1")) },
+			"Not a primitive wrapper class: java/lang/String"},
+		{"unbox null", func() { Unbox(nil) }, "java.lang.NullPointerException"},
+	} {
+		if r := catchPanic(test.f); r != test.want {
+			t.Errorf("%s: panicked with %v, want %q", test.name, r, test.want)
+		}
+	}
+}
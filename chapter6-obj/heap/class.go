@@ -26,6 +26,7 @@ type Class struct {
 	constantPool   *ConstantPool
 	fields         []*Field
 	methods        []*Method
+	//定义这个类的加载器
 	loader         *ClassLoader
 	superClass     *Class
 	interfaces     []*Class
@@ -34,23 +35,42 @@ type Class struct {
 	//类变量(及static类型的变量)占据的空间大小
 	staticSlotCount uint
 	staticVars      Slots
+	//静态变量在记忆集里，可能引用着新生代的对象
+	staticsRemembered bool
 	//类的 <clinit> 方法是否已经开始执行
 	initStarted bool
+	//类的 <clinit> 方法是否已经执行完毕
+	initFinished bool
+	//<clinit>（或者超类、超接口的<clinit>）因为异常退出，以后用到这个类都抛出NoClassDefFoundError
+	initFailed bool
 	//与一个java中的java.lang.Class对应，而这个struct本身指的是虚拟机中的方法区中class的相关数据
 	jClass     *Object
 	sourceFile string
+	//泛型信息（Signature属性），没有时为""
+	signature  string
+	//成员类的外部类（来自InnerClasses属性）
+	declaringClassName string
+	//局部类、匿名类所在的类（来自EnclosingMethod属性）
+	enclosingClassName string
+	//BootstrapMethods属性，invokedynamic解析调用点时用
+	bootstrapMethods []*BootstrapMethod
 }
 
 func newClass(cf *chapter3_cf.ClassFile) *Class {
 	class := &Class{}
 	class.accessFlags = cf.AccessFlags()
-	class.name = cf.ClassName()
-	class.superClassName = cf.SuperClassName()
-	class.interfaceNames = cf.InterfaceNames()
+	class.name = internName(cf.ClassName())
+	class.superClassName = internName(cf.SuperClassName())
+	class.interfaceNames = internNames(cf.InterfaceNames())
 	class.constantPool = newConstantPool(class, cf.ConstantPool())
 	class.fields = newFields(class, cf.Fields())
 	class.methods = newMethods(class, cf.Methods())
 	class.sourceFile = getSourceFile(cf)
+	if sigAttr := cf.SignatureAttribute(); sigAttr != nil {
+		class.signature = sigAttr.Signature()
+	}
+	class.declaringClassName, class.enclosingClassName = getOuterClassNames(cf, class.name)
+	class.bootstrapMethods = newBootstrapMethods(cf)
 	return class
 }
 
@@ -61,6 +81,21 @@ func getSourceFile(cf *chapter3_cf.ClassFile) string {
 	return "Unknown"
 }
 
+/**
+	成员类的外部类名字来自InnerClasses属性，局部类和匿名类所在的类来自EnclosingMethod属性
+ */
+func getOuterClassNames(cf *chapter3_cf.ClassFile, className string) (string, string) {
+	declaringClassName := ""
+	if icAttr := cf.InnerClassesAttribute(); icAttr != nil {
+		declaringClassName = icAttr.OuterClassName(className)
+	}
+	enclosingClassName := ""
+	if emAttr := cf.EnclosingMethodAttribute(); emAttr != nil {
+		enclosingClassName = emAttr.ClassName()
+	}
+	return declaringClassName, enclosingClassName
+}
+
 func (self *Class) IsPublic() bool {
 	return 0 != self.accessFlags&ACC_PUBLIC
 }
@@ -91,6 +126,20 @@ func (self *Class) StartInit() {
 	self.initStarted = true
 }
 
+/**
+	<clinit>方法返回时调用，类进入已初始化的状态
+ */
+func (self *Class) FinishInit() {
+	self.initFinished = true
+}
+
+/**
+	<clinit>方法因为异常退出时调用，类进入初始化失败的状态，不会再次初始化
+ */
+func (self *Class) FailInit() {
+	self.initFailed = true
+}
+
 // getters start
 func (self *Class) ConstantPool() *ConstantPool {
 	return self.constantPool
@@ -110,6 +159,22 @@ func (self *Class) Methods() []*Method {
 	return self.methods
 }
 
+func (self *Class) Interfaces() []*Class {
+	return self.interfaces
+}
+
+/**
+	接口里有没有default方法（非抽象的实例方法），有的话实现类初始化时要先初始化这个接口
+ */
+func (self *Class) HasDefaultMethods() bool {
+	for _, method := range self.methods {
+		if !method.IsAbstract() && !method.IsStatic() {
+			return true
+		}
+	}
+	return false
+}
+
 func (self *Class) SuperClass() *Class {
 	return self.superClass
 }
@@ -118,6 +183,14 @@ func (self *Class) InitStarted() bool {
 	return self.initStarted
 }
 
+func (self *Class) InitFinished() bool {
+	return self.initFinished
+}
+
+func (self *Class) InitFailed() bool {
+	return self.initFailed
+}
+
 func (self *Class) Loader() *ClassLoader {
 	return self.loader
 }
@@ -132,7 +205,20 @@ func (self *Class) JavaName() string {
 	return strings.Replace(self.name, "/", ".", -1)
 }
 
+/**
+	类在描述符里的写法，比如 int -> I，java/lang/String -> Ljava/lang/String;
+ */
+func (self *Class) Descriptor() string {
+	return toDescriptor(self.name)
+}
+
+/**
+	数组类没有实例字段，按元素类型分配一个长度为0的数组
+ */
 func (self *Class) NewObject() *Object {
+	if self.IsArray() {
+		return self.NewArray(0)
+	}
 	return newObject(self)
 }
 
@@ -202,6 +288,50 @@ func (self *Class) getMethod(name, descriptor string, isStatic bool) *Method {
 	return nil
 }
 
+/**
+	类自己的方法 + 继承来的没被覆盖的方法 + 接口的default方法，按 名字+描述符 去重，子类的优先
+	查找顺序和 lookupMethod 一样：先沿超类链往上，再找接口。
+	构造方法、<clinit>和private方法不会被继承，只算类自己的
+ */
+func (self *Class) AllMethods() []*Method {
+	seen := make(map[string]bool)
+	var methods []*Method
+	add := func(method *Method) {
+		key := method.name + method.descriptor
+		if !seen[key] {
+			seen[key] = true
+			methods = append(methods, method)
+		}
+	}
+
+	methods = append(methods, self.methods...)
+	for _, method := range self.methods {
+		seen[method.name + method.descriptor] = true
+	}
+	for c := self.superClass; c != nil; c = c.superClass {
+		for _, method := range c.methods {
+			if method.name != "<init>" && method.name != "<clinit>" && !method.IsPrivate() {
+				add(method)
+			}
+		}
+	}
+	for c := self; c != nil; c = c.superClass {
+		addDefaultMethods(c.interfaces, add)
+	}
+	return methods
+}
+
+func addDefaultMethods(ifaces []*Class, add func(*Method)) {
+	for _, iface := range ifaces {
+		for _, method := range iface.methods {
+			if !method.IsAbstract() && !method.IsStatic() && !method.IsPrivate() {
+				add(method)
+			}
+		}
+		addDefaultMethods(iface.interfaces, add)
+	}
+}
+
 func (self *Class) GetMainMethod() *Method {
 	return self.getStaticMethod("main", "([Ljava/lang/String;)V")
 }
@@ -210,22 +340,59 @@ func (self *Class) GetInstanceMethod(name, descriptor string) *Method {
 	return self.getMethod(name, descriptor, false)
 }
 
+/**
+	给嵌入方用的静态成员查询接口，会沿着超类链查找，找不到返回nil，不panic
+ */
+func (self *Class) GetStaticMethod(name, descriptor string) *Method {
+	return self.getMethod(name, descriptor, true)
+}
+
+func (self *Class) GetStaticField(name, descriptor string) *Field {
+	return self.getField(name, descriptor, true)
+}
+
+/**
+	返回静态字段在声明它的类的staticVars里占的slot，long和double是两个
+	返回的Slots和staticVars共用底层数组，字段不存在时返回nil
+ */
+func (self *Class) GetStaticFieldValue(name, descriptor string) Slots {
+	field := self.GetStaticField(name, descriptor)
+	if field == nil {
+		return nil
+	}
+	count := uint(1)
+	if field.isLongOrDouble() {
+		count = 2
+	}
+	return field.class.staticVars.Slice(field.slotId, count)
+}
+
+/**
+	静态字段可能是从超类或接口继承来的，要用声明它的类的staticVars
+ */
 func (self *Class) GetRefVar(fieldName, fieldDescriptor string) *Object {
 	field := self.getField(fieldName, fieldDescriptor, true)
-	return self.staticVars.GetRef(field.slotId)
+	return field.class.staticVars.GetRef(field.slotId)
 }
 func (self *Class) SetRefVar(fieldName, fieldDescriptor string, ref *Object) {
 	field := self.getField(fieldName, fieldDescriptor, true)
-	self.staticVars.SetRef(field.slotId, ref)
+	field.class.staticVars.SetRef(field.slotId, ref)
+	field.class.StaticWriteBarrier(ref)
 }
 
 /**
 根据字段名、描述符以及是否是static来查找方法
+静态字段还可能是接口中的常量，超类链上找不到时，再到每个类实现的接口以及这些接口的超接口里找
 */
 func (self *Class) getField(name, descriptor string, isStatic bool) *Field {
 	for c := self; c != nil; c = c.superClass {
-		for _, field := range c.fields {
-			if field.IsStatic() == isStatic && field.name == name && field.descriptor == descriptor {
+		if field := c.getDeclaredField(name, descriptor, isStatic); field != nil {
+			return field
+		}
+	}
+	if isStatic {
+		for c := self; c != nil; c = c.superClass {
+			if field := getInterfaceStaticField(c.interfaces, name, descriptor); field != nil {
 				return field
 			}
 		}
@@ -233,6 +400,30 @@ func (self *Class) getField(name, descriptor string, isStatic bool) *Field {
 	return nil
 }
 
+/**
+	接口 extends 接口 时，常量可能声明在更上层的接口里，递归地找
+ */
+func getInterfaceStaticField(ifaces []*Class, name, descriptor string) *Field {
+	for _, iface := range ifaces {
+		if field := iface.getDeclaredField(name, descriptor, true); field != nil {
+			return field
+		}
+		if field := getInterfaceStaticField(iface.interfaces, name, descriptor); field != nil {
+			return field
+		}
+	}
+	return nil
+}
+
+func (self *Class) getDeclaredField(name, descriptor string, isStatic bool) *Field {
+	for _, field := range self.fields {
+		if field.IsStatic() == isStatic && field.name == name && field.descriptor == descriptor {
+			return field
+		}
+	}
+	return nil
+}
+
 func (self *Class) getStaticMethod(name, descriptor string) *Method {
 	for _, method := range self.methods {
 		if method.IsStatic() && method.name == name && method.descriptor == descriptor {
@@ -246,6 +437,36 @@ func (self *Class) GetClinitMethod() *Method {
 	return self.getStaticMethod("<clinit>", "()V")
 }
 
+func (self *Class) Signature() string {
+	return self.signature
+}
+
 func (self *Class) SourceFile() string {
 	return self.sourceFile
 }
+
+func (self *Class) BootstrapMethods() []*BootstrapMethod {
+	return self.bootstrapMethods
+}
+
+
+/**
+	成员类返回声明它的外部类，其他类返回nil
+ */
+func (self *Class) DeclaringClass() *Class {
+	if self.declaringClassName == "" {
+		return nil
+	}
+	return self.loader.LoadClass(self.declaringClassName)
+}
+
+/**
+	直接包含这个类的类：
+	局部类和匿名类以EnclosingMethod属性为准，成员类看InnerClasses属性，顶层类返回nil
+ */
+func (self *Class) EnclosingClass() *Class {
+	if self.enclosingClassName != "" {
+		return self.loader.LoadClass(self.enclosingClassName)
+	}
+	return self.DeclaringClass()
+}
\ No newline at end of file
@@ -1,7 +1,9 @@
 package heap
 
 /**
-	一个类是否是可以看成是另外一个
+	一个类是否是可以看成是另外一个，instanceof、checkcast、aastore和catch都按这个规则判断
+	类 -> 类 沿超类链找；类 -> 接口 找实现的接口（包括超类和超接口的）；接口 -> 接口 找超接口；
+	数组可以赋给Object、Cloneable、Serializable，数组之间按元素类型协变
  */
 func (self *Class) IsAssignableFrom(other *Class) bool {
 	//s -> other  t -> self
@@ -34,7 +36,7 @@ func (self *Class) IsAssignableFrom(other *Class) bool {
 		// s is array
 		if !t.IsArray() {
 			if !t.IsInterface() {
-				// t is classBubbleSortTest.class
+				// t is class
 				return t.isJlObject()
 			} else {
 				// t is interface
@@ -42,13 +44,15 @@ func (self *Class) IsAssignableFrom(other *Class) bool {
 			}
 		} else {
 			// t is array
+			//S[] 能赋给 T[] 当且仅当 S 能赋给 T，元素是基本类型时只能是同一个类型，比如 int[] 不能赋给 long[]
 			sc := s.ComponentClass()
 			tc := t.ComponentClass()
-			return sc == tc || tc.IsAssignableFrom(sc)
+			if sc.IsPrimitive() || tc.IsPrimitive() {
+				return sc == tc
+			}
+			return tc.IsAssignableFrom(sc)
 		}
 	}
-
-	return false
 }
 
 /**
@@ -63,25 +67,15 @@ func (self *Class) isSubClassOf(other *Class) bool {
 	return false
 }
 
-// self implements iface
-func (self *Class) IsImplements(iface *Class) bool {
-	for c := self; c != nil; c = c.superClass {
-		for _, i := range c.interfaces {
-			if i == iface || i.isSubInterfaceOf(iface) {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 /**
 	一个类是不是一个接口的实现类
+	除了直接声明的接口，还要沿着超类链，以及接口继承的接口递归地查找
+	比如 C implements I，I extends J，那么 C 也实现了 J
  */
-func (self *Class) isImplement(inface *Class) bool {
+func (self *Class) IsImplements(iface *Class) bool {
 	for c := self; c != nil; c = c.superClass {
 		for _, i := range c.interfaces {
-			if i == inface || i.isSubInterfaceOf(inface) {
+			if i == iface || i.isSubInterfaceOf(iface) {
 				return true
 			}
 		}
@@ -0,0 +1,59 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"testing"
+)
+
+/**
+	interface J {}  interface I extends J {}
+	class Base implements I {}  class Sub extends Base {}  class Other {}
+ */
+func TestIsAssignableFrom(t *testing.T) {
+	loader := newTestLoader(t,
+		classgen.NewInterface("hier/J"), classgen.NewInterface("hier/I", "hier/J"),
+		classgen.New("hier/Base", "java/lang/Object", "hier/I"), classgen.New("hier/Sub", "hier/Base"),
+		classgen.New("hier/Other", "java/lang/Object"))
+	for _, test := range []struct {
+		to, from string
+		want     bool
+	}{
+		//类 -> 类
+		{"hier/Base", "hier/Sub", true},
+		{"hier/Sub", "hier/Base", false},
+		{"hier/Base", "hier/Other", false},
+		{"java/lang/Object", "hier/Sub", true},
+		//类 -> 接口，包括从超类继承的和超接口
+		{"hier/I", "hier/Base", true},
+		{"hier/J", "hier/Sub", true},
+		{"hier/I", "hier/Other", false},
+		//接口 -> 接口、接口 -> Object
+		{"hier/J", "hier/I", true},
+		{"hier/I", "hier/J", false},
+		{"java/lang/Object", "hier/I", true},
+		{"hier/Base", "hier/I", false},
+		//数组按元素类型协变
+		{"[Ljava/lang/Object;", "[Ljava/lang/String;", true},
+		{"[Ljava/lang/String;", "[Ljava/lang/Object;", false},
+		{"[Lhier/J;", "[Lhier/Sub;", true},
+		{"[[Lhier/Base;", "[[Lhier/Sub;", true},
+		{"[Ljava/lang/Object;", "[[I", true},
+		//基本类型的数组只能赋给自己
+		{"[I", "[I", true},
+		{"[J", "[I", false},
+		{"[Ljava/lang/Object;", "[I", false},
+		{"[I", "[Ljava/lang/Integer;", false},
+		//任何数组都能赋给Object、Cloneable、Serializable，别的接口和类不行
+		{"java/lang/Object", "[I", true},
+		{"java/lang/Cloneable", "[Lhier/Base;", true},
+		{"java/io/Serializable", "[[D", true},
+		{"hier/I", "[Lhier/Base;", false},
+		{"java/lang/String", "[C", false},
+		{"[Ljava/lang/Object;", "java/lang/Object", false},
+	} {
+		to, from := loader.LoadClass(test.to), loader.LoadClass(test.from)
+		if got := to.IsAssignableFrom(from); got != test.want {
+			t.Errorf("%s.IsAssignableFrom(%s) = %v, want %v", test.to, test.from, got, test.want)
+		}
+	}
+}
@@ -4,27 +4,67 @@ import (
 	"GoVM/chapter2-class/classpath"
 	"fmt"
 	"GoVM/chapter3-cf/classfile"
+	"time"
 )
 
 type ClassLoader struct {
+	//父加载器，为nil的是最顶层的加载器
+	parent      *ClassLoader
 	cp          *classpath.Classpath
 	//是否输出加载class信息
 	verboseFlag bool
+	//链接之前是否验证class，对应 -Xverify:all
+	verifyFlag  bool
 	//key 是类的完全限定名称
+	//每个加载器都有自己的classMap，类其实是由 (定义它的加载器, 类名) 唯一确定的，
+	//同一份class文件被两个加载器加载，得到的是两个不同的Class
+	//委托给父加载器加载的类也会记在这里，下次不用再问父加载器
 	classMap    map[string]*Class
+	//正在解析超类和接口的类，用来发现 A extends B，B extends A 这样的循环
+	loading     map[string]bool
+	//加载统计信息
+	stats       ClassLoaderStats
 }
 
-func NewClassLoader(cp *classpath.Classpath, verboseFlag bool) *ClassLoader {
+/**
+	类加载器的统计信息，用来分析启动开销
+	ClassesLoaded 已经加载的非数组类的数量
+	BytesParsed   解析过的class文件的总字节数
+	ParseTime     解析class文件所花的时间
+	LinkTime      链接（验证 + 准备）所花的时间
+ */
+type ClassLoaderStats struct {
+	ClassesLoaded uint
+	BytesParsed   uint64
+	ParseTime     time.Duration
+	LinkTime      time.Duration
+}
+
+/**
+	parent为nil时创建的是最顶层的加载器，由它加载java.lang.Class和基本类型的类。
+	有父加载器时，这些类都从父加载器那里拿，保证整个虚拟机里只有一份
+ */
+func NewClassLoader(parent *ClassLoader, cp *classpath.Classpath, verboseFlag, verifyFlag bool) *ClassLoader {
 	loader := &ClassLoader{
+		parent:        parent,
 		cp:        cp,
 		verboseFlag:        verboseFlag,
+		verifyFlag:        verifyFlag,
 		classMap:        make(map[string]*Class),
+		loading:        make(map[string]bool),
+	}
+	classLoaders = append(classLoaders, loader)
+	if parent == nil {
+		loader.loadBasicClasses()
+		loader.loadPrimitiveClasses()
 	}
-	loader.loadBasicClasses()
-	loader.loadPrimitiveClasses()
 	return loader
 }
 
+func (self *ClassLoader) Parent() *ClassLoader {
+	return self.parent
+}
+
 /**
 	先加载 java.lang.Class 类，这会触发 java.lang.Object等类和接口的加载。
 	然后遍历classMap，给已经加载的每一个 类 关联 类对象。
@@ -59,41 +99,117 @@ func (self *ClassLoader) loadPrimitiveClass(className string) {
 		name: className,
 		loader: self,
 		initStarted: true,
+		initFinished: true,
 	}
 	class.jClass = self.classMap["java/lang/Class"].NewObject()
 	class.jClass.extra = class
 	self.classMap[className] = class
 }
 
+/**
+	先查缓存，再委托给父加载器，父加载器找不到才自己加载
+ */
 func (self *ClassLoader) LoadClass(name string) *Class {
 	if class, ok := self.classMap[name]; ok {
 		//类已经被加载过了
 		return class
 	}
+	if class := self.findInParent(name); class != nil {
+		self.classMap[name] = class
+		return class
+	}
+
+	var class *Class
+	if name[0] == '[' {
+		class = self.loadArrayClass(name)
+	} else {
+		data, entry := self.readClass(name)
+		class = self.loadNonArrayClass(name, data, entry)
+	}
+	self.initJClass(class)
+	return class
+}
+
+func (self *ClassLoader) findInParent(name string) *Class {
+	if self.parent == nil {
+		return nil
+	}
+	return self.parent.findClass(name)
+}
+
+/**
+	和LoadClass一样，只是类路径上找不到时返回nil，不抛ClassNotFoundException，委托时用
+	数组类跟着元素类型走，元素类型能在这个加载器找到，数组类也在这里加载
+ */
+func (self *ClassLoader) findClass(name string) *Class {
+	if class, ok := self.classMap[name]; ok {
+		return class
+	}
+	if class := self.findInParent(name); class != nil {
+		self.classMap[name] = class
+		return class
+	}
 
 	var class *Class
 	if name[0] == '[' {
+		if self.findClass(getComponentClassName(name)) == nil {
+			return nil
+		}
 		class = self.loadArrayClass(name)
 	} else {
-		class = self.loadNonArrayClass(name)
+		data, entry, err := self.cp.ReadClass(name)
+		if err != nil {
+			return nil
+		}
+		class = self.loadNonArrayClass(name, data, entry)
 	}
+	self.initJClass(class)
+	return class
+}
 
-	if jlClassClass, ok := self.classMap["java/lang/Class"]; ok {
+/**
+	java.lang.Class还没加载时（加载基本类的过程中）先跳过，loadBasicClasses会补上
+ */
+func (self *ClassLoader) initJClass(class *Class) {
+	jlClassClass, ok := self.classMap["java/lang/Class"]
+	if !ok && self.parent != nil {
+		jlClassClass, ok = self.LoadClass("java/lang/Class"), true
+	}
+	if ok {
 		//这里其实是把 方法区中的Class 的jClass字段 存放了 new java.lang.Class()
 		class.jClass = jlClassClass.NewObject()
 		class.jClass.extra = class
 	}
+}
 
-	return class
+/**
+	从类路径读取资源文件，给 getResourceAsStream 用
+ */
+func (self *ClassLoader) ReadResource(name string) ([]byte, error) {
+	return self.cp.ReadResource(name)
+}
+
+func (self *ClassLoader) Stats() ClassLoaderStats {
+	return self.stats
 }
 
 /**
 	加载 所有 非数组 的类
  */
-func (self *ClassLoader) loadNonArrayClass(name string) *Class {
-	data, entry := self.readClass(name)
+func (self *ClassLoader) loadNonArrayClass(name string, data []byte, entry classpath.Entry) *Class {
+	//类在defineClass之后才放进classMap，解析超类时又回来加载自己，说明继承关系有环
+	if self.loading[name] {
+		panic("java.lang.ClassCircularityError: " + name)
+	}
+	self.loading[name] = true
+	defer delete(self.loading, name)
+
 	class := self.defineClass(data)
+
+	start := time.Now()
 	link(class)
+	self.stats.LinkTime += time.Since(start)
+	self.stats.ClassesLoaded++
 
 	if self.verboseFlag {
 		fmt.Printf("[Loaded %s from %s]\n", name, entry)
@@ -111,6 +227,7 @@ func (self *ClassLoader) loadArrayClass(name string) *Class {
 		name:        name,
 		loader:      self,
 		initStarted: true,
+		initFinished: true,
 		superClass:  self.LoadClass("java/lang/Object"),
 		interfaces: []*Class{
 			//数组默认实现了Cloneable和Serializable接口
@@ -132,7 +249,10 @@ func (self *ClassLoader) readClass(name string) ([]byte, classpath.Entry) {
 
 func (self *ClassLoader) defineClass(data []byte) *Class {
 	//byte转成class结构体
+	start := time.Now()
 	class := parseClass(data)
+	self.stats.ParseTime += time.Since(start)
+	self.stats.BytesParsed += uint64(len(data))
 	class.loader = self
 	resolveSuperClass(class)
 	resolveInterfaces(class)
@@ -173,7 +293,9 @@ func link(class *Class) {
 }
 
 func verify(class *Class) {
-	//todo
+	if class.loader.verifyFlag {
+		verifyClass(class)
+	}
 }
 
 /**
@@ -222,8 +344,9 @@ func calcStaticFieldSlotIds(class *Class) {
 func allocAndInitStaticVars(class *Class) {
 	class.staticVars = NewSlots(class.staticSlotCount)
 	for _, field := range class.fields {
-		if field.IsStatic() && field.IsFinal() {
-			//如果是static final 修饰的静态变量，从class文件的常量池里加载数值
+		if field.IsStatic() {
+			//带ConstantValue属性的静态变量（javac只给static final的常量生成），在准备阶段就从常量池里加载数值，
+			//不用等<clinit>。jvms 4.7.2 对非final的静态字段也是这么要求的
 			initStaticFinalVar(class, field)
 		}
 	}
@@ -238,19 +361,19 @@ func initStaticFinalVar(class *Class, field *Field) {
 	if cpIndex > 0 {
 		switch field.Descriptor() {
 		case "Z", "B", "C", "S", "I" :
-			val := cp.GetConstant(cpIndex).(int32)
+			val := cp.GetIntConstant(cpIndex)
 			vars.SetInt(slotId, val)
 		case "J":
-			val := cp.GetConstant(cpIndex).(int64)
+			val := cp.GetLongConstant(cpIndex)
 			vars.SetLong(slotId, val)
 		case "F":
-			val := cp.GetConstant(cpIndex).(float32)
+			val := cp.GetFloatConstant(cpIndex)
 			vars.SetFloat(slotId, val)
 		case "D":
-			val := cp.GetConstant(cpIndex).(float64)
+			val := cp.GetDoubleConstant(cpIndex)
 			vars.SetDouble(slotId, val)
-		case "Ljava/lang/String":
-			goStr := cp.GetConstant(cpIndex).(string)
+		case "Ljava/lang/String;":
+			goStr := cp.GetStringConstant(cpIndex)
 			jStr := JString(class.Loader(), goStr)
 			vars.SetRef(slotId, jStr)
 		}
@@ -0,0 +1,218 @@
+package heap
+
+import (
+	"GoVM/chapter2-class/classpath"
+	"GoVM/internal/classgen"
+	"GoVM/internal/testjdk"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+var (
+	bootOnce   sync.Once
+	bootLoader *ClassLoader
+)
+
+/**
+	测试共用一个最顶层的加载器，每个测试的类用自己的子加载器加载
+ */
+func testBootLoader(tb testing.TB) *ClassLoader {
+	jre := testjdk.JRE(tb)
+	bootOnce.Do(func() {
+		bootLoader = NewClassLoader(nil, classpath.Parse(jre, jre), false, false)
+	})
+	return bootLoader
+}
+
+func newTestLoader(t *testing.T, classes ...*classgen.Class) *ClassLoader {
+	t.Helper()
+	return NewClassLoader(testBootLoader(t), testjdk.Classpath(t, classes...), false, false)
+}
+
+func TestStatsCountLoadedClasses(t *testing.T) {
+	const n = 5
+	var classes []*classgen.Class
+	for i := 0; i < n; i++ {
+		classes = append(classes, classgen.New(fmt.Sprintf("stats/C%d", i), "java/lang/Object").DefaultConstructor())
+	}
+	loader := newTestLoader(t, classes...)
+	before := loader.Stats()
+	for _, class := range classes {
+		loader.LoadClass(class.Name())
+	}
+	after := loader.Stats()
+
+	if got := after.ClassesLoaded - before.ClassesLoaded; got != n {
+		t.Errorf("ClassesLoaded grew by %d, want %d", got, n)
+	}
+	if after.BytesParsed <= before.BytesParsed {
+		t.Errorf("BytesParsed did not grow: %d -> %d", before.BytesParsed, after.BytesParsed)
+	}
+	if after.ParseTime <= before.ParseTime {
+		t.Errorf("ParseTime did not grow: %v -> %v", before.ParseTime, after.ParseTime)
+	}
+
+	//已经加载过的类和委托给父加载器的类都不算
+	loader.LoadClass(classes[0].Name())
+	loader.LoadClass("java/lang/String")
+	if got := loader.Stats().ClassesLoaded; got != after.ClassesLoaded {
+		t.Errorf("ClassesLoaded = %d after reloading, want %d", got, after.ClassesLoaded)
+	}
+}
+
+/**
+	类由 (定义它的加载器, 类名) 确定：同样的class文件被两个加载器加载，得到两个互不兼容的类
+ */
+func TestSameClassFromTwoLoadersIsDistinct(t *testing.T) {
+	foo := classgen.New("ident/Foo", "java/lang/Object").DefaultConstructor()
+	bar := classgen.New("ident/Bar", "ident/Foo").DefaultConstructor()
+	loader1 := newTestLoader(t, foo, bar)
+	loader2 := newTestLoader(t, foo, bar)
+
+	foo1, foo2 := loader1.LoadClass("ident/Foo"), loader2.LoadClass("ident/Foo")
+	if foo1 == foo2 {
+		t.Fatal("both loaders returned the same Class")
+	}
+	if foo1.Loader() != loader1 || foo2.Loader() != loader2 {
+		t.Error("Class does not record its defining loader")
+	}
+	if foo1.IsAssignableFrom(foo2) || foo2.IsAssignableFrom(foo1) {
+		t.Error("classes from different loaders are assignable to each other")
+	}
+
+	//子类只和同一个加载器里的父类兼容
+	bar1 := loader1.LoadClass("ident/Bar")
+	if !foo1.IsAssignableFrom(bar1) {
+		t.Error("Foo is not assignable from Bar in the same loader")
+	}
+	if foo2.IsAssignableFrom(bar1) {
+		t.Error("Foo from loader2 is assignable from Bar from loader1")
+	}
+	if array1, array2 := loader1.LoadClass("[Lident/Foo;"), loader2.LoadClass("[Lident/Foo;"); array1 == array2 ||
+		array1.IsAssignableFrom(array2) {
+		t.Error("array classes of the two Foos are not distinct")
+	}
+
+	//委托给父加载器的类是同一个
+	if loader1.LoadClass("java/lang/Object") != loader2.LoadClass("java/lang/Object") {
+		t.Error("java.lang.Object differs between sibling loaders")
+	}
+}
+
+/**
+	class Consts {
+		static final int I = -5; static final long J = 1L << 40; static final float F = 1.5f;
+		static final double D = -0.25; static final String S = "constant value";
+		static int plain = 7;  //非final的字段带ConstantValue也要在准备阶段赋值
+	}
+	没有<clinit>，值只能来自ConstantValue属性
+ */
+func TestConstantValueIsSetAtPreparation(t *testing.T) {
+	class := classgen.New("prep/Consts", "java/lang/Object")
+	constant := func(flags uint16, name, descriptor string, index uint16) {
+		class.Field(flags, name, descriptor).Attribute("ConstantValue", []byte{byte(index >> 8), byte(index)})
+	}
+	const final = classgen.ACC_STATIC | classgen.ACC_FINAL
+	constant(final, "I", "I", class.IntInfo(-5))
+	constant(final, "J", "J", class.LongInfo(1 << 40))
+	constant(final, "F", "F", class.FloatInfo(1.5))
+	constant(final, "D", "D", class.DoubleInfo(-0.25))
+	constant(final, "S", "Ljava/lang/String;", class.StringInfo("constant value"))
+	constant(classgen.ACC_STATIC, "plain", "I", class.IntInfo(7))
+	loader := newTestLoader(t, class)
+	consts := loader.LoadClass("prep/Consts")
+
+	value := func(name, descriptor string) Slots {
+		return consts.GetStaticFieldValue(name, descriptor)
+	}
+	if got := value("I", "I").GetInt(0); got != -5 {
+		t.Errorf("I = %d, want -5", got)
+	}
+	if got := value("J", "J").GetLong(0); got != 1 << 40 {
+		t.Errorf("J = %d, want %d", got, int64(1) << 40)
+	}
+	if got := value("F", "F").GetFloat(0); got != 1.5 {
+		t.Errorf("F = %v, want 1.5", got)
+	}
+	if got := value("D", "D").GetDouble(0); got != -0.25 {
+		t.Errorf("D = %v, want -0.25", got)
+	}
+	if got := value("plain", "I").GetInt(0); got != 7 {
+		t.Errorf("plain = %d, want 7", got)
+	}
+	jStr := value("S", "Ljava/lang/String;").GetRef(0)
+	if jStr == nil || GoString(jStr) != "constant value" {
+		t.Fatalf("S = %v, want \"constant value\"", jStr)
+	}
+	if jStr != JString(loader, "constant value") {
+		t.Error("String constant is not the interned string")
+	}
+	if consts.InitStarted() {
+		t.Error("reading the constants initialized the class")
+	}
+}
+
+/**
+	javac不会编出来的继承关系：class A extends B，class B extends A，以及 interface I extends J，interface J extends I
+ */
+func TestCyclicHierarchyThrowsClassCircularityError(t *testing.T) {
+	loader := newTestLoader(t,
+		classgen.New("cyc/A", "cyc/B"), classgen.New("cyc/B", "cyc/A"),
+		classgen.NewInterface("cyc/I", "cyc/J"), classgen.NewInterface("cyc/J", "cyc/I"),
+		classgen.New("cyc/Fine", "java/lang/Object"))
+	for _, test := range []struct{ name, want string }{
+		{"cyc/A", "java.lang.ClassCircularityError: cyc/A"},
+		{"cyc/B", "java.lang.ClassCircularityError: cyc/B"},
+		{"cyc/I", "java.lang.ClassCircularityError: cyc/I"},
+		//失败之后正在加载的记录要清掉，再加载一次还是同样的错误，而不是别的什么
+		{"cyc/A", "java.lang.ClassCircularityError: cyc/A"},
+	} {
+		if r := loadPanic(loader, test.name); r != test.want {
+			t.Errorf("loading %s panicked with %v, want %q", test.name, r, test.want)
+		}
+	}
+	if r := loadPanic(loader, "cyc/Fine"); r != nil {
+		t.Errorf("loading an ordinary class after the cycles panicked: %v", r)
+	}
+}
+
+/**
+	父加载器和子加载器的classpath里都有 deleg/Shared，子加载器另外还有 deleg/Child extends Shared
+	先委托给父加载器，所以子加载器拿到的Shared是父加载器定义的那个
+ */
+func TestLoadClassDelegatesToParentFirst(t *testing.T) {
+	parentShared := classgen.New("deleg/Shared", "java/lang/Object")
+	parentShared.Field(0, "fromParent", "I")
+	parent := newTestLoader(t, parentShared)
+	child := NewClassLoader(parent, testjdk.Classpath(t,
+		classgen.New("deleg/Shared", "java/lang/Object"), classgen.New("deleg/Child", "deleg/Shared")), false, false)
+
+	shared := child.LoadClass("deleg/Shared")
+	if shared != parent.LoadClass("deleg/Shared") || shared.Loader() != parent {
+		t.Fatal("child loader defined deleg/Shared itself instead of delegating")
+	}
+	if shared.getField("fromParent", "I", false) == nil {
+		t.Error("deleg/Shared came from the child's classpath")
+	}
+
+	//同一个加载器加载两次是同一个指针
+	childClass := child.LoadClass("deleg/Child")
+	if childClass != child.LoadClass("deleg/Child") || childClass.Loader() != child {
+		t.Error("deleg/Child is not cached in the child loader")
+	}
+	if childClass.SuperClass() != shared {
+		t.Error("deleg/Child's superclass is not the parent's deleg/Shared")
+	}
+	//数组类跟着元素类型走
+	if array := child.LoadClass("[Ldeleg/Shared;"); array != parent.LoadClass("[Ldeleg/Shared;") {
+		t.Error("the child loader has its own deleg/Shared[]")
+	}
+	if child.LoadClass("java/lang/Object") != testBootLoader(t).LoadClass("java/lang/Object") {
+		t.Error("java/lang/Object is not the boot loader's")
+	}
+	//父加载器看不到子加载器的类
+	if r := loadPanic(parent, "deleg/Child"); r == nil {
+		t.Error("the parent loader found a class on the child's classpath")
+	}
+}
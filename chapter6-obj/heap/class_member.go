@@ -9,6 +9,8 @@ type ClassMember struct {
 	accessFlags uint16
 	name        string
 	descriptor  string
+	//泛型信息（Signature属性），没有时为""
+	signature   string
 	//主要为了通过字段或方法访问到它所属的类
 	class       *Class
 }
@@ -18,8 +20,15 @@ type ClassMember struct {
  */
 func (self *ClassMember) copyMemberInfo(memberInfo *chapter3_cf.MemberInfo) {
 	self.accessFlags = memberInfo.AccessFlags()
-	self.name = memberInfo.Name()
-	self.descriptor = memberInfo.Descriptor()
+	self.name = internName(memberInfo.Name())
+	self.descriptor = internName(memberInfo.Descriptor())
+	if sigAttr := memberInfo.SignatureAttribute(); sigAttr != nil {
+		self.signature = sigAttr.Signature()
+	}
+}
+
+func (self *ClassMember) AccessFlags() uint16 {
+	return self.accessFlags
 }
 
 func (self *ClassMember) IsPublic() bool {
@@ -55,20 +64,33 @@ func (self *ClassMember) Descriptor() string {
 	return self.descriptor
 }
 
+func (self *ClassMember) Signature() string {
+	return self.signature
+}
+
 func (self *ClassMember) Class() *Class {
 	return self.class
 }
 
 func (self *ClassMember) isAccessibleTo(d *Class) bool {
-	if self.IsPublic() {
+	return CanAccess(self.accessFlags, self.class, d)
+}
+
+/**
+	jvms 5.4.4，声明在类c中的成员能不能被类d访问：
+	public 任何类都可以；protected 子类和同一个包的类可以；包私有的只有同一个包的类可以；private 只有c自己可以
+	包取决于加载器和包名，一个加载器加载的类和另一个加载器加载的同名包里的类不在同一个运行时包中
+ */
+func CanAccess(accessFlags uint16, c, d *Class) bool {
+	if accessFlags & ACC_PUBLIC != 0 {
 		return true
 	}
-	c := self.class
-	if self.IsProtected() {
-		return d == c || d.isSubClassOf(c) || c.GetPackageName() == d.GetPackageName()
+	if accessFlags & ACC_PRIVATE != 0 {
+		return d == c
 	}
-	if !self.IsPrivate() {
-		return c.GetPackageName() == d.GetPackageName()
+	samePackage := c.loader == d.loader && c.GetPackageName() == d.GetPackageName()
+	if accessFlags & ACC_PROTECTED != 0 {
+		return d == c || d.isSubClassOf(c) || samePackage
 	}
-	return d == c
+	return samePackage
 }
\ No newline at end of file
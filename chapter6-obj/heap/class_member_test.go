@@ -0,0 +1,71 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"testing"
+)
+
+/**
+	成员声明在 pa/Owner 中：
+		pa/Peer       同一个包
+		pb/Sub        别的包里的子类
+		pb/Stranger   别的包里的无关类
+		otherPeer     另一个加载器加载的pa/Peer，包名相同但不是同一个运行时包
+ */
+func TestCanAccess(t *testing.T) {
+	classes := []*classgen.Class{
+		classgen.New("pa/Owner", "java/lang/Object"), classgen.New("pa/Peer", "java/lang/Object"),
+		classgen.New("pb/Sub", "pa/Owner"), classgen.New("pb/Stranger", "java/lang/Object"),
+	}
+	loader, other := newTestLoader(t, classes...), newTestLoader(t, classes...)
+	owner := loader.LoadClass("pa/Owner")
+	accessors := map[string]*Class{
+		"self":      owner,
+		"peer":      loader.LoadClass("pa/Peer"),
+		"sub":       loader.LoadClass("pb/Sub"),
+		"stranger":  loader.LoadClass("pb/Stranger"),
+		"otherPeer": other.LoadClass("pa/Peer"),
+	}
+	for _, test := range []struct {
+		flags    uint16
+		accessor string
+		want     bool
+	}{
+		{ACC_PUBLIC, "stranger", true},
+		{ACC_PUBLIC, "otherPeer", true},
+		{ACC_PROTECTED, "self", true},
+		{ACC_PROTECTED, "peer", true},
+		{ACC_PROTECTED, "sub", true},
+		{ACC_PROTECTED, "stranger", false},
+		{ACC_PROTECTED, "otherPeer", false},
+		{0, "self", true},
+		{0, "peer", true},
+		{0, "sub", false},
+		{0, "otherPeer", false},
+		{ACC_PRIVATE, "self", true},
+		{ACC_PRIVATE, "peer", false},
+		{ACC_PRIVATE, "sub", false},
+		//static、final之类的标志不影响访问控制
+		{ACC_PRIVATE | ACC_STATIC, "peer", false},
+		{ACC_PUBLIC | ACC_STATIC | ACC_FINAL, "sub", true},
+	} {
+		if got := CanAccess(test.flags, owner, accessors[test.accessor]); got != test.want {
+			t.Errorf("CanAccess(%#x, pa/Owner, %s) = %v, want %v", test.flags, test.accessor, got, test.want)
+		}
+	}
+}
+
+func TestMemberAccessGoesThroughCanAccess(t *testing.T) {
+	owner := classgen.New("pa/Fields", "java/lang/Object")
+	owner.Field(classgen.ACC_PROTECTED, "guarded", "I")
+	owner.Field(0, "local", "I")
+	loader := newTestLoader(t, owner, classgen.New("pb/FieldSub", "pa/Fields"))
+	fields, sub := loader.LoadClass("pa/Fields"), loader.LoadClass("pb/FieldSub")
+
+	if !fields.getField("guarded", "I", false).isAccessibleTo(sub) {
+		t.Error("protected field is not accessible to a subclass in another package")
+	}
+	if fields.getField("local", "I", false).isAccessibleTo(sub) {
+		t.Error("package-private field is accessible to a subclass in another package")
+	}
+}
@@ -0,0 +1,155 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"testing"
+)
+
+/**
+	class Base { static int count; static long total; static void reset() {} void run() {} }
+	class Sub extends Base {}
+ */
+func staticMemberClasses() []*classgen.Class {
+	base := classgen.New("statics/Base", "java/lang/Object")
+	base.Field(classgen.ACC_STATIC, "count", "I")
+	base.Field(classgen.ACC_STATIC, "total", "J")
+	base.Method(classgen.ACC_STATIC, "reset", "()V").Code(0, 0).Op(classgen.RETURN)
+	base.Method(0, "run", "()V").Code(0, 1).Op(classgen.RETURN)
+	return []*classgen.Class{base, classgen.New("statics/Sub", "statics/Base")}
+}
+
+func TestGetStaticMembersWalkSuperclasses(t *testing.T) {
+	loader := newTestLoader(t, staticMemberClasses()...)
+	base, sub := loader.LoadClass("statics/Base"), loader.LoadClass("statics/Sub")
+
+	if method := sub.GetStaticMethod("reset", "()V"); method == nil || method.Class() != base {
+		t.Errorf("Sub.GetStaticMethod(reset) = %v, want Base.reset", method)
+	}
+	if field := sub.GetStaticField("count", "I"); field == nil || field.Class() != base {
+		t.Errorf("Sub.GetStaticField(count) = %v, want Base.count", field)
+	}
+	//不存在的、以及不是静态的成员都返回nil
+	for _, method := range []*Method{sub.GetStaticMethod("missing", "()V"), sub.GetStaticMethod("run", "()V")} {
+		if method != nil {
+			t.Errorf("GetStaticMethod returned %s", method.Name())
+		}
+	}
+	if field := sub.GetStaticField("count", "J"); field != nil {
+		t.Errorf("GetStaticField with the wrong descriptor returned %s", field.Name())
+	}
+}
+
+func TestGetStaticFieldValueSharesStaticVars(t *testing.T) {
+	loader := newTestLoader(t, staticMemberClasses()...)
+	base, sub := loader.LoadClass("statics/Base"), loader.LoadClass("statics/Sub")
+
+	count := sub.GetStaticFieldValue("count", "I")
+	total := sub.GetStaticFieldValue("total", "J")
+	if len(count) != 1 || len(total) != 2 {
+		t.Fatalf("slot counts: int %d, long %d, want 1 and 2", len(count), len(total))
+	}
+	count.SetInt(0, 42)
+	total.SetLong(0, -1 << 50)
+	if got := base.GetStaticFieldValue("count", "I").GetInt(0); got != 42 {
+		t.Errorf("Base.count = %d, want 42", got)
+	}
+	field := base.GetStaticField("total", "J")
+	if got := base.StaticVars().GetLong(field.SlotId()); got != -1 << 50 {
+		t.Errorf("Base.total = %d, want %d", got, int64(-1) << 50)
+	}
+	if value := sub.GetStaticFieldValue("missing", "I"); value != nil {
+		t.Errorf("GetStaticFieldValue(missing) = %v, want nil", value)
+	}
+}
+
+/**
+	class A { void a(); void shared(); private void p(); }
+	interface E { default void e(); }
+	interface D extends E { default void d(); void x(); }
+	class B extends A implements D { void b(); void shared(); }
+	class C extends B { void c(); }
+ */
+func TestAllMethodsListsOwnAndInheritedOnce(t *testing.T) {
+	method := func(class *classgen.Class, flags uint16, name string) {
+		class.Method(flags, name, "()V").Code(0, 1).Op(classgen.RETURN)
+	}
+	a := classgen.New("all/A", "java/lang/Object").DefaultConstructor()
+	method(a, 0, "a")
+	method(a, 0, "shared")
+	method(a, classgen.ACC_PRIVATE, "p")
+	e := classgen.NewInterface("all/E")
+	method(e, classgen.ACC_PUBLIC, "e")
+	d := classgen.NewInterface("all/D", "all/E")
+	method(d, classgen.ACC_PUBLIC, "d")
+	d.Method(classgen.ACC_PUBLIC|classgen.ACC_ABSTRACT, "x", "()V")
+	b := classgen.New("all/B", "all/A", "all/D").DefaultConstructor()
+	method(b, 0, "b")
+	method(b, 0, "shared")
+	c := classgen.New("all/C", "all/B").DefaultConstructor()
+	method(c, 0, "c")
+	class := newTestLoader(t, a, e, d, b, c).LoadClass("all/C")
+
+	owners := map[string][]string{}
+	for _, m := range class.AllMethods() {
+		key := m.Name() + m.Descriptor()
+		owners[key] = append(owners[key], m.Class().Name())
+	}
+	for key, want := range map[string]string{
+		"<init>()V": "all/C", "c()V": "all/C", "b()V": "all/B", "shared()V": "all/B", "a()V": "all/A",
+		"d()V": "all/D", "e()V": "all/E", "toString()Ljava/lang/String;": "java/lang/Object",
+	} {
+		if got := owners[key]; len(got) != 1 || got[0] != want {
+			t.Errorf("%s comes from %v, want exactly once from %s", key, got, want)
+		}
+	}
+	for key, got := range owners {
+		if len(got) != 1 {
+			t.Errorf("%s is listed %d times: %v", key, len(got), got)
+		}
+	}
+	//private方法不继承，抽象的接口方法不是default方法
+	for _, key := range []string{"p()V", "x()V"} {
+		if got, ok := owners[key]; ok {
+			t.Errorf("%s should not be listed, got it from %v", key, got)
+		}
+	}
+}
+
+/**
+	class Box<T extends Number> implements Comparable<Box<T>> {
+		List<String> names;
+		int plain;
+		void take(List<String> list) {}
+		void none() {}
+	}
+ */
+func TestSignatureAttributes(t *testing.T) {
+	const (
+		classSig  = "<T:Ljava/lang/Number;>Ljava/lang/Object;Ljava/lang/Comparable<Lsig/Box<TT;>;>;"
+		fieldSig  = "Ljava/util/List<Ljava/lang/String;>;"
+		methodSig = "(Ljava/util/List<Ljava/lang/String;>;)V"
+	)
+	class := classgen.New("sig/Box", "java/lang/Object", "java/lang/Comparable")
+	signature := func(s string) []byte { return indexBytes(class.Utf8(s)) }
+	class.Attribute("Signature", signature(classSig))
+	class.Field(0, "names", "Ljava/util/List;").Attribute("Signature", signature(fieldSig))
+	class.Field(0, "plain", "I")
+	class.Method(0, "take", "(Ljava/util/List;)V").Attribute("Signature", signature(methodSig)).
+		Code(0, 2).Op(classgen.RETURN)
+	class.Method(0, "none", "()V").Code(0, 1).Op(classgen.RETURN)
+	box := newTestLoader(t, class).LoadClass("sig/Box")
+
+	for _, test := range []struct{ name, got, want string }{
+		{"class", box.Signature(), classSig},
+		{"field names", box.getField("names", "Ljava/util/List;", false).Signature(), fieldSig},
+		{"field plain", box.getField("plain", "I", false).Signature(), ""},
+		{"method take", box.GetInstanceMethod("take", "(Ljava/util/List;)V").Signature(), methodSig},
+		{"method none", box.GetInstanceMethod("none", "()V").Signature(), ""},
+		//没有Signature属性的类
+		{"java/lang/Object", box.SuperClass().Signature(), ""},
+	} {
+		if test.got != test.want {
+			t.Errorf("%s signature = %q, want %q", test.name, test.got, test.want)
+		}
+	}
+}
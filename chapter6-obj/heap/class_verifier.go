@@ -0,0 +1,136 @@
+package heap
+
+import (
+	"GoVM/chapter3-cf/classfile"
+	"fmt"
+)
+
+/**
+	-Xverify:all 时在链接之前对class做的检查，不通过就抛出 java.lang.VerifyError
+	1.抽象方法和本地方法不能有字节码，其他方法必须有字节码
+	2.局部变量表要能放下所有参数
+	3.异常表的范围和处理器都要落在字节码之内，并且在指令的开始处
+	4.StackMapTable要能正确解析，每一帧都要在指令的开始处，
+	  帧里声明的操作数栈要和字节码推导出来的一样（局部变量表不检查）
+ */
+func verifyClass(class *Class) {
+	for _, method := range class.methods {
+		if method.IsNative() {
+			//本地方法的字节码是注入的
+			continue
+		}
+		verifyMethodStructure(method)
+	}
+}
+
+func verifyMethodStructure(method *Method) {
+	codeLength := len(method.code)
+	if method.IsAbstract() {
+		if codeLength != 0 {
+			throwVerifyError(method, "abstract method has code")
+		}
+		return
+	}
+	if codeLength == 0 {
+		throwVerifyError(method, "missing code")
+	}
+	if method.maxLocals < method.argSlotCount {
+		throwVerifyError(method, "arguments can't fit into locals")
+	}
+
+	for _, handler := range method.exceptionTable {
+		if handler.startPc >= handler.endPc || handler.endPc > codeLength || handler.handlerPc >= codeLength {
+			throwVerifyError(method, "illegal exception table range")
+		}
+	}
+
+	verifier := newStackVerifier(method)
+	starts, err := verifier.instructionStarts()
+	if err != nil {
+		throwVerifyError(method, err.Error())
+	}
+	for _, handler := range method.exceptionTable {
+		for _, pc := range []int{handler.startPc, handler.endPc, handler.handlerPc} {
+			if pc < codeLength && !starts[pc] {
+				throwVerifyError(method, fmt.Sprintf("exception handler at %d is not at an instruction start", pc))
+			}
+		}
+	}
+
+	if method.stackMapTable != nil {
+		verifyStackMap(method, verifier, starts)
+	}
+}
+
+/**
+	用字节码推导出来的操作数栈检查StackMapTable的每一帧，到达不了的帧只检查位置
+ */
+func verifyStackMap(method *Method, verifier *stackVerifier, starts []bool) {
+	frames, err := method.stackMapTable.Frames()
+	if err != nil {
+		throwVerifyError(method, err.Error())
+	}
+	if err := verifier.verify(); err != nil {
+		throwVerifyError(method, err.Error())
+	}
+
+	for _, frame := range frames {
+		offset := frame.Offset()
+		if offset >= len(method.code) {
+			throwVerifyError(method, fmt.Sprintf("stack map frame at %d is out of code", offset))
+		}
+		if !starts[offset] {
+			throwVerifyError(method, fmt.Sprintf("stack map frame at %d is not at an instruction start", offset))
+		}
+		declared := stackMapSlotTypes(method, frame.Stack(), starts)
+		if verifier.reached[offset] && declared != verifier.stacks[offset] {
+			throwVerifyError(method, fmt.Sprintf("stack map frame at %d declares stack [%s], bytecode has [%s]",
+				offset, declared, verifier.stacks[offset]))
+		}
+	}
+}
+
+/**
+	把StackMapTable的verification_type_info换成stackVerifier的slot类型
+ */
+func stackMapSlotTypes(method *Method, types []chapter3_cf.VerificationType, starts []bool) string {
+	slots := ""
+	for _, t := range types {
+		switch t.Tag() {
+		case chapter3_cf.ITEM_Top:
+			slots += "T"
+		case chapter3_cf.ITEM_Integer:
+			slots += "I"
+		case chapter3_cf.ITEM_Float:
+			slots += "F"
+		case chapter3_cf.ITEM_Double:
+			slots += "DT"
+		case chapter3_cf.ITEM_Long:
+			slots += "JT"
+		case chapter3_cf.ITEM_Object:
+			index := int(t.Index())
+			if index >= len(method.class.constantPool.consts) {
+				throwVerifyError(method, fmt.Sprintf("bad stack map class #%d", index))
+			}
+			if _, ok := method.class.constantPool.consts[index].(*ClassRef); !ok {
+				throwVerifyError(method, fmt.Sprintf("bad stack map class #%d", index))
+			}
+			slots += "L"
+		case chapter3_cf.ITEM_Uninitialized:
+			//offset是创建这个对象的new指令
+			offset := int(t.Index())
+			if offset >= len(method.code) || !starts[offset] || method.code[offset] != 0xbb {
+				throwVerifyError(method, fmt.Sprintf("bad stack map uninitialized offset %d", offset))
+			}
+			slots += "L"
+		default: // Null UninitializedThis
+			slots += "L"
+		}
+	}
+	return slots
+}
+
+func throwVerifyError(method *Method, msg string) {
+	panic(fmt.Sprintf("java.lang.VerifyError: (class: %s, method: %s signature: %s) %s",
+		method.class.name, method.name, method.descriptor, msg))
+}
@@ -0,0 +1,149 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"GoVM/internal/testjdk"
+	"strings"
+	"testing"
+)
+
+/**
+	static int pick(int x) { if (x != 0) return 2; return 1; }，分支目标pc 6 要有一个栈帧
+ */
+func stackMapClass(name string, stackMapTable []byte) *classgen.Class {
+	class := classgen.New(name, "java/lang/Object")
+	class.Method(classgen.ACC_STATIC, "pick", "(I)I").Code(1, 1).
+		Op(classgen.ILOAD_0).Branch(classgen.IFNE, "two").
+		Op(classgen.ICONST_1).Op(classgen.IRETURN).
+		Label("two").Op(classgen.ICONST_2).Op(classgen.IRETURN).
+		Attribute("StackMapTable", stackMapTable)
+	return class
+}
+
+/**
+	static int choose(int x) { return x == 0 ? 2 : 1; }，pc 8 是空栈，pc 9 的栈上有一个int
+ */
+func ternaryClass(name string, stackMapTable []byte) *classgen.Class {
+	class := classgen.New(name, "java/lang/Object")
+	class.Method(classgen.ACC_STATIC, "choose", "(I)I").Code(1, 1).
+		Op(classgen.ILOAD_0).Branch(classgen.IFEQ, "two").
+		Op(classgen.ICONST_1).Branch(classgen.GOTO, "done").
+		Label("two").Op(classgen.ICONST_2).
+		Label("done").Op(classgen.IRETURN).
+		Attribute("StackMapTable", stackMapTable)
+	return class
+}
+
+func newVerifyingLoader(t *testing.T, verify bool, classes ...*classgen.Class) *ClassLoader {
+	return NewClassLoader(testBootLoader(t), testjdk.Classpath(t, classes...), false, verify)
+}
+
+func loadPanic(loader *ClassLoader, name string) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	loader.LoadClass(name)
+	return nil
+}
+
+func TestVerifyAllRejectsCorruptedStackMap(t *testing.T) {
+	corrupted := []struct {
+		name, want    string
+		stackMapTable []byte
+	}{
+		//same_frame，offset_delta 63 超出了字节码
+		{"verify/OutOfCode", "stack map frame at 63 is out of code", []byte{0, 1, 63}},
+		//说有两帧，只有一帧
+		{"verify/Truncated", "malformed StackMapTable", []byte{0, 2, 6}},
+		//128-246 是保留的frame_type
+		{"verify/Reserved", "malformed StackMapTable", []byte{0, 1, 200}},
+		{"verify/Trailing", "malformed StackMapTable", []byte{0, 1, 6, 0}},
+		//pc 2 在ifne的操作数中间
+		{"verify/MidInstruction", "stack map frame at 2 is not at an instruction start", []byte{0, 1, 2}},
+		//same_locals_1_stack_item 说pc 6 栈上有一个int，实际是空的
+		{"verify/ExtraItem", "stack map frame at 6 declares stack [I], bytecode has []", []byte{0, 1, 70, 1}},
+	}
+	var classes []*classgen.Class
+	for _, test := range corrupted {
+		classes = append(classes, stackMapClass(test.name, test.stackMapTable))
+	}
+	classes = append(classes, stackMapClass("verify/Valid", []byte{0, 1, 6}))
+
+	verifying := newVerifyingLoader(t, true, classes...)
+	for _, test := range corrupted {
+		r := loadPanic(verifying, test.name)
+		msg, _ := r.(string)
+		if !strings.HasPrefix(msg, "java.lang.VerifyError: (class: " + test.name + ", method: pick signature: (I)I)") ||
+			!strings.Contains(msg, test.want) {
+			t.Errorf("%s: got %v, want a VerifyError containing %q", test.name, r, test.want)
+		}
+	}
+	if r := loadPanic(verifying, "verify/Valid"); r != nil {
+		t.Errorf("valid stack map rejected: %v", r)
+	}
+
+	//默认（-Xverify:none）不检查
+	lenient := newVerifyingLoader(t, false, classes...)
+	for _, test := range corrupted {
+		if r := loadPanic(lenient, test.name); r != nil {
+			t.Errorf("%s rejected without -Xverify:all: %v", test.name, r)
+		}
+	}
+}
+
+func TestVerifyAllTypeChecksStackMap(t *testing.T) {
+	corrupted := []struct {
+		name, want    string
+		stackMapTable []byte
+	}{
+		{"verify/FloatItem", "stack map frame at 9 declares stack [F], bytecode has [I]", []byte{0, 2, 8, 64, 2}},
+		{"verify/LongItem", "stack map frame at 9 declares stack [JT], bytecode has [I]", []byte{0, 2, 8, 64, 4}},
+		//本该是same_locals_1_stack_item，写成了same_frame
+		{"verify/MissingItem", "stack map frame at 9 declares stack [], bytecode has [I]", []byte{0, 2, 8, 0}},
+	}
+	classes := []*classgen.Class{ternaryClass("verify/Ternary", []byte{0, 2, 8, 64, 1})}
+	for _, test := range corrupted {
+		classes = append(classes, ternaryClass(test.name, test.stackMapTable))
+	}
+
+	loader := newVerifyingLoader(t, true, classes...)
+	if r := loadPanic(loader, "verify/Ternary"); r != nil {
+		t.Errorf("valid stack map rejected: %v", r)
+	}
+	for _, test := range corrupted {
+		msg, _ := loadPanic(loader, test.name).(string)
+		if !strings.HasPrefix(msg, "java.lang.VerifyError") || !strings.HasSuffix(msg, test.want) {
+			t.Errorf("%s: got %q, want a VerifyError ending in %q", test.name, msg, test.want)
+		}
+	}
+}
+
+func TestVerifyAllChecksMethodStructure(t *testing.T) {
+	noCode := classgen.New("verify/NoCode", "java/lang/Object")
+	noCode.Method(classgen.ACC_STATIC, "f", "()V")
+	fewLocals := classgen.New("verify/FewLocals", "java/lang/Object")
+	fewLocals.Method(classgen.ACC_STATIC, "f", "(JI)V").Code(0, 2).Op(classgen.RETURN)
+	badHandler := classgen.New("verify/BadHandler", "java/lang/Object")
+	badHandler.Method(classgen.ACC_STATIC, "f", "()V").Code(1, 0).
+		Label("start").Op(classgen.RETURN).
+		Catch("start", "start", "start", "")
+	//处理器从sipush的操作数开始
+	midHandler := classgen.New("verify/MidHandler", "java/lang/Object")
+	midHandler.Method(classgen.ACC_STATIC, "f", "()V").Code(1, 0).
+		Label("start").Op(classgen.SIPUSH).Label("mid").Op(0, 5).Op(classgen.POP).
+		Label("end").Op(classgen.RETURN).
+		Catch("start", "end", "mid", "")
+
+	loader := newVerifyingLoader(t, true, noCode, fewLocals, badHandler, midHandler)
+	for _, test := range []struct{ name, want string }{
+		{"verify/NoCode", "missing code"},
+		{"verify/FewLocals", "arguments can't fit into locals"},
+		{"verify/BadHandler", "illegal exception table range"},
+		{"verify/MidHandler", "exception handler at 1 is not at an instruction start"},
+	} {
+		msg, _ := loadPanic(loader, test.name).(string)
+		if !strings.HasPrefix(msg, "java.lang.VerifyError") || !strings.HasSuffix(msg, test.want) {
+			t.Errorf("%s: got %q, want a VerifyError ending in %q", test.name, msg, test.want)
+		}
+	}
+}
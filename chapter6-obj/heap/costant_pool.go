@@ -17,6 +17,8 @@ type ConstantPool struct {
 
 /**
 	把classFile中的常量池转化成运行时常量池
+	classFile的常量池在解析时已经完整读出来了，类名、名字和描述符都是按索引去classFile常量池里取的，
+	符号引用本身要到第一次使用时才解析，所以这里不依赖常量的顺序
  */
 func newConstantPool(class *Class, cfCp chapter3_cf.ConstantPool) *ConstantPool {
 	cpCount := len(cfCp)
@@ -40,6 +42,7 @@ func newConstantPool(class *Class, cfCp chapter3_cf.ConstantPool) *ConstantPool
 		case *chapter3_cf.ConstantDoubleInfo:
 			doubleInfo := cpInfo.(*chapter3_cf.ConstantDoubleInfo)
 			consts[i] = doubleInfo.Value()
+			i++
 		case *chapter3_cf.ConstantStringInfo:
 			stringInfo := cpInfo.(*chapter3_cf.ConstantStringInfo)
 			consts[i] = stringInfo.String()
@@ -55,6 +58,15 @@ func newConstantPool(class *Class, cfCp chapter3_cf.ConstantPool) *ConstantPool
 		case *chapter3_cf.ConstantInterfaceMethodrefInfo:
 			methodrefInfo := cpInfo.(*chapter3_cf.ConstantInterfaceMethodrefInfo)
 			consts[i] = newInterfaceMethodRef(rtCp, methodrefInfo)
+		case *chapter3_cf.ConstantMethodHandleInfo:
+			methodHandleInfo := cpInfo.(*chapter3_cf.ConstantMethodHandleInfo)
+			consts[i] = newMethodHandleRef(rtCp, methodHandleInfo)
+		case *chapter3_cf.ConstantMethodTypeInfo:
+			methodTypeInfo := cpInfo.(*chapter3_cf.ConstantMethodTypeInfo)
+			consts[i] = newMethodTypeRef(rtCp, methodTypeInfo)
+		case *chapter3_cf.ConstantInvokeDynamicInfo:
+			invokeDynamicInfo := cpInfo.(*chapter3_cf.ConstantInvokeDynamicInfo)
+			consts[i] = newInvokeDynamicRef(rtCp, invokeDynamicInfo)
 		default:
 		// todo
 		}
@@ -70,4 +82,87 @@ func (self *ConstantPool) GetConstant(index uint) Constant {
 		return c
 	}
 	panic(fmt.Sprintf("No constants at index %d", index))
-}
\ No newline at end of file
+}
+/**
+	提前解析常量池里所有的类、字段、方法、接口方法、方法句柄和方法类型符号引用，给想要稳定延迟的嵌入方使用
+	解析失败时虚拟机是直接panic的，这里recover之后返回遇到的第一个错误，已经解析的引用保留
+ */
+func (self *ConstantPool) ResolveAll() (err error) {
+	for i, c := range self.consts {
+		if err = resolveConstant(c); err != nil {
+			return fmt.Errorf("constant #%d in %s: %v", i, self.class.name, err)
+		}
+	}
+	return nil
+}
+
+func resolveConstant(c Constant) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	switch ref := c.(type) {
+	case *FieldRef:
+		ref.ResolvedField()
+	case *MethodRef:
+		ref.ResolvedMethod()
+	case *InterfaceMethodRef:
+		ref.ResolvedInterfaceMethod()
+	case *ClassRef:
+		ref.ResolvedClass()
+	case *MethodHandleRef:
+		if ref.IsFieldHandle() {
+			ref.ResolvedField()
+		} else {
+			ref.ResolvedMethod()
+		}
+	case *MethodTypeRef:
+		ref.ReturnType()
+	}
+	return nil
+}
+
+/**
+	按类型读取常量，类型不对时panic，信息里带上常量实际的类型
+	字符串常量返回的是go字符串，需要java字符串时再用JString转换
+ */
+func (self *ConstantPool) GetIntConstant(index uint) int32 {
+	if val, ok := self.GetConstant(index).(int32); ok {
+		return val
+	}
+	panic(self.constantTypeError(index, "Integer"))
+}
+
+func (self *ConstantPool) GetLongConstant(index uint) int64 {
+	if val, ok := self.GetConstant(index).(int64); ok {
+		return val
+	}
+	panic(self.constantTypeError(index, "Long"))
+}
+
+func (self *ConstantPool) GetFloatConstant(index uint) float32 {
+	if val, ok := self.GetConstant(index).(float32); ok {
+		return val
+	}
+	panic(self.constantTypeError(index, "Float"))
+}
+
+func (self *ConstantPool) GetDoubleConstant(index uint) float64 {
+	if val, ok := self.GetConstant(index).(float64); ok {
+		return val
+	}
+	panic(self.constantTypeError(index, "Double"))
+}
+
+func (self *ConstantPool) GetStringConstant(index uint) string {
+	if val, ok := self.GetConstant(index).(string); ok {
+		return val
+	}
+	panic(self.constantTypeError(index, "String"))
+}
+
+func (self *ConstantPool) constantTypeError(index uint, expected string) string {
+	return fmt.Sprintf("Constant #%d in %s is not %s: %T", index, self.class.name, expected, self.consts[index])
+}
@@ -0,0 +1,172 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"strings"
+	"testing"
+)
+
+/**
+	检查常量池里每个符号引用是否都已经解析
+ */
+func unresolvedRefs(cp *ConstantPool) (indexes []int) {
+	for i, c := range cp.consts {
+		resolved := true
+		switch ref := c.(type) {
+		case *ClassRef:
+			resolved = ref.class != nil
+		case *FieldRef:
+			resolved = ref.field != nil
+		case *MethodRef:
+			resolved = ref.method != nil
+		case *InterfaceMethodRef:
+			resolved = ref.method != nil
+		}
+		if !resolved {
+			indexes = append(indexes, i)
+		}
+	}
+	return
+}
+
+func TestResolveAllResolvesCleanPool(t *testing.T) {
+	class := classgen.New("cp/Clean", "java/lang/Object")
+	class.ClassInfo("java/lang/String")
+	class.FieldRef("java/lang/System", "out", "Ljava/io/PrintStream;")
+	class.MethodRef("java/lang/Object", "hashCode", "()I")
+	class.InterfaceMethodRef("java/lang/Runnable", "toString", "()Ljava/lang/String;")
+	cp := newTestLoader(t, class).LoadClass("cp/Clean").ConstantPool()
+
+	if unresolved := unresolvedRefs(cp); len(unresolved) == 0 {
+		t.Fatal("refs were resolved before ResolveAll")
+	}
+	if err := cp.ResolveAll(); err != nil {
+		t.Fatalf("ResolveAll() = %v", err)
+	}
+	if unresolved := unresolvedRefs(cp); len(unresolved) != 0 {
+		t.Errorf("constants %v are still unresolved", unresolved)
+	}
+}
+
+func TestResolveAllReturnsDanglingRef(t *testing.T) {
+	class := classgen.New("cp/Dangling", "java/lang/Object")
+	hashCode := class.MethodRef("java/lang/Object", "hashCode", "()I")
+	missing := class.MethodRef("java/lang/Object", "noSuchMethod", "()V")
+	cp := newTestLoader(t, class).LoadClass("cp/Dangling").ConstantPool()
+
+	err := cp.ResolveAll()
+	if err == nil {
+		t.Fatal("ResolveAll() = nil with a dangling method ref")
+	}
+	for _, want := range []string{"cp/Dangling", "NoSuchMethodError"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("ResolveAll() = %q, want it to mention %s", err, want)
+		}
+	}
+	//出错之前解析好的引用保留
+	if cp.GetConstant(uint(hashCode)).(*MethodRef).method == nil {
+		t.Error("ref before the dangling one was not kept resolved")
+	}
+	if cp.GetConstant(uint(missing)).(*MethodRef).method != nil {
+		t.Error("dangling ref resolved to a method")
+	}
+}
+
+func indexBytes(indexes ...uint16) (info []byte) {
+	for _, index := range indexes {
+		info = append(info, byte(index >> 8), byte(index))
+	}
+	return
+}
+
+/**
+	Class、Methodref和NameAndType常量都引用下标在自己后面的常量
+ */
+func TestConstantPoolEntriesMayReferenceLaterEntries(t *testing.T) {
+	class := classgen.New("cp/Forward", "java/lang/Object")
+	classIndex := class.NextIndex()
+	class.RawConstant(classgen.CONSTANT_Class, indexBytes(classIndex + 2))
+	methodIndex := class.RawConstant(classgen.CONSTANT_Methodref, indexBytes(classIndex, classIndex + 3))
+	if name := class.Utf8("java/lang/String"); name != classIndex + 2 {
+		t.Fatalf("class name is at #%d, want #%d", name, classIndex + 2)
+	}
+	class.RawConstant(classgen.CONSTANT_NameAndType, indexBytes(classIndex + 4, classIndex + 5))
+	if name, descriptor := class.Utf8("hashCode"), class.Utf8("()I"); name != classIndex + 4 || descriptor != classIndex + 5 {
+		t.Fatalf("name and descriptor are at #%d #%d, want #%d #%d", name, descriptor, classIndex + 4, classIndex + 5)
+	}
+	cp := newTestLoader(t, class).LoadClass("cp/Forward").ConstantPool()
+
+	if got := cp.GetConstant(uint(classIndex)).(*ClassRef).ResolvedClass().Name(); got != "java/lang/String" {
+		t.Errorf("class #%d resolved to %s", classIndex, got)
+	}
+	ref := cp.GetConstant(uint(methodIndex)).(*MethodRef)
+	if method := ref.ResolvedMethod(); ref.ResolvedClass().Name() != "java/lang/String" || method.Name() != "hashCode" {
+		t.Errorf("method #%d resolved to %s.%s", methodIndex, ref.ResolvedClass().Name(), method.Name())
+	}
+}
+
+func TestDoubleAndLongTakeTwoEntries(t *testing.T) {
+	class := classgen.New("cp/Wide", "java/lang/Object")
+	double := class.DoubleInfo(1.5)
+	afterDouble := class.IntInfo(7)
+	long := class.LongInfo(-2)
+	afterLong := class.IntInfo(8)
+	cp := newTestLoader(t, class).LoadClass("cp/Wide").ConstantPool()
+
+	if got := cp.GetDoubleConstant(uint(double)); got != 1.5 {
+		t.Errorf("double #%d = %v, want 1.5", double, got)
+	}
+	if got := cp.GetLongConstant(uint(long)); got != -2 {
+		t.Errorf("long #%d = %v, want -2", long, got)
+	}
+	for _, test := range []struct {
+		index uint16
+		want  int32
+	}{{afterDouble, 7}, {afterLong, 8}} {
+		if got := cp.GetIntConstant(uint(test.index)); got != test.want {
+			t.Errorf("int #%d = %d, want %d", test.index, got, test.want)
+		}
+	}
+	//第二个位置不能用
+	for _, index := range []uint16{double + 1, long + 1} {
+		if c := cp.consts[index]; c != nil {
+			t.Errorf("second slot #%d holds %v", index, c)
+		}
+	}
+}
+
+func TestTypedConstantReaders(t *testing.T) {
+	class := classgen.New("cp/Typed", "java/lang/Object")
+	i, j, f, d := class.IntInfo(-3), class.LongInfo(1 << 33), class.FloatInfo(0.5), class.DoubleInfo(-2.25)
+	s := class.StringInfo("typed")
+	class.Field(classgen.ACC_STATIC|classgen.ACC_FINAL, "S", "Ljava/lang/String;").
+		Attribute("ConstantValue", indexBytes(s))
+	loaded := newTestLoader(t, class).LoadClass("cp/Typed")
+	cp := loaded.ConstantPool()
+
+	if cp.GetIntConstant(uint(i)) != -3 || cp.GetLongConstant(uint(j)) != 1 << 33 ||
+		cp.GetFloatConstant(uint(f)) != 0.5 || cp.GetDoubleConstant(uint(d)) != -2.25 {
+		t.Error("typed readers returned the wrong values")
+	}
+	//不运行代码也能读出编译期常量
+	field := loaded.GetStaticField("S", "Ljava/lang/String;")
+	if got := cp.GetStringConstant(field.ConstValueIndex()); got != "typed" {
+		t.Errorf("ConstantValue of S = %q, want \"typed\"", got)
+	}
+
+	for _, test := range []struct {
+		read func()
+		want string
+	}{
+		{func() { cp.GetIntConstant(uint(j)) }, "is not Integer: int64"},
+		{func() { cp.GetLongConstant(uint(d)) }, "is not Long: float64"},
+		{func() { cp.GetFloatConstant(uint(i)) }, "is not Float: int32"},
+		{func() { cp.GetDoubleConstant(uint(s)) }, "is not Double: string"},
+		{func() { cp.GetStringConstant(uint(f)) }, "is not String: float32"},
+	} {
+		msg, _ := catchPanic(test.read).(string)
+		if !strings.HasPrefix(msg, "Constant #") || !strings.Contains(msg, "cp/Typed " + test.want) {
+			t.Errorf("panic = %q, want it to end with %q", msg, "cp/Typed " + test.want)
+		}
+	}
+}
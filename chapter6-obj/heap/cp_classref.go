@@ -9,6 +9,6 @@ type ClassRef struct {
 func newClassRef(cp *ConstantPool, classInfo *chapter3_cf.ConstantClassInfo) *ClassRef {
 	ref := &ClassRef{}
 	ref.cp = cp
-	ref.className = classInfo.Name()
+	ref.className = internName(classInfo.Name())
 	return ref
 }
\ No newline at end of file
@@ -35,10 +35,12 @@ func (self *FieldRef) resolveFieldRef() {
 	field := lookupField(c, self.name, self.descriptor)
 
 	if field == nil {
-		panic("java.lang.NoSuchFieldError")
+		panic("java.lang.NoSuchFieldError: " + self.name)
 	}
 	if !field.isAccessibleTo(d) {
-		panic("java.lang.IllegalAccessError")
+		//和HotSpot（jdk8）的信息一样
+		panic("java.lang.IllegalAccessError: tried to access field " + field.class.JavaName() + "." + self.name +
+			" from class " + d.JavaName())
 	}
 
 	self.field = field
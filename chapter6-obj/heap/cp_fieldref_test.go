@@ -0,0 +1,59 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"testing"
+)
+
+/**
+	interface I { int K = 1; }
+	class Base implements I { int base; private int secret; }
+	class Sub extends Base {}
+	fr/User的常量池里是对这些字段的引用，都通过Sub或者Base引用
+ */
+func TestFieldRefResolution(t *testing.T) {
+	i := classgen.NewInterface("fr/I")
+	i.Field(classgen.ACC_PUBLIC|classgen.ACC_STATIC|classgen.ACC_FINAL, "K", "I")
+	base := classgen.New("fr/Base", "java/lang/Object", "fr/I")
+	base.Field(0, "base", "I")
+	base.Field(classgen.ACC_PRIVATE, "secret", "I")
+	user := classgen.New("fr/User", "java/lang/Object")
+	refs := map[string]uint16{
+		"inherited": user.FieldRef("fr/Sub", "base", "I"),
+		"constant":  user.FieldRef("fr/Sub", "K", "I"),
+		"private":   user.FieldRef("fr/Base", "secret", "I"),
+		"missing":   user.FieldRef("fr/Sub", "missing", "I"),
+		"wrongType": user.FieldRef("fr/Sub", "base", "J"),
+	}
+	loader := newTestLoader(t, i, base, classgen.New("fr/Sub", "fr/Base"), user)
+	cp := loader.LoadClass("fr/User").ConstantPool()
+	fieldRef := func(name string) *FieldRef {
+		return cp.GetConstant(uint(refs[name])).(*FieldRef)
+	}
+
+	for _, test := range []struct{ ref, owner string }{
+		{"inherited", "fr/Base"},
+		{"constant", "fr/I"},
+	} {
+		ref := fieldRef(test.ref)
+		field := ref.ResolvedField()
+		if field == nil || field.Class().Name() != test.owner {
+			t.Errorf("%s resolved to %v, want a field of %s", test.ref, field, test.owner)
+			continue
+		}
+		if ref.field != field || ref.ResolvedField() != field {
+			t.Errorf("%s: resolved field is not cached", test.ref)
+		}
+	}
+
+	for _, test := range []struct{ ref, want string }{
+		{"private", "java.lang.IllegalAccessError: tried to access field fr.Base.secret from class fr.User"},
+		{"missing", "java.lang.NoSuchFieldError: missing"},
+		//名字对上了，描述符不对也是找不到
+		{"wrongType", "java.lang.NoSuchFieldError: base"},
+	} {
+		if r := catchPanic(func() { fieldRef(test.ref).ResolvedField() }); r != test.want {
+			t.Errorf("%s: panic = %v, want %q", test.ref, r, test.want)
+		}
+	}
+}
@@ -39,11 +39,34 @@ func (self *InterfaceMethodRef) resolveInterfaceMethodRef() {
 	self.method = method
 }
 
+/**
+	接口里找不到时，还要去java.lang.Object里找它的public实例方法（jvms 5.4.3.4）
+	这样通过接口类型的引用调用 toString() hashCode() equals() 才能解析成功，
+	真正执行哪个方法由invokeinterface在对象的实际类里查找
+ */
 func lookupInterfaceMethod(iface *Class, name, descriptor string) *Method {
 	for _, method := range iface.methods {
 		if method.name == name && method.descriptor == descriptor {
 			return method
 		}
 	}
-	return lookupMethodInInterface(iface.interfaces, name, descriptor)
+	if method := lookupMethodInInterface(iface.interfaces, name, descriptor); method != nil {
+		return method
+	}
+	return lookupObjectMethod(iface, name, descriptor)
+}
+
+func lookupObjectMethod(iface *Class, name, descriptor string) *Method {
+	//接口的超类总是java.lang.Object
+	object := iface.superClass
+	if object == nil {
+		return nil
+	}
+	for _, method := range object.methods {
+		if method.name == name && method.descriptor == descriptor &&
+			method.IsPublic() && !method.IsStatic() {
+			return method
+		}
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,68 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"testing"
+)
+
+func catchPanic(resolve func()) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	resolve()
+	return nil
+}
+
+/**
+	interface J { void j(); }  interface I extends J {}  class K {}
+	cp/User的常量池里有 I.j 和 K.k 两个接口方法引用
+ */
+func TestInterfaceMethodRefResolvesThroughSuperinterface(t *testing.T) {
+	j := classgen.NewInterface("cp/J")
+	j.Method(classgen.ACC_PUBLIC|classgen.ACC_ABSTRACT, "j", "()V")
+	i := classgen.NewInterface("cp/I", "cp/J")
+	k := classgen.New("cp/K", "java/lang/Object")
+	k.Method(classgen.ACC_PUBLIC, "k", "()V").Code(0, 1).Op(classgen.RETURN)
+	user := classgen.New("cp/User", "java/lang/Object")
+	iRef := user.InterfaceMethodRef("cp/I", "j", "()V")
+	kRef := user.InterfaceMethodRef("cp/K", "k", "()V")
+	cp := newTestLoader(t, j, i, k, user).LoadClass("cp/User").ConstantPool()
+
+	ref := cp.GetConstant(uint(iRef)).(*InterfaceMethodRef)
+	method := ref.ResolvedInterfaceMethod()
+	if method == nil || method.Class().Name() != "cp/J" || method.Name() != "j" {
+		t.Fatalf("I.j resolved to %v", method)
+	}
+	//解析结果缓存在引用里，之后不再查找
+	if ref.method != method || ref.ResolvedInterfaceMethod() != method {
+		t.Error("resolved method is not cached")
+	}
+
+	r := catchPanic(func() { cp.GetConstant(uint(kRef)).(*InterfaceMethodRef).ResolvedInterfaceMethod() })
+	if r != "java.lang.IncompatibleClassChangeError" {
+		t.Errorf("interface method ref to a class: panic = %v, want IncompatibleClassChangeError", r)
+	}
+}
+
+/**
+	缓存在调用方的运行时常量池里，两个加载器各自加载的调用方解析到各自加载的接口
+ */
+func TestInterfaceMethodRefCacheIsPerLoader(t *testing.T) {
+	var methods []*Method
+	for n := 0; n < 2; n++ {
+		j := classgen.NewInterface("cp/J")
+		j.Method(classgen.ACC_PUBLIC|classgen.ACC_ABSTRACT, "j", "()V")
+		user := classgen.New("cp/User", "java/lang/Object")
+		index := user.InterfaceMethodRef("cp/J", "j", "()V")
+		loader := newTestLoader(t, j, user)
+		ref := loader.LoadClass("cp/User").ConstantPool().GetConstant(uint(index)).(*InterfaceMethodRef)
+		method := ref.ResolvedInterfaceMethod()
+		if method.Class().Loader() != loader {
+			t.Errorf("loader %d: J.j resolved through another loader", n)
+		}
+		methods = append(methods, method)
+	}
+	if methods[0] == methods[1] {
+		t.Error("two loaders share one resolved method")
+	}
+}
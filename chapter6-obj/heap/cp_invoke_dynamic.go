@@ -0,0 +1,353 @@
+package heap
+
+import (
+	"GoVM/chapter3-cf/classfile"
+	"fmt"
+)
+
+/**
+	方法句柄的种类，jvms 5.4.3.5
+ */
+const (
+	REF_getField         = 1
+	REF_getStatic        = 2
+	REF_putField         = 3
+	REF_putStatic        = 4
+	REF_invokeVirtual    = 5
+	REF_invokeStatic     = 6
+	REF_invokeSpecial    = 7
+	REF_newInvokeSpecial = 8
+	REF_invokeInterface  = 9
+)
+
+var referenceKindNames = [...]string{
+	REF_getField:         "REF_getField",
+	REF_getStatic:        "REF_getStatic",
+	REF_putField:         "REF_putField",
+	REF_putStatic:        "REF_putStatic",
+	REF_invokeVirtual:    "REF_invokeVirtual",
+	REF_invokeStatic:     "REF_invokeStatic",
+	REF_invokeSpecial:    "REF_invokeSpecial",
+	REF_newInvokeSpecial: "REF_newInvokeSpecial",
+	REF_invokeInterface:  "REF_invokeInterface",
+}
+
+/**
+	方法句柄符号引用，reference_index指向同一个常量池里的字段或者方法符号引用
+	种类1到4是字段句柄，5到9是方法句柄，解析之后的字段或方法缓存起来
+ */
+type MethodHandleRef struct {
+	cp             *ConstantPool
+	referenceKind  uint8
+	referenceIndex uint
+	field          *Field
+	method         *Method
+}
+
+func newMethodHandleRef(cp *ConstantPool, info *chapter3_cf.ConstantMethodHandleInfo) *MethodHandleRef {
+	return &MethodHandleRef{
+		cp:             cp,
+		referenceKind:  info.ReferenceKind(),
+		referenceIndex: uint(info.ReferenceIndex()),
+	}
+}
+
+func (self *MethodHandleRef) ReferenceKind() uint8 {
+	return self.referenceKind
+}
+
+/**
+	种类的名字，比如 REF_invokeStatic，种类不合法时panic
+ */
+func (self *MethodHandleRef) ReferenceKindName() string {
+	self.checkKind()
+	return referenceKindNames[self.referenceKind]
+}
+
+func (self *MethodHandleRef) IsFieldHandle() bool {
+	return self.referenceKind >= REF_getField && self.referenceKind <= REF_putStatic
+}
+
+func (self *MethodHandleRef) IsMethodHandle() bool {
+	return self.referenceKind >= REF_invokeVirtual && self.referenceKind <= REF_invokeInterface
+}
+
+func (self *MethodHandleRef) kindName() string {
+	if self.IsFieldHandle() || self.IsMethodHandle() {
+		return referenceKindNames[self.referenceKind]
+	}
+	return fmt.Sprintf("kind %d", self.referenceKind)
+}
+
+func (self *MethodHandleRef) checkKind() {
+	if !self.IsFieldHandle() && !self.IsMethodHandle() {
+		panic(fmt.Sprintf("java.lang.ClassFormatError: bad method handle kind %d in %s",
+			self.referenceKind, self.cp.class.name))
+	}
+}
+
+/**
+	句柄指向的字段或方法的符号引用，不触发解析
+ */
+func (self *MethodHandleRef) MemberRef() *MemberRef {
+	switch ref := self.cp.GetConstant(self.referenceIndex).(type) {
+	case *FieldRef:
+		return &ref.MemberRef
+	case *MethodRef:
+		return &ref.MemberRef
+	case *InterfaceMethodRef:
+		return &ref.MemberRef
+	}
+	panic(self.badReference("a field or method"))
+}
+
+/**
+	解析字段句柄（REF_getField ~ REF_putStatic）指向的字段
+	get/putStatic 要求是静态字段，get/putField 要求是实例字段，否则抛出IncompatibleClassChangeError
+ */
+func (self *MethodHandleRef) ResolvedField() *Field {
+	if self.field == nil {
+		self.resolveField()
+	}
+	return self.field
+}
+
+func (self *MethodHandleRef) resolveField() {
+	self.checkKind()
+	if !self.IsFieldHandle() {
+		panic(fmt.Sprintf("java.lang.IncompatibleClassChangeError: %s method handle in %s is not a field handle",
+			self.kindName(), self.cp.class.name))
+	}
+	ref, ok := self.cp.GetConstant(self.referenceIndex).(*FieldRef)
+	if !ok {
+		panic(self.badReference("a field"))
+	}
+
+	field := ref.ResolvedField()
+	wantStatic := self.referenceKind == REF_getStatic || self.referenceKind == REF_putStatic
+	if field.IsStatic() != wantStatic {
+		panic(self.staticMismatch(field.class.JavaName() + "." + field.name, wantStatic))
+	}
+	self.field = field
+}
+
+/**
+	解析方法句柄（REF_invokeVirtual ~ REF_invokeInterface）指向的方法，jvms 4.4.8：
+		REF_invokeVirtual、REF_newInvokeSpecial 指向普通方法
+		REF_invokeStatic、REF_invokeSpecial 可以指向普通方法，也可以指向接口方法
+		REF_invokeInterface 指向接口方法
+		只有 REF_newInvokeSpecial 指向 <init>，任何种类都不能指向 <clinit>
+ */
+func (self *MethodHandleRef) ResolvedMethod() *Method {
+	if self.method == nil {
+		self.resolveMethod()
+	}
+	return self.method
+}
+
+func (self *MethodHandleRef) resolveMethod() {
+	self.checkKind()
+	if !self.IsMethodHandle() {
+		panic(fmt.Sprintf("java.lang.IncompatibleClassChangeError: %s method handle in %s is not a method handle",
+			self.kindName(), self.cp.class.name))
+	}
+
+	var method *Method
+	switch ref := self.cp.GetConstant(self.referenceIndex).(type) {
+	case *MethodRef:
+		if self.referenceKind == REF_invokeInterface {
+			panic(self.badReference("an interface method"))
+		}
+		method = ref.ResolvedMethod()
+	case *InterfaceMethodRef:
+		if self.referenceKind != REF_invokeStatic && self.referenceKind != REF_invokeSpecial &&
+			self.referenceKind != REF_invokeInterface {
+			panic(self.badReference("a method"))
+		}
+		method = ref.ResolvedInterfaceMethod()
+	default:
+		panic(self.badReference("a method"))
+	}
+
+	if (method.name == "<init>") != (self.referenceKind == REF_newInvokeSpecial) || method.name == "<clinit>" {
+		panic(fmt.Sprintf("java.lang.ClassFormatError: %s method handle in %s cannot reference %s",
+			self.kindName(), self.cp.class.name, method.name))
+	}
+	wantStatic := self.referenceKind == REF_invokeStatic
+	if method.IsStatic() != wantStatic {
+		panic(self.staticMismatch(method.class.JavaName() + "." + method.name + method.descriptor, wantStatic))
+	}
+	self.method = method
+}
+
+func (self *MethodHandleRef) badReference(expected string) string {
+	return fmt.Sprintf("java.lang.ClassFormatError: %s method handle in %s must reference %s, #%d is %T",
+		self.kindName(), self.cp.class.name, expected,
+		self.referenceIndex, self.cp.GetConstant(self.referenceIndex))
+}
+
+func (self *MethodHandleRef) staticMismatch(member string, wantStatic bool) string {
+	expected := "non-static"
+	if wantStatic {
+		expected = "static"
+	}
+	return fmt.Sprintf("java.lang.IncompatibleClassChangeError: %s method handle expects %s member, %s is not",
+		self.kindName(), expected, member)
+}
+
+/**
+	方法类型符号引用，就是一个方法描述符
+	参数类型和返回值类型第一次用到时才用当前类的加载器加载，之后缓存起来
+ */
+type MethodTypeRef struct {
+	cp             *ConstantPool
+	descriptor     string
+	parameterTypes []*Class
+	returnType     *Class
+}
+
+func newMethodTypeRef(cp *ConstantPool, info *chapter3_cf.ConstantMethodTypeInfo) *MethodTypeRef {
+	return &MethodTypeRef{cp: cp, descriptor: internName(info.Descriptor())}
+}
+
+func (self *MethodTypeRef) Descriptor() string {
+	return self.descriptor
+}
+
+func (self *MethodTypeRef) ParameterTypes() []*Class {
+	if self.returnType == nil {
+		self.resolveMethodType()
+	}
+	return self.parameterTypes
+}
+
+func (self *MethodTypeRef) ReturnType() *Class {
+	if self.returnType == nil {
+		self.resolveMethodType()
+	}
+	return self.returnType
+}
+
+func (self *MethodTypeRef) resolveMethodType() {
+	loader := self.cp.class.loader
+	parsed := parseMethodDescriptor(self.descriptor)
+	parameterTypes := make([]*Class, len(parsed.parameterTypes))
+	for i, paramType := range parsed.parameterTypes {
+		parameterTypes[i] = loader.LoadClass(toClassName(paramType))
+	}
+	self.parameterTypes = parameterTypes
+	self.returnType = loader.LoadClass(toClassName(parsed.returnType))
+}
+
+/**
+	类的BootstrapMethods属性里的一项，下标都是这个类的常量池下标
+ */
+type BootstrapMethod struct {
+	methodHandleIndex uint
+	argumentIndexes   []uint
+}
+
+func newBootstrapMethods(cf *chapter3_cf.ClassFile) []*BootstrapMethod {
+	bmAttr := cf.BootstrapMethodsAttribute()
+	if bmAttr == nil {
+		return nil
+	}
+	cfMethods := bmAttr.BootstrapMethods()
+	methods := make([]*BootstrapMethod, len(cfMethods))
+	for i, cfMethod := range cfMethods {
+		args := cfMethod.BootstrapArguments()
+		methods[i] = &BootstrapMethod{
+			methodHandleIndex: uint(cfMethod.BootstrapMethodRef()),
+			argumentIndexes:   make([]uint, len(args)),
+		}
+		for j, arg := range args {
+			methods[i].argumentIndexes[j] = uint(arg)
+		}
+	}
+	return methods
+}
+
+func (self *BootstrapMethod) MethodHandleIndex() uint {
+	return self.methodHandleIndex
+}
+
+func (self *BootstrapMethod) ArgumentIndexes() []uint {
+	return self.argumentIndexes
+}
+
+/**
+	invokedynamic的符号引用
+	jvms规定每一条invokedynamic指令都是一个单独的调用点，几条指令可以共用同一个常量，
+	所以解析出来的调用点按 (方法, pc) 缓存，不是直接缓存在常量上
+ */
+type InvokeDynamicRef struct {
+	cp                   *ConstantPool
+	bootstrapMethodIndex uint
+	name                 string
+	descriptor           string
+	callSites            map[callSiteKey]*CallSite
+}
+
+type callSiteKey struct {
+	method *Method
+	pc     int
+}
+
+func newInvokeDynamicRef(cp *ConstantPool, info *chapter3_cf.ConstantInvokeDynamicInfo) *InvokeDynamicRef {
+	name, descriptor := info.NameAndDescriptor()
+	return &InvokeDynamicRef{
+		cp:                   cp,
+		bootstrapMethodIndex: uint(info.BootstrapMethodAttrIndex()),
+		name:                 internName(name),
+		descriptor:           internName(descriptor),
+		callSites:            make(map[callSiteKey]*CallSite),
+	}
+}
+
+func (self *InvokeDynamicRef) Name() string {
+	return self.name
+}
+
+func (self *InvokeDynamicRef) Descriptor() string {
+	return self.descriptor
+}
+
+func (self *InvokeDynamicRef) ResolvedCallSite(method *Method, pc int) *CallSite {
+	key := callSiteKey{method, pc}
+	if callSite, ok := self.callSites[key]; ok {
+		return callSite
+	}
+	callSite := self.resolveCallSite()
+	self.callSites[key] = callSite
+	return callSite
+}
+
+/**
+	找到引导方法和它的静态参数，再"调用"引导方法得到调用点
+	引导方法目前没有真的去执行，认识的引导方法由虚拟机自己实现，其他的抛BootstrapMethodError
+ */
+func (self *InvokeDynamicRef) resolveCallSite() *CallSite {
+	class := self.cp.class
+	bootstrapMethods := class.BootstrapMethods()
+	if self.bootstrapMethodIndex >= uint(len(bootstrapMethods)) {
+		panic(fmt.Sprintf("java.lang.BootstrapMethodError: bad bootstrap method index %d in %s",
+			self.bootstrapMethodIndex, class.name))
+	}
+	bootstrapMethod := bootstrapMethods[self.bootstrapMethodIndex]
+	methodHandle := self.cp.GetConstant(bootstrapMethod.methodHandleIndex).(*MethodHandleRef)
+	args := make([]Constant, len(bootstrapMethod.argumentIndexes))
+	for i, index := range bootstrapMethod.argumentIndexes {
+		args[i] = self.cp.GetConstant(index)
+	}
+
+	ref := methodHandle.MemberRef()
+	if methodHandle.referenceKind == REF_invokeStatic && ref.className == "java/lang/invoke/LambdaMetafactory" {
+		switch ref.name {
+		//altMetafactory多出来的参数（Serializable标记、额外的接口和桥接方法）先忽略
+		case "metafactory", "altMetafactory":
+			return lambdaMetafactory(self, args)
+		}
+	}
+	panic(fmt.Sprintf("java.lang.BootstrapMethodError: unsupported bootstrap method %s.%s%s",
+		ref.className, ref.name, ref.descriptor))
+}
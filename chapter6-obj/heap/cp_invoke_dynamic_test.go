@@ -0,0 +1,208 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"fmt"
+	"testing"
+)
+
+const metafactoryDescriptor = "(Ljava/lang/invoke/MethodHandles$Lookup;Ljava/lang/String;Ljava/lang/invoke/MethodType;" +
+	"Ljava/lang/invoke/MethodType;Ljava/lang/invoke/MethodHandle;Ljava/lang/invoke/MethodType;)Ljava/lang/invoke/CallSite;"
+
+/**
+	interface IntOp { int apply(int x); }
+	class Lambdas {
+		static IntOp adder(int n) { return x -> x + n; }
+		private static int lambda$add(int n, int x) { return x + n; }
+	}
+	另外两个常量：引导方法不是LambdaMetafactory（Lambdas.bsm），以及引导方法下标越界
+ */
+func TestInvokeDynamicRefResolvesCallSites(t *testing.T) {
+	op := classgen.NewInterface("indy/IntOp")
+	op.Method(classgen.ACC_PUBLIC|classgen.ACC_ABSTRACT, "apply", "(I)I")
+	class := classgen.New("indy/Lambdas", "java/lang/Object")
+	class.Method(classgen.ACC_PRIVATE|classgen.ACC_STATIC|classgen.ACC_SYNTHETIC, "lambda$add", "(II)I").Code(2, 2).
+		Op(classgen.ILOAD_1).Op(classgen.ILOAD_0).Op(classgen.IADD).Op(classgen.IRETURN)
+	metafactory := class.MethodHandle(REF_invokeStatic,
+		class.MethodRef("java/lang/invoke/LambdaMetafactory", "metafactory", metafactoryDescriptor))
+	samType := class.MethodType("(I)I")
+	implHandle := class.MethodHandle(REF_invokeStatic, class.MethodRef("indy/Lambdas", "lambda$add", "(II)I"))
+	lambda := class.InvokeDynamic(class.BootstrapMethod(metafactory, samType, implHandle, samType), "apply", "(I)Lindy/IntOp;")
+	custom := class.InvokeDynamic(class.BootstrapMethod(class.MethodHandle(REF_invokeStatic,
+		class.MethodRef("indy/Lambdas", "bsm", metafactoryDescriptor))), "apply", "(I)Lindy/IntOp;")
+	badIndex := class.InvokeDynamic(7, "apply", "(I)Lindy/IntOp;")
+	class.Method(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "adder", "(I)Lindy/IntOp;").Code(1, 1).
+		Op(classgen.ILOAD_0).Invokedynamic(lambda).Op(classgen.ARETURN)
+	lambdas := newTestLoader(t, op, class).LoadClass("indy/Lambdas")
+	cp := lambdas.ConstantPool()
+
+	bootstrapMethods := lambdas.BootstrapMethods()
+	if len(bootstrapMethods) != 2 {
+		t.Fatalf("%d bootstrap methods, want 2", len(bootstrapMethods))
+	}
+	if bsm := bootstrapMethods[0]; bsm.MethodHandleIndex() != uint(metafactory) || len(bsm.ArgumentIndexes()) != 3 ||
+		bsm.ArgumentIndexes()[1] != uint(implHandle) {
+		t.Errorf("bootstrap method 0 = #%d %v", bsm.MethodHandleIndex(), bsm.ArgumentIndexes())
+	}
+
+	ref := cp.GetConstant(uint(lambda)).(*InvokeDynamicRef)
+	if ref.Name() != "apply" || ref.Descriptor() != "(I)Lindy/IntOp;" {
+		t.Errorf("invokedynamic constant is %s%s", ref.Name(), ref.Descriptor())
+	}
+	adder := lambdas.GetStaticMethod("adder", "(I)Lindy/IntOp;")
+	callSite := ref.ResolvedCallSite(adder, 1)
+	if callSite.LambdaClass() == nil || !callSite.LambdaClass().IsImplements(lambdas.Loader().LoadClass("indy/IntOp")) {
+		t.Fatalf("lambda class %v does not implement indy/IntOp", callSite.LambdaClass())
+	}
+	if callSite.CapturedSlotCount() != 1 {
+		t.Errorf("captured %d slots, want 1", callSite.CapturedSlotCount())
+	}
+	//同一个 (方法, pc) 是同一个调用点，另一个pc上的指令是另一个调用点
+	if ref.ResolvedCallSite(adder, 1) != callSite {
+		t.Error("call site is not cached per instruction")
+	}
+	if other := ref.ResolvedCallSite(adder, 9); other == callSite || other.LambdaClass() == callSite.LambdaClass() {
+		t.Error("two instructions share one call site")
+	}
+
+	captured := NewSlots(1)
+	captured.SetInt(0, 5)
+	if obj := callSite.NewInstance(captured); obj.Class() != callSite.LambdaClass() || obj.Fields().GetInt(0) != 5 {
+		t.Error("NewInstance did not store the captured variable")
+	}
+
+	for _, test := range []struct {
+		index uint16
+		want  string
+	}{
+		{custom, "java.lang.BootstrapMethodError: unsupported bootstrap method indy/Lambdas.bsm" + metafactoryDescriptor},
+		{badIndex, "java.lang.BootstrapMethodError: bad bootstrap method index 7 in indy/Lambdas"},
+	} {
+		r := catchPanic(func() { cp.GetConstant(uint(test.index)).(*InvokeDynamicRef).ResolvedCallSite(adder, 4) })
+		if r != test.want {
+			t.Errorf("panic = %v, want %q", r, test.want)
+		}
+	}
+}
+
+/**
+	class Target { static int sfield; int ifield; static void sm() {} void im() {} Target() {} }
+	interface Iface { default void d() {} }
+	mh/User的常量池里是指向这些成员的各种方法句柄
+ */
+func TestMethodHandleRefResolution(t *testing.T) {
+	target := classgen.New("mh/Target", "java/lang/Object").DefaultConstructor()
+	target.Field(classgen.ACC_STATIC, "sfield", "I")
+	target.Field(0, "ifield", "I")
+	target.Method(classgen.ACC_STATIC, "sm", "()V").Code(0, 0).Op(classgen.RETURN)
+	target.Method(0, "im", "()V").Code(0, 1).Op(classgen.RETURN)
+	iface := classgen.NewInterface("mh/Iface")
+	iface.Method(classgen.ACC_PUBLIC, "d", "()V").Code(0, 1).Op(classgen.RETURN)
+	user := classgen.New("mh/User", "java/lang/Object")
+	sfield, ifield := user.FieldRef("mh/Target", "sfield", "I"), user.FieldRef("mh/Target", "ifield", "I")
+	sm, im := user.MethodRef("mh/Target", "sm", "()V"), user.MethodRef("mh/Target", "im", "()V")
+	init, d := user.MethodRef("mh/Target", "<init>", "()V"), user.InterfaceMethodRef("mh/Iface", "d", "()V")
+	handle := func(kind byte, index uint16) uint16 { return user.MethodHandle(kind, index) }
+	good := []struct {
+		handle uint16
+		kind   string
+		member string
+	}{
+		{handle(REF_getStatic, sfield), "REF_getStatic", "sfield"},
+		{handle(REF_putField, ifield), "REF_putField", "ifield"},
+		{handle(REF_invokeStatic, sm), "REF_invokeStatic", "sm"},
+		{handle(REF_invokeVirtual, im), "REF_invokeVirtual", "im"},
+		{handle(REF_newInvokeSpecial, init), "REF_newInvokeSpecial", "<init>"},
+		{handle(REF_invokeInterface, d), "REF_invokeInterface", "d"},
+		{handle(REF_invokeSpecial, d), "REF_invokeSpecial", "d"},
+	}
+	bad := []struct {
+		handle  uint16
+		resolve func(ref *MethodHandleRef)
+		want    string
+	}{
+		{handle(10, sm), func(ref *MethodHandleRef) { ref.ReferenceKindName() },
+			"java.lang.ClassFormatError: bad method handle kind 10 in mh/User"},
+		{handle(0, sm), func(ref *MethodHandleRef) { ref.ResolvedMethod() },
+			"java.lang.ClassFormatError: bad method handle kind 0 in mh/User"},
+		{handle(REF_getStatic, ifield), func(ref *MethodHandleRef) { ref.ResolvedField() },
+			"java.lang.IncompatibleClassChangeError: REF_getStatic method handle expects static member, mh.Target.ifield is not"},
+		{handle(REF_invokeVirtual, sm), func(ref *MethodHandleRef) { ref.ResolvedMethod() },
+			"java.lang.IncompatibleClassChangeError: REF_invokeVirtual method handle expects non-static member, mh.Target.sm()V is not"},
+		{handle(REF_invokeVirtual, init), func(ref *MethodHandleRef) { ref.ResolvedMethod() },
+			"java.lang.ClassFormatError: REF_invokeVirtual method handle in mh/User cannot reference <init>"},
+		{handle(REF_invokeInterface, sm), func(ref *MethodHandleRef) { ref.ResolvedMethod() },
+			"java.lang.ClassFormatError: REF_invokeInterface method handle in mh/User must reference an interface method, " +
+				"#" + fmt.Sprint(sm) + " is *heap.MethodRef"},
+		{handle(REF_getField, im), func(ref *MethodHandleRef) { ref.ResolvedField() },
+			"java.lang.ClassFormatError: REF_getField method handle in mh/User must reference a field, " +
+				"#" + fmt.Sprint(im) + " is *heap.MethodRef"},
+		{handle(REF_getStatic, sfield), func(ref *MethodHandleRef) { ref.ResolvedMethod() },
+			"java.lang.IncompatibleClassChangeError: REF_getStatic method handle in mh/User is not a method handle"},
+		{handle(REF_invokeStatic, sm), func(ref *MethodHandleRef) { ref.ResolvedField() },
+			"java.lang.IncompatibleClassChangeError: REF_invokeStatic method handle in mh/User is not a field handle"},
+	}
+	cp := newTestLoader(t, target, iface, user).LoadClass("mh/User").ConstantPool()
+
+	for _, test := range good {
+		ref := cp.GetConstant(uint(test.handle)).(*MethodHandleRef)
+		if ref.ReferenceKindName() != test.kind {
+			t.Errorf("#%d kind = %s, want %s", test.handle, ref.ReferenceKindName(), test.kind)
+		}
+		var member *ClassMember
+		if ref.IsFieldHandle() {
+			member = &ref.ResolvedField().ClassMember
+			if ref.field == nil || ref.ResolvedField() != ref.field {
+				t.Errorf("%s: resolved field is not cached", test.kind)
+			}
+		} else {
+			member = &ref.ResolvedMethod().ClassMember
+			if ref.method == nil || ref.ResolvedMethod() != ref.method {
+				t.Errorf("%s: resolved method is not cached", test.kind)
+			}
+		}
+		if member.Name() != test.member {
+			t.Errorf("%s handle resolved to %s, want %s", test.kind, member.Name(), test.member)
+		}
+	}
+	for _, test := range bad {
+		ref := cp.GetConstant(uint(test.handle)).(*MethodHandleRef)
+		if r := catchPanic(func() { test.resolve(ref) }); r != test.want {
+			t.Errorf("#%d: panic = %v,\nwant %q", test.handle, r, test.want)
+		}
+	}
+}
+
+func TestMethodTypeRefResolvesThroughTheLoader(t *testing.T) {
+	user := classgen.New("mt/User", "java/lang/Object")
+	index := user.MethodType("(I[Ljava/lang/String;Lmt/User;)J")
+	void := user.MethodType("()V")
+	class := newTestLoader(t, user).LoadClass("mt/User")
+	loader := class.Loader()
+
+	ref := class.ConstantPool().GetConstant(uint(index)).(*MethodTypeRef)
+	if ref.Descriptor() != "(I[Ljava/lang/String;Lmt/User;)J" {
+		t.Errorf("descriptor = %s", ref.Descriptor())
+	}
+	params := ref.ParameterTypes()
+	want := []*Class{loader.LoadClass("int"), loader.LoadClass("[Ljava/lang/String;"), class}
+	if len(params) != len(want) {
+		t.Fatalf("%d parameter types, want %d", len(params), len(want))
+	}
+	for i := range want {
+		if params[i] != want[i] {
+			t.Errorf("parameter %d = %s, want %s", i, params[i].Name(), want[i].Name())
+		}
+	}
+	if ref.ReturnType() != loader.LoadClass("long") {
+		t.Errorf("return type = %s, want long", ref.ReturnType().Name())
+	}
+	if &ref.ParameterTypes()[0] != &params[0] {
+		t.Error("parameter types are resolved again")
+	}
+
+	voidRef := class.ConstantPool().GetConstant(uint(void)).(*MethodTypeRef)
+	if len(voidRef.ParameterTypes()) != 0 || voidRef.ReturnType() != loader.LoadClass("void") {
+		t.Errorf("()V resolved to %v -> %v", voidRef.ParameterTypes(), voidRef.ReturnType())
+	}
+}
@@ -12,8 +12,9 @@ type MemberRef struct {
 }
 
 func (self *MemberRef) copyMemberRefInfo(refInfo *chapter3_cf.ConstantMemberrefInfo) {
-	self.className = refInfo.ClassName()
-	self.name, self.descriptor = refInfo.NameAndDescriptor()
+	self.className = internName(refInfo.ClassName())
+	name, descriptor := refInfo.NameAndDescriptor()
+	self.name, self.descriptor = internName(name), internName(descriptor)
 }
 
 func (self *MemberRef) Name() string {
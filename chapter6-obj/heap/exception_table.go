@@ -50,7 +50,8 @@ func (self ExceptionTable) findExceptionHandler(exClass *Class, pc int) *Excepti
 				return handler
 			}
 			catchClass := handler.catchType.ResolvedClass()
-			if catchClass == exClass || catchClass.IsSuperClassOf(exClass) {
+			//catch的类型是异常类本身或者它的超类都能匹配
+			if catchClass.IsAssignableFrom(exClass) {
 				return handler
 			}
 		}
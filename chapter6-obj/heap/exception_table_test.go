@@ -0,0 +1,48 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"testing"
+)
+
+/**
+	pc 0~4 都是nop，异常表：
+		[0, 2) RuntimeException -> 3
+		[0, 3) 任何异常         -> 4
+		[2, 4) Error            -> 3
+ */
+func TestFindExceptionHandler(t *testing.T) {
+	class := classgen.New("ex/Table", "java/lang/Object")
+	class.Method(classgen.ACC_STATIC, "run", "()V").Code(1, 0).
+		Label("start").Op(classgen.NOP).Op(classgen.NOP).
+		Label("mid").Op(classgen.NOP).
+		Label("handler").Op(classgen.NOP).
+		Label("any").Op(classgen.RETURN).
+		Catch("start", "mid", "handler", "java/lang/RuntimeException").
+		Catch("start", "handler", "any", "").
+		Catch("mid", "any", "handler", "java/lang/Error")
+	loader := newTestLoader(t, class)
+	method := loader.LoadClass("ex/Table").GetStaticMethod("run", "()V")
+
+	for _, test := range []struct {
+		exception string
+		pc        int
+		want      int
+	}{
+		//子类也能被捕获
+		{"java/lang/ArithmeticException", 1, 3},
+		{"java/lang/RuntimeException", 0, 3},
+		//endPc不包括在范围内
+		{"java/lang/ArithmeticException", 2, 4},
+		//catch_type为0的处理器捕获所有异常
+		{"java/lang/Error", 0, 4},
+		{"java/lang/Exception", 1, 4},
+		{"java/lang/Error", 3, 3},
+		{"java/lang/Exception", 3, -1},
+		{"java/lang/Error", 4, -1},
+	} {
+		if got := method.FindExceptionHandler(loader.LoadClass(test.exception), test.pc); got != test.want {
+			t.Errorf("%s at pc %d: handler = %d, want %d", test.exception, test.pc, got, test.want)
+		}
+	}
+}
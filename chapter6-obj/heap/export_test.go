@@ -0,0 +1,4 @@
+package heap
+
+//给 heap_test 包里通过解释器运行的测试用
+var NewTestHeap = newTestHeap
@@ -0,0 +1,281 @@
+package heap
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+/**
+	标记-清除垃圾回收。
+	对象的内存最终还是由Go的GC释放，这里做的是java堆的记账：堆记录所有分配过的对象，
+	从根出发标记能到达的对象，没被标记的从记录里删掉，Go那边没有引用之后就会回收，
+	已用的字节数也相应减少，这样 -Xmx 和 Runtime.freeMemory 才符合实际。
+	给了 -Xmn 时还有一个新生代，新对象先放在那里，由minor GC回收，见gc_young.go；这里的GC是整个堆的full GC
+	即使漏掉了某个根，也只是记账不准，不会出现悬空的引用（弱引用的referent会被错误地清空，见processReferences）
+ */
+
+//默认每分配这么多个对象请求一次GC
+const defaultGCThreshold = 100000
+
+type GCStats struct {
+	//full GC的次数
+	Collections      uint
+	MinorCollections uint
+	ObjectsCollected uint64
+	//按对象布局估算的大小，见instanceSize和arraySize
+	BytesFreed       uint64
+	//GC花掉的时间，解释器在GC期间是停下来的
+	PauseTime        time.Duration
+}
+
+//所有的类加载器，它们加载的类的静态变量和类对象都是根
+var classLoaders []*ClassLoader
+
+/**
+	对象创建之后登记到堆里，分配的对象数达到阈值时请求GC
+	解释器在指令之间（这时所有活着的对象都能从栈帧和静态变量找到）检查GCRequested再真正执行GC
+ */
+func (self *Heap) track(obj *Object) {
+	if self.youngSize > 0 {
+		self.allocateYoung(obj)
+		return
+	}
+	self.tenure(obj)
+}
+
+/**
+	放进老年代。没有新生代时所有对象都直接放在这里，有新生代时这是晋升
+ */
+func (self *Heap) tenure(obj *Object) {
+	obj.old = true
+	self.objects = append(self.objects, obj)
+	self.allocCount++
+	if self.gcThreshold > 0 && self.allocCount >= self.gcThreshold {
+		self.gcRequested = true
+	}
+}
+
+/**
+	阈值为0时不自动GC
+ */
+func (self *Heap) SetGCThreshold(threshold uint) {
+	self.gcThreshold = threshold
+}
+
+func (self *Heap) GCRequested() bool {
+	return self.gcRequested
+}
+
+/**
+	System.gc() 和 Runtime.gc() 用，解释器执行下一条指令之前进行GC
+ */
+func (self *Heap) RequestGC() {
+	self.gcRequested = true
+	self.gcCause = "System.gc()"
+}
+
+/**
+	-XX:+PrintGC，每次GC之后往w输出一行日志，w为nil时不输出（默认）
+ */
+func (self *Heap) SetGCLog(w io.Writer) {
+	self.gcLog = w
+}
+
+func (self *Heap) GCStats() GCStats {
+	return self.stats
+}
+
+/**
+	roots 是解释器给出的根（操作数栈、局部变量表里的引用等），
+	类的静态变量、类对象和字符串池由堆自己加进来
+ */
+func (self *Heap) GC(roots []*Object) GCStats {
+	start := time.Now()
+	objectsBefore, usedBefore := len(self.objects) + len(self.young), self.used
+	marker := &gcMarker{}
+	for _, root := range roots {
+		marker.mark(root)
+	}
+	markClassRoots(marker)
+	for _, jStr := range internedStrings {
+		marker.mark(jStr)
+	}
+	marker.drain()
+	marker.processReferences()
+
+	collected, freed := self.sweep()
+	//新生代里活下来的对象全部晋升，新生代清空之后记忆集也就没用了
+	youngCollected, youngFreed := self.sweepYoung(true)
+	collected, freed = collected + youngCollected, freed + youngFreed
+	self.forgetRemembered()
+	self.minorRequested = false
+	pause := time.Since(start)
+	cause := self.gcCause
+	self.allocCount = 0
+	self.gcRequested = false
+	self.gcCause = ""
+	self.stats.Collections++
+	self.stats.ObjectsCollected += collected
+	self.stats.BytesFreed += freed
+	self.stats.PauseTime += pause
+
+	if self.gcLog != nil {
+		if cause == "" {
+			cause = "Allocation Threshold"
+		}
+		//和HotSpot的 -XX:+PrintGC 差不多，多了对象的个数
+		fmt.Fprintf(self.gcLog, "[GC #%d (%s) %d->%d objects, %d->%d bytes, %.7f secs]\n",
+			self.stats.Collections, cause, objectsBefore, len(self.objects), usedBefore, self.used, pause.Seconds())
+	}
+	return GCStats{Collections: 1, ObjectsCollected: collected, BytesFreed: freed, PauseTime: pause}
+}
+
+func markClassRoots(marker *gcMarker) {
+	for _, loader := range classLoaders {
+		for _, class := range loader.classMap {
+			marker.mark(class.jClass)
+			for _, slot := range class.staticVars {
+				marker.mark(slot.Ref)
+			}
+		}
+	}
+}
+
+/**
+	没被标记的对象从记录里删掉，被标记的清除标记，留给下一次GC
+ */
+func (self *Heap) sweep() (collected, freed uint64) {
+	live := self.objects[:0]
+	for _, obj := range self.objects {
+		if obj.gcMark {
+			obj.gcMark = false
+			live = append(live, obj)
+		} else {
+			collected++
+			freed += obj.size()
+		}
+	}
+	//把后面的位置清空，否则底层数组还引用着被回收的对象
+	for i := len(live); i < len(self.objects); i++ {
+		self.objects[i] = nil
+	}
+	self.objects = live
+	freed = self.release(freed)
+	return
+}
+
+func (self *Heap) release(freed uint64) uint64 {
+	if freed > self.used {
+		freed = self.used
+	}
+	self.used -= freed
+	return freed
+}
+
+/**
+	用一个工作队列代替递归，很长的链表也不会让Go的栈溢出
+ */
+type gcMarker struct {
+	//minor GC只标记新生代的对象，老年代的对象都当作活的，也不往下找
+	youngOnly bool
+	worklist []*Object
+	//标记过程中遇到的弱引用和虚引用，referent要等全部标记完才知道还能不能到达
+	references []*Object
+}
+
+func (self *gcMarker) mark(obj *Object) {
+	if obj != nil && !obj.gcMark && !(self.youngOnly && obj.old) {
+		obj.gcMark = true
+		self.worklist = append(self.worklist, obj)
+	}
+}
+
+/**
+	沿着引用类型的字段和引用数组的元素往下标记，基本类型的数组里没有引用。
+	extra只是Go这一侧的数据，不往下找
+ */
+func (self *gcMarker) drain() {
+	for len(self.worklist) > 0 {
+		last := len(self.worklist) - 1
+		obj := self.worklist[last]
+		self.worklist = self.worklist[:last]
+		self.scan(obj)
+	}
+}
+
+func (self *gcMarker) scan(obj *Object) {
+	switch data := obj.data.(type) {
+	case Slots:
+		referentSlot := -1
+		if refClass := weakReferenceClass(obj.class); refClass != nil {
+			referentSlot = int(refClass.referentSlotId())
+			self.references = append(self.references, obj)
+		}
+		for i, slot := range data {
+			if i != referentSlot {
+				self.mark(slot.Ref)
+			}
+		}
+	case []*Object:
+		for _, ref := range data {
+			self.mark(ref)
+		}
+	}
+}
+
+/**
+	java.lang.ref.Reference的referent字段不算强引用：
+	WeakReference和PhantomReference的referent只能通过这类引用到达时，清空referent，
+	然后像HotSpot一样用discovered字段把引用串到Reference.pending链表上，由Reference-handler线程放进ReferenceQueue。
+	SoftReference（以及FinalReference）目前当成强引用处理，内存不够时才应该清除它们
+ */
+func (self *gcMarker) processReferences() {
+	for _, ref := range self.references {
+		refClass := weakReferenceClass(ref.class)
+		slots := ref.data.(Slots)
+		referentSlot := refClass.referentSlotId()
+		referent := slots.GetRef(referentSlot)
+		if referent == nil || referent.gcMark || (self.youngOnly && referent.old) {
+			continue
+		}
+		slots.SetRef(referentSlot, nil)
+		enqueuePending(refClass, ref)
+	}
+}
+
+func enqueuePending(refClass *Class, ref *Object) {
+	pendingField := refClass.getField("pending", "Ljava/lang/ref/Reference;", true)
+	discoveredField := refClass.getField("discovered", "Ljava/lang/ref/Reference;", false)
+	if pendingField == nil || discoveredField == nil {
+		return
+	}
+	pending := refClass.staticVars.GetRef(pendingField.slotId)
+	ref.data.(Slots).SetRef(discoveredField.slotId, pending)
+	ref.WriteBarrier(pending)
+	refClass.staticVars.SetRef(pendingField.slotId, ref)
+	refClass.StaticWriteBarrier(ref)
+}
+
+/**
+	对象是WeakReference或者PhantomReference（包括它们的子类）时返回java.lang.ref.Reference类，否则返回nil
+ */
+func weakReferenceClass(class *Class) *Class {
+	weak := false
+	for c := class; c != nil; c = c.superClass {
+		switch c.name {
+		case "java/lang/ref/WeakReference", "java/lang/ref/PhantomReference":
+			weak = true
+		case "java/lang/ref/Reference":
+			if weak {
+				return c
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (self *Class) referentSlotId() uint {
+	return self.getField("referent", "Ljava/lang/Object;", false).slotId
+}
@@ -0,0 +1,221 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+/**
+	换上一个空的堆，测试结束后换回原来的。
+	新堆的GC也会标记旧堆里那些能从类和字符串池到达的对象，这些标记新堆不会清除，换回去时要清掉
+ */
+func newTestHeap(t testing.TB) *Heap {
+	old := jvmHeap
+	InitHeap(0, 0)
+	t.Cleanup(func() {
+		for _, obj := range old.objects {
+			obj.gcMark = false
+		}
+		for _, obj := range old.young {
+			obj.gcMark = false
+		}
+		jvmHeap = old
+	})
+	return jvmHeap
+}
+
+func nodeClass() *classgen.Class {
+	class := classgen.New("gc/Node", "java/lang/Object").DefaultConstructor()
+	class.Field(classgen.ACC_PUBLIC, "next", "Lgc/Node;")
+	class.Field(classgen.ACC_PUBLIC, "items", "[Ljava/lang/Object;")
+	class.Field(classgen.ACC_PUBLIC, "count", "I")
+	return class
+}
+
+func liveSet(heap *Heap) map[*Object]bool {
+	live := map[*Object]bool{}
+	for _, obj := range heap.objects {
+		live[obj] = true
+	}
+	return live
+}
+
+func TestGCMarksThroughFieldsAndArrays(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	objects := loader.LoadClass("[Ljava/lang/Object;")
+	ints := loader.LoadClass("[I")
+	heap := newTestHeap(t)
+
+	root := node.NewObject()
+	child := node.NewObject()
+	root.SetRefVar("next", "Lgc/Node;", child)
+	items := objects.NewArray(2)
+	child.SetRefVar("items", "[Ljava/lang/Object;", items)
+	elem := node.NewObject()
+	items.Refs()[1] = elem
+
+	//指向活对象的垃圾也是垃圾
+	garbage := node.NewObject()
+	garbage.SetRefVar("next", "Lgc/Node;", root)
+	garbageInts := ints.NewArray(4)
+	garbage.SetRefVar("items", "[Ljava/lang/Object;", garbageInts)
+
+	stats := heap.GC([]*Object{root})
+	if stats.ObjectsCollected != 2 {
+		t.Errorf("ObjectsCollected = %d, want 2", stats.ObjectsCollected)
+	}
+	live := liveSet(heap)
+	for _, obj := range []*Object{root, child, items, elem} {
+		if !live[obj] {
+			t.Errorf("reachable %s was swept", obj.class.name)
+		}
+		if obj.gcMark {
+			t.Errorf("mark of %s was not cleared", obj.class.name)
+		}
+	}
+	for _, obj := range []*Object{garbage, garbageInts} {
+		if live[obj] {
+			t.Errorf("unreachable %s survived", obj.class.name)
+		}
+	}
+}
+
+func holderClass() *classgen.Class {
+	class := classgen.New("gc/Holder", "java/lang/Object")
+	class.Field(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "node", "Lgc/Node;")
+	return class
+}
+
+func TestGCMarksStaticVars(t *testing.T) {
+	loader := newTestLoader(t, nodeClass(), holderClass())
+	node := loader.LoadClass("gc/Node")
+	holder := loader.LoadClass("gc/Holder")
+	heap := newTestHeap(t)
+
+	kept := node.NewObject()
+	holder.SetRefVar("node", "Lgc/Node;", kept)
+	node.NewObject()
+
+	heap.GC(nil)
+	if live := liveSet(heap); !live[kept] || len(live) != 1 {
+		t.Errorf("got %d live objects, want only the object in the static field", len(live))
+	}
+}
+
+func TestGCSweepAccounting(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := newTestHeap(t)
+
+	const n = 10
+	root := node.NewObject()
+	for i := 0; i < n; i++ {
+		node.NewObject()
+	}
+	usedBefore := heap.used
+	stats := heap.GC([]*Object{root})
+
+	if stats.ObjectsCollected != n {
+		t.Errorf("ObjectsCollected = %d, want %d", stats.ObjectsCollected, n)
+	}
+	if want := n * root.size(); stats.BytesFreed != want {
+		t.Errorf("BytesFreed = %d, want %d", stats.BytesFreed, want)
+	}
+	if heap.used != usedBefore - stats.BytesFreed || heap.used != root.size() {
+		t.Errorf("used = %d, want %d", heap.used, root.size())
+	}
+
+	//没有新的垃圾，累计的统计不变
+	again := heap.GC([]*Object{root})
+	if again.ObjectsCollected != 0 || again.BytesFreed != 0 {
+		t.Errorf("second GC collected %d objects, %d bytes", again.ObjectsCollected, again.BytesFreed)
+	}
+	total := heap.GCStats()
+	if total.Collections != 2 || total.ObjectsCollected != n || total.BytesFreed != stats.BytesFreed {
+		t.Errorf("GCStats() = %+v", total)
+	}
+}
+
+func TestGCThresholdRequestsCollection(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := newTestHeap(t)
+	heap.SetGCThreshold(3)
+
+	node.NewObject()
+	node.NewObject()
+	if heap.GCRequested() {
+		t.Fatal("GC requested before reaching the threshold")
+	}
+	node.NewObject()
+	if !heap.GCRequested() {
+		t.Fatal("GC not requested at the threshold")
+	}
+
+	//GC之后重新计数
+	heap.GC(nil)
+	if heap.GCRequested() {
+		t.Fatal("GC still requested after collecting")
+	}
+	node.NewObject()
+	node.NewObject()
+	if heap.GCRequested() {
+		t.Fatal("allocation count was not reset by GC")
+	}
+
+	heap.SetGCThreshold(0)
+	for i := 0; i < 10; i++ {
+		node.NewObject()
+	}
+	if heap.GCRequested() {
+		t.Error("GC requested with the threshold disabled")
+	}
+}
+
+var gcLogLine = regexp.MustCompile(`^\[GC #1 \(System\.gc\(\)\) (\d+)->(\d+) objects, (\d+)->(\d+) bytes, [0-9.]+ secs\]\n$`)
+
+func TestGCLogPrintsBeforeAndAfterCounts(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := newTestHeap(t)
+	var log bytes.Buffer
+	heap.SetGCLog(&log)
+
+	root := node.NewObject()
+	for i := 0; i < 3; i++ {
+		node.NewObject()
+	}
+	heap.RequestGC()
+	heap.GC([]*Object{root})
+
+	match := gcLogLine.FindStringSubmatch(log.String())
+	if match == nil {
+		t.Fatalf("unexpected GC log %q", log.String())
+	}
+	counts := make([]uint64, 4)
+	for i := range counts {
+		counts[i], _ = strconv.ParseUint(match[i + 1], 10, 64)
+		if counts[i] == 0 {
+			t.Errorf("count %d in %q is zero", i, log.String())
+		}
+	}
+	if counts[0] != 4 || counts[1] != 1 {
+		t.Errorf("objects %d->%d, want 4->1", counts[0], counts[1])
+	}
+	if counts[2] <= counts[3] {
+		t.Errorf("bytes %d->%d did not shrink", counts[2], counts[3])
+	}
+}
+
+func TestGCLogSilentByDefault(t *testing.T) {
+	heap := newTestHeap(t)
+	if heap.gcLog != nil {
+		t.Fatal("a new heap has a GC log")
+	}
+	//没有设置日志时GC也不能出错
+	heap.GC(nil)
+}
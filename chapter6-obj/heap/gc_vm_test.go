@@ -0,0 +1,211 @@
+package heap_test
+
+import (
+	"GoVM/chapter6-obj/heap"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	static void run() { for (int i = 0; i < 20; i++) new Object(); }
+ */
+func allocClass() *Class {
+	class := New("gc/Alloc", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "run", "()V").Code(2, 1).
+		Op(ICONST_0).Op(ISTORE_0).
+		Label("cond").Op(ILOAD_0).Iconst(20).Branch(IF_ICMPGE, "done").
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").Op(POP).
+		Op(IINC, 0, 1).Branch(GOTO, "cond").
+		Label("done").Op(RETURN)
+	return class
+}
+
+func TestInterpreterCollectsAtThreshold(t *testing.T) {
+	vm := jvmtest.New(t, allocClass())
+	vm.Class("gc/Alloc")
+	jvmHeap := heap.NewTestHeap(t)
+	jvmHeap.SetGCThreshold(5)
+
+	vm.Call("gc/Alloc", "run", "()V")
+	stats := jvmHeap.GCStats()
+	if stats.Collections < 3 {
+		t.Fatalf("Collections = %d after 20 allocations with threshold 5, want at least 3", stats.Collections)
+	}
+	if stats.ObjectsCollected < 10 {
+		t.Errorf("ObjectsCollected = %d, want at least 10", stats.ObjectsCollected)
+	}
+}
+
+const jlrWeakReference = "java/lang/ref/WeakReference"
+
+/**
+	static Object collected() { WeakReference r = new WeakReference(new Object()); System.gc(); return r.get(); }
+	static Object kept()      { Object o = new Object(); WeakReference r = new WeakReference(o); System.gc(); return r.get(); }
+ */
+func weakClass() *Class {
+	class := New("gc/Weak", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "collected", "()Ljava/lang/Object;").Code(4, 1).
+		New(jlrWeakReference).Op(DUP).
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").
+		Invokespecial(jlrWeakReference, "<init>", "(Ljava/lang/Object;)V").Op(ASTORE_0).
+		Invokestatic("java/lang/System", "gc", "()V").
+		Op(ALOAD_0).Invokevirtual(jlrWeakReference, "get", "()Ljava/lang/Object;").Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "kept", "()Ljava/lang/Object;").Code(3, 2).
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").Op(ASTORE_0).
+		New(jlrWeakReference).Op(DUP).Op(ALOAD_0).
+		Invokespecial(jlrWeakReference, "<init>", "(Ljava/lang/Object;)V").Op(ASTORE_1).
+		Invokestatic("java/lang/System", "gc", "()V").
+		Op(ALOAD_1).Invokevirtual(jlrWeakReference, "get", "()Ljava/lang/Object;").Op(ARETURN)
+	return class
+}
+
+func TestWeakReferenceClearedWhenReferentUnreachable(t *testing.T) {
+	vm := jvmtest.New(t, weakClass())
+	vm.Class("gc/Weak")
+	reference := vm.Class("java/lang/ref/Reference")
+	jvmHeap := heap.NewTestHeap(t)
+	reference.SetRefVar("pending", "Ljava/lang/ref/Reference;", nil)
+	t.Cleanup(func() {
+		reference.SetRefVar("pending", "Ljava/lang/ref/Reference;", nil)
+	})
+
+	if got := vm.Call("gc/Weak", "collected", "()Ljava/lang/Object;").Ref(); got != nil {
+		t.Errorf("get() = %v after GC, want null", got)
+	}
+	if jvmHeap.GCStats().Collections == 0 {
+		t.Fatal("System.gc() did not collect")
+	}
+	//被清空的引用放到了pending链表上
+	pending := reference.GetRefVar("pending", "Ljava/lang/ref/Reference;")
+	if pending == nil || pending.Class().Name() != jlrWeakReference {
+		t.Errorf("Reference.pending = %v, want the cleared WeakReference", pending)
+	}
+}
+
+func TestWeakReferenceKeepsStronglyReachableReferent(t *testing.T) {
+	vm := jvmtest.New(t, weakClass())
+	vm.Class("gc/Weak")
+	jvmHeap := heap.NewTestHeap(t)
+
+	got := vm.Call("gc/Weak", "kept", "()Ljava/lang/Object;").Ref()
+	if jvmHeap.GCStats().Collections == 0 {
+		t.Fatal("System.gc() did not collect")
+	}
+	if got == nil || got.Class().Name() != "java/lang/Object" {
+		t.Errorf("get() = %v, want the strongly reachable referent", got)
+	}
+}
+
+/**
+	static Object[] keep = new Object[64];
+	static Churn last;
+	static Object fresh;
+	Object next;
+
+	static void churn(int n) {
+		for (int i = 0; i < n; i++) {
+			Object o = new Object();
+			if ((i & 1023) == 0) keep[(i >> 10) & 63] = o;
+		}
+	}
+	static void init()  { last = new Churn(); }
+	static void store() { keep[0] = new Object(); last.next = new Object(); fresh = new Object(); new Object(); }
+ */
+func churnClass() *Class {
+	class := New("gc/Churn", "java/lang/Object").DefaultConstructor()
+	class.Field(ACC_STATIC, "keep", "[Ljava/lang/Object;")
+	class.Field(ACC_STATIC, "last", "Lgc/Churn;")
+	class.Field(ACC_STATIC, "fresh", "Ljava/lang/Object;")
+	class.Field(0, "next", "Ljava/lang/Object;")
+	class.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		Iconst(64).Anewarray("java/lang/Object").Putstatic("gc/Churn", "keep", "[Ljava/lang/Object;").
+		Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "churn", "(I)V").Code(3, 3).
+		Op(ICONST_0).Op(ISTORE_1).
+		Label("cond").Op(ILOAD_1).Op(ILOAD_0).Branch(IF_ICMPGE, "done").
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").Op(ASTORE_2).
+		Op(ILOAD_1).Iconst(1023).Op(IAND).Branch(IFNE, "next").
+		Getstatic("gc/Churn", "keep", "[Ljava/lang/Object;").
+		Op(ILOAD_1).Iconst(10).Op(ISHR).Iconst(63).Op(IAND).Op(ALOAD_2).Op(AASTORE).
+		Label("next").Op(IINC, 1, 1).Branch(GOTO, "cond").
+		Label("done").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "init", "()V").Code(2, 0).
+		New("gc/Churn").Op(DUP).Invokespecial("gc/Churn", "<init>", "()V").
+		Putstatic("gc/Churn", "last", "Lgc/Churn;").
+		Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "store", "()V").Code(4, 0).
+		Getstatic("gc/Churn", "keep", "[Ljava/lang/Object;").Op(ICONST_0).
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").Op(AASTORE).
+		Getstatic("gc/Churn", "last", "Lgc/Churn;").
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").
+		Putfield("gc/Churn", "next", "Ljava/lang/Object;").
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").
+		Putstatic("gc/Churn", "fresh", "Ljava/lang/Object;").
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").Op(POP).
+		Op(RETURN)
+	return class
+}
+
+func TestWriteBarriersKeepYoungObjectsStoredIntoOldOnes(t *testing.T) {
+	vm := jvmtest.New(t, churnClass())
+	jvmHeap := heap.NewTestHeap(t)
+	jvmHeap.SetYoungSize(1 << 20)
+	vm.Call("gc/Churn", "init", "()V")
+	//keep和last晋升到老年代
+	jvmHeap.GC(nil)
+
+	vm.Call("gc/Churn", "store", "()V")
+	//aastore、putfield、putstatic存下来的三个对象都只能从记忆集找到
+	if stats := jvmHeap.MinorGC(nil); stats.ObjectsCollected != 1 {
+		t.Errorf("ObjectsCollected = %d, want only the dropped object", stats.ObjectsCollected)
+	}
+}
+
+/**
+	分配n个几乎都马上变成垃圾的对象，返回这期间的GC统计
+ */
+func churn(tb testing.TB, vm *jvmtest.VM, youngSize uint64, n int32) heap.GCStats {
+	jvmHeap := heap.NewTestHeap(tb)
+	jvmHeap.SetGCThreshold(1000)
+	jvmHeap.SetYoungSize(youngSize)
+	vm.Call("gc/Churn", "churn", "(I)V", n)
+	return jvmHeap.GCStats()
+}
+
+func TestYoungGenerationReducesFullGCs(t *testing.T) {
+	vm := jvmtest.New(t, churnClass())
+	markSweep := churn(t, vm, 0, 100000)
+	generational := churn(t, vm, 16 << 10, 100000)
+
+	if markSweep.Collections < 100 {
+		t.Fatalf("mark-sweep ran %d full GCs, want at least 100", markSweep.Collections)
+	}
+	if generational.MinorCollections == 0 || generational.Collections * 10 > markSweep.Collections {
+		t.Errorf("generational ran %d full and %d minor GCs, mark-sweep %d full GCs",
+			generational.Collections, generational.MinorCollections, markSweep.Collections)
+	}
+	if generational.ObjectsCollected < 100000 - 64 - 1024 {
+		t.Errorf("generational collected %d objects", generational.ObjectsCollected)
+	}
+}
+
+func BenchmarkAllocateShortLived(b *testing.B) {
+	vm := jvmtest.New(b, churnClass())
+	for _, bench := range []struct {
+		name      string
+		youngSize uint64
+	}{
+		{"mark_sweep", 0},
+		{"generational", 16 << 10},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			var stats heap.GCStats
+			for i := 0; i < b.N; i++ {
+				stats = churn(b, vm, bench.youngSize, 100000)
+			}
+			b.ReportMetric(float64(stats.Collections), "full-GCs/op")
+			b.ReportMetric(float64(stats.MinorCollections), "minor-GCs/op")
+		})
+	}
+}
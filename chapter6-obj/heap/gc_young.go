@@ -0,0 +1,211 @@
+package heap
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+	分代回收。对象不会移动（内存是Go在管，没办法像HotSpot那样把活对象复制到survivor区），
+	新生代只是一个对象列表：
+	1.新对象先放进新生代，新生代的对象加起来超过 -Xmn 时请求一次minor GC
+	2.minor GC只标记新生代：根是栈帧、类对象、字符串池和记忆集，碰到老年代的对象就停下
+	3.活下来的对象年龄加一，熬过maxTenuringThreshold次minor GC的晋升到老年代，晋升的对象数到了阈值才请求full GC
+	4.老年代的对象引用新生代的对象时，minor GC从根找不到这条引用，所以putfield、putstatic、aastore
+	  以及本地代码里写引用的地方都要经过写屏障，把老年代的对象（或者类的静态变量）记到记忆集里
+	大部分对象活不过一次minor GC，它们不会进老年代，full GC也就少了
+ */
+
+//熬过这么多次minor GC之后晋升到老年代
+const maxTenuringThreshold = 3
+
+/**
+	-Xmn，新生代的大小，单位是字节，0表示不分代
+ */
+func (self *Heap) SetYoungSize(size uint64) {
+	self.youngSize = size
+}
+
+func (self *Heap) YoungSize() uint64 {
+	return self.youngSize
+}
+
+func (self *Heap) MinorGCRequested() bool {
+	return self.minorRequested
+}
+
+func (self *Heap) allocateYoung(obj *Object) {
+	self.young = append(self.young, obj)
+	self.youngUsed += obj.size()
+	if self.youngUsed >= self.youngSize {
+		self.minorRequested = true
+	}
+}
+
+/**
+	写屏障：self 的某个字段（或者数组元素）写入了 ref，
+	老年代的对象第一次引用新生代的对象时放进记忆集
+ */
+func (self *Object) WriteBarrier(ref *Object) {
+	if ref != nil && self.old && !ref.old && !self.remembered {
+		self.remembered = true
+		jvmHeap.remembered = append(jvmHeap.remembered, self)
+	}
+}
+
+/**
+	静态变量的写屏障。类对象和静态变量不在新生代，要单独记
+ */
+func (self *Class) StaticWriteBarrier(ref *Object) {
+	if ref != nil && !ref.old && !self.staticsRemembered {
+		self.staticsRemembered = true
+		jvmHeap.rememberedClasses = append(jvmHeap.rememberedClasses, self)
+	}
+}
+
+/**
+	roots 和GC一样是解释器给出的根。类对象都当作根，静态变量只看记忆集里的类
+ */
+func (self *Heap) MinorGC(roots []*Object) GCStats {
+	start := time.Now()
+	youngBefore, usedBefore := len(self.young), self.used
+	marker := &gcMarker{youngOnly: true}
+	for _, root := range roots {
+		marker.mark(root)
+	}
+	for _, loader := range classLoaders {
+		for _, class := range loader.classMap {
+			marker.mark(class.jClass)
+		}
+	}
+	for _, class := range self.rememberedClasses {
+		for _, slot := range class.staticVars {
+			marker.mark(slot.Ref)
+		}
+	}
+	for _, jStr := range internedStrings {
+		marker.mark(jStr)
+	}
+	for _, holder := range self.remembered {
+		marker.scan(holder)
+	}
+	marker.drain()
+	marker.processReferences()
+
+	oldCount := len(self.objects)
+	collected, freed := self.sweepYoung(false)
+	self.updateRemembered(self.objects[oldCount:])
+	self.minorRequested = false
+	pause := time.Since(start)
+	self.stats.MinorCollections++
+	self.stats.ObjectsCollected += collected
+	self.stats.BytesFreed += freed
+	self.stats.PauseTime += pause
+
+	if self.gcLog != nil {
+		fmt.Fprintf(self.gcLog, "[Minor GC #%d (Young Generation Full) %d->%d young objects, %d->%d bytes, %.7f secs]\n",
+			self.stats.MinorCollections, youngBefore, len(self.young), usedBefore, self.used, pause.Seconds())
+	}
+	return GCStats{MinorCollections: 1, ObjectsCollected: collected, BytesFreed: freed, PauseTime: pause}
+}
+
+/**
+	没被标记的新生代对象回收，活下来的年龄加一，到了年龄就晋升。tenureAll 时（full GC）全部晋升
+ */
+func (self *Heap) sweepYoung(tenureAll bool) (collected, freed uint64) {
+	live := self.young[:0]
+	for _, obj := range self.young {
+		size := obj.size()
+		if !obj.gcMark {
+			collected++
+			freed += size
+			self.youngUsed -= size
+			continue
+		}
+		obj.gcMark = false
+		obj.gcAge++
+		if tenureAll || obj.gcAge >= maxTenuringThreshold {
+			self.youngUsed -= size
+			self.tenure(obj)
+		} else {
+			live = append(live, obj)
+		}
+	}
+	for i := len(live); i < len(self.young); i++ {
+		self.young[i] = nil
+	}
+	self.young = live
+	freed = self.release(freed)
+	return
+}
+
+/**
+	minor GC之后重新整理记忆集：已经不再引用新生代的去掉，刚晋升的对象如果引用着新生代的对象要加进来
+ */
+func (self *Heap) updateRemembered(promoted []*Object) {
+	remembered := self.remembered[:0]
+	for _, holder := range self.remembered {
+		if referencesYoung(holder) {
+			remembered = append(remembered, holder)
+		} else {
+			holder.remembered = false
+		}
+	}
+	for i := len(remembered); i < len(self.remembered); i++ {
+		self.remembered[i] = nil
+	}
+	self.remembered = remembered
+	for _, obj := range promoted {
+		if !obj.remembered && referencesYoung(obj) {
+			obj.remembered = true
+			self.remembered = append(self.remembered, obj)
+		}
+	}
+
+	classes := self.rememberedClasses[:0]
+	for _, class := range self.rememberedClasses {
+		if slotsReferenceYoung(class.staticVars) {
+			classes = append(classes, class)
+		} else {
+			class.staticsRemembered = false
+		}
+	}
+	self.rememberedClasses = classes
+}
+
+/**
+	full GC之后新生代是空的，记忆集全部清空
+ */
+func (self *Heap) forgetRemembered() {
+	for _, holder := range self.remembered {
+		holder.remembered = false
+	}
+	for _, class := range self.rememberedClasses {
+		class.staticsRemembered = false
+	}
+	self.remembered = nil
+	self.rememberedClasses = nil
+}
+
+func referencesYoung(obj *Object) bool {
+	switch data := obj.data.(type) {
+	case Slots:
+		return slotsReferenceYoung(data)
+	case []*Object:
+		for _, ref := range data {
+			if ref != nil && !ref.old {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func slotsReferenceYoung(slots Slots) bool {
+	for _, slot := range slots {
+		if slot.Ref != nil && !slot.Ref.old {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package heap
+
+import "testing"
+
+/**
+	打开新生代之前分配的对象直接进老年代
+ */
+func generationalHeap(t *testing.T) *Heap {
+	heap := newTestHeap(t)
+	heap.SetYoungSize(1 << 20)
+	return heap
+}
+
+func TestMinorGCCollectsOnlyYoungObjects(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := newTestHeap(t)
+	oldGarbage := node.NewObject()
+	heap.SetYoungSize(1 << 20)
+
+	root := node.NewObject()
+	child := node.NewObject()
+	root.SetRefVar("next", "Lgc/Node;", child)
+	node.NewObject()
+
+	stats := heap.MinorGC([]*Object{root})
+	if stats.ObjectsCollected != 1 || stats.MinorCollections != 1 {
+		t.Errorf("MinorGC() = %+v, want one young object collected", stats)
+	}
+	if len(heap.young) != 2 || heap.young[0] != root || heap.young[1] != child {
+		t.Errorf("young = %v, want [root child]", heap.young)
+	}
+	//老年代的垃圾要等full GC
+	if live := liveSet(heap); !live[oldGarbage] || len(live) != 1 {
+		t.Errorf("minor GC touched the old generation: %d old objects", len(live))
+	}
+	if heap.youngUsed != 2 * root.size() {
+		t.Errorf("youngUsed = %d, want %d", heap.youngUsed, 2 * root.size())
+	}
+	if total := heap.GCStats(); total.Collections != 0 || total.MinorCollections != 1 {
+		t.Errorf("GCStats() = %+v", total)
+	}
+}
+
+func TestMinorGCPromotesSurvivors(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := generationalHeap(t)
+	heap.SetGCThreshold(1)
+
+	root := node.NewObject()
+	for i := 1; i < maxTenuringThreshold; i++ {
+		heap.MinorGC([]*Object{root})
+		if root.old || len(heap.young) != 1 {
+			t.Fatalf("promoted after %d minor GCs", i)
+		}
+	}
+	if heap.GCRequested() {
+		t.Fatal("full GC requested before anything was promoted")
+	}
+	heap.MinorGC([]*Object{root})
+	if !root.old || len(heap.young) != 0 || heap.youngUsed != 0 || !liveSet(heap)[root] {
+		t.Fatalf("not promoted after %d minor GCs", maxTenuringThreshold)
+	}
+	//晋升的对象数到了阈值才请求full GC
+	if !heap.GCRequested() {
+		t.Error("promotion did not count toward the full GC threshold")
+	}
+}
+
+func TestWriteBarrierRemembersOldToYoungReferences(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	objects := loader.LoadClass("[Ljava/lang/Object;")
+	heap := newTestHeap(t)
+	holder := node.NewObject()
+	oldArray := objects.NewArray(1)
+	heap.SetYoungSize(1 << 20)
+
+	child := node.NewObject()
+	holder.SetRefVar("next", "Lgc/Node;", child)
+	youngArray := objects.NewArray(1)
+	elem := node.NewObject()
+	youngArray.Refs()[0] = elem
+	ArrayCopy(youngArray, oldArray, 0, 0, 1)
+	if !holder.remembered || !oldArray.remembered || len(heap.remembered) != 2 {
+		t.Fatalf("remembered = %v", heap.remembered)
+	}
+	//新生代的对象之间的引用不进记忆集
+	if youngArray.remembered {
+		t.Error("a young holder was remembered")
+	}
+
+	//只能从记忆集找到child和elem
+	if stats := heap.MinorGC(nil); stats.ObjectsCollected != 1 {
+		t.Errorf("ObjectsCollected = %d, want only the young array", stats.ObjectsCollected)
+	}
+	if len(heap.young) != 2 {
+		t.Fatalf("young = %v, want [child elem]", heap.young)
+	}
+
+	//不再引用新生代的对象从记忆集里去掉
+	holder.SetRefVar("next", "Lgc/Node;", nil)
+	heap.MinorGC(nil)
+	if holder.remembered || len(heap.remembered) != 1 || heap.remembered[0] != oldArray {
+		t.Errorf("remembered = %v after the holder dropped its young reference", heap.remembered)
+	}
+	if len(heap.young) != 1 || heap.young[0] != elem {
+		t.Errorf("young = %v, want [elem]", heap.young)
+	}
+}
+
+func TestStaticWriteBarrier(t *testing.T) {
+	loader := newTestLoader(t, nodeClass(), holderClass())
+	node := loader.LoadClass("gc/Node")
+	holder := loader.LoadClass("gc/Holder")
+	heap := generationalHeap(t)
+
+	kept := node.NewObject()
+	holder.SetRefVar("node", "Lgc/Node;", kept)
+	node.NewObject()
+	if !holder.staticsRemembered {
+		t.Fatal("static write was not remembered")
+	}
+	if stats := heap.MinorGC(nil); stats.ObjectsCollected != 1 || len(heap.young) != 1 || heap.young[0] != kept {
+		t.Errorf("MinorGC() = %+v, young = %v, want only the static's object kept", stats, heap.young)
+	}
+}
+
+func TestFullGCEmptiesYoungGeneration(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := newTestHeap(t)
+	holder := node.NewObject()
+	heap.SetYoungSize(1 << 20)
+
+	root := node.NewObject()
+	holder.SetRefVar("next", "Lgc/Node;", node.NewObject())
+	node.NewObject()
+	heap.minorRequested = true
+
+	//holder没有根，连同它引用的新生代对象一起回收
+	stats := heap.GC([]*Object{root})
+	if stats.ObjectsCollected != 3 {
+		t.Errorf("ObjectsCollected = %d, want 3", stats.ObjectsCollected)
+	}
+	if len(heap.young) != 0 || heap.youngUsed != 0 || heap.minorRequested {
+		t.Errorf("young generation not emptied: %d objects, %d bytes", len(heap.young), heap.youngUsed)
+	}
+	if !root.old || !liveSet(heap)[root] || root.gcMark {
+		t.Error("young survivor was not promoted")
+	}
+	if len(heap.remembered) != 0 || holder.remembered {
+		t.Error("remembered set not cleared")
+	}
+	if heap.used != root.size() {
+		t.Errorf("used = %d, want %d", heap.used, root.size())
+	}
+}
+
+func TestYoungSizeRequestsMinorGC(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := newTestHeap(t)
+	heap.SetYoungSize(2 * instanceSize(node.InstanceSlotCount))
+
+	node.NewObject()
+	if heap.MinorGCRequested() {
+		t.Fatal("minor GC requested before the young generation is full")
+	}
+	node.NewObject()
+	if !heap.MinorGCRequested() {
+		t.Fatal("minor GC not requested when the young generation is full")
+	}
+	heap.MinorGC(nil)
+	if heap.MinorGCRequested() || heap.GCRequested() {
+		t.Error("collection still requested after a minor GC")
+	}
+}
@@ -0,0 +1,129 @@
+package heap
+
+import (
+	"io"
+	"math"
+)
+
+/**
+	按java对象的布局估算大小：对象头16字节，每个slot 4字节，引用4字节（压缩指针）
+ */
+const (
+	objectHeaderSize = 16
+	slotSize         = 4
+	refSize          = 4
+)
+
+/**
+	堆。对象的内存其实是Go的GC在管，这里只记录 -Xms -Xmx 给出的大小和已经分配的字节数，
+	用来实现 OutOfMemoryError 和 Runtime 的 maxMemory/totalMemory/freeMemory
+	maxSize 为0表示没有上限（没有给 -Xmx）
+	对象的回收见gc.go
+ */
+type Heap struct {
+	initSize uint64
+	maxSize  uint64
+	used     uint64
+	//所有还没被回收的对象，有新生代时只是老年代的对象
+	objects     []*Object
+	//上一次GC之后分配的对象数，有新生代时是晋升到老年代的对象数
+	allocCount  uint
+	gcThreshold uint
+	gcRequested bool
+	//请求GC的原因，只用在GC日志里，为空表示分配的对象数到了阈值
+	gcCause     string
+	stats       GCStats
+	//-XX:+PrintGC 时的日志输出
+	gcLog       io.Writer
+	//新生代，见gc_young.go。youngSize 为0表示没有新生代（没有给 -Xmn）
+	youngSize         uint64
+	youngUsed         uint64
+	young             []*Object
+	minorRequested    bool
+	remembered        []*Object
+	rememberedClasses []*Class
+}
+
+var jvmHeap = &Heap{gcThreshold: defaultGCThreshold}
+
+/**
+	启动虚拟机时根据 -Xms -Xmx 初始化堆，参数为0表示没有指定
+ */
+func InitHeap(initSize, maxSize uint64) {
+	jvmHeap = &Heap{
+		initSize: initSize,
+		maxSize:  maxSize,
+		gcThreshold: defaultGCThreshold,
+	}
+}
+
+func GetHeap() *Heap {
+	return jvmHeap
+}
+
+/**
+	分配size字节，超过 -Xmx 时panic，报告OutOfMemoryError
+	这是致命错误，java代码catch不到：NewObject和NewArray拿不到栈帧，没办法像ThrowException那样抛给java代码，虚拟机直接退出
+	要在真正make之前调用，否则一个超大的数组会先把Go进程的内存耗尽
+ */
+func (self *Heap) alloc(size uint64) {
+	if self.maxSize > 0 && self.used + size > self.maxSize {
+		panic("java.lang.OutOfMemoryError: Java heap space")
+	}
+	self.used += size
+}
+
+/**
+	没有 -Xmx 时和HotSpot一样返回Long.MAX_VALUE
+ */
+func (self *Heap) MaxMemory() int64 {
+	if self.maxSize == 0 {
+		return math.MaxInt64
+	}
+	return int64(self.maxSize)
+}
+
+/**
+	堆从 -Xms 开始，放不下时再扩大
+ */
+func (self *Heap) TotalMemory() int64 {
+	if self.used > self.initSize {
+		return int64(self.used)
+	}
+	return int64(self.initSize)
+}
+
+func (self *Heap) FreeMemory() int64 {
+	return self.TotalMemory() - int64(self.used)
+}
+
+func instanceSize(slotCount uint) uint64 {
+	return objectHeaderSize + uint64(slotCount) * slotSize
+}
+
+func arraySize(arrayClassName string, count uint) uint64 {
+	var elementSize uint64
+	switch arrayClassName[1] {
+	case 'Z', 'B':
+		elementSize = 1
+	case 'C', 'S':
+		elementSize = 2
+	case 'J', 'D':
+		elementSize = 8
+	case 'I', 'F':
+		elementSize = 4
+	default:
+		elementSize = refSize
+	}
+	return objectHeaderSize + uint64(count) * elementSize
+}
+
+/**
+	已经存在的对象的大小，用于clone
+ */
+func (self *Object) size() uint64 {
+	if self.class.IsArray() {
+		return arraySize(self.class.name, uint(self.ArrayLength()))
+	}
+	return instanceSize(uint(len(self.data.(Slots))))
+}
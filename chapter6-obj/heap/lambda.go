@@ -0,0 +1,334 @@
+package heap
+
+import "fmt"
+
+/**
+	invokedynamic解析出来的调用点
+	目前只有LambdaMetafactory一种，调用点的目标是 new 一个合成的、实现了函数式接口的类的对象，
+	invokedynamic弹出的参数就是lambda捕获的变量，存在这个对象的字段里
+ */
+type CallSite struct {
+	lambdaClass       *Class
+	capturedSlotCount uint
+}
+
+func (self *CallSite) LambdaClass() *Class {
+	return self.lambdaClass
+}
+
+func (self *CallSite) CapturedSlotCount() uint {
+	return self.capturedSlotCount
+}
+
+/**
+	捕获的变量按顺序放在从0开始的字段里，和操作数栈里的slot布局一样，直接整段复制
+ */
+func (self *CallSite) NewInstance(captured Slots) *Object {
+	obj := self.lambdaClass.NewObject()
+	obj.Fields().CopyFrom(0, captured, 0, self.capturedSlotCount)
+	return obj
+}
+
+//合成的lambda类的编号，和HotSpot一样拼在类名后面
+var lambdaCount = 0
+
+/**
+	LambdaMetafactory.metafactory(lookup, samMethodName, invokedType, samMethodType, implMethod, instantiatedMethodType)
+	前三个参数由虚拟机提供：samMethodName 是invokedynamic的名字，invokedType 是invokedynamic的描述符，
+	(捕获的变量)函数式接口；后三个是常量池里的静态参数
+	合成的类实现函数式接口，接口方法（名字是samMethodName，描述符是擦除之后的samMethodType）的字节码由spinLambdaMethod生成：
+	把捕获的变量和接口方法的参数转调给implMethod
+ */
+func lambdaMetafactory(indy *InvokeDynamicRef, args []Constant) *CallSite {
+	if len(args) < 3 {
+		panic("java.lang.BootstrapMethodError: LambdaMetafactory needs 3 static arguments, got " + fmt.Sprint(len(args)))
+	}
+	samType, ok1 := args[0].(*MethodTypeRef)
+	implHandle, ok2 := args[1].(*MethodHandleRef)
+	if !ok1 || !ok2 {
+		panic("java.lang.BootstrapMethodError: bad LambdaMetafactory arguments in " + indy.cp.class.name)
+	}
+
+	caller := indy.cp.class
+	loader := caller.loader
+	invokedType := parseMethodDescriptor(indy.descriptor)
+	interfaceName := toClassName(invokedType.returnType)
+
+	lambdaCount++
+	class := &Class{
+		accessFlags:    ACC_FINAL | ACC_SYNTHETIC,
+		name:           fmt.Sprintf("%s$$Lambda$%d", caller.name, lambdaCount),
+		superClassName: "java/lang/Object",
+		interfaceNames: []string{interfaceName},
+		loader:         loader,
+		superClass:     loader.LoadClass("java/lang/Object"),
+		interfaces:     []*Class{loader.LoadClass(interfaceName)},
+		initStarted:    true,
+		initFinished:   true,
+		sourceFile:     "Unknown",
+	}
+	class.constantPool = &ConstantPool{class, []Constant{nil}}
+	for i, capturedType := range invokedType.parameterTypes {
+		field := &Field{}
+		field.class = class
+		field.accessFlags = ACC_PRIVATE | ACC_FINAL
+		field.name = fmt.Sprintf("arg$%d", i + 1)
+		field.descriptor = capturedType
+		class.fields = append(class.fields, field)
+	}
+	prepare(class)
+	class.methods = []*Method{spinLambdaMethod(class, indy.name, samType.descriptor, implHandle)}
+
+	loader.classMap[class.name] = class
+	loader.initJClass(class)
+
+	capturedSlotCount := uint(0)
+	for _, capturedType := range invokedType.parameterTypes {
+		capturedSlotCount += uint(descriptorSlotSize(capturedType))
+	}
+	return &CallSite{class, capturedSlotCount}
+}
+
+/**
+	生成接口方法，字节码大致是：
+		[new implClass; dup]                    -> 只有构造方法引用（REF_newInvokeSpecial）才有
+		aload_0; getfield arg$1 ...             -> 捕获的变量
+		xload 1 ...                             -> 接口方法的参数，必要时装箱、拆箱或者checkcast
+		invokeXXX implMethod
+		[装箱/拆箱/pop]
+		xreturn
+ */
+func spinLambdaMethod(class *Class, name, descriptor string, implHandle *MethodHandleRef) *Method {
+	implMethod := implHandle.ResolvedMethod()
+	implClass := implMethod.class
+	samType := parseMethodDescriptor(descriptor)
+	implType := parseMethodDescriptor(implMethod.descriptor)
+
+	//把接收者也当成implMethod的第一个参数，这样捕获的变量和接口参数可以统一按位置对应
+	implParams := implType.parameterTypes
+	implReturn := implType.returnType
+	kind := implHandle.referenceKind
+	switch kind {
+	case REF_invokeVirtual, REF_invokeSpecial, REF_invokeInterface:
+		implParams = append([]string{"L" + implClass.name + ";"}, implParams...)
+	case REF_newInvokeSpecial:
+		implReturn = "L" + implClass.name + ";"
+	}
+
+	method := &Method{}
+	method.class = class
+	method.accessFlags = ACC_PUBLIC | ACC_SYNTHETIC
+	method.name = name
+	method.descriptor = descriptor
+	method.calcArgSlotCount(samType.parameterTypes)
+	method.maxLocals = method.argSlotCount
+
+	w := &lambdaCodeWriter{cp: class.constantPool}
+	if kind == REF_newInvokeSpecial {
+		w.emit(0xbb) // new
+		w.emitU2(w.classRef(implClass.name))
+		w.emit(0x59) // dup
+	}
+
+	captured := len(class.fields)
+	if captured + len(samType.parameterTypes) != len(implParams) {
+		panic(fmt.Sprintf("java.lang.invoke.LambdaConversionException: %s%s cannot implement %s%s",
+			implMethod.name, implMethod.descriptor, name, descriptor))
+	}
+	for _, field := range class.fields {
+		w.emit(0x2a) // aload_0
+		w.emit(0xb4) // getfield
+		w.emitU2(w.fieldRef(field))
+	}
+	local := uint(1)
+	for i, paramType := range samType.parameterTypes {
+		w.emitLoad(paramType, local)
+		w.emitConversion(paramType, implParams[captured + i])
+		local += uint(descriptorSlotSize(paramType))
+	}
+
+	switch kind {
+	case REF_invokeStatic:
+		w.emit(0xb8)
+		w.emitU2(w.methodRef(implMethod))
+	case REF_invokeVirtual:
+		w.emit(0xb6)
+		w.emitU2(w.methodRef(implMethod))
+	case REF_invokeSpecial, REF_newInvokeSpecial:
+		w.emit(0xb7)
+		w.emitU2(w.methodRef(implMethod))
+	case REF_invokeInterface:
+		w.emit(0xb9)
+		w.emitU2(w.interfaceMethodRef(implMethod))
+		w.emit(byte(implMethod.argSlotCount))
+		w.emit(0)
+	default:
+		panic(fmt.Sprintf("java.lang.invoke.LambdaConversionException: unsupported method handle kind %d", kind))
+	}
+
+	switch {
+	case samType.returnType == "V":
+		if size := descriptorSlotSize(implReturn); size == 1 {
+			w.emit(0x57) // pop
+		} else if size == 2 {
+			w.emit(0x58) // pop2
+		}
+	case implReturn == "V":
+		w.emit(0x01) // aconst_null
+	default:
+		w.emitConversion(implReturn, samType.returnType)
+	}
+	w.emitReturn(samType.returnType)
+
+	method.code = w.code
+	//implMethod的参数之外，new和dup最多两个，装箱拆箱时long和double最多多出两个
+	method.maxStack = implMethod.argSlotCount + 4
+	return method
+}
+
+type lambdaCodeWriter struct {
+	cp   *ConstantPool
+	code []byte
+}
+
+func (self *lambdaCodeWriter) emit(b byte) {
+	self.code = append(self.code, b)
+}
+
+func (self *lambdaCodeWriter) emitU2(index uint) {
+	self.code = append(self.code, byte(index >> 8), byte(index))
+}
+
+func (self *lambdaCodeWriter) addConstant(c Constant) uint {
+	self.cp.consts = append(self.cp.consts, c)
+	return uint(len(self.cp.consts) - 1)
+}
+
+func (self *lambdaCodeWriter) classRef(className string) uint {
+	ref := &ClassRef{}
+	ref.cp = self.cp
+	ref.className = className
+	return self.addConstant(ref)
+}
+
+func (self *lambdaCodeWriter) fieldRef(field *Field) uint {
+	ref := &FieldRef{field: field}
+	ref.cp = self.cp
+	ref.className, ref.class = field.class.name, field.class
+	ref.name, ref.descriptor = field.name, field.descriptor
+	return self.addConstant(ref)
+}
+
+/**
+	implMethod可能是调用者的私有方法，合成的类访问不了，所以符号引用直接指向已经解析好的方法，不再做访问检查
+ */
+func (self *lambdaCodeWriter) methodRef(method *Method) uint {
+	ref := &MethodRef{method: method}
+	ref.cp = self.cp
+	ref.className, ref.class = method.class.name, method.class
+	ref.name, ref.descriptor = method.name, method.descriptor
+	return self.addConstant(ref)
+}
+
+func (self *lambdaCodeWriter) interfaceMethodRef(method *Method) uint {
+	ref := &InterfaceMethodRef{method: method}
+	ref.cp = self.cp
+	ref.className, ref.class = method.class.name, method.class
+	ref.name, ref.descriptor = method.name, method.descriptor
+	return self.addConstant(ref)
+}
+
+/**
+	装箱拆箱用的是包装类的public方法，正常解析就行
+ */
+func (self *lambdaCodeWriter) symbolicMethodRef(className, name, descriptor string) uint {
+	ref := &MethodRef{}
+	ref.cp = self.cp
+	ref.className, ref.name, ref.descriptor = className, name, descriptor
+	return self.addConstant(ref)
+}
+
+func (self *lambdaCodeWriter) emitLoad(descriptor string, index uint) {
+	switch descriptor[0] {
+	case 'J':
+		self.emit(0x16) // lload
+	case 'F':
+		self.emit(0x17) // fload
+	case 'D':
+		self.emit(0x18) // dload
+	case 'L', '[':
+		self.emit(0x19) // aload
+	default:
+		self.emit(0x15) // iload
+	}
+	self.emit(byte(index))
+}
+
+func (self *lambdaCodeWriter) emitReturn(descriptor string) {
+	switch descriptor[0] {
+	case 'V':
+		self.emit(0xb1) // return
+	case 'J':
+		self.emit(0xad) // lreturn
+	case 'F':
+		self.emit(0xae) // freturn
+	case 'D':
+		self.emit(0xaf) // dreturn
+	case 'L', '[':
+		self.emit(0xb0) // areturn
+	default:
+		self.emit(0xac) // ireturn
+	}
+}
+
+//基本类型的拓宽转换
+var wideningOpcodes = map[string]byte{
+	"IJ": 0x85, // i2l
+	"IF": 0x86, // i2f
+	"ID": 0x87, // i2d
+	"JF": 0x89, // l2f
+	"JD": 0x8a, // l2d
+	"FD": 0x8d, // f2d
+}
+
+/**
+	接口方法擦除之后的类型和implMethod的类型不一样时做转换：
+	引用到引用用checkcast，基本类型到引用装箱，引用到基本类型拆箱，基本类型之间只支持拓宽
+ */
+func (self *lambdaCodeWriter) emitConversion(from, to string) {
+	if from == to {
+		return
+	}
+	fromRef := from[0] == 'L' || from[0] == '['
+	toRef := to[0] == 'L' || to[0] == '['
+	switch {
+	case fromRef && toRef:
+		if to != "Ljava/lang/Object;" {
+			self.emit(0xc0) // checkcast
+			self.emitU2(self.classRef(toClassName(to)))
+		}
+	case toRef:
+		wrapper := wrapperClassName(from)
+		self.emit(0xb8) // invokestatic
+		self.emitU2(self.symbolicMethodRef(wrapper, "valueOf", "(" + from + ")L" + wrapper + ";"))
+	case fromRef:
+		wrapper := wrapperClassName(to)
+		self.emit(0xc0) // checkcast
+		self.emitU2(self.classRef(wrapper))
+		self.emit(0xb6) // invokevirtual
+		self.emitU2(self.symbolicMethodRef(wrapper, toClassName(to) + "Value", "()" + to))
+	default:
+		if opcode, ok := wideningOpcodes[from + to]; ok {
+			self.emit(opcode)
+		}
+	}
+}
+
+func wrapperClassName(descriptor string) string {
+	for className, d := range wrapperValueDescriptors {
+		if d == descriptor {
+			return className
+		}
+	}
+	panic("Not a primitive descriptor: " + descriptor)
+}
@@ -0,0 +1,46 @@
+package heap
+
+import (
+	"GoVM/chapter3-cf/classfile"
+	"sort"
+)
+
+/**
+	按startPc排好序的行号表。class文件里的表不保证有序（比如循环条件经常被编译到循环体后面），
+	也可能分成好几个属性，所以合并之后再排序，查找时用二分
+ */
+type LineNumberTable []lineNumberEntry
+
+type lineNumberEntry struct {
+	startPc    int
+	lineNumber int
+}
+
+func newLineNumberTable(attrs []*chapter3_cf.LineNumberTableAttribute) LineNumberTable {
+	var table LineNumberTable
+	for _, attr := range attrs {
+		for _, entry := range attr.Entries() {
+			table = append(table, lineNumberEntry{
+				startPc: int(entry.StartPc()),
+				lineNumber: int(entry.LineNumber()),
+			})
+		}
+	}
+	sort.SliceStable(table, func(i, j int) bool {
+		return table[i].startPc < table[j].startPc
+	})
+	return table
+}
+
+/**
+	找startPc <= pc 的最后一项，找不到返回-1
+ */
+func (self LineNumberTable) lookup(pc int) int {
+	i := sort.Search(len(self), func(i int) bool {
+		return self[i].startPc > pc
+	})
+	if i == 0 {
+		return -1
+	}
+	return self[i - 1].lineNumber
+}
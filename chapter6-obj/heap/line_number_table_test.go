@@ -0,0 +1,56 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"testing"
+)
+
+/**
+	run的字节码和行号：
+		pc 0 iconst_0, 1 istore_0      第10行
+		pc 2 iinc 0 1                  第20行（在单独的第二个LineNumberTable属性里）
+		pc 5 iload_0, 6 ireturn        第30行
+	late 的第一条指令没有行号，noLines 没有行号表
+ */
+func lineNumberClass() *classgen.Class {
+	class := classgen.New("lines/Lines", "java/lang/Object")
+	class.Method(classgen.ACC_STATIC, "run", "()I").Code(1, 1).
+		Line(10).Op(classgen.ICONST_0).Op(classgen.ISTORE_0).
+		Op(classgen.IINC, 0, 1).
+		Line(30).Op(classgen.ILOAD_0).Op(classgen.IRETURN).
+		Attribute("LineNumberTable", []byte{0, 1, 0, 2, 0, 20})
+	class.Method(classgen.ACC_STATIC, "late", "()V").Code(0, 0).
+		Op(classgen.NOP).Line(40).Op(classgen.RETURN)
+	class.Method(classgen.ACC_STATIC, "noLines", "()V").Code(0, 0).Op(classgen.RETURN)
+	class.Method(classgen.ACC_STATIC|classgen.ACC_NATIVE, "nativeMethod", "()V")
+	return class
+}
+
+func TestGetLineNumber(t *testing.T) {
+	class := newTestLoader(t, lineNumberClass()).LoadClass("lines/Lines")
+	run := class.GetStaticMethod("run", "()I")
+	for pc, want := range []int{10, 10, 20, 20, 20, 30, 30} {
+		if got := run.GetLineNumber(pc); got != want {
+			t.Errorf("run: line at pc %d = %d, want %d", pc, got, want)
+		}
+	}
+	//第二次查询用缓存的表，结果一样
+	if got := run.GetLineNumber(3); got != 20 {
+		t.Errorf("cached lookup at pc 3 = %d, want 20", got)
+	}
+
+	for _, test := range []struct {
+		method string
+		pc     int
+		want   int
+	}{
+		{"late", 0, -1},
+		{"late", 1, 40},
+		{"noLines", 0, -1},
+		{"nativeMethod", 0, -2},
+	} {
+		if got := class.GetStaticMethod(test.method, "()V").GetLineNumber(test.pc); got != test.want {
+			t.Errorf("%s: line at pc %d = %d, want %d", test.method, test.pc, got, test.want)
+		}
+	}
+}
@@ -12,7 +12,29 @@ type Method struct {
 	maxStack        uint
 	maxLocals       uint
 	exceptionTable  ExceptionTable
-	lineNumberTable *chapter3_cf.LineNumberTableAttribute
+	//class文件里的LineNumberTable属性，第一次查行号时才整理成lineNumbers
+	lineNumberAttrs []*chapter3_cf.LineNumberTableAttribute
+	lineNumbers     LineNumberTable
+	lineNumbersBuilt bool
+	//只在开启验证时使用
+	stackMapTable   *chapter3_cf.StackMapTableAttribute
+	//解释器缓存的解码之后的字节码，具体类型由解释器决定
+	decodedCode     interface{}
+	//Exceptions属性里声明的异常（throws子句）
+	exceptions      []*ClassRef
+	//是否已经做过操作数栈深度的验证
+	verified        bool
+}
+
+/**
+	判断一个java方法是不是有go实现的intrinsic版本，由native包注册进来
+ */
+var isIntrinsic = func(className, methodName, methodDescriptor string) bool {
+	return false
+}
+
+func SetIntrinsicChecker(checker func(className, methodName, methodDescriptor string) bool) {
+	isIntrinsic = checker
 }
 
 func newMethods(class *Class, cfMethods []*chapter3_cf.MemberInfo) []*Method {
@@ -32,6 +54,12 @@ func newMethod(class *Class, cfMethod *chapter3_cf.MemberInfo) *Method {
 	method.calcArgSlotCount(methodDescriptor.parameterTypes)
 	if method.IsNative() {
 		method.injectCodeAttribute(methodDescriptor.returnType)
+	} else if isIntrinsic(class.name, method.name, method.descriptor) {
+		//字节码被替换成了go实现，原来的异常表、行号表等也就没用了
+		method.exceptionTable = nil
+		method.lineNumberAttrs = nil
+		method.stackMapTable = nil
+		method.injectCodeAttribute(methodDescriptor.returnType)
 	}
 	return method
 }
@@ -70,10 +98,17 @@ func (self *Method) copyAttributes(cfMethod *chapter3_cf.MemberInfo) {
 	if codeAttr := cfMethod.CodeAttribute(); codeAttr != nil {
 		self.maxStack = codeAttr.MaxStack()
 		self.code = codeAttr.Code()
-		self.lineNumberTable = codeAttr.LineNumberTableAttribute()
+		self.lineNumberAttrs = codeAttr.LineNumberTableAttributes()
+		self.stackMapTable = codeAttr.StackMapTableAttribute()
 		self.maxLocals = codeAttr.MaxLocals()
 		self.exceptionTable = newExceptionTable(codeAttr.ExceptionTable(), self.class.constantPool)
 	}
+	if exAttr := cfMethod.ExceptionsAttribute(); exAttr != nil {
+		cp := self.class.constantPool
+		for _, index := range exAttr.ExceptionIndexTable() {
+			self.exceptions = append(self.exceptions, cp.GetConstant(uint(index)).(*ClassRef))
+		}
+	}
 }
 
 /**
@@ -95,10 +130,11 @@ func (self *Method) GetLineNumber(pc int) int {
 	if self.IsNative() {
 		return -2
 	}
-	if self.lineNumberTable == nil {
-		return -1
+	if !self.lineNumbersBuilt {
+		self.lineNumbers = newLineNumberTable(self.lineNumberAttrs)
+		self.lineNumbersBuilt = true
 	}
-	return self.lineNumberTable.GetLineNumber(pc)
+	return self.lineNumbers.lookup(pc)
 }
 
 func (self *Method) calcArgSlotCount(paramTypes []string) {
@@ -114,6 +150,39 @@ func (self *Method) calcArgSlotCount(paramTypes []string) {
 	}
 }
 
+/**
+	参数类型和throws子句里的异常类型，给反射用，会触发这些类的加载
+ */
+func (self *Method) ParameterTypes() []*Class {
+	paramTypes := parseMethodDescriptor(self.descriptor).parameterTypes
+	classes := make([]*Class, len(paramTypes))
+	for i, paramType := range paramTypes {
+		classes[i] = self.class.loader.LoadClass(toClassName(paramType))
+	}
+	return classes
+}
+
+func (self *Method) ReturnType() *Class {
+	returnType := parseMethodDescriptor(self.descriptor).returnType
+	return self.class.loader.LoadClass(toClassName(returnType))
+}
+
+func (self *Method) ExceptionTypes() []*Class {
+	classes := make([]*Class, len(self.exceptions))
+	for i, exRef := range self.exceptions {
+		classes[i] = exRef.ResolvedClass()
+	}
+	return classes
+}
+
+func (self *Method) IsConstructor() bool {
+	return !self.IsStatic() && self.name == "<init>"
+}
+
+func (self *Method) IsClinit() bool {
+	return self.IsStatic() && self.name == "<clinit>"
+}
+
 func (self *Method) IsSynchronized() bool {
 	return 0 != self.accessFlags & ACC_SYNCHRONIZED
 }
@@ -145,6 +214,21 @@ func (self *Method) Code() []byte {
 	return self.code
 }
 
+/**
+	抽象方法没有字节码；本地方法和intrinsic方法的字节码是加载时注入的
+ */
+func (self *Method) HasCode() bool {
+	return len(self.code) > 0
+}
+
+func (self *Method) DecodedCode() interface{} {
+	return self.decodedCode
+}
+
+func (self *Method) SetDecodedCode(decodedCode interface{}) {
+	self.decodedCode = decodedCode
+}
+
 func (self *Method) ArgSlotCount() uint {
 	return self.argSlotCount
 }
\ No newline at end of file
@@ -0,0 +1,48 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"bytes"
+	"testing"
+)
+
+/**
+	abstract class Coded {
+		static int add(int a, int b) { return a + b; }
+		abstract void todo();
+		static native long now();
+	}
+ */
+func TestMethodExposesCodeAttribute(t *testing.T) {
+	class := classgen.New("code/Coded", "java/lang/Object")
+	class.SetAccessFlags(classgen.ACC_PUBLIC | classgen.ACC_SUPER | classgen.ACC_ABSTRACT)
+	class.Method(classgen.ACC_STATIC, "add", "(II)I").Code(2, 3).
+		Op(classgen.ILOAD_0).Op(classgen.ILOAD_1).Op(classgen.IADD).Op(classgen.IRETURN)
+	class.Method(classgen.ACC_ABSTRACT, "todo", "()V")
+	class.Method(classgen.ACC_STATIC|classgen.ACC_NATIVE, "now", "()J")
+	coded := newTestLoader(t, class).LoadClass("code/Coded")
+
+	for _, test := range []struct {
+		name, descriptor    string
+		maxStack, maxLocals uint
+		code                []byte
+	}{
+		//Code属性里写的是什么就是什么，局部变量表可以比参数多
+		{"add", "(II)I", 2, 3, []byte{classgen.ILOAD_0, classgen.ILOAD_1, classgen.IADD, classgen.IRETURN}},
+		{"todo", "()V", 0, 0, nil},
+		//本地方法是注入的 0xfe + 返回指令，解释器要执行它们
+		{"now", "()J", 4, 0, []byte{0xfe, classgen.LRETURN}},
+	} {
+		method := coded.GetStaticMethod(test.name, test.descriptor)
+		if method == nil {
+			method = coded.GetInstanceMethod(test.name, test.descriptor)
+		}
+		if method.MaxStack() != test.maxStack || method.MaxLocals() != test.maxLocals {
+			t.Errorf("%s: maxStack %d, maxLocals %d, want %d and %d",
+				test.name, method.MaxStack(), method.MaxLocals(), test.maxStack, test.maxLocals)
+		}
+		if !bytes.Equal(method.Code(), test.code) || (test.code == nil) != (method.Code() == nil) {
+			t.Errorf("%s: code % x, want % x", test.name, method.Code(), test.code)
+		}
+	}
+}
@@ -0,0 +1,545 @@
+package heap
+
+import "fmt"
+
+/**
+	操作数栈的验证：对字节码做一次抽象解释，跟踪每条指令执行前栈里每个slot的类型
+	1.每条指令弹出的slot不能比栈里的多（underflow）
+	2.压栈之后不能超过方法声明的maxStack（overflow）
+	3.不同路径到达同一条指令时，栈的深度和每个slot的类型必须一样
+	4.异常处理器开始时栈里只有异常对象一个slot
+	long和double按两个slot计算，和OperandStack一致
+ */
+func VerifyMethod(method *Method) error {
+	return newStackVerifier(method).verify()
+}
+
+/**
+	操作数栈深度的验证默认打开，和 -Xverify:all 无关；-Xverify:none 时关闭
+ */
+var verifyMethods = true
+
+func DisableMethodVerification() {
+	verifyMethods = false
+}
+
+/**
+	第一次执行方法之前调用，验证过的方法不再重复验证
+	本地方法和intrinsic的字节码是注入的，不需要验证
+ */
+func (self *Method) EnsureVerified() {
+	if self.verified || !verifyMethods {
+		return
+	}
+	self.verified = true
+	if self.IsNative() || self.IsAbstract() || isIntrinsic(self.class.name, self.name, self.descriptor) {
+		return
+	}
+	if err := VerifyMethod(self); err != nil {
+		throwVerifyError(self, err.Error())
+	}
+}
+
+/**
+	栈里slot的类型，只区分解释器要区分的几类：
+		I int（boolean byte char short也是）  F float  L 引用（包括null和还没初始化的对象）  R jsr的返回地址
+		J long  D double，它们的第二个slot是 T
+ */
+type stackVerifier struct {
+	method  *Method
+	code    []byte
+	//每条指令执行前的操作数栈，每个字节是一个slot的类型
+	stacks  []string
+	reached []bool
+	//待处理的指令
+	pending []int
+}
+
+func newStackVerifier(method *Method) *stackVerifier {
+	return &stackVerifier{
+		method:  method,
+		code:    method.code,
+		stacks:  make([]string, len(method.code)),
+		reached: make([]bool, len(method.code)),
+	}
+}
+
+func (self *stackVerifier) verify() error {
+	if len(self.code) == 0 {
+		return nil
+	}
+	if err := self.reach(0, ""); err != nil {
+		return err
+	}
+	for _, handler := range self.method.exceptionTable {
+		if err := self.reach(handler.handlerPc, "L"); err != nil {
+			return err
+		}
+	}
+
+	for len(self.pending) > 0 {
+		pc := self.pending[len(self.pending) - 1]
+		self.pending = self.pending[:len(self.pending) - 1]
+		if err := self.step(pc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+	记录到达pc时的操作数栈
+ */
+func (self *stackVerifier) reach(pc int, stack string) error {
+	if pc < 0 || pc >= len(self.code) {
+		return fmt.Errorf("branch target %d is out of code", pc)
+	}
+	if !self.reached[pc] {
+		self.reached[pc] = true
+		self.stacks[pc] = stack
+		self.pending = append(self.pending, pc)
+		return nil
+	}
+	if old := self.stacks[pc]; len(old) != len(stack) {
+		return fmt.Errorf("inconsistent stack height %d != %d at pc %d", len(old), len(stack), pc)
+	} else if old != stack {
+		return fmt.Errorf("inconsistent stack types [%s] != [%s] at pc %d", old, stack, pc)
+	}
+	return nil
+}
+
+func (self *stackVerifier) step(pc int) error {
+	opcode := self.code[pc]
+	stack := self.stacks[pc]
+
+	effect, err := self.effectOf(pc)
+	if err != nil {
+		return fmt.Errorf("%v at pc %d (opcode 0x%02x)", err, pc, opcode)
+	}
+	if len(stack) < effect.pop {
+		return fmt.Errorf("stack underflow at pc %d (opcode 0x%02x)", pc, opcode)
+	}
+	kept, popped := stack[:len(stack) - effect.pop], stack[len(stack) - effect.pop:]
+	after := kept + effect.pushed(popped)
+	if len(after) > int(self.method.maxStack) {
+		return fmt.Errorf("stack overflow at pc %d (opcode 0x%02x), max stack %d", pc, opcode, self.method.maxStack)
+	}
+
+	for _, target := range effect.targets {
+		if err := self.reach(target, after); err != nil {
+			return err
+		}
+	}
+	if !effect.terminal {
+		if effect.subroutine {
+			after = kept
+		}
+		next := pc + effect.length
+		if next >= len(self.code) {
+			return fmt.Errorf("falling off the end of code at pc %d (opcode 0x%02x)", pc, opcode)
+		}
+		if err := self.reach(next, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+	顺序扫描一遍字节码，标出每条指令开始的位置，不管指令能不能执行到
+ */
+func (self *stackVerifier) instructionStarts() ([]bool, error) {
+	starts := make([]bool, len(self.code))
+	for pc := 0; pc < len(self.code); {
+		effect, err := self.effectOf(pc)
+		if err != nil {
+			return nil, fmt.Errorf("%v at pc %d (opcode 0x%02x)", err, pc, self.code[pc])
+		}
+		starts[pc] = true
+		pc += effect.length
+	}
+	return starts, nil
+}
+
+/**
+	一条指令的长度、弹出的slot数、压入的slot的类型，以及跳转目标
+	push 里的数字表示复制弹出的第几个slot（从栈底一侧数），dup、swap这样的指令用
+	terminal 表示执行完之后不会顺序执行下一条（return、athrow、goto、switch、ret）
+	subroutine 表示jsr：压入的返回地址只在子程序里，子程序ret回到下一条指令时已经被astore弹出了
+ */
+type stackEffect struct {
+	length     int
+	pop        int
+	push       string
+	targets    []int
+	terminal   bool
+	subroutine bool
+}
+
+func (self stackEffect) pushed(popped string) string {
+	pushed := []byte(self.push)
+	for i, c := range pushed {
+		if c >= '0' && c <= '9' {
+			pushed[i] = popped[c - '0']
+		}
+	}
+	return string(pushed)
+}
+
+/**
+	没有操作数、栈变化固定的指令，下标是opcode，值是 {弹出的slot数, 压入的slot类型}
+ */
+var simpleStackEffects = map[byte]struct {
+	pop  int
+	push string
+}{
+	0x00: {0, ""}, 0x01: {0, "L"},
+	0x02: {0, "I"}, 0x03: {0, "I"}, 0x04: {0, "I"}, 0x05: {0, "I"}, 0x06: {0, "I"}, 0x07: {0, "I"}, 0x08: {0, "I"},
+	0x09: {0, "JT"}, 0x0a: {0, "JT"},
+	0x0b: {0, "F"}, 0x0c: {0, "F"}, 0x0d: {0, "F"},
+	0x0e: {0, "DT"}, 0x0f: {0, "DT"},
+	// xload_n
+	0x1a: {0, "I"}, 0x1b: {0, "I"}, 0x1c: {0, "I"}, 0x1d: {0, "I"},
+	0x1e: {0, "JT"}, 0x1f: {0, "JT"}, 0x20: {0, "JT"}, 0x21: {0, "JT"},
+	0x22: {0, "F"}, 0x23: {0, "F"}, 0x24: {0, "F"}, 0x25: {0, "F"},
+	0x26: {0, "DT"}, 0x27: {0, "DT"}, 0x28: {0, "DT"}, 0x29: {0, "DT"},
+	0x2a: {0, "L"}, 0x2b: {0, "L"}, 0x2c: {0, "L"}, 0x2d: {0, "L"},
+	// xaload
+	0x2e: {2, "I"}, 0x2f: {2, "JT"}, 0x30: {2, "F"}, 0x31: {2, "DT"}, 0x32: {2, "L"}, 0x33: {2, "I"}, 0x34: {2, "I"}, 0x35: {2, "I"},
+	// xstore_n
+	0x3b: {1, ""}, 0x3c: {1, ""}, 0x3d: {1, ""}, 0x3e: {1, ""},
+	0x3f: {2, ""}, 0x40: {2, ""}, 0x41: {2, ""}, 0x42: {2, ""},
+	0x43: {1, ""}, 0x44: {1, ""}, 0x45: {1, ""}, 0x46: {1, ""},
+	0x47: {2, ""}, 0x48: {2, ""}, 0x49: {2, ""}, 0x4a: {2, ""},
+	0x4b: {1, ""}, 0x4c: {1, ""}, 0x4d: {1, ""}, 0x4e: {1, ""},
+	// xastore
+	0x4f: {3, ""}, 0x50: {4, ""}, 0x51: {3, ""}, 0x52: {4, ""}, 0x53: {3, ""}, 0x54: {3, ""}, 0x55: {3, ""}, 0x56: {3, ""},
+	// pop dup swap
+	0x57: {1, ""}, 0x58: {2, ""},
+	0x59: {1, "00"}, 0x5a: {2, "101"}, 0x5b: {3, "2012"}, 0x5c: {2, "0101"}, 0x5d: {3, "12012"}, 0x5e: {4, "230123"}, 0x5f: {2, "10"},
+	// add sub mul div rem
+	0x60: {2, "I"}, 0x61: {4, "JT"}, 0x62: {2, "F"}, 0x63: {4, "DT"},
+	0x64: {2, "I"}, 0x65: {4, "JT"}, 0x66: {2, "F"}, 0x67: {4, "DT"},
+	0x68: {2, "I"}, 0x69: {4, "JT"}, 0x6a: {2, "F"}, 0x6b: {4, "DT"},
+	0x6c: {2, "I"}, 0x6d: {4, "JT"}, 0x6e: {2, "F"}, 0x6f: {4, "DT"},
+	0x70: {2, "I"}, 0x71: {4, "JT"}, 0x72: {2, "F"}, 0x73: {4, "DT"},
+	// neg
+	0x74: {1, "I"}, 0x75: {2, "JT"}, 0x76: {1, "F"}, 0x77: {2, "DT"},
+	// shift
+	0x78: {2, "I"}, 0x79: {3, "JT"}, 0x7a: {2, "I"}, 0x7b: {3, "JT"}, 0x7c: {2, "I"}, 0x7d: {3, "JT"},
+	// and or xor
+	0x7e: {2, "I"}, 0x7f: {4, "JT"}, 0x80: {2, "I"}, 0x81: {4, "JT"}, 0x82: {2, "I"}, 0x83: {4, "JT"},
+	// conversions
+	0x85: {1, "JT"}, 0x86: {1, "F"}, 0x87: {1, "DT"},
+	0x88: {2, "I"}, 0x89: {2, "F"}, 0x8a: {2, "DT"},
+	0x8b: {1, "I"}, 0x8c: {1, "JT"}, 0x8d: {1, "DT"},
+	0x8e: {2, "I"}, 0x8f: {2, "JT"}, 0x90: {2, "F"},
+	0x91: {1, "I"}, 0x92: {1, "I"}, 0x93: {1, "I"},
+	// comparisons
+	0x94: {4, "I"}, 0x95: {2, "I"}, 0x96: {2, "I"}, 0x97: {4, "I"}, 0x98: {4, "I"},
+	// arraylength monitorenter monitorexit
+	0xbe: {1, "I"}, 0xc2: {1, ""}, 0xc3: {1, ""},
+}
+
+/**
+	xload/xstore 的局部变量类型，0x15~0x19 和 0x36~0x3a，对应 I J F D L
+ */
+func localSlotTypes(opcode byte) string {
+	return [...]string{"I", "JT", "F", "DT", "L"}[(opcode - 0x15) % 0x21]
+}
+
+func (self *stackVerifier) effectOf(pc int) (stackEffect, error) {
+	opcode := self.code[pc]
+	if e, ok := simpleStackEffects[opcode]; ok {
+		return stackEffect{length: 1, pop: e.pop, push: e.push}, nil
+	}
+
+	switch {
+	case opcode >= 0x15 && opcode <= 0x19: // xload
+		return stackEffect{length: 2, push: localSlotTypes(opcode)}, self.need(pc, 2)
+	case opcode >= 0x36 && opcode <= 0x3a: // xstore
+		return stackEffect{length: 2, pop: len(localSlotTypes(opcode))}, self.need(pc, 2)
+	case opcode >= 0x99 && opcode <= 0x9e, opcode == 0xc6, opcode == 0xc7: // ifxx ifnull ifnonnull
+		return self.branch(pc, 1, "", false)
+	case opcode >= 0x9f && opcode <= 0xa6: // if_icmpxx if_acmpxx
+		return self.branch(pc, 2, "", false)
+	case opcode >= 0xac && opcode <= 0xaf: // ireturn lreturn freturn dreturn
+		return stackEffect{length: 1, pop: 1 + int(opcode - 0xac) % 2, terminal: true}, nil
+	}
+
+	switch opcode {
+	case 0x10: // bipush
+		return stackEffect{length: 2, push: "I"}, self.need(pc, 2)
+	case 0x11: // sipush
+		return stackEffect{length: 3, push: "I"}, self.need(pc, 3)
+	case 0x12, 0x13, 0x14: // ldc ldc_w ldc2_w
+		return self.ldc(pc)
+	case 0x84: // iinc
+		return stackEffect{length: 3}, self.need(pc, 3)
+	case 0xa7: // goto
+		return self.branch(pc, 0, "", true)
+	case 0xa8: // jsr 返回地址压栈之后跳到子程序，子程序ret之后从下一条指令继续
+		effect, err := self.branch(pc, 0, "R", false)
+		effect.subroutine = true
+		return effect, err
+	case 0xa9: // ret
+		return stackEffect{length: 2, terminal: true}, self.need(pc, 2)
+	case 0xaa:
+		return self.tableSwitch(pc)
+	case 0xab:
+		return self.lookupSwitch(pc)
+	case 0xb0: // areturn
+		return stackEffect{length: 1, pop: 1, terminal: true}, nil
+	case 0xb1: // return
+		return stackEffect{length: 1, terminal: true}, nil
+	case 0xb2, 0xb3, 0xb4, 0xb5:
+		return self.fieldAccess(pc)
+	case 0xb6, 0xb7, 0xb8, 0xb9, 0xba:
+		return self.invoke(pc)
+	case 0xbb: // new
+		return stackEffect{length: 3, push: "L"}, self.need(pc, 3)
+	case 0xbc: // newarray
+		return stackEffect{length: 2, pop: 1, push: "L"}, self.need(pc, 2)
+	case 0xbd, 0xc0: // anewarray checkcast
+		return stackEffect{length: 3, pop: 1, push: "L"}, self.need(pc, 3)
+	case 0xc1: // instanceof
+		return stackEffect{length: 3, pop: 1, push: "I"}, self.need(pc, 3)
+	case 0xbf: // athrow
+		return stackEffect{length: 1, pop: 1, terminal: true}, nil
+	case 0xc4:
+		return self.wide(pc)
+	case 0xc5: // multianewarray
+		if err := self.need(pc, 4); err != nil {
+			return stackEffect{}, err
+		}
+		return stackEffect{length: 4, pop: int(self.code[pc + 3]), push: "L"}, nil
+	case 0xc8: // goto_w
+		return self.branchW(pc, "", true)
+	case 0xc9: // jsr_w
+		effect, err := self.branchW(pc, "R", false)
+		effect.subroutine = true
+		return effect, err
+	}
+	return stackEffect{}, fmt.Errorf("unsupported opcode")
+}
+
+/**
+	检查pc开始的n个字节都在字节码之内
+ */
+func (self *stackVerifier) need(pc, n int) error {
+	if pc + n > len(self.code) {
+		return fmt.Errorf("truncated instruction")
+	}
+	return nil
+}
+
+func (self *stackVerifier) u16(pc int) int {
+	return int(self.code[pc]) << 8 | int(self.code[pc + 1])
+}
+
+func (self *stackVerifier) s32(pc int) int {
+	return int(int32(uint32(self.code[pc]) << 24 | uint32(self.code[pc + 1]) << 16 |
+		uint32(self.code[pc + 2]) << 8 | uint32(self.code[pc + 3])))
+}
+
+func (self *stackVerifier) branch(pc, pop int, push string, terminal bool) (stackEffect, error) {
+	if err := self.need(pc, 3); err != nil {
+		return stackEffect{}, err
+	}
+	offset := int(int16(self.u16(pc + 1)))
+	return stackEffect{length: 3, pop: pop, push: push, targets: []int{pc + offset}, terminal: terminal}, nil
+}
+
+func (self *stackVerifier) branchW(pc int, push string, terminal bool) (stackEffect, error) {
+	if err := self.need(pc, 5); err != nil {
+		return stackEffect{}, err
+	}
+	offset := self.s32(pc + 1)
+	return stackEffect{length: 5, push: push, targets: []int{pc + offset}, terminal: terminal}, nil
+}
+
+/**
+	switch的操作数从4字节对齐的位置开始
+ */
+func (self *stackVerifier) tableSwitch(pc int) (stackEffect, error) {
+	p := (pc + 4) &^ 3
+	if err := self.need(p, 12); err != nil {
+		return stackEffect{}, err
+	}
+	low, high := self.s32(p + 4), self.s32(p + 8)
+	if low > high {
+		return stackEffect{}, fmt.Errorf("tableswitch low %d > high %d", low, high)
+	}
+	n := high - low + 1
+	if err := self.need(p + 12, n * 4); err != nil {
+		return stackEffect{}, err
+	}
+	targets := []int{pc + self.s32(p)}
+	for i := 0; i < n; i++ {
+		targets = append(targets, pc + self.s32(p + 12 + i * 4))
+	}
+	return stackEffect{length: p + 12 + n * 4 - pc, pop: 1, targets: targets, terminal: true}, nil
+}
+
+func (self *stackVerifier) lookupSwitch(pc int) (stackEffect, error) {
+	p := (pc + 4) &^ 3
+	if err := self.need(p, 8); err != nil {
+		return stackEffect{}, err
+	}
+	npairs := self.s32(p + 4)
+	if npairs < 0 {
+		return stackEffect{}, fmt.Errorf("lookupswitch npairs %d < 0", npairs)
+	}
+	if err := self.need(p + 8, npairs * 8); err != nil {
+		return stackEffect{}, err
+	}
+	targets := []int{pc + self.s32(p)}
+	for i := 0; i < npairs; i++ {
+		targets = append(targets, pc + self.s32(p + 8 + i * 8 + 4))
+	}
+	return stackEffect{length: p + 8 + npairs * 8 - pc, pop: 1, targets: targets, terminal: true}, nil
+}
+
+func (self *stackVerifier) wide(pc int) (stackEffect, error) {
+	if err := self.need(pc, 2); err != nil {
+		return stackEffect{}, err
+	}
+	opcode := self.code[pc + 1]
+	switch {
+	case opcode >= 0x15 && opcode <= 0x19:
+		return stackEffect{length: 4, push: localSlotTypes(opcode)}, self.need(pc, 4)
+	case opcode >= 0x36 && opcode <= 0x3a:
+		return stackEffect{length: 4, pop: len(localSlotTypes(opcode))}, self.need(pc, 4)
+	case opcode == 0x84:
+		return stackEffect{length: 6}, self.need(pc, 6)
+	case opcode == 0xa9:
+		return stackEffect{length: 4, terminal: true}, self.need(pc, 4)
+	}
+	return stackEffect{}, fmt.Errorf("bad wide opcode 0x%02x", opcode)
+}
+
+/**
+	常量的类型决定压入的slot：int float String Class MethodHandle MethodType，ldc2_w是long double
+ */
+func (self *stackVerifier) ldc(pc int) (stackEffect, error) {
+	length, index := 3, 0
+	if err := self.need(pc, 2); err != nil {
+		return stackEffect{}, err
+	}
+	if self.code[pc] == 0x12 {
+		length, index = 2, int(self.code[pc + 1])
+	} else if err := self.need(pc, 3); err != nil {
+		return stackEffect{}, err
+	} else {
+		index = self.u16(pc + 1)
+	}
+
+	push := ""
+	switch self.constant(index).(type) {
+	case int32:
+		push = "I"
+	case float32:
+		push = "F"
+	case string, *ClassRef, *MethodHandleRef, *MethodTypeRef:
+		push = "L"
+	case int64:
+		push = "JT"
+	case float64:
+		push = "DT"
+	}
+	if push == "" || (len(push) == 2) != (self.code[pc] == 0x14) {
+		return stackEffect{}, fmt.Errorf("bad ldc constant #%d", index)
+	}
+	return stackEffect{length: length, push: push}, nil
+}
+
+/**
+	字段的描述符决定压入或者弹出的slot
+ */
+func (self *stackVerifier) fieldAccess(pc int) (stackEffect, error) {
+	if err := self.need(pc, 3); err != nil {
+		return stackEffect{}, err
+	}
+	ref, ok := self.constant(self.u16(pc + 1)).(*FieldRef)
+	if !ok {
+		return stackEffect{}, fmt.Errorf("bad field ref")
+	}
+	types := descriptorSlotTypes(ref.descriptor)
+	switch self.code[pc] {
+	case 0xb2: // getstatic
+		return stackEffect{length: 3, push: types}, nil
+	case 0xb3: // putstatic
+		return stackEffect{length: 3, pop: len(types)}, nil
+	case 0xb4: // getfield
+		return stackEffect{length: 3, pop: 1, push: types}, nil
+	default: // putfield
+		return stackEffect{length: 3, pop: 1 + len(types)}, nil
+	}
+}
+
+/**
+	方法的描述符决定弹出的参数slot数和压入的返回值，除了invokestatic和invokedynamic都还要弹出this
+ */
+func (self *stackVerifier) invoke(pc int) (stackEffect, error) {
+	opcode := self.code[pc]
+	length := 3
+	if opcode == 0xb9 || opcode == 0xba {
+		length = 5
+	}
+	if err := self.need(pc, length); err != nil {
+		return stackEffect{}, err
+	}
+
+	var descriptor string
+	switch ref := self.constant(self.u16(pc + 1)).(type) {
+	case *MethodRef:
+		descriptor = ref.descriptor
+	case *InterfaceMethodRef:
+		descriptor = ref.descriptor
+	case *InvokeDynamicRef:
+		descriptor = ref.descriptor
+	default:
+		return stackEffect{}, fmt.Errorf("bad method ref")
+	}
+
+	parsed := parseMethodDescriptor(descriptor)
+	pop := 0
+	for _, paramType := range parsed.parameterTypes {
+		pop += descriptorSlotSize(paramType)
+	}
+	if opcode != 0xb8 && opcode != 0xba {
+		pop++
+	}
+	return stackEffect{length: length, pop: pop, push: descriptorSlotTypes(parsed.returnType)}, nil
+}
+
+/**
+	越界或者不存在的常量返回nil，不panic
+ */
+func (self *stackVerifier) constant(index int) Constant {
+	consts := self.method.class.constantPool.consts
+	if index <= 0 || index >= len(consts) {
+		return nil
+	}
+	return consts[index]
+}
+
+func descriptorSlotSize(descriptor string) int {
+	return len(descriptorSlotTypes(descriptor))
+}
+
+func descriptorSlotTypes(descriptor string) string {
+	switch descriptor[0] {
+	case 'V':
+		return ""
+	case 'J':
+		return "JT"
+	case 'D':
+		return "DT"
+	case 'F':
+		return "F"
+	case 'L', '[':
+		return "L"
+	}
+	return "I"
+}
@@ -0,0 +1,88 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"strings"
+	"testing"
+)
+
+/**
+	verify/Methods 里各种操作数栈有问题的方法
+ */
+func loadVerifierMethods(t *testing.T) *Class {
+	c := classgen.New("verify/Methods", "java/lang/Object")
+	static := uint16(classgen.ACC_PUBLIC | classgen.ACC_STATIC)
+	c.Method(static, "ok", "(I)I").Code(2, 1).
+		Op(classgen.ILOAD_0).Branch(classgen.IFEQ, "zero").
+		Op(classgen.ICONST_1).Op(classgen.IRETURN).
+		Label("zero").Op(classgen.ICONST_0).Op(classgen.IRETURN)
+	c.Method(static, "overflow", "()V").Code(1, 0).
+		Op(classgen.ICONST_1).Op(classgen.ICONST_1).Op(classgen.POP2).Op(classgen.RETURN)
+	c.Method(static, "underflow", "()V").Code(1, 0).
+		Op(classgen.POP).Op(classgen.RETURN)
+	//两条路径到达join时栈深度不同
+	c.Method(static, "inconsistent", "(I)V").Code(2, 1).
+		Op(classgen.ILOAD_0).Branch(classgen.IFEQ, "join").
+		Op(classgen.ICONST_1).
+		Label("join").Op(classgen.RETURN)
+	//两条路径到达join时栈深度相同，类型不同
+	c.Method(static, "mixed", "(I)V").Code(1, 1).
+		Op(classgen.ILOAD_0).Branch(classgen.IFEQ, "float").
+		Op(classgen.ICONST_1).Branch(classgen.GOTO, "join").
+		Label("float").Op(classgen.FCONST_1).
+		Label("join").Op(classgen.POP).Op(classgen.RETURN)
+	c.Method(static, "fallOff", "()V").Code(1, 0).Op(classgen.NOP)
+	return newTestLoader(t, c).LoadClass("verify/Methods")
+}
+
+func TestVerifyMethodReportsPcAndOpcode(t *testing.T) {
+	class := loadVerifierMethods(t)
+	for _, test := range []struct{ name, descriptor, want string }{
+		{"overflow", "()V", "stack overflow at pc 1 (opcode 0x04), max stack 1"},
+		{"underflow", "()V", "stack underflow at pc 0 (opcode 0x57)"},
+		{"inconsistent", "(I)V", "inconsistent stack height 0 != 1 at pc 5"},
+		{"mixed", "(I)V", "inconsistent stack types [I] != [F] at pc 9"},
+		{"fallOff", "()V", "falling off the end of code at pc 0 (opcode 0x00)"},
+	} {
+		err := VerifyMethod(class.GetStaticMethod(test.name, test.descriptor))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: err = %v, want %q", test.name, err, test.want)
+		}
+	}
+	if err := VerifyMethod(class.GetStaticMethod("ok", "(I)I")); err != nil {
+		t.Errorf("ok: %v", err)
+	}
+}
+
+func ensureVerified(method *Method) (r interface{}) {
+	defer func() {
+		r = recover()
+	}()
+	method.EnsureVerified()
+	return nil
+}
+
+func TestEnsureVerifiedRunsWithoutXverifyAll(t *testing.T) {
+	//测试用的加载器没有打开 -Xverify:all
+	class := loadVerifierMethods(t)
+	r := ensureVerified(class.GetStaticMethod("underflow", "()V"))
+	if msg, _ := r.(string); !strings.HasPrefix(msg, "java.lang.VerifyError: (class: verify/Methods, method: underflow") {
+		t.Fatalf("EnsureVerified panicked with %v, want a VerifyError", r)
+	}
+
+	ok := class.GetStaticMethod("ok", "(I)I")
+	if r := ensureVerified(ok); r != nil || !ok.verified {
+		t.Fatalf("ok: panic %v, verified %v", r, ok.verified)
+	}
+}
+
+func TestDisableMethodVerification(t *testing.T) {
+	DisableMethodVerification()
+	t.Cleanup(func() {
+		verifyMethods = true
+	})
+	class := loadVerifierMethods(t)
+	if r := ensureVerified(class.GetStaticMethod("overflow", "()V")); r != nil {
+		t.Fatalf("-Xverify:none still verified: %v", r)
+	}
+}
@@ -0,0 +1,25 @@
+package heap
+
+/**
+	类名、字段名、方法名和描述符的字符串池
+	很多类的常量池里都有"java/lang/Object"、"()V"这样的字符串，解析class文件时
+	每个类都会得到一份自己的拷贝。统一放到这里之后，相同的名字共用同一块内存
+	这是一个全局的map，所有加载器共用，只增不减，没有上限：虚拟机不会卸载类，名字一直有人引用，
+	所以进程退出之前池里的字符串也不会释放。加载的类越多，池越大
+ */
+var internedNames = map[string]string{}
+
+func internName(name string) string {
+	if interned, ok := internedNames[name]; ok {
+		return interned
+	}
+	internedNames[name] = name
+	return name
+}
+
+func internNames(names []string) []string {
+	for i, name := range names {
+		names[i] = internName(name)
+	}
+	return names
+}
@@ -0,0 +1,93 @@
+package heap
+
+import (
+	"GoVM/chapter2-class/classpath"
+	"GoVM/internal/classgen"
+	"GoVM/internal/testjdk"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestClassNamesShareInternedString(t *testing.T) {
+	loader := newTestLoader(t,
+		classgen.New("names/A", "java/lang/Object").DefaultConstructor(),
+		classgen.New("names/B", "java/lang/Object").DefaultConstructor())
+	a := loader.LoadClass("names/A")
+	b := loader.LoadClass("names/B")
+
+	entry, ok := internedNames["java/lang/Object"]
+	if !ok {
+		t.Fatal(`"java/lang/Object" is not in the name pool`)
+	}
+	//两个类各自解析出来的超类名，底层是池里同一个字符串
+	for _, class := range []*Class{a, b} {
+		if unsafe.StringData(class.superClassName) != unsafe.StringData(entry) {
+			t.Errorf("%s: superClassName is not the pooled string", class.name)
+		}
+	}
+	if unsafe.StringData(a.superClass.name) != unsafe.StringData(entry) {
+		t.Error("java/lang/Object's own name is not the pooled string")
+	}
+}
+
+/**
+	一个有classCount个类的jar，每个类都引用同样的一批名字和描述符
+ */
+func writeLargeJar(b *testing.B, classCount int) string {
+	classes := make([]*classgen.Class, classCount)
+	for i := range classes {
+		c := classgen.New(fmt.Sprintf("large/C%04d", i), "java/lang/Object", "java/io/Serializable")
+		c.Field(classgen.ACC_PRIVATE, "name", "Ljava/lang/String;")
+		c.Field(classgen.ACC_PRIVATE, "count", "I")
+		c.DefaultConstructor()
+		for _, m := range []string{"toString", "hashCode", "getName", "setName", "run"} {
+			c.Method(classgen.ACC_PUBLIC, m, "(Ljava/lang/String;)Ljava/lang/String;").Code(1, 2).
+				Op(classgen.ALOAD_0).Getfield(c.Name(), "name", "Ljava/lang/String;").
+				Invokevirtual("java/lang/Object", "toString", "()Ljava/lang/String;").Op(classgen.ARETURN)
+		}
+		classes[i] = c
+	}
+	dir := b.TempDir()
+	jar := filepath.Join(dir, "large.jar")
+	if err := os.WriteFile(jar, testjdk.Jar(classes), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return jar
+}
+
+func liveHeap() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+/**
+	加载一个2000个类的jar之后还活着的内存，每个类平均多少字节，以及名字池的大小
+ */
+func BenchmarkLoadLargeJar(b *testing.B) {
+	const classCount = 2000
+	jar := writeLargeJar(b, classCount)
+	boot := testBootLoader(b)
+	cp := classpath.Parse(testjdk.JRE(b), jar)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		before := liveHeap()
+		loader := NewClassLoader(boot, cp, false, false)
+		for j := 0; j < classCount; j++ {
+			loader.LoadClass(fmt.Sprintf("large/C%04d", j))
+		}
+		b.ReportMetric(float64(liveHeap()-before)/classCount, "live-B/class")
+		runtime.KeepAlive(loader)
+	}
+	poolBytes := 0
+	for name := range internedNames {
+		poolBytes += len(name)
+	}
+	b.ReportMetric(float64(len(internedNames)), "pooled-names")
+	b.ReportMetric(float64(poolBytes), "pool-B")
+}
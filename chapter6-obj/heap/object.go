@@ -6,15 +6,31 @@ type Object struct {
 	/**
 		用来记录Object结构体的额外信息:
 			1.某个类的对象 对应的 Class结构体指针，这里的这个Class是JVM方法区中的Class结构体 -> heap.Class
+			2.本地方法需要挂在Java对象上的Go数据，比如 *os.File 等
+		extra 只是Go这一侧的数据，不是Java引用，将来做对象追踪时不能沿着它往下找
 	 */
 	extra interface{}
+	//monitorenter 的重入次数，虚拟机目前只有一个线程，不需要真正的锁
+	monitorCount uint
+	//GC标记阶段能从根到达
+	gcMark bool
+	//在新生代里熬过的minor GC次数，以及是否已经晋升到老年代，见gc_young.go
+	gcAge  uint8
+	old    bool
+	//老年代对象在记忆集里，它可能引用着新生代的对象
+	remembered bool
+	//identity hash，第一次用到时才生成，0表示还没有生成
+	hash   int32
 }
 
 func newObject(class *Class) *Object {
-	return &Object{
+	jvmHeap.alloc(instanceSize(class.InstanceSlotCount))
+	obj := &Object{
 		class:        class,
 		data: NewSlots(class.InstanceSlotCount),
 	}
+	jvmHeap.track(obj)
+	return obj
 }
 
 func (self *Object) IsInstanceOf(class *Class) bool {
@@ -38,11 +54,47 @@ func (self *Object) SetExtra(extra interface{}) {
 }
 //getter end
 
+func (self *Object) EnterMonitor() {
+	self.monitorCount++
+}
+
+/**
+	没有持有锁就执行monitorexit，返回false
+ */
+func (self *Object) ExitMonitor() bool {
+	if self.monitorCount == 0 {
+		return false
+	}
+	self.monitorCount--
+	return true
+}
+
+func (self *Object) MonitorCount() uint {
+	return self.monitorCount
+}
+
+//已经生成过的identity hash的个数
+var identityHashCount uint32
+
+/**
+	Object.hashCode() 和 System.identityHashCode() 的值。Go的指针不能保证不变，而且会把地址暴露出来，
+	所以用一个递增的计数器，乘上黄金分割数把位打散（乘奇数是一一映射，不同对象的hash不会重复，
+	直到计数器绕回来），生成之后存在对象上，对象活多久都不变。clone出来的是新对象，hash重新生成
+ */
+func (self *Object) IdentityHashCode() int32 {
+	for self.hash == 0 {
+		identityHashCount++
+		self.hash = int32(identityHashCount * 0x9e3779b9)
+	}
+	return self.hash
+}
+
 // reflection
 func (self *Object) SetRefVar(name, descriptor string, ref *Object) {
 	field := self.class.getField(name, descriptor, false)
 	slots := self.data.(Slots)
 	slots.SetRef(field.slotId, ref)
+	self.WriteBarrier(ref)
 }
 
 /**
@@ -52,4 +104,28 @@ func (self *Object) GetRefVar(name, descriptor string) *Object {
 	field := self.class.getField(name, descriptor, false)
 	slots := self.data.(Slots)
 	return slots.GetRef(field.slotId)
-}
\ No newline at end of file
+}
+func (self *Object) SetIntVar(name, descriptor string, val int32) {
+	field := self.class.getField(name, descriptor, false)
+	slots := self.data.(Slots)
+	slots.SetInt(field.slotId, val)
+}
+
+func (self *Object) GetIntVar(name, descriptor string) int32 {
+	field := self.class.getField(name, descriptor, false)
+	slots := self.data.(Slots)
+	return slots.GetInt(field.slotId)
+}
+
+/**
+	给本地方法抛异常用的，和其他本地方法创建的对象一样，不调用构造函数，直接给字段赋值。
+	Throwable.getOurStackTrace()在backtrace为null时不会去取栈信息，所以backtrace要给一个非null的值
+ */
+func NewException(class *Class, message string) *Object {
+	ex := class.NewObject()
+	if message != "" {
+		ex.SetRefVar("detailMessage", "Ljava/lang/String;", JString(class.loader, message))
+	}
+	ex.SetRefVar("backtrace", "Ljava/lang/Object;", ex)
+	return ex
+}
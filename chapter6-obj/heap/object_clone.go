@@ -1,10 +1,13 @@
 package heap
 
 func (self *Object) Clone() *Object {
-	return &Object{
+	jvmHeap.alloc(self.size())
+	obj := &Object{
 		class: self.class,
 		data:  self.cloneData(),
 	}
+	jvmHeap.track(obj)
+	return obj
 }
 
 func (self *Object) cloneData() interface{} {
@@ -0,0 +1,236 @@
+package heap
+
+import (
+	"GoVM/internal/classgen"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var primitiveDescriptors = []string{"Z", "B", "C", "S", "I", "J", "F", "D"}
+
+/**
+	每种类型一个实例字段和一个静态字段
+ */
+func defaultsClass() *classgen.Class {
+	class := classgen.New("defaults/All", "java/lang/Object")
+	for _, descriptor := range append(primitiveDescriptors, "Ljava/lang/Object;", "[I") {
+		class.Field(classgen.ACC_PUBLIC, "f" + descriptor, descriptor)
+		class.Field(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "s" + descriptor, descriptor)
+	}
+	return class
+}
+
+func checkDefault(t *testing.T, slots Slots, field *Field) {
+	t.Helper()
+	id := field.slotId
+	switch field.descriptor {
+	case "J":
+		if slots.GetLong(id) != 0 {
+			t.Errorf("%s = %d", field.name, slots.GetLong(id))
+		}
+	case "F":
+		if f := slots.GetFloat(id); f != 0 || math.Signbit(float64(f)) {
+			t.Errorf("%s = %v, want +0.0", field.name, f)
+		}
+	case "D":
+		if d := slots.GetDouble(id); d != 0 || math.Signbit(d) {
+			t.Errorf("%s = %v, want +0.0", field.name, d)
+		}
+	case "Ljava/lang/Object;", "[I":
+		if slots.GetRef(id) != nil {
+			t.Errorf("%s is not null", field.name)
+		}
+	default:
+		if slots.GetInt(id) != 0 {
+			t.Errorf("%s = %d", field.name, slots.GetInt(id))
+		}
+	}
+}
+
+func TestNewObjectFieldsStartAtDefaults(t *testing.T) {
+	class := newTestLoader(t, defaultsClass()).LoadClass("defaults/All")
+	obj := class.NewObject()
+	for _, field := range class.fields {
+		if field.IsStatic() {
+			checkDefault(t, class.staticVars, field)
+		} else {
+			checkDefault(t, obj.Fields(), field)
+		}
+	}
+}
+
+func TestNewArrayElementsStartAtDefaults(t *testing.T) {
+	loader := newTestLoader(t)
+	for _, descriptor := range append(primitiveDescriptors, "Ljava/lang/Object;", "[I") {
+		arr := loader.LoadClass("[" + descriptor).NewArray(5)
+		if arr.ArrayLength() != 5 {
+			t.Fatalf("new %s length = %d", arr.class.JavaName(), arr.ArrayLength())
+		}
+		//各种元素类型的切片都按Go的零值判断；float和double的零值是+0.0
+		elems := reflect.ValueOf(arr.data)
+		for i := 0; i < elems.Len(); i++ {
+			if elem := elems.Index(i); !elem.IsZero() {
+				t.Errorf("new %s[%d] = %v", arr.class.JavaName(), i, elem)
+			}
+		}
+	}
+}
+
+type nativeState struct {
+	buf  []byte
+	open bool
+}
+
+func TestExtraRoundTrip(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	obj := loader.LoadClass("gc/Node").NewObject()
+	if obj.Extra() != nil {
+		t.Fatal("a new object has extra data")
+	}
+
+	state := &nativeState{buf: []byte("abc"), open: true}
+	obj.SetExtra(state)
+	got, ok := obj.Extra().(*nativeState)
+	if !ok || got != state {
+		t.Fatalf("Extra() = %#v, want the stored *nativeState", obj.Extra())
+	}
+	//取出来的是同一个值，本地方法对它的修改下次还能看到
+	got.buf = append(got.buf, 'd')
+	if s := string(obj.Extra().(*nativeState).buf); s != "abcd" {
+		t.Errorf("buf = %q after the round trip", s)
+	}
+}
+
+/**
+	extra里就算放了一个Java对象，GC也不沿着它标记
+ */
+func TestGCDoesNotTraceExtra(t *testing.T) {
+	loader := newTestLoader(t, nodeClass())
+	node := loader.LoadClass("gc/Node")
+	heap := newTestHeap(t)
+
+	root := node.NewObject()
+	hidden := node.NewObject()
+	root.SetExtra(hidden)
+	other := node.NewObject()
+	other.SetExtra(&nativeState{})
+
+	heap.GC([]*Object{root, other})
+	live := liveSet(heap)
+	if !live[root] || !live[other] {
+		t.Fatal("a root was swept")
+	}
+	if live[hidden] {
+		t.Error("the object referenced only from extra survived")
+	}
+	if root.Extra() != hidden {
+		t.Error("GC changed extra")
+	}
+}
+
+/**
+	class Base { int i; double a; static long s1; }
+	class Wide extends Base { double b; long l; int j; static double s2; static int s3; }
+	long和double各占两个slot，布局时不能和下一个字段重叠
+ */
+func wideClasses() []*classgen.Class {
+	base := classgen.New("layout/Base", "java/lang/Object")
+	base.Field(classgen.ACC_PUBLIC, "i", "I")
+	base.Field(classgen.ACC_PUBLIC, "a", "D")
+	base.Field(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "s1", "J")
+	wide := classgen.New("layout/Wide", "layout/Base")
+	wide.Field(classgen.ACC_PUBLIC, "b", "D")
+	wide.Field(classgen.ACC_PUBLIC, "l", "J")
+	wide.Field(classgen.ACC_PUBLIC, "j", "I")
+	wide.Field(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "s2", "D")
+	wide.Field(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "s3", "I")
+	return []*classgen.Class{base, wide}
+}
+
+func TestTwoSlotFieldsDoNotOverlap(t *testing.T) {
+	wide := newTestLoader(t, wideClasses()...).LoadClass("layout/Wide")
+	base := wide.superClass
+	obj := wide.NewObject()
+	slots := obj.Fields()
+
+	//每个字段按宽度占住自己的slot，实例字段正好用掉InstanceSlotCount个
+	instanceUsed := make([]string, wide.InstanceSlotCount)
+	for _, class := range []*Class{base, wide} {
+		staticUsed := make([]string, class.staticSlotCount)
+		for _, field := range class.fields {
+			used := instanceUsed
+			if field.IsStatic() {
+				used = staticUsed
+			}
+			for i := uint(0); i < uint(descriptorSlotSize(field.descriptor)); i++ {
+				id := field.slotId + i
+				if id >= uint(len(used)) {
+					t.Fatalf("%s.%s at slot %d is past the slot count %d", class.name, field.name, id, len(used))
+				}
+				if used[id] != "" {
+					t.Errorf("%s.%s and %s share slot %d", class.name, field.name, used[id], id)
+				}
+				used[id] = field.name
+			}
+		}
+	}
+
+	i, a := base.getField("i", "I", false), base.getField("a", "D", false)
+	b, l, j := wide.getField("b", "D", false), wide.getField("l", "J", false), wide.getField("j", "I", false)
+	if b.slotId != a.slotId + 2 {
+		t.Errorf("adjacent doubles at slots %d and %d, want N and N+2", a.slotId, b.slotId)
+	}
+
+	//每个字段的值独立往返，写一个不影响相邻的
+	const da, db = math.MaxFloat64, -math.SmallestNonzeroFloat64
+	slots.SetInt(i.slotId, -1)
+	slots.SetDouble(a.slotId, da)
+	slots.SetDouble(b.slotId, db)
+	slots.SetLong(l.slotId, math.MinInt64 + 1)
+	slots.SetInt(j.slotId, 0x7fff0000)
+	if got := slots.GetDouble(a.slotId); got != da {
+		t.Errorf("a = %v, want %v", got, da)
+	}
+	if got := slots.GetDouble(b.slotId); got != db {
+		t.Errorf("b = %v, want %v", got, db)
+	}
+	if got := slots.GetLong(l.slotId); got != math.MinInt64 + 1 {
+		t.Errorf("l = %d", got)
+	}
+	if slots.GetInt(i.slotId) != -1 || slots.GetInt(j.slotId) != 0x7fff0000 {
+		t.Error("an int field next to a two-slot field was overwritten")
+	}
+
+	s2, s3 := wide.getField("s2", "D", true), wide.getField("s3", "I", true)
+	wide.staticVars.SetDouble(s2.slotId, da)
+	wide.staticVars.SetInt(s3.slotId, 3)
+	if wide.staticVars.GetDouble(s2.slotId) != da {
+		t.Error("static double s2 was overwritten by s3")
+	}
+}
+
+func TestIdentityHashCodeIsLazyAndStable(t *testing.T) {
+	object := newTestLoader(t).LoadClass("java/lang/Object")
+	first := object.NewObject()
+	if first.hash != 0 {
+		t.Fatal("identity hash was generated at allocation")
+	}
+	hash := first.IdentityHashCode()
+	if hash == 0 || first.IdentityHashCode() != hash {
+		t.Fatalf("IdentityHashCode() = %#x, then %#x", hash, first.IdentityHashCode())
+	}
+
+	seen := map[int32]bool{hash: true}
+	for i := 0; i < 1000; i++ {
+		h := object.NewObject().IdentityHashCode()
+		if seen[h] {
+			t.Fatalf("hash %#x was given to two objects", h)
+		}
+		seen[h] = true
+	}
+	//clone出来的对象有自己的hash，原对象的不变
+	if clone := first.Clone(); clone.IdentityHashCode() == hash || first.IdentityHashCode() != hash {
+		t.Error("clone shares the identity hash of the original")
+	}
+}
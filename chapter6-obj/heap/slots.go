@@ -12,6 +12,11 @@ type Slot struct {
 
 type Slots []Slot
 
+/**
+	make出来的Slot全部是零值：Num为0，Ref为nil
+	对应到java里就是 int 0、long 0L、float 0.0f、double 0.0、boolean false、char '\u0000'、引用 null
+	（float和double按位存放，全0的位模式正好是+0.0）
+ */
 func NewSlots(slotCount uint) Slots {
 	if slotCount > 0 {
 		return make([]Slot, slotCount)
@@ -19,6 +24,20 @@ func NewSlots(slotCount uint) Slots {
 	return nil
 }
 
+/**
+	把src[srcStart:srcStart+count]拷贝到self[dstStart:]，Num和Ref一起拷贝
+ */
+func (self Slots) CopyFrom(dstStart uint, src Slots, srcStart, count uint) {
+	copy(self[dstStart:dstStart + count], src[srcStart:srcStart + count])
+}
+
+/**
+	返回self[start:start+count]，和原来的Slots共用底层数组
+ */
+func (self Slots) Slice(start, count uint) Slots {
+	return self[start:start + count]
+}
+
 func (self Slots) SetInt(index uint, val int32) {
 	self[index].Num = val
 }
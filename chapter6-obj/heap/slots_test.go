@@ -0,0 +1,53 @@
+package heap
+
+import "testing"
+
+/**
+	int、ref、long（两个slot）、double（两个slot）、ref 混在一起
+ */
+func mixedSlots(a, b *Object) Slots {
+	slots := NewSlots(7)
+	slots.SetInt(0, -7)
+	slots.SetRef(1, a)
+	slots.SetLong(2, -1 << 40)
+	slots.SetDouble(4, 2.5)
+	slots.SetRef(6, b)
+	return slots
+}
+
+func TestCopyFromKeepsRefsAndPrimitives(t *testing.T) {
+	a, b := &Object{}, &Object{}
+	dst := NewSlots(9)
+	dst.SetRef(0, b)
+	dst.CopyFrom(2, mixedSlots(a, b), 0, 7)
+
+	if dst.GetRef(0) != b || dst.GetRef(1) != nil {
+		t.Error("slots outside the copied range changed")
+	}
+	if got := dst.GetInt(2); got != -7 {
+		t.Errorf("int = %d, want -7", got)
+	}
+	if dst.GetRef(3) != a || dst.GetRef(8) != b {
+		t.Error("refs were not copied")
+	}
+	if got := dst.GetLong(4); got != -1 << 40 {
+		t.Errorf("long = %d, want %d", got, int64(-1) << 40)
+	}
+	if got := dst.GetDouble(6); got != 2.5 {
+		t.Errorf("double = %v, want 2.5", got)
+	}
+}
+
+func TestSliceSharesTheSlots(t *testing.T) {
+	a, b := &Object{}, &Object{}
+	slots := mixedSlots(a, b)
+	slice := slots.Slice(1, 3)
+
+	if len(slice) != 3 || slice.GetRef(0) != a || slice.GetLong(1) != -1 << 40 {
+		t.Fatalf("Slice(1, 3) = %v", slice)
+	}
+	slice.SetRef(0, b)
+	if slots.GetRef(1) != b {
+		t.Error("writing through the slice did not change the original slots")
+	}
+}
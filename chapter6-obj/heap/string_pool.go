@@ -13,19 +13,37 @@ func JString(loader *ClassLoader, goStr string) *Object {
 		return internedStrings
 	}
 
-	chars := stringToUtf16(goStr)
-	jChars := &Object{
-		class :        loader.LoadClass("[C"),
-		data :        chars,
-	}
+	jStr := NewJString(loader, stringToUtf16(goStr))
+	internedStrings[goStr] = jStr
+	return jStr
+}
 
+/**
+	直接用utf16字符创建一个java字符串，不放入字符串池，比如substring的结果
+ */
+func NewJString(loader *ClassLoader, chars []uint16) *Object {
 	jStr := loader.LoadClass("java/lang/String").NewObject()
-	jStr.SetRefVar("value", "[C", jChars)
-
-	internedStrings[goStr] = jStr
+	jStr.SetRefVar("value", "[C", newJChars(loader, chars))
 	return jStr
 }
 
+/**
+	go字符串 -> java字符数组，比如Thread的name字段就是char[]
+ */
+func JChars(loader *ClassLoader, goStr string) *Object {
+	return newJChars(loader, stringToUtf16(goStr))
+}
+
+func newJChars(loader *ClassLoader, chars []uint16) *Object {
+	jvmHeap.alloc(arraySize("[C", uint(len(chars))))
+	obj := &Object{
+		class :        loader.LoadClass("[C"),
+		data :        chars,
+	}
+	jvmHeap.track(obj)
+	return obj
+}
+
 /**
 	intern方法
  */
@@ -50,6 +68,11 @@ func GoString(jStr *Object) string {
 	return utf16ToString(charArr.Chars())
 }
 
+// char[] -> go string
+func GoStringFromChars(jChars *Object) string {
+	return utf16ToString(jChars.Chars())
+}
+
 // utf16 -> utf8
 func utf16ToString(s []uint16) string {
 	runes := utf16.Decode(s) // func Decode(s []uint16) []rune
@@ -0,0 +1,34 @@
+package heap
+
+import "testing"
+
+func TestJStringIsInterned(t *testing.T) {
+	loader := testBootLoader(t)
+	//字符串池是全局的，用别的测试不会用到的字符串
+	const s = "intern 测试 \U0001F600"
+	jStr := JString(loader, s)
+	if JString(loader, s) != jStr {
+		t.Error("JString returned two objects for one string")
+	}
+	if got := GoString(jStr); got != s {
+		t.Errorf("GoString(JString(%q)) = %q", s, got)
+	}
+	//代理对占两个char
+	if n := len(jStr.GetRefVar("value", "[C").Chars()); n != len([]rune(s)) + 1 {
+		t.Errorf("value has %d chars, want %d", n, len([]rune(s)) + 1)
+	}
+
+	//NewJString不放进池里，intern之后才得到池里的那个
+	copied := NewJString(loader, stringToUtf16(s))
+	if copied == jStr {
+		t.Fatal("NewJString returned the interned string")
+	}
+	if InternString(copied) != jStr {
+		t.Error("intern() of an equal string did not return the pooled one")
+	}
+	const fresh = "intern 测试 fresh"
+	first := NewJString(loader, stringToUtf16(fresh))
+	if InternString(first) != first || JString(loader, fresh) != first {
+		t.Error("the first interned string did not become the pooled one")
+	}
+}
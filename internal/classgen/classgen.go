@@ -0,0 +1,386 @@
+/**
+	测试用的class文件生成器。沙箱里没有javac，也没有JDK，测试用到的类（包括一个最小的java.lang）都用它拼出来
+	只管按jvms 4的格式写字节，不做任何检查，所以也能用来构造格式错误的class文件
+ */
+package classgen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+const (
+	ACC_PUBLIC       = 0x0001
+	ACC_PRIVATE      = 0x0002
+	ACC_PROTECTED    = 0x0004
+	ACC_STATIC       = 0x0008
+	ACC_FINAL        = 0x0010
+	ACC_SUPER        = 0x0020
+	ACC_SYNCHRONIZED = 0x0020
+	ACC_VOLATILE     = 0x0040
+	ACC_BRIDGE       = 0x0040
+	ACC_TRANSIENT    = 0x0080
+	ACC_VARARGS      = 0x0080
+	ACC_NATIVE       = 0x0100
+	ACC_INTERFACE    = 0x0200
+	ACC_ABSTRACT     = 0x0400
+	ACC_STRICT       = 0x0800
+	ACC_SYNTHETIC    = 0x1000
+	ACC_ANNOTATION   = 0x2000
+	ACC_ENUM         = 0x4000
+	ACC_MODULE       = 0x8000
+)
+
+// 常量池的tag
+const (
+	CONSTANT_Utf8               = 1
+	CONSTANT_Integer            = 3
+	CONSTANT_Float              = 4
+	CONSTANT_Long               = 5
+	CONSTANT_Double             = 6
+	CONSTANT_Class              = 7
+	CONSTANT_String             = 8
+	CONSTANT_Fieldref           = 9
+	CONSTANT_Methodref          = 10
+	CONSTANT_InterfaceMethodref = 11
+	CONSTANT_NameAndType        = 12
+	CONSTANT_MethodHandle       = 15
+	CONSTANT_MethodType         = 16
+	CONSTANT_InvokeDynamic      = 18
+	CONSTANT_Module             = 19
+	CONSTANT_Package            = 20
+)
+
+type Class struct {
+	accessFlags  uint16
+	name         string
+	superName    string
+	interfaces   []string
+	majorVersion uint16
+	fields       []*Member
+	methods      []*Member
+	attributes   []attribute
+	//BootstrapMethods属性，有内容时才写
+	bootstrapMethods [][]uint16
+
+	//常量池从下标1开始，相同的常量只放一份
+	constants [][]byte
+	indexes   map[string]uint16
+	nextIndex uint16
+}
+
+type Member struct {
+	class       *Class
+	accessFlags uint16
+	name        string
+	descriptor  string
+	attributes  []attribute
+	code        *Code
+}
+
+type attribute struct {
+	name string
+	data []byte
+}
+
+/**
+	superName为空表示没有超类，只有java/lang/Object才这样
+	默认是 public、带ACC_SUPER 的Java 8 class文件
+ */
+func New(name, superName string, interfaces ...string) *Class {
+	return &Class{
+		accessFlags:  ACC_PUBLIC | ACC_SUPER,
+		name:         name,
+		superName:    superName,
+		interfaces:   interfaces,
+		majorVersion: 52,
+		indexes:      map[string]uint16{},
+		nextIndex:    1,
+	}
+}
+
+/**
+	接口：public abstract interface，超类是Object
+ */
+func NewInterface(name string, interfaces ...string) *Class {
+	class := New(name, "java/lang/Object", interfaces...)
+	class.accessFlags = ACC_PUBLIC | ACC_INTERFACE | ACC_ABSTRACT
+	return class
+}
+
+func (self *Class) Name() string {
+	return self.name
+}
+
+func (self *Class) SetAccessFlags(flags uint16) *Class {
+	self.accessFlags = flags
+	return self
+}
+
+func (self *Class) SetMajorVersion(version uint16) *Class {
+	self.majorVersion = version
+	return self
+}
+
+/**
+	常量池相关，返回常量在常量池中的下标
+ */
+func (self *Class) constant(key string, tag byte, info []byte) uint16 {
+	if index, ok := self.indexes[key]; ok {
+		return index
+	}
+	index := self.RawConstant(tag, info)
+	self.indexes[key] = index
+	return index
+}
+
+/**
+	不去重，直接在常量池末尾加一项，比如构造引用下标在自己后面的常量
+ */
+func (self *Class) RawConstant(tag byte, info []byte) uint16 {
+	index := self.nextIndex
+	self.constants = append(self.constants, append([]byte{tag}, info...))
+	self.nextIndex++
+	if tag == CONSTANT_Long || tag == CONSTANT_Double {
+		self.nextIndex++
+	}
+	return index
+}
+
+// 下一个常量的下标
+func (self *Class) NextIndex() uint16 {
+	return self.nextIndex
+}
+
+func (self *Class) Utf8(s string) uint16 {
+	info := append(u2(uint16(len(s))), s...)
+	return self.constant("Utf8:"+s, CONSTANT_Utf8, info)
+}
+
+func (self *Class) ClassInfo(name string) uint16 {
+	return self.constant("Class:"+name, CONSTANT_Class, u2(self.Utf8(name)))
+}
+
+func (self *Class) StringInfo(s string) uint16 {
+	return self.constant("String:"+s, CONSTANT_String, u2(self.Utf8(s)))
+}
+
+func (self *Class) IntInfo(val int32) uint16 {
+	return self.constant("Integer:"+string(u4(uint32(val))), CONSTANT_Integer, u4(uint32(val)))
+}
+
+func (self *Class) FloatInfo(val float32) uint16 {
+	bits := u4(math.Float32bits(val))
+	return self.constant("Float:"+string(bits), CONSTANT_Float, bits)
+}
+
+func (self *Class) LongInfo(val int64) uint16 {
+	bits := u8(uint64(val))
+	return self.constant("Long:"+string(bits), CONSTANT_Long, bits)
+}
+
+func (self *Class) DoubleInfo(val float64) uint16 {
+	bits := u8(math.Float64bits(val))
+	return self.constant("Double:"+string(bits), CONSTANT_Double, bits)
+}
+
+func (self *Class) NameAndType(name, descriptor string) uint16 {
+	info := append(u2(self.Utf8(name)), u2(self.Utf8(descriptor))...)
+	return self.constant("NameAndType:"+name+":"+descriptor, CONSTANT_NameAndType, info)
+}
+
+func (self *Class) FieldRef(className, name, descriptor string) uint16 {
+	return self.memberRef(CONSTANT_Fieldref, className, name, descriptor)
+}
+
+func (self *Class) MethodRef(className, name, descriptor string) uint16 {
+	return self.memberRef(CONSTANT_Methodref, className, name, descriptor)
+}
+
+func (self *Class) InterfaceMethodRef(className, name, descriptor string) uint16 {
+	return self.memberRef(CONSTANT_InterfaceMethodref, className, name, descriptor)
+}
+
+func (self *Class) memberRef(tag byte, className, name, descriptor string) uint16 {
+	info := append(u2(self.ClassInfo(className)), u2(self.NameAndType(name, descriptor))...)
+	key := string([]byte{'0' + tag}) + ":" + className + "." + name + ":" + descriptor
+	return self.constant(key, tag, info)
+}
+
+func (self *Class) MethodHandle(referenceKind byte, referenceIndex uint16) uint16 {
+	info := append([]byte{referenceKind}, u2(referenceIndex)...)
+	return self.constant("MethodHandle:"+string(info), CONSTANT_MethodHandle, info)
+}
+
+func (self *Class) MethodType(descriptor string) uint16 {
+	return self.constant("MethodType:"+descriptor, CONSTANT_MethodType, u2(self.Utf8(descriptor)))
+}
+
+/**
+	bootstrapMethod是BootstrapMethod返回的下标
+ */
+func (self *Class) InvokeDynamic(bootstrapMethod uint16, name, descriptor string) uint16 {
+	info := append(u2(bootstrapMethod), u2(self.NameAndType(name, descriptor))...)
+	return self.constant("InvokeDynamic:"+string(info), CONSTANT_InvokeDynamic, info)
+}
+
+/**
+	在BootstrapMethods属性里加一项，返回它在属性里的下标
+ */
+func (self *Class) BootstrapMethod(methodHandle uint16, args ...uint16) uint16 {
+	self.bootstrapMethods = append(self.bootstrapMethods, append([]uint16{methodHandle}, args...))
+	return uint16(len(self.bootstrapMethods) - 1)
+}
+
+func (self *Class) Field(accessFlags uint16, name, descriptor string) *Member {
+	field := &Member{class: self, accessFlags: accessFlags, name: name, descriptor: descriptor}
+	self.fields = append(self.fields, field)
+	return field
+}
+
+/**
+	抽象方法和本地方法没有Code属性，其他方法要再调用Code
+ */
+func (self *Class) Method(accessFlags uint16, name, descriptor string) *Member {
+	method := &Member{class: self, accessFlags: accessFlags, name: name, descriptor: descriptor}
+	self.methods = append(self.methods, method)
+	return method
+}
+
+/**
+	只调用super.<init>()的无参构造方法
+ */
+func (self *Class) DefaultConstructor() *Class {
+	self.Method(ACC_PUBLIC, "<init>", "()V").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(self.superName, "<init>", "()V").Op(RETURN)
+	return self
+}
+
+func (self *Class) Attribute(name string, data []byte) *Class {
+	self.attributes = append(self.attributes, attribute{name, data})
+	return self
+}
+
+func (self *Class) SourceFile(fileName string) *Class {
+	return self.Attribute("SourceFile", u2(self.Utf8(fileName)))
+}
+
+func (self *Member) Attribute(name string, data []byte) *Member {
+	self.attributes = append(self.attributes, attribute{name, data})
+	return self
+}
+
+/**
+	static final字段的ConstantValue属性，index是常量池下标
+ */
+func (self *Member) ConstantValue(index uint16) *Member {
+	return self.Attribute("ConstantValue", u2(index))
+}
+
+func (self *Member) Code(maxStack, maxLocals uint16) *Code {
+	self.code = &Code{
+		class:     self.class,
+		maxStack:  maxStack,
+		maxLocals: maxLocals,
+		labels:    map[string]int{},
+	}
+	return self.code
+}
+
+/**
+	整个class文件。先写常量池以外的部分，这样写的过程中用到的常量都已经进了常量池
+ */
+func (self *Class) Bytes() []byte {
+	var body bytes.Buffer
+	body.Write(u2(self.accessFlags))
+	body.Write(u2(self.ClassInfo(self.name)))
+	if self.superName == "" {
+		body.Write(u2(0))
+	} else {
+		body.Write(u2(self.ClassInfo(self.superName)))
+	}
+	body.Write(u2(uint16(len(self.interfaces))))
+	for _, iface := range self.interfaces {
+		body.Write(u2(self.ClassInfo(iface)))
+	}
+	self.writeMembers(&body, self.fields)
+	self.writeMembers(&body, self.methods)
+
+	attributes := self.attributes
+	if len(self.bootstrapMethods) > 0 {
+		data := u2(uint16(len(self.bootstrapMethods)))
+		for _, bsm := range self.bootstrapMethods {
+			data = append(data, u2(bsm[0])...)
+			data = append(data, u2(uint16(len(bsm)-1))...)
+			for _, arg := range bsm[1:] {
+				data = append(data, u2(arg)...)
+			}
+		}
+		attributes = append(attributes, attribute{"BootstrapMethods", data})
+	}
+	self.writeAttributes(&body, attributes)
+
+	var out bytes.Buffer
+	out.Write(u4(0xCAFEBABE))
+	out.Write(u2(0))
+	out.Write(u2(self.majorVersion))
+	out.Write(u2(self.nextIndex))
+	for _, c := range self.constants {
+		out.Write(c)
+	}
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func (self *Class) writeMembers(buf *bytes.Buffer, members []*Member) {
+	buf.Write(u2(uint16(len(members))))
+	for _, member := range members {
+		buf.Write(u2(member.accessFlags))
+		buf.Write(u2(self.Utf8(member.name)))
+		buf.Write(u2(self.Utf8(member.descriptor)))
+		attributes := member.attributes
+		if member.code != nil {
+			attributes = append([]attribute{{"Code", member.code.bytes()}}, attributes...)
+		}
+		self.writeAttributes(buf, attributes)
+	}
+}
+
+func (self *Class) writeAttributes(buf *bytes.Buffer, attributes []attribute) {
+	buf.Write(u2(uint16(len(attributes))))
+	for _, attr := range attributes {
+		buf.Write(u2(self.Utf8(attr.name)))
+		buf.Write(u4(uint32(len(attr.data))))
+		buf.Write(attr.data)
+	}
+}
+
+func u2(val uint16) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, val)
+	return b
+}
+
+func u4(val uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, val)
+	return b
+}
+
+func u8(val uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, val)
+	return b
+}
+
+/**
+	给手写属性内容用的：按大端把每个值拼起来，值的类型决定宽度（uint8 1字节，uint16 2字节，uint32/int32 4字节）
+ */
+func Bytes(values ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, val := range values {
+		binary.Write(&buf, binary.BigEndian, val)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,338 @@
+package classgen
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+/**
+	方法的Code属性。指令按顺序追加，跳转目标用标签表示，最后写字节时再回填偏移量
+ */
+type Code struct {
+	class       *Class
+	maxStack    uint16
+	maxLocals   uint16
+	code        []byte
+	labels      map[string]int
+	fixups      []fixup
+	handlers    []handler
+	lineNumbers [][2]uint16
+	attributes  []attribute
+}
+
+/**
+	at是偏移量在code里的位置，偏移量相对于opPC（跳转指令自己的pc）
+ */
+type fixup struct {
+	at    int
+	opPC  int
+	label string
+	wide  bool
+}
+
+type handler struct {
+	start, end, handler string
+	catchType           uint16
+}
+
+func (self *Code) PC() int {
+	return len(self.code)
+}
+
+/**
+	操作码加上原样写入的操作数
+ */
+func (self *Code) Op(opcode byte, operands ...byte) *Code {
+	self.code = append(self.code, opcode)
+	self.code = append(self.code, operands...)
+	return self
+}
+
+// 操作数是一个u2下标的指令，比如 new、getstatic
+func (self *Code) U2(opcode byte, index uint16) *Code {
+	return self.Op(opcode, u2(index)...)
+}
+
+func (self *Code) Label(name string) *Code {
+	self.labels[name] = len(self.code)
+	return self
+}
+
+/**
+	if<cond>、goto、jsr 等两字节偏移量的跳转，goto_w 和 jsr_w 是四字节
+ */
+func (self *Code) Branch(opcode byte, label string) *Code {
+	opPC := len(self.code)
+	wide := opcode == GOTO_W || opcode == JSR_W
+	self.code = append(self.code, opcode)
+	self.fixups = append(self.fixups, fixup{len(self.code), opPC, label, wide})
+	if wide {
+		self.code = append(self.code, 0, 0, 0, 0)
+	} else {
+		self.code = append(self.code, 0, 0)
+	}
+	return self
+}
+
+/**
+	tableswitch：low到low+len(labels)-1 依次跳到labels
+ */
+func (self *Code) TableSwitch(defaultLabel string, low int32, labels ...string) *Code {
+	opPC := self.switchPadding(TABLESWITCH)
+	self.switchTarget(opPC, defaultLabel)
+	self.code = append(self.code, u4(uint32(low))...)
+	self.code = append(self.code, u4(uint32(low+int32(len(labels))-1))...)
+	for _, label := range labels {
+		self.switchTarget(opPC, label)
+	}
+	return self
+}
+
+/**
+	lookupswitch：按给出的顺序写 key -> label，不排序，方便测试乱序的情况
+ */
+func (self *Code) LookupSwitch(defaultLabel string, keys []int32, labels []string) *Code {
+	opPC := self.switchPadding(LOOKUPSWITCH)
+	self.switchTarget(opPC, defaultLabel)
+	self.code = append(self.code, u4(uint32(len(keys)))...)
+	for i, key := range keys {
+		self.code = append(self.code, u4(uint32(key))...)
+		self.switchTarget(opPC, labels[i])
+	}
+	return self
+}
+
+// 操作码之后补0，让后面的操作数从4的倍数开始
+func (self *Code) switchPadding(opcode byte) int {
+	opPC := len(self.code)
+	self.code = append(self.code, opcode)
+	for len(self.code)%4 != 0 {
+		self.code = append(self.code, 0)
+	}
+	return opPC
+}
+
+func (self *Code) switchTarget(opPC int, label string) {
+	self.fixups = append(self.fixups, fixup{len(self.code), opPC, label, true})
+	self.code = append(self.code, 0, 0, 0, 0)
+}
+
+/**
+	异常表的一项：[start, end) 里抛出的className（为空表示捕获所有异常）跳到handler
+ */
+func (self *Code) Catch(start, end, handlerLabel, className string) *Code {
+	catchType := uint16(0)
+	if className != "" {
+		catchType = self.class.ClassInfo(className)
+	}
+	self.handlers = append(self.handlers, handler{start, end, handlerLabel, catchType})
+	return self
+}
+
+/**
+	当前pc开始的指令属于源文件的第line行，写进LineNumberTable
+ */
+func (self *Code) Line(line uint16) *Code {
+	self.lineNumbers = append(self.lineNumbers, [2]uint16{uint16(len(self.code)), line})
+	return self
+}
+
+/**
+	Code属性自己的属性，比如手写的StackMapTable
+ */
+func (self *Code) Attribute(name string, data []byte) *Code {
+	self.attributes = append(self.attributes, attribute{name, data})
+	return self
+}
+
+/**
+	常用的需要常量池的指令
+ */
+func (self *Code) Getstatic(className, name, descriptor string) *Code {
+	return self.U2(GETSTATIC, self.class.FieldRef(className, name, descriptor))
+}
+
+func (self *Code) Putstatic(className, name, descriptor string) *Code {
+	return self.U2(PUTSTATIC, self.class.FieldRef(className, name, descriptor))
+}
+
+func (self *Code) Getfield(className, name, descriptor string) *Code {
+	return self.U2(GETFIELD, self.class.FieldRef(className, name, descriptor))
+}
+
+func (self *Code) Putfield(className, name, descriptor string) *Code {
+	return self.U2(PUTFIELD, self.class.FieldRef(className, name, descriptor))
+}
+
+func (self *Code) Invokestatic(className, name, descriptor string) *Code {
+	return self.U2(INVOKESTATIC, self.class.MethodRef(className, name, descriptor))
+}
+
+func (self *Code) Invokevirtual(className, name, descriptor string) *Code {
+	return self.U2(INVOKEVIRTUAL, self.class.MethodRef(className, name, descriptor))
+}
+
+func (self *Code) Invokespecial(className, name, descriptor string) *Code {
+	return self.U2(INVOKESPECIAL, self.class.MethodRef(className, name, descriptor))
+}
+
+/**
+	count是参数占的slot数加上this，由描述符算出来
+ */
+func (self *Code) Invokeinterface(className, name, descriptor string) *Code {
+	index := self.class.InterfaceMethodRef(className, name, descriptor)
+	return self.Op(INVOKEINTERFACE, append(u2(index), byte(ArgSlotCount(descriptor)+1), 0)...)
+}
+
+func (self *Code) Invokedynamic(index uint16) *Code {
+	return self.Op(INVOKEDYNAMIC, append(u2(index), 0, 0)...)
+}
+
+func (self *Code) New(className string) *Code {
+	return self.U2(NEW, self.class.ClassInfo(className))
+}
+
+func (self *Code) Anewarray(className string) *Code {
+	return self.U2(ANEWARRAY, self.class.ClassInfo(className))
+}
+
+func (self *Code) Checkcast(className string) *Code {
+	return self.U2(CHECKCAST, self.class.ClassInfo(className))
+}
+
+func (self *Code) Instanceof(className string) *Code {
+	return self.U2(INSTANCEOF, self.class.ClassInfo(className))
+}
+
+/**
+	下标小于256用ldc，否则用ldc_w
+ */
+func (self *Code) Ldc(index uint16) *Code {
+	if index < 256 {
+		return self.Op(LDC, byte(index))
+	}
+	return self.U2(LDC_W, index)
+}
+
+func (self *Code) LdcString(s string) *Code {
+	return self.Ldc(self.class.StringInfo(s))
+}
+
+func (self *Code) LdcClass(className string) *Code {
+	return self.Ldc(self.class.ClassInfo(className))
+}
+
+/**
+	按大小选 iconst_<n>、bipush、sipush 或者 ldc
+ */
+func (self *Code) Iconst(val int32) *Code {
+	switch {
+	case val >= -1 && val <= 5:
+		return self.Op(byte(ICONST_0 + val))
+	case val >= -128 && val <= 127:
+		return self.Op(BIPUSH, byte(int8(val)))
+	case val >= -32768 && val <= 32767:
+		return self.Op(SIPUSH, u2(uint16(int16(val)))...)
+	}
+	return self.Ldc(self.class.IntInfo(val))
+}
+
+func (self *Code) Lconst(val int64) *Code {
+	return self.U2(LDC2_W, self.class.LongInfo(val))
+}
+
+func (self *Code) Fconst(val float32) *Code {
+	return self.Ldc(self.class.FloatInfo(val))
+}
+
+func (self *Code) Dconst(val float64) *Code {
+	return self.U2(LDC2_W, self.class.DoubleInfo(val))
+}
+
+/**
+	Code属性的内容（不包括属性名和长度）
+ */
+func (self *Code) bytes() []byte {
+	code := make([]byte, len(self.code))
+	copy(code, self.code)
+	for _, f := range self.fixups {
+		target, ok := self.labels[f.label]
+		if !ok {
+			panic("classgen: undefined label " + f.label)
+		}
+		offset := target - f.opPC
+		if f.wide {
+			binary.BigEndian.PutUint32(code[f.at:], uint32(int32(offset)))
+		} else {
+			if offset < -32768 || offset > 32767 {
+				panic(fmt.Sprintf("classgen: branch to %s out of range", f.label))
+			}
+			binary.BigEndian.PutUint16(code[f.at:], uint16(int16(offset)))
+		}
+	}
+
+	data := append(u2(self.maxStack), u2(self.maxLocals)...)
+	data = append(data, u4(uint32(len(code)))...)
+	data = append(data, code...)
+	data = append(data, u2(uint16(len(self.handlers)))...)
+	for _, h := range self.handlers {
+		data = append(data, u2(uint16(self.pcOf(h.start)))...)
+		data = append(data, u2(uint16(self.pcOf(h.end)))...)
+		data = append(data, u2(uint16(self.pcOf(h.handler)))...)
+		data = append(data, u2(h.catchType)...)
+	}
+
+	attributes := self.attributes
+	if len(self.lineNumbers) > 0 {
+		table := u2(uint16(len(self.lineNumbers)))
+		for _, entry := range self.lineNumbers {
+			table = append(table, u2(entry[0])...)
+			table = append(table, u2(entry[1])...)
+		}
+		attributes = append(attributes, attribute{"LineNumberTable", table})
+	}
+	data = append(data, u2(uint16(len(attributes)))...)
+	for _, attr := range attributes {
+		data = append(data, u2(self.class.Utf8(attr.name))...)
+		data = append(data, u4(uint32(len(attr.data)))...)
+		data = append(data, attr.data...)
+	}
+	return data
+}
+
+func (self *Code) pcOf(label string) int {
+	pc, ok := self.labels[label]
+	if !ok {
+		panic("classgen: undefined label " + label)
+	}
+	return pc
+}
+
+/**
+	描述符里的参数占多少个slot，long和double占两个，不包括this
+ */
+func ArgSlotCount(descriptor string) int {
+	params := descriptor[1:strings.IndexByte(descriptor, ')')]
+	count := 0
+	for i := 0; i < len(params); i++ {
+		switch params[i] {
+		case 'J', 'D':
+			count += 2
+		case 'L':
+			i += strings.IndexByte(params[i:], ';')
+			count++
+		case '[':
+			for params[i] == '[' {
+				i++
+			}
+			if params[i] == 'L' {
+				i += strings.IndexByte(params[i:], ';')
+			}
+			count++
+		default:
+			count++
+		}
+	}
+	return count
+}
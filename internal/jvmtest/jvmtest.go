@@ -0,0 +1,270 @@
+/**
+	在测试JDK上运行字节码的测试工具
+	所有VM共用一个最顶层的类加载器（java.lang里的类、字符串池都是全局的），
+	每个VM用一个子加载器加载自己的测试类，测试之间不会互相看到对方的类
+ */
+package jvmtest
+
+import (
+	"GoVM/chapter2-class/classpath"
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter5-instructions"
+	"GoVM/chapter5-instructions/base"
+	"GoVM/chapter6-obj/heap"
+	"GoVM/internal/classgen"
+	"GoVM/internal/testjdk"
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+const hostClassName = "jvmtest/Host"
+
+var (
+	bootOnce   sync.Once
+	bootLoader *heap.ClassLoader
+)
+
+/**
+	最顶层的加载器，类路径就是测试JRE
+ */
+func BootLoader(t testing.TB) *heap.ClassLoader {
+	jre := testjdk.JRE(t)
+	bootOnce.Do(func() {
+		bootLoader = heap.NewClassLoader(nil, classpath.Parse(jre, jre), false, false)
+	})
+	return bootLoader
+}
+
+type VM struct {
+	t      testing.TB
+	Loader *heap.ClassLoader
+	//System.out 和 System.err 的输出
+	Out    bytes.Buffer
+	Err    bytes.Buffer
+	host   *heap.Method
+}
+
+/**
+	classes写到一个临时目录里，作为子加载器的类路径
+ */
+func New(t testing.TB, classes ...*classgen.Class) *VM {
+	t.Helper()
+	boot := BootLoader(t)
+	cp := testjdk.Classpath(t, append(classes, hostClass())...)
+	vm := &VM{t: t, Loader: heap.NewClassLoader(boot, cp, false, false)}
+	vm.host = vm.Loader.LoadClass(hostClassName).GetStaticMethod("call", "()V")
+
+	system := vm.Loader.LoadClass("java/lang/System")
+	system.SetRefVar("out", "Ljava/io/PrintStream;", vm.NewPrintStream(&vm.Out))
+	system.SetRefVar("err", "Ljava/io/PrintStream;", vm.NewPrintStream(&vm.Err))
+	return vm
+}
+
+/**
+	调用方的栈帧：pc 0 是发起调用的nop，被调用的方法返回之后执行pc 1的return；
+	[0, 1) 里抛出的任何异常都跳到pc 2的return，异常对象留在操作数栈上
+ */
+func hostClass() *classgen.Class {
+	class := classgen.New(hostClassName, "java/lang/Object")
+	code := class.Method(classgen.ACC_PUBLIC|classgen.ACC_STATIC, "call", "()V").Code(16, 0)
+	code.Label("call").Op(classgen.NOP).Label("returned").Op(classgen.RETURN).Label("thrown").Op(classgen.RETURN)
+	code.Catch("call", "returned", "thrown", "")
+	return class
+}
+
+/**
+	Go字符串转成java.lang.String
+ */
+func (self *VM) String(s string) *heap.Object {
+	return heap.JString(self.Loader, s)
+}
+
+func (self *VM) Class(name string) *heap.Class {
+	return self.Loader.LoadClass(name)
+}
+
+/**
+	执行结果。方法正常返回时返回值在 stack 上，抛出异常时 Thrown 是异常对象
+ */
+type Result struct {
+	t      testing.TB
+	method *heap.Method
+	stack  *chapter4_rtdt.OperandStack
+	Thrown *heap.Object
+}
+
+/**
+	调用类的静态方法，参数可以是 bool、int32、int64、float32、float64、*heap.Object 和 string（转成java.lang.String）
+	类还没有初始化时先执行<clinit>。Go代码里的panic（虚拟机的致命错误）会让测试失败，想检查它们用 CallPanic
+ */
+func (self *VM) Call(className, name, descriptor string, args ...interface{}) *Result {
+	self.t.Helper()
+	var result *Result
+	if r := self.CallPanic(&result, className, name, descriptor, args...); r != nil {
+		self.t.Fatalf("%s.%s%s: panic: %v", className, name, descriptor, r)
+	}
+	return result
+}
+
+/**
+	和Call一样，但是把Go代码里的panic返回
+ */
+func (self *VM) CallPanic(result **Result, className, name, descriptor string, args ...interface{}) (r interface{}) {
+	self.t.Helper()
+	class := self.Class(className)
+	method := class.GetStaticMethod(name, descriptor)
+	if method == nil {
+		self.t.Fatalf("no static method %s.%s%s", className, name, descriptor)
+	}
+
+	defer func() {
+		r = recover()
+	}()
+	if !class.InitStarted() {
+		if thrown := self.run(nil, nil, func(thread *chapter4_rtdt.Thread) {
+			base.InitClass(thread, class)
+		}); thrown.Thrown != nil {
+			*result = thrown
+			return nil
+		}
+	}
+	*result = self.run(method, args, nil)
+	return nil
+}
+
+/**
+	在新线程的宿主帧上调用setup（比如直接调用base.InitClass），然后解释执行，直到回到宿主帧
+ */
+func (self *VM) Run(setup func(*chapter4_rtdt.Thread)) *Result {
+	return self.run(nil, nil, setup)
+}
+
+func (self *VM) run(method *heap.Method, args []interface{}, setup func(*chapter4_rtdt.Thread)) *Result {
+	thread := chapter4_rtdt.NewThread()
+	host := thread.NewFrame(self.host)
+	host.SetNextPC(1)
+	thread.PushFrame(host)
+	if method != nil {
+		self.pushArgs(host.OperandStack(), args)
+		base.InvokeMethod(host, method)
+	}
+	if setup != nil {
+		setup(thread)
+	}
+	chapter5_instructions.Interpret(thread, false)
+
+	result := &Result{t: self.t, method: method, stack: host.OperandStack()}
+	if host.NextPC() == 3 {
+		result.Thrown = result.stack.PopRef()
+	}
+	return result
+}
+
+func (self *VM) pushArgs(stack *chapter4_rtdt.OperandStack, args []interface{}) {
+	for _, arg := range args {
+		switch val := arg.(type) {
+		case bool:
+			stack.PushBoolean(val)
+		case int:
+			stack.PushInt(int32(val))
+		case int32:
+			stack.PushInt(val)
+		case int64:
+			stack.PushLong(val)
+		case float32:
+			stack.PushFloat(val)
+		case float64:
+			stack.PushDouble(val)
+		case string:
+			stack.PushRef(self.String(val))
+		case *heap.Object:
+			stack.PushRef(val)
+		case nil:
+			stack.PushRef(nil)
+		default:
+			self.t.Fatalf("unsupported argument %T", arg)
+		}
+	}
+}
+
+func (self *Result) checkReturned() {
+	self.t.Helper()
+	if self.Thrown != nil {
+		self.t.Fatalf("%s.%s%s threw %s", self.method.Class().Name(), self.method.Name(), self.method.Descriptor(),
+			describe(self.Thrown))
+	}
+}
+
+func (self *Result) Int() int32 {
+	self.t.Helper()
+	self.checkReturned()
+	return self.stack.PopInt()
+}
+
+func (self *Result) Bool() bool {
+	self.t.Helper()
+	return self.Int() != 0
+}
+
+func (self *Result) Long() int64 {
+	self.t.Helper()
+	self.checkReturned()
+	return self.stack.PopLong()
+}
+
+func (self *Result) Float() float32 {
+	self.t.Helper()
+	self.checkReturned()
+	return self.stack.PopFloat()
+}
+
+func (self *Result) Double() float64 {
+	self.t.Helper()
+	self.checkReturned()
+	return self.stack.PopDouble()
+}
+
+func (self *Result) Ref() *heap.Object {
+	self.t.Helper()
+	self.checkReturned()
+	return self.stack.PopRef()
+}
+
+/**
+	返回值是java.lang.String，null返回"null"
+ */
+func (self *Result) String() string {
+	self.t.Helper()
+	ref := self.Ref()
+	if ref == nil {
+		return "null"
+	}
+	return heap.GoString(ref)
+}
+
+/**
+	方法正常返回就失败；返回异常的detailMessage，没有消息时是空字符串
+ */
+func (self *Result) Throws(className string) string {
+	self.t.Helper()
+	if self.Thrown == nil {
+		self.t.Fatalf("expected %s, returned normally", className)
+	}
+	if self.Thrown.Class().Name() != className {
+		self.t.Fatalf("expected %s, got %s", className, describe(self.Thrown))
+	}
+	return message(self.Thrown)
+}
+
+func message(ex *heap.Object) string {
+	if jMsg := ex.GetRefVar("detailMessage", "Ljava/lang/String;"); jMsg != nil {
+		return heap.GoString(jMsg)
+	}
+	return ""
+}
+
+func describe(ex *heap.Object) string {
+	return fmt.Sprintf("%s: %s", ex.Class().JavaName(), message(ex))
+}
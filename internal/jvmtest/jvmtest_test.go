@@ -0,0 +1,49 @@
+package jvmtest_test
+
+import (
+	"GoVM/internal/jvmtest"
+	. "GoVM/internal/classgen"
+	"testing"
+)
+
+func TestCallReturnsValue(t *testing.T) {
+	class := New("jvmtest/Add", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "add", "(II)I").Code(2, 2).
+		Op(ILOAD_0).Op(ILOAD_1).Op(IADD).Op(IRETURN)
+	vm := jvmtest.New(t, class)
+	if got := vm.Call("jvmtest/Add", "add", "(II)I", 2, 3).Int(); got != 5 {
+		t.Fatalf("add(2, 3) = %d", got)
+	}
+}
+
+func TestCallCatchesThrownException(t *testing.T) {
+	class := New("jvmtest/Thrower", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "fail", "()V").Code(3, 0).
+		New("java/lang/IllegalArgumentException").Op(DUP).LdcString("boom").
+		Invokespecial("java/lang/IllegalArgumentException", "<init>", "(Ljava/lang/String;)V").Op(ATHROW)
+	vm := jvmtest.New(t, class)
+	if msg := vm.Call("jvmtest/Thrower", "fail", "()V").Throws("java/lang/IllegalArgumentException"); msg != "boom" {
+		t.Fatalf("message = %q", msg)
+	}
+}
+
+func TestPrintlnAndToString(t *testing.T) {
+	class := New("jvmtest/Hello", "java/lang/Object")
+	class.Field(ACC_STATIC, "greeting", "Ljava/lang/String;")
+	class.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		LdcString("hello").Putstatic("jvmtest/Hello", "greeting", "Ljava/lang/String;").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "main", "()Ljava/lang/String;").Code(3, 0).
+		Getstatic("java/lang/System", "out", "Ljava/io/PrintStream;").
+		Getstatic("jvmtest/Hello", "greeting", "Ljava/lang/String;").
+		Invokevirtual("java/io/PrintStream", "println", "(Ljava/lang/String;)V").
+		New("java/lang/Object").Op(DUP).Invokespecial("java/lang/Object", "<init>", "()V").
+		Invokevirtual("java/lang/Object", "toString", "()Ljava/lang/String;").Op(ARETURN)
+	vm := jvmtest.New(t, class)
+	str := vm.Call("jvmtest/Hello", "main", "()Ljava/lang/String;").String()
+	if vm.Out.String() != "hello\n" {
+		t.Fatalf("out = %q", vm.Out.String())
+	}
+	if len(str) < len("java.lang.Object@") || str[:len("java.lang.Object@")] != "java.lang.Object@" {
+		t.Fatalf("toString() = %q", str)
+	}
+}
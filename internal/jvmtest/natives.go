@@ -0,0 +1,61 @@
+package jvmtest
+
+import (
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+	"GoVM/native"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+/**
+	测试JDK里声明成native、真JDK里是java代码的方法
+ */
+func init() {
+	native.Register("java/lang/Integer", "toHexString", "(I)Ljava/lang/String;", integerToHexString)
+	native.Register("java/io/PrintStream", "print", "(Ljava/lang/String;)V", printString)
+	native.Register("java/io/PrintStream", "println", "(Ljava/lang/String;)V", printlnString)
+	native.Register("java/io/PrintStream", "println", "(I)V", printlnInt)
+}
+
+/**
+	输出写到w的PrintStream
+ */
+func (self *VM) NewPrintStream(w io.Writer) *heap.Object {
+	stream := self.Class("java/io/PrintStream").NewObject()
+	stream.SetExtra(w)
+	return stream
+}
+
+// public static String toHexString(int i)
+// (I)Ljava/lang/String;
+func integerToHexString(frame *chapter4_rtdt.Frame) {
+	i := frame.LocalVars().GetInt(0)
+	hex := strconv.FormatUint(uint64(uint32(i)), 16)
+	frame.OperandStack().PushRef(heap.JString(frame.Method().Class().Loader(), hex))
+}
+
+func printString(frame *chapter4_rtdt.Frame) {
+	print(frame, "%s", javaString(frame.LocalVars().GetRef(1)))
+}
+
+func printlnString(frame *chapter4_rtdt.Frame) {
+	print(frame, "%s\n", javaString(frame.LocalVars().GetRef(1)))
+}
+
+func printlnInt(frame *chapter4_rtdt.Frame) {
+	print(frame, "%d\n", frame.LocalVars().GetInt(1))
+}
+
+func print(frame *chapter4_rtdt.Frame, format string, arg interface{}) {
+	w := frame.LocalVars().GetThis().Extra().(io.Writer)
+	fmt.Fprintf(w, format, arg)
+}
+
+func javaString(jStr *heap.Object) string {
+	if jStr == nil {
+		return "null"
+	}
+	return heap.GoString(jStr)
+}
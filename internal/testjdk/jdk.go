@@ -0,0 +1,644 @@
+package testjdk
+
+import (
+	. "GoVM/internal/classgen"
+	"strings"
+)
+
+const (
+	jlObject    = "java/lang/Object"
+	jlString    = "java/lang/String"
+	jlClass     = "java/lang/Class"
+	jlThrowable = "java/lang/Throwable"
+	jlASB       = "java/lang/AbstractStringBuilder"
+	jlSB        = "java/lang/StringBuilder"
+)
+
+/**
+	测试JDK里所有的类
+ */
+func Classes() []*Class {
+	classes := []*Class{
+		object(), class(), str(), abstractStringBuilder(), stringBuilder(), number(),
+		system(), runtime(), thread(), throwable(), stackTraceElement(), mathClass(),
+		classLoader(), NewInterface("java/lang/Cloneable"), NewInterface("java/lang/Runnable"),
+		NewInterface("java/io/Serializable"), NewInterface("java/lang/Comparable"),
+		reflectMethod(), reflectConstructor(),
+		abstractClass("java/io/InputStream", jlObject), byteArrayInputStream(),
+		abstractClass("java/io/OutputStream", jlObject), printStream(),
+		reference(), reference2("java/lang/ref/WeakReference"), reference2("java/lang/ref/SoftReference"),
+		reference2("java/lang/ref/PhantomReference"),
+	}
+	classes = append(classes, wrappers()...)
+	classes = append(classes, exceptions()...)
+	return classes
+}
+
+/**
+	intrinsic方法的占位实现：返回0或者null。虚拟机没把它换成Go实现的话，测试会拿到错误的结果
+ */
+func stub(class *Class, accessFlags uint16, name, descriptor string) {
+	locals := uint16(ArgSlotCount(descriptor))
+	if accessFlags&ACC_STATIC == 0 {
+		locals++
+	}
+	code := class.Method(accessFlags, name, descriptor).Code(2, locals)
+	switch descriptor[strings.IndexByte(descriptor, ')')+1] {
+	case 'V':
+		code.Op(RETURN)
+	case 'J':
+		code.Op(LCONST_0).Op(LRETURN)
+	case 'F':
+		code.Op(FCONST_0).Op(FRETURN)
+	case 'D':
+		code.Op(DCONST_0).Op(DRETURN)
+	case 'L', '[':
+		code.Op(ACONST_NULL).Op(ARETURN)
+	default:
+		code.Op(ICONST_0).Op(IRETURN)
+	}
+}
+
+func native(class *Class, accessFlags uint16, name, descriptor string) {
+	class.Method(accessFlags|ACC_NATIVE, name, descriptor)
+}
+
+func abstractClass(name, superName string) *Class {
+	class := New(name, superName).DefaultConstructor()
+	class.SetAccessFlags(ACC_PUBLIC | ACC_SUPER | ACC_ABSTRACT)
+	return class
+}
+
+func object() *Class {
+	class := New(jlObject, "")
+	class.Method(ACC_PUBLIC, "<init>", "()V").Code(0, 1).Op(RETURN)
+	native(class, ACC_PUBLIC|ACC_FINAL, "getClass", "()Ljava/lang/Class;")
+	native(class, ACC_PUBLIC, "hashCode", "()I")
+	native(class, ACC_PROTECTED, "clone", "()Ljava/lang/Object;")
+	native(class, ACC_PUBLIC|ACC_FINAL, "notify", "()V")
+	native(class, ACC_PUBLIC|ACC_FINAL, "notifyAll", "()V")
+	native(class, ACC_PUBLIC|ACC_FINAL, "wait", "(J)V")
+	class.Method(ACC_PUBLIC|ACC_FINAL, "wait", "()V").Code(3, 1).
+		Op(ALOAD_0).Op(LCONST_0).Invokevirtual(jlObject, "wait", "(J)V").Op(RETURN)
+	class.Method(ACC_PUBLIC, "equals", "(Ljava/lang/Object;)Z").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Branch(IF_ACMPNE, "ne").
+		Op(ICONST_1).Op(IRETURN).
+		Label("ne").Op(ICONST_0).Op(IRETURN)
+	//getClass().getName() + "@" + Integer.toHexString(hashCode())
+	class.Method(ACC_PUBLIC, "toString", "()Ljava/lang/String;").Code(3, 1).
+		New(jlSB).Op(DUP).Invokespecial(jlSB, "<init>", "()V").
+		Op(ALOAD_0).Invokevirtual(jlObject, "getClass", "()Ljava/lang/Class;").
+		Invokevirtual(jlClass, "getName", "()Ljava/lang/String;").
+		Invokevirtual(jlSB, "append", "(Ljava/lang/String;)Ljava/lang/StringBuilder;").
+		LdcString("@").Invokevirtual(jlSB, "append", "(Ljava/lang/String;)Ljava/lang/StringBuilder;").
+		Op(ALOAD_0).Invokevirtual(jlObject, "hashCode", "()I").
+		Invokestatic("java/lang/Integer", "toHexString", "(I)Ljava/lang/String;").
+		Invokevirtual(jlSB, "append", "(Ljava/lang/String;)Ljava/lang/StringBuilder;").
+		Invokevirtual(jlSB, "toString", "()Ljava/lang/String;").Op(ARETURN)
+	return class
+}
+
+func class() *Class {
+	class := New(jlClass, jlObject, "java/io/Serializable")
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	class.Method(ACC_PRIVATE, "<init>", "()V").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").Op(RETURN)
+	native(class, ACC_STATIC, "getPrimitiveClass", "(Ljava/lang/String;)Ljava/lang/Class;")
+	native(class, ACC_PRIVATE, "getName0", "()Ljava/lang/String;")
+	class.Method(ACC_PUBLIC, "getName", "()Ljava/lang/String;").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(jlClass, "getName0", "()Ljava/lang/String;").Op(ARETURN)
+	native(class, ACC_PRIVATE|ACC_STATIC, "desiredAssertionStatus0", "(Ljava/lang/Class;)Z")
+	class.Method(ACC_PUBLIC, "desiredAssertionStatus", "()Z").Code(1, 1).
+		Op(ALOAD_0).Invokestatic(jlClass, "desiredAssertionStatus0", "(Ljava/lang/Class;)Z").Op(IRETURN)
+	native(class, ACC_PUBLIC, "isInterface", "()Z")
+	native(class, ACC_PUBLIC, "getComponentType", "()Ljava/lang/Class;")
+	native(class, ACC_PRIVATE, "getDeclaringClass0", "()Ljava/lang/Class;")
+	class.Method(ACC_PUBLIC, "getDeclaringClass", "()Ljava/lang/Class;").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(jlClass, "getDeclaringClass0", "()Ljava/lang/Class;").Op(ARETURN)
+	native(class, ACC_PRIVATE, "getDeclaredConstructors0", "(Z)[Ljava/lang/reflect/Constructor;")
+	class.Method(ACC_PUBLIC, "getDeclaredConstructors", "()[Ljava/lang/reflect/Constructor;").Code(2, 1).
+		Op(ALOAD_0).Op(ICONST_0).
+		Invokespecial(jlClass, "getDeclaredConstructors0", "(Z)[Ljava/lang/reflect/Constructor;").Op(ARETURN)
+	class.Method(ACC_PUBLIC, "getConstructors", "()[Ljava/lang/reflect/Constructor;").Code(2, 1).
+		Op(ALOAD_0).Op(ICONST_1).
+		Invokespecial(jlClass, "getDeclaredConstructors0", "(Z)[Ljava/lang/reflect/Constructor;").Op(ARETURN)
+	stub(class, ACC_PUBLIC|ACC_VARARGS, "getDeclaredMethod", "(Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;")
+	stub(class, ACC_PUBLIC|ACC_VARARGS, "getMethod", "(Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;")
+	stub(class, ACC_PUBLIC, "getEnclosingClass", "()Ljava/lang/Class;")
+	stub(class, ACC_PUBLIC, "getResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;")
+	return class
+}
+
+func str() *Class {
+	class := New(jlString, jlObject, "java/io/Serializable", "java/lang/Comparable")
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	class.Field(ACC_PRIVATE|ACC_FINAL, "value", "[C")
+	class.Field(ACC_PRIVATE, "hash", "I")
+	class.Method(ACC_PUBLIC, "<init>", "()V").Code(2, 1).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").
+		Op(ALOAD_0).Op(ICONST_0).Op(NEWARRAY, T_CHAR).Putfield(jlString, "value", "[C").Op(RETURN)
+	class.Method(ACC_PUBLIC, "<init>", "([C)V").Code(4, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Op(ICONST_0).Op(ALOAD_1).Op(ARRAYLENGTH).
+		Invokespecial(jlString, "<init>", "([CII)V").Op(RETURN)
+	//value = Arrays.copyOfRange(value, offset, offset+count)
+	class.Method(ACC_PUBLIC, "<init>", "([CII)V").Code(5, 5).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").
+		Op(ILOAD_3).Op(NEWARRAY, T_CHAR).Op(ASTORE, 4).
+		Op(ALOAD_1).Op(ILOAD_2).Op(ALOAD, 4).Op(ICONST_0).Op(ILOAD_3).
+		Invokestatic("java/lang/System", "arraycopy", "(Ljava/lang/Object;ILjava/lang/Object;II)V").
+		Op(ALOAD_0).Op(ALOAD, 4).Putfield(jlString, "value", "[C").Op(RETURN)
+	stub(class, ACC_PUBLIC, "length", "()I")
+	stub(class, ACC_PUBLIC, "charAt", "(I)C")
+	stub(class, ACC_PUBLIC, "substring", "(I)Ljava/lang/String;")
+	stub(class, ACC_PUBLIC, "substring", "(II)Ljava/lang/String;")
+	stub(class, ACC_PUBLIC, "indexOf", "(I)I")
+	stub(class, ACC_PUBLIC, "indexOf", "(II)I")
+	native(class, ACC_PUBLIC, "intern", "()Ljava/lang/String;")
+	class.Method(ACC_PUBLIC, "toString", "()Ljava/lang/String;").Code(1, 1).Op(ALOAD_0).Op(ARETURN)
+	class.Method(ACC_PUBLIC, "toCharArray", "()[C").Code(5, 2).
+		Op(ALOAD_0).Getfield(jlString, "value", "[C").Op(ARRAYLENGTH).Op(NEWARRAY, T_CHAR).Op(ASTORE_1).
+		Op(ALOAD_0).Getfield(jlString, "value", "[C").Op(ICONST_0).Op(ALOAD_1).Op(ICONST_0).
+		Op(ALOAD_1).Op(ARRAYLENGTH).
+		Invokestatic("java/lang/System", "arraycopy", "(Ljava/lang/Object;ILjava/lang/Object;II)V").
+		Op(ALOAD_1).Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "valueOf", "(Ljava/lang/Object;)Ljava/lang/String;").Code(1, 1).
+		Op(ALOAD_0).Branch(IFNONNULL, "nonnull").
+		LdcString("null").Op(ARETURN).
+		Label("nonnull").Op(ALOAD_0).Invokevirtual(jlObject, "toString", "()Ljava/lang/String;").Op(ARETURN)
+	return class
+}
+
+/**
+	StringBuilder的方法都转调AbstractStringBuilder，append(String)用intrinsic的insert实现
+ */
+func abstractStringBuilder() *Class {
+	class := New(jlASB, jlObject)
+	class.SetAccessFlags(ACC_SUPER | ACC_ABSTRACT)
+	class.Field(0, "value", "[C")
+	class.Field(0, "count", "I")
+	class.Method(0, "<init>", "(I)V").Code(2, 2).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").
+		Op(ALOAD_0).Op(ILOAD_1).Op(NEWARRAY, T_CHAR).Putfield(jlASB, "value", "[C").Op(RETURN)
+	stub(class, ACC_PUBLIC, "length", "()I")
+	stub(class, ACC_PUBLIC, "setLength", "(I)V")
+	stub(class, ACC_PUBLIC, "insert", "(ILjava/lang/String;)Ljava/lang/AbstractStringBuilder;")
+	stub(class, ACC_PUBLIC, "deleteCharAt", "(I)Ljava/lang/AbstractStringBuilder;")
+	stub(class, ACC_PUBLIC, "reverse", "()Ljava/lang/AbstractStringBuilder;")
+	class.Method(ACC_PUBLIC, "append", "(Ljava/lang/String;)Ljava/lang/AbstractStringBuilder;").Code(3, 2).
+		Op(ALOAD_0).Op(ALOAD_0).Invokevirtual(jlASB, "length", "()I").Op(ALOAD_1).
+		Invokevirtual(jlASB, "insert", "(ILjava/lang/String;)Ljava/lang/AbstractStringBuilder;").Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_ABSTRACT, "toString", "()Ljava/lang/String;")
+	return class
+}
+
+func stringBuilder() *Class {
+	class := New(jlSB, jlASB, "java/io/Serializable")
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	class.Method(ACC_PUBLIC, "<init>", "()V").Code(2, 1).
+		Op(ALOAD_0).Iconst(16).Invokespecial(jlASB, "<init>", "(I)V").Op(RETURN)
+	class.Method(ACC_PUBLIC, "<init>", "(Ljava/lang/String;)V").Code(3, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Invokevirtual(jlString, "length", "()I").Iconst(16).Op(IADD).
+		Invokespecial(jlASB, "<init>", "(I)V").
+		Op(ALOAD_0).Op(ALOAD_1).Invokevirtual(jlSB, "append", "(Ljava/lang/String;)Ljava/lang/StringBuilder;").
+		Op(POP).Op(RETURN)
+	class.Method(ACC_PUBLIC, "append", "(Ljava/lang/String;)Ljava/lang/StringBuilder;").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).
+		Invokespecial(jlASB, "append", "(Ljava/lang/String;)Ljava/lang/AbstractStringBuilder;").
+		Op(POP).Op(ALOAD_0).Op(ARETURN)
+	class.Method(ACC_PUBLIC, "append", "(Ljava/lang/Object;)Ljava/lang/StringBuilder;").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Invokestatic(jlString, "valueOf", "(Ljava/lang/Object;)Ljava/lang/String;").
+		Invokevirtual(jlSB, "append", "(Ljava/lang/String;)Ljava/lang/StringBuilder;").Op(ARETURN)
+	class.Method(ACC_PUBLIC, "insert", "(ILjava/lang/String;)Ljava/lang/StringBuilder;").Code(3, 3).
+		Op(ALOAD_0).Op(ILOAD_1).Op(ALOAD_2).
+		Invokespecial(jlASB, "insert", "(ILjava/lang/String;)Ljava/lang/AbstractStringBuilder;").
+		Op(POP).Op(ALOAD_0).Op(ARETURN)
+	class.Method(ACC_PUBLIC, "deleteCharAt", "(I)Ljava/lang/StringBuilder;").Code(2, 2).
+		Op(ALOAD_0).Op(ILOAD_1).
+		Invokespecial(jlASB, "deleteCharAt", "(I)Ljava/lang/AbstractStringBuilder;").
+		Op(POP).Op(ALOAD_0).Op(ARETURN)
+	class.Method(ACC_PUBLIC, "reverse", "()Ljava/lang/StringBuilder;").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(jlASB, "reverse", "()Ljava/lang/AbstractStringBuilder;").
+		Op(POP).Op(ALOAD_0).Op(ARETURN)
+	class.Method(ACC_PUBLIC, "toString", "()Ljava/lang/String;").Code(5, 1).
+		New(jlString).Op(DUP).
+		Op(ALOAD_0).Getfield(jlSB, "value", "[C").Op(ICONST_0).Op(ALOAD_0).Getfield(jlSB, "count", "I").
+		Invokespecial(jlString, "<init>", "([CII)V").Op(ARETURN)
+	return class
+}
+
+func number() *Class {
+	return abstractClass("java/lang/Number", jlObject)
+}
+
+/**
+	八个包装类：value字段、TYPE、构造方法、valueOf 和 xxxValue
+ */
+func wrappers() []*Class {
+	type wrapper struct {
+		name, primitive, descriptor string
+		load, ret                   byte
+	}
+	var classes []*Class
+	for _, w := range []wrapper{
+		{"Boolean", "boolean", "Z", ILOAD, IRETURN},
+		{"Character", "char", "C", ILOAD, IRETURN},
+		{"Byte", "byte", "B", ILOAD, IRETURN},
+		{"Short", "short", "S", ILOAD, IRETURN},
+		{"Integer", "int", "I", ILOAD, IRETURN},
+		{"Long", "long", "J", LLOAD, LRETURN},
+		{"Float", "float", "F", FLOAD, FRETURN},
+		{"Double", "double", "D", DLOAD, DRETURN},
+	} {
+		name := "java/lang/" + w.name
+		superName := "java/lang/Number"
+		if w.descriptor == "Z" || w.descriptor == "C" {
+			superName = jlObject
+		}
+		class := New(name, superName, "java/io/Serializable")
+		class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+		class.Field(ACC_PRIVATE|ACC_FINAL, "value", w.descriptor)
+		class.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "TYPE", "Ljava/lang/Class;")
+		class.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+			LdcString(w.primitive).Invokestatic(jlClass, "getPrimitiveClass", "(Ljava/lang/String;)Ljava/lang/Class;").
+			Putstatic(name, "TYPE", "Ljava/lang/Class;").Op(RETURN)
+		class.Method(ACC_PUBLIC, "<init>", "("+w.descriptor+")V").Code(3, 3).
+			Op(ALOAD_0).Invokespecial(superName, "<init>", "()V").
+			Op(ALOAD_0).Op(w.load, 1).Putfield(name, "value", w.descriptor).Op(RETURN)
+		class.Method(ACC_PUBLIC|ACC_STATIC, "valueOf", "("+w.descriptor+")L"+name+";").Code(4, 2).
+			New(name).Op(DUP).Op(w.load, 0).Invokespecial(name, "<init>", "("+w.descriptor+")V").Op(ARETURN)
+		class.Method(ACC_PUBLIC, w.primitive+"Value", "()"+w.descriptor).Code(2, 1).
+			Op(ALOAD_0).Getfield(name, "value", w.descriptor).Op(w.ret)
+		classes = append(classes, class)
+
+		switch w.name {
+		case "Integer":
+			for _, m := range []string{"bitCount", "numberOfLeadingZeros", "numberOfTrailingZeros",
+				"highestOneBit", "lowestOneBit", "reverse", "reverseBytes"} {
+				stub(class, ACC_PUBLIC|ACC_STATIC, m, "(I)I")
+			}
+			native(class, ACC_PUBLIC|ACC_STATIC, "toHexString", "(I)Ljava/lang/String;")
+		case "Long":
+			for _, m := range []string{"bitCount", "numberOfLeadingZeros", "numberOfTrailingZeros"} {
+				stub(class, ACC_PUBLIC|ACC_STATIC, m, "(J)I")
+			}
+			for _, m := range []string{"highestOneBit", "lowestOneBit", "reverse", "reverseBytes"} {
+				stub(class, ACC_PUBLIC|ACC_STATIC, m, "(J)J")
+			}
+		case "Float":
+			native(class, ACC_PUBLIC|ACC_STATIC, "floatToRawIntBits", "(F)I")
+			native(class, ACC_PUBLIC|ACC_STATIC, "intBitsToFloat", "(I)F")
+			stub(class, ACC_PUBLIC|ACC_STATIC, "floatToIntBits", "(F)I")
+			stub(class, ACC_PUBLIC|ACC_STATIC, "isNaN", "(F)Z")
+			stub(class, ACC_PUBLIC|ACC_STATIC, "isInfinite", "(F)Z")
+		case "Double":
+			native(class, ACC_PUBLIC|ACC_STATIC, "doubleToRawLongBits", "(D)J")
+			native(class, ACC_PUBLIC|ACC_STATIC, "longBitsToDouble", "(J)D")
+			stub(class, ACC_PUBLIC|ACC_STATIC, "doubleToLongBits", "(D)J")
+			stub(class, ACC_PUBLIC|ACC_STATIC, "isNaN", "(D)Z")
+			stub(class, ACC_PUBLIC|ACC_STATIC, "isInfinite", "(D)Z")
+		}
+	}
+	return classes
+}
+
+func mathClass() *Class {
+	class := New("java/lang/Math", jlObject)
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	stub(class, ACC_PUBLIC|ACC_STATIC, "floor", "(D)D")
+	stub(class, ACC_PUBLIC|ACC_STATIC, "ceil", "(D)D")
+	stub(class, ACC_PUBLIC|ACC_STATIC, "rint", "(D)D")
+	stub(class, ACC_PUBLIC|ACC_STATIC, "round", "(D)J")
+	stub(class, ACC_PUBLIC|ACC_STATIC, "round", "(F)I")
+	return class
+}
+
+func system() *Class {
+	name := "java/lang/System"
+	class := New(name, jlObject)
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	class.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "in", "Ljava/io/InputStream;")
+	class.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "out", "Ljava/io/PrintStream;")
+	class.Field(ACC_PUBLIC|ACC_STATIC|ACC_FINAL, "err", "Ljava/io/PrintStream;")
+	native(class, ACC_PUBLIC|ACC_STATIC, "arraycopy", "(Ljava/lang/Object;ILjava/lang/Object;II)V")
+	native(class, ACC_PUBLIC|ACC_STATIC, "identityHashCode", "(Ljava/lang/Object;)I")
+	native(class, ACC_PUBLIC|ACC_STATIC, "currentTimeMillis", "()J")
+	native(class, ACC_PUBLIC|ACC_STATIC, "nanoTime", "()J")
+	for _, s := range []struct{ field, descriptor string }{
+		{"In", "Ljava/io/InputStream;"}, {"Out", "Ljava/io/PrintStream;"}, {"Err", "Ljava/io/PrintStream;"},
+	} {
+		native(class, ACC_PRIVATE|ACC_STATIC, "set"+s.field+"0", "("+s.descriptor+")V")
+		class.Method(ACC_PUBLIC|ACC_STATIC, "set"+s.field, "("+s.descriptor+")V").Code(1, 1).
+			Op(ALOAD_0).Invokestatic(name, "set"+s.field+"0", "("+s.descriptor+")V").Op(RETURN)
+	}
+	stub(class, ACC_PUBLIC|ACC_STATIC, "getenv", "(Ljava/lang/String;)Ljava/lang/String;")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "gc", "()V").Code(1, 0).
+		Invokestatic("java/lang/Runtime", "getRuntime", "()Ljava/lang/Runtime;").
+		Invokevirtual("java/lang/Runtime", "gc", "()V").Op(RETURN)
+	return class
+}
+
+func runtime() *Class {
+	name := "java/lang/Runtime"
+	class := New(name, jlObject)
+	class.Field(ACC_PRIVATE|ACC_STATIC, "currentRuntime", "Ljava/lang/Runtime;")
+	class.Method(ACC_STATIC, "<clinit>", "()V").Code(2, 0).
+		New(name).Op(DUP).Invokespecial(name, "<init>", "()V").
+		Putstatic(name, "currentRuntime", "Ljava/lang/Runtime;").Op(RETURN)
+	class.Method(ACC_PRIVATE, "<init>", "()V").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "getRuntime", "()Ljava/lang/Runtime;").Code(1, 0).
+		Getstatic(name, "currentRuntime", "Ljava/lang/Runtime;").Op(ARETURN)
+	native(class, ACC_PUBLIC, "gc", "()V")
+	native(class, ACC_PUBLIC, "availableProcessors", "()I")
+	native(class, ACC_PUBLIC, "freeMemory", "()J")
+	native(class, ACC_PUBLIC, "totalMemory", "()J")
+	native(class, ACC_PUBLIC, "maxMemory", "()J")
+	return class
+}
+
+func thread() *Class {
+	name := "java/lang/Thread"
+	class := New(name, jlObject, "java/lang/Runnable")
+	class.Field(ACC_PRIVATE|ACC_VOLATILE, "name", "[C")
+	class.Field(ACC_PRIVATE, "priority", "I")
+	class.Field(ACC_PRIVATE, "daemon", "Z")
+	class.Method(ACC_PUBLIC, "<init>", "()V").Code(2, 1).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").
+		Op(ALOAD_0).LdcString("Thread-0").Invokevirtual(jlString, "toCharArray", "()[C").
+		Putfield(name, "name", "[C").
+		Op(ALOAD_0).Iconst(5).Putfield(name, "priority", "I").Op(RETURN)
+	class.Method(ACC_PUBLIC, "run", "()V").Code(0, 1).Op(RETURN)
+	native(class, ACC_PUBLIC|ACC_STATIC, "currentThread", "()Ljava/lang/Thread;")
+	native(class, ACC_PUBLIC|ACC_FINAL, "isAlive", "()Z")
+	class.Method(ACC_PUBLIC|ACC_FINAL, "setName", "(Ljava/lang/String;)V").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Invokevirtual(jlString, "toCharArray", "()[C").
+		Putfield(name, "name", "[C").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_FINAL, "getName", "()Ljava/lang/String;").Code(3, 1).
+		New(jlString).Op(DUP).Op(ALOAD_0).Getfield(name, "name", "[C").
+		Invokespecial(jlString, "<init>", "([C)V").Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_FINAL, "isDaemon", "()Z").Code(1, 1).
+		Op(ALOAD_0).Getfield(name, "daemon", "Z").Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_FINAL, "setDaemon", "(Z)V").Code(2, 2).
+		Op(ALOAD_0).Invokevirtual(name, "isAlive", "()Z").Branch(IFEQ, "notAlive").
+		New("java/lang/IllegalThreadStateException").Op(DUP).
+		Invokespecial("java/lang/IllegalThreadStateException", "<init>", "()V").Op(ATHROW).
+		Label("notAlive").Op(ALOAD_0).Op(ILOAD_1).Putfield(name, "daemon", "Z").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_FINAL, "join", "()V").Code(3, 1).
+		Op(ALOAD_0).Op(LCONST_0).Invokevirtual(name, "join", "(J)V").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_FINAL|ACC_SYNCHRONIZED, "join", "(J)V").Code(4, 9).
+		Invokestatic("java/lang/System", "currentTimeMillis", "()J").Op(LSTORE_3).
+		Op(LCONST_0).Op(LSTORE, 5).
+		//millis < 0
+		Op(LLOAD_1).Op(LCONST_0).Op(LCMP).Branch(IFGE, "nonNegative").
+		New("java/lang/IllegalArgumentException").Op(DUP).LdcString("timeout value is negative").
+		Invokespecial("java/lang/IllegalArgumentException", "<init>", "(Ljava/lang/String;)V").Op(ATHROW).
+		Label("nonNegative").
+		Op(LLOAD_1).Op(LCONST_0).Op(LCMP).Branch(IFNE, "timed").
+		//while (isAlive()) wait(0);
+		Label("loop0").Op(ALOAD_0).Invokevirtual(name, "isAlive", "()Z").Branch(IFEQ, "done").
+		Op(ALOAD_0).Op(LCONST_0).Invokevirtual(jlObject, "wait", "(J)V").Branch(GOTO, "loop0").
+		//while (isAlive()) { delay = millis - now; if (delay <= 0) break; wait(delay); now = currentTimeMillis() - base; }
+		Label("timed").Op(ALOAD_0).Invokevirtual(name, "isAlive", "()Z").Branch(IFEQ, "done").
+		Op(LLOAD_1).Op(LLOAD, 5).Op(LSUB).Op(LSTORE, 7).
+		Op(LLOAD, 7).Op(LCONST_0).Op(LCMP).Branch(IFLE, "done").
+		Op(ALOAD_0).Op(LLOAD, 7).Invokevirtual(jlObject, "wait", "(J)V").
+		Invokestatic("java/lang/System", "currentTimeMillis", "()J").Op(LLOAD_3).Op(LSUB).Op(LSTORE, 5).
+		Branch(GOTO, "timed").
+		Label("done").Op(RETURN)
+	return class
+}
+
+func throwable() *Class {
+	class := New(jlThrowable, jlObject, "java/io/Serializable")
+	class.Field(ACC_PRIVATE, "detailMessage", "Ljava/lang/String;")
+	class.Field(ACC_PRIVATE|ACC_TRANSIENT, "backtrace", "Ljava/lang/Object;")
+	class.Method(ACC_PUBLIC, "<init>", "()V").Code(1, 1).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").
+		Op(ALOAD_0).Invokevirtual(jlThrowable, "fillInStackTrace", "()Ljava/lang/Throwable;").Op(POP).
+		Op(RETURN)
+	class.Method(ACC_PUBLIC, "<init>", "(Ljava/lang/String;)V").Code(2, 2).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").
+		Op(ALOAD_0).Invokevirtual(jlThrowable, "fillInStackTrace", "()Ljava/lang/Throwable;").Op(POP).
+		Op(ALOAD_0).Op(ALOAD_1).Putfield(jlThrowable, "detailMessage", "Ljava/lang/String;").
+		Op(RETURN)
+	class.Method(ACC_PUBLIC, "fillInStackTrace", "()Ljava/lang/Throwable;").Code(2, 1).
+		Op(ALOAD_0).Op(ICONST_0).Invokespecial(jlThrowable, "fillInStackTrace", "(I)Ljava/lang/Throwable;").
+		Op(ARETURN)
+	native(class, ACC_PRIVATE, "fillInStackTrace", "(I)Ljava/lang/Throwable;")
+	class.Method(ACC_PUBLIC, "getMessage", "()Ljava/lang/String;").Code(1, 1).
+		Op(ALOAD_0).Getfield(jlThrowable, "detailMessage", "Ljava/lang/String;").Op(ARETURN)
+	native(class, 0, "getStackTraceDepth", "()I")
+	native(class, 0, "getStackTraceElement", "(I)Ljava/lang/StackTraceElement;")
+	ste := "java/lang/StackTraceElement"
+	class.Method(ACC_PUBLIC, "getStackTrace", "()[Ljava/lang/StackTraceElement;").Code(4, 4).
+		Op(ALOAD_0).Invokevirtual(jlThrowable, "getStackTraceDepth", "()I").Op(ISTORE_1).
+		Op(ILOAD_1).Anewarray(ste).Op(ASTORE_2).
+		Op(ICONST_0).Op(ISTORE_3).
+		Label("loop").Op(ILOAD_3).Op(ILOAD_1).Branch(IF_ICMPGE, "done").
+		Op(ALOAD_2).Op(ILOAD_3).Op(ALOAD_0).Op(ILOAD_3).
+		Invokevirtual(jlThrowable, "getStackTraceElement", "(I)Ljava/lang/StackTraceElement;").Op(AASTORE).
+		Op(IINC, 3, 1).Branch(GOTO, "loop").
+		Label("done").Op(ALOAD_2).Op(ARETURN)
+	return class
+}
+
+/**
+	每个异常类都只有 ()V 和 (String)V 两个构造方法，一层层调用到Throwable，
+	和JDK一样，fillInStackTrace要靠这个层次来跳过构造方法的栈帧
+ */
+func exceptions() []*Class {
+	hierarchy := [][2]string{
+		{"Exception", "Throwable"},
+		{"RuntimeException", "Exception"},
+		{"NullPointerException", "RuntimeException"},
+		{"ArithmeticException", "RuntimeException"},
+		{"ClassCastException", "RuntimeException"},
+		{"ArrayStoreException", "RuntimeException"},
+		{"NegativeArraySizeException", "RuntimeException"},
+		{"IllegalArgumentException", "RuntimeException"},
+		{"IllegalThreadStateException", "IllegalArgumentException"},
+		{"IllegalMonitorStateException", "RuntimeException"},
+		{"IndexOutOfBoundsException", "RuntimeException"},
+		{"ArrayIndexOutOfBoundsException", "IndexOutOfBoundsException"},
+		{"StringIndexOutOfBoundsException", "IndexOutOfBoundsException"},
+		{"UnsupportedOperationException", "RuntimeException"},
+		{"CloneNotSupportedException", "Exception"},
+		{"InterruptedException", "Exception"},
+		{"ReflectiveOperationException", "Exception"},
+		{"ClassNotFoundException", "ReflectiveOperationException"},
+		{"NoSuchMethodException", "ReflectiveOperationException"},
+		{"NoSuchFieldException", "ReflectiveOperationException"},
+		{"Error", "Throwable"},
+		{"AssertionError", "Error"},
+		{"LinkageError", "Error"},
+		{"IncompatibleClassChangeError", "LinkageError"},
+		{"AbstractMethodError", "IncompatibleClassChangeError"},
+		{"IllegalAccessError", "IncompatibleClassChangeError"},
+		{"NoSuchFieldError", "IncompatibleClassChangeError"},
+		{"NoSuchMethodError", "IncompatibleClassChangeError"},
+		{"ClassFormatError", "LinkageError"},
+		{"VerifyError", "LinkageError"},
+		{"ClassCircularityError", "LinkageError"},
+		{"NoClassDefFoundError", "LinkageError"},
+		{"UnsatisfiedLinkError", "LinkageError"},
+		{"BootstrapMethodError", "LinkageError"},
+		{"VirtualMachineError", "Error"},
+		{"OutOfMemoryError", "VirtualMachineError"},
+		{"InternalError", "VirtualMachineError"},
+		{"StackOverflowError", "VirtualMachineError"},
+	}
+	var classes []*Class
+	for _, pair := range hierarchy {
+		name, superName := "java/lang/"+pair[0], "java/lang/"+pair[1]
+		class := New(name, superName)
+		class.Method(ACC_PUBLIC, "<init>", "()V").Code(1, 1).
+			Op(ALOAD_0).Invokespecial(superName, "<init>", "()V").Op(RETURN)
+		class.Method(ACC_PUBLIC, "<init>", "(Ljava/lang/String;)V").Code(2, 2).
+			Op(ALOAD_0).Op(ALOAD_1).Invokespecial(superName, "<init>", "(Ljava/lang/String;)V").Op(RETURN)
+		if pair[0] == "AssertionError" {
+			//javac给 assert cond : detail 生成的是 new AssertionError(Object)
+			class.Method(ACC_PUBLIC, "<init>", "(Ljava/lang/Object;)V").Code(2, 2).
+				Op(ALOAD_0).Op(ALOAD_1).Invokestatic(jlString, "valueOf", "(Ljava/lang/Object;)Ljava/lang/String;").
+				Invokespecial(superName, "<init>", "(Ljava/lang/String;)V").Op(RETURN)
+		}
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+func stackTraceElement() *Class {
+	name := "java/lang/StackTraceElement"
+	class := New(name, jlObject, "java/io/Serializable")
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	class.Field(ACC_PRIVATE, "declaringClass", "Ljava/lang/String;")
+	class.Field(ACC_PRIVATE, "methodName", "Ljava/lang/String;")
+	class.Field(ACC_PRIVATE, "fileName", "Ljava/lang/String;")
+	class.Field(ACC_PRIVATE, "lineNumber", "I")
+	for _, getter := range []struct{ method, field, descriptor string }{
+		{"getClassName", "declaringClass", "Ljava/lang/String;"},
+		{"getMethodName", "methodName", "Ljava/lang/String;"},
+		{"getFileName", "fileName", "Ljava/lang/String;"},
+		{"getLineNumber", "lineNumber", "I"},
+	} {
+		ret := byte(ARETURN)
+		if getter.descriptor == "I" {
+			ret = IRETURN
+		}
+		class.Method(ACC_PUBLIC, getter.method, "()"+getter.descriptor).Code(1, 1).
+			Op(ALOAD_0).Getfield(name, getter.field, getter.descriptor).Op(ret)
+	}
+	return class
+}
+
+func classLoader() *Class {
+	class := abstractClass("java/lang/ClassLoader", jlObject)
+	stub(class, ACC_PUBLIC, "getResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;")
+	stub(class, ACC_PUBLIC|ACC_STATIC, "getSystemResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;")
+	return class
+}
+
+func reflectMethod() *Class {
+	name := "java/lang/reflect/Method"
+	class := New(name, jlObject)
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	class.Field(ACC_PRIVATE, "clazz", "Ljava/lang/Class;")
+	class.Field(ACC_PRIVATE, "slot", "I")
+	class.Field(ACC_PRIVATE, "name", "Ljava/lang/String;")
+	class.Field(ACC_PRIVATE, "returnType", "Ljava/lang/Class;")
+	class.Field(ACC_PRIVATE, "parameterTypes", "[Ljava/lang/Class;")
+	class.Field(ACC_PRIVATE, "exceptionTypes", "[Ljava/lang/Class;")
+	class.Field(ACC_PRIVATE, "modifiers", "I")
+	class.Method(ACC_PUBLIC, "getName", "()Ljava/lang/String;").Code(1, 1).
+		Op(ALOAD_0).Getfield(name, "name", "Ljava/lang/String;").Op(ARETURN)
+	class.Method(ACC_PUBLIC, "getModifiers", "()I").Code(1, 1).
+		Op(ALOAD_0).Getfield(name, "modifiers", "I").Op(IRETURN)
+	class.Method(ACC_PUBLIC, "getReturnType", "()Ljava/lang/Class;").Code(1, 1).
+		Op(ALOAD_0).Getfield(name, "returnType", "Ljava/lang/Class;").Op(ARETURN)
+	//JDK里是 (getModifiers() & Modifier.BRIDGE) != 0
+	for _, flag := range []struct {
+		method string
+		mask   int32
+	}{{"isBridge", ACC_BRIDGE}, {"isVarArgs", ACC_VARARGS}, {"isSynthetic", ACC_SYNTHETIC}} {
+		class.Method(ACC_PUBLIC, flag.method, "()Z").Code(2, 1).
+			Op(ALOAD_0).Getfield(name, "modifiers", "I").Iconst(flag.mask).Op(IAND).Branch(IFEQ, "no").
+			Op(ICONST_1).Op(IRETURN).
+			Label("no").Op(ICONST_0).Op(IRETURN)
+	}
+	return class
+}
+
+func reflectConstructor() *Class {
+	name := "java/lang/reflect/Constructor"
+	class := New(name, jlObject)
+	class.SetAccessFlags(ACC_PUBLIC | ACC_FINAL | ACC_SUPER)
+	class.Field(ACC_PRIVATE, "clazz", "Ljava/lang/Class;")
+	class.Field(ACC_PRIVATE, "slot", "I")
+	class.Field(ACC_PRIVATE, "parameterTypes", "[Ljava/lang/Class;")
+	class.Field(ACC_PRIVATE, "exceptionTypes", "[Ljava/lang/Class;")
+	class.Field(ACC_PRIVATE, "modifiers", "I")
+	class.Method(ACC_PUBLIC, "getParameterCount", "()I").Code(1, 1).
+		Op(ALOAD_0).Getfield(name, "parameterTypes", "[Ljava/lang/Class;").Op(ARRAYLENGTH).Op(IRETURN)
+	class.Method(ACC_PUBLIC, "getModifiers", "()I").Code(1, 1).
+		Op(ALOAD_0).Getfield(name, "modifiers", "I").Op(IRETURN)
+	return class
+}
+
+func byteArrayInputStream() *Class {
+	name := "java/io/ByteArrayInputStream"
+	class := New(name, "java/io/InputStream")
+	class.Field(ACC_PROTECTED, "buf", "[B")
+	class.Field(ACC_PROTECTED, "pos", "I")
+	class.Field(ACC_PROTECTED, "mark", "I")
+	class.Field(ACC_PROTECTED, "count", "I")
+	//return (pos < count) ? (buf[pos++] & 0xff) : -1;
+	class.Method(ACC_PUBLIC|ACC_SYNCHRONIZED, "read", "()I").Code(4, 1).
+		Op(ALOAD_0).Getfield(name, "pos", "I").Op(ALOAD_0).Getfield(name, "count", "I").
+		Branch(IF_ICMPGE, "eof").
+		Op(ALOAD_0).Getfield(name, "buf", "[B").
+		Op(ALOAD_0).Op(DUP).Getfield(name, "pos", "I").Op(DUP_X1).Op(ICONST_1).Op(IADD).Putfield(name, "pos", "I").
+		Op(BALOAD).Iconst(0xff).Op(IAND).Op(IRETURN).
+		Label("eof").Op(ICONST_M1).Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_SYNCHRONIZED, "available", "()I").Code(2, 1).
+		Op(ALOAD_0).Getfield(name, "count", "I").Op(ALOAD_0).Getfield(name, "pos", "I").Op(ISUB).Op(IRETURN)
+	return class
+}
+
+/**
+	输出由jvmtest用Go实现，写到PrintStream对象extra里的io.Writer
+ */
+func printStream() *Class {
+	name := "java/io/PrintStream"
+	class := New(name, "java/io/OutputStream")
+	native(class, ACC_PUBLIC, "print", "(Ljava/lang/String;)V")
+	native(class, ACC_PUBLIC, "println", "(Ljava/lang/String;)V")
+	native(class, ACC_PUBLIC, "println", "(I)V")
+	class.Method(ACC_PUBLIC, "println", "(Ljava/lang/Object;)V").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Invokestatic(jlString, "valueOf", "(Ljava/lang/Object;)Ljava/lang/String;").
+		Invokevirtual(name, "println", "(Ljava/lang/String;)V").Op(RETURN)
+	return class
+}
+
+func reference() *Class {
+	name := "java/lang/ref/Reference"
+	class := New(name, jlObject)
+	class.SetAccessFlags(ACC_PUBLIC | ACC_SUPER | ACC_ABSTRACT)
+	class.Field(ACC_PRIVATE, "referent", "Ljava/lang/Object;")
+	class.Field(ACC_PRIVATE|ACC_TRANSIENT, "discovered", "Ljava/lang/ref/Reference;")
+	class.Field(ACC_PRIVATE|ACC_STATIC, "pending", "Ljava/lang/ref/Reference;")
+	class.Method(0, "<init>", "(Ljava/lang/Object;)V").Code(2, 2).
+		Op(ALOAD_0).Invokespecial(jlObject, "<init>", "()V").
+		Op(ALOAD_0).Op(ALOAD_1).Putfield(name, "referent", "Ljava/lang/Object;").Op(RETURN)
+	class.Method(ACC_PUBLIC, "get", "()Ljava/lang/Object;").Code(1, 1).
+		Op(ALOAD_0).Getfield(name, "referent", "Ljava/lang/Object;").Op(ARETURN)
+	class.Method(ACC_PUBLIC, "clear", "()V").Code(2, 1).
+		Op(ALOAD_0).Op(ACONST_NULL).Putfield(name, "referent", "Ljava/lang/Object;").Op(RETURN)
+	return class
+}
+
+func reference2(name string) *Class {
+	class := New(name, "java/lang/ref/Reference")
+	class.Method(ACC_PUBLIC, "<init>", "(Ljava/lang/Object;)V").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Invokespecial("java/lang/ref/Reference", "<init>", "(Ljava/lang/Object;)V").
+		Op(RETURN)
+	return class
+}
@@ -0,0 +1,131 @@
+/**
+	测试用的最小JDK。沙箱里没有JRE，java.lang里虚拟机用到的类都在这里用classgen拼出来，
+	打成 <jre>/lib/rt.jar，和真的JRE一样从启动类路径加载
+	只实现测试用到的部分：虚拟机自己实现的本地方法和intrinsic方法只有声明（intrinsic给一个会返回错误结果的方法体），
+	JDK里是java代码、虚拟机又没有实现的少数方法（比如Integer.toHexString）声明成native，由jvmtest用Go实现
+ */
+package testjdk
+
+import (
+	"GoVM/chapter2-class/classpath"
+	"GoVM/internal/classgen"
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+var (
+	jreOnce sync.Once
+	jreDir  string
+	jreErr  error
+)
+
+/**
+	生成的JRE目录。目录名里带上rt.jar内容的hash，内容不变时多次运行（以及并行跑的几个测试包）共用一个目录
+ */
+func JRE(t testing.TB) string {
+	jreOnce.Do(func() {
+		jreDir, jreErr = writeJRE()
+	})
+	if jreErr != nil {
+		t.Fatalf("testjdk: %v", jreErr)
+	}
+	return jreDir
+}
+
+func writeJRE() (string, error) {
+	jar := Jar(Classes())
+	sum := sha256.Sum256(jar)
+	dir := filepath.Join(os.TempDir(), "govm-testjdk-"+hex.EncodeToString(sum[:8]))
+	rtJar := filepath.Join(dir, "lib", "rt.jar")
+	if _, err := os.Stat(rtJar); err == nil {
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "lib", "ext"), 0755); err != nil {
+		return "", err
+	}
+	//先写临时文件再改名，别的测试进程不会读到写了一半的jar
+	tmp, err := os.CreateTemp(filepath.Join(dir, "lib"), "rt-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	_, err = tmp.Write(jar)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), rtJar)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return dir, nil
+}
+
+/**
+	把类写到一个临时目录里，返回 -Xjre 是测试JRE、-cp 是这个目录的类路径
+ */
+func Classpath(t testing.TB, classes ...*classgen.Class) *classpath.Classpath {
+	dir := t.TempDir()
+	if err := WriteClasses(dir, classes...); err != nil {
+		t.Fatal(err)
+	}
+	return classpath.Parse(JRE(t), dir)
+}
+
+/**
+	按包名建子目录，写成 <dir>/a/b/C.class
+ */
+func WriteClasses(dir string, classes ...*classgen.Class) error {
+	for _, class := range classes {
+		path := filepath.Join(dir, filepath.FromSlash(class.Name())+".class")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, class.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+	类打成jar的内容，entries按名字排序，同样的类得到同样的字节
+ */
+func Jar(classes []*classgen.Class) []byte {
+	files := map[string][]byte{}
+	for _, class := range classes {
+		files[class.Name()+".class"] = class.Bytes()
+	}
+	return Zip(files)
+}
+
+func Zip(files map[string][]byte) []byte {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, name := range names {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			panic(err)
+		}
+		f.Write(files[name])
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
@@ -4,6 +4,7 @@ import (
 	"GoVM/native"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
+	"strings"
 )
 
 const jlClass = "java/lang/Class"
@@ -13,6 +14,13 @@ func init() {
 	native.Register(jlClass, "getName0", "()Ljava/lang/String;", getName0)
 	native.Register(jlClass, "desiredAssertionStatus0", "(Ljava/lang/Class;)Z", desiredAssertionStatus0)
 	native.Register(jlClass, "isInterface", "()Z", isInterface)
+	native.Register(jlClass, "getComponentType", "()Ljava/lang/Class;", getComponentType)
+	native.Register(jlClass, "getDeclaringClass0", "()Ljava/lang/Class;", getDeclaringClass0)
+	native.Register(jlClass, "getDeclaredConstructors0", "(Z)[Ljava/lang/reflect/Constructor;", getDeclaredConstructors0)
+	native.RegisterIntrinsic(jlClass, "getDeclaredMethod", "(Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;", classGetDeclaredMethod)
+	native.RegisterIntrinsic(jlClass, "getMethod", "(Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;", classGetMethod)
+	native.RegisterIntrinsic(jlClass, "getEnclosingClass", "()Ljava/lang/Class;", getEnclosingClass)
+	native.RegisterIntrinsic(jlClass, "getResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;", classGetResourceAsStream)
 }
 
 func getPrimitiveClass(frame *chapter4_rtdt.Frame) {
@@ -35,11 +43,35 @@ func getName0(frame *chapter4_rtdt.Frame) {
 	frame.OperandStack().PushRef(nameObj)
 }
 
+/**
+	-ea/-da 设置的默认断言状态，默认关闭
+	和HotSpot一样，-ea 不打开JDK自己的类里的断言（那需要 -esa）
+ */
+var assertionsEnabled = false
+
+func SetAssertionsEnabled(enabled bool) {
+	assertionsEnabled = enabled
+}
+
 /**
 	断言相关
+	assert语句编译之后会在<clinit>里用 desiredAssertionStatus() 初始化 $assertionsDisabled 字段
  */
+// private static native boolean desiredAssertionStatus0(Class<?> clazz);
+// (Ljava/lang/Class;)Z
 func desiredAssertionStatus0(frame *chapter4_rtdt.Frame) {
-	frame.OperandStack().PushBoolean(false)
+	jClass := frame.LocalVars().GetRef(0)
+	class := jClass.Extra().(*heap.Class)
+	frame.OperandStack().PushBoolean(assertionsEnabled && !isSystemClass(class.Name()))
+}
+
+func isSystemClass(className string) bool {
+	for _, prefix := range []string{"java/", "javax/", "sun/", "jdk/"} {
+		if strings.HasPrefix(className, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // public native boolean isInterface();
@@ -53,6 +85,258 @@ func isInterface(frame *chapter4_rtdt.Frame) {
 	stack.PushBoolean(class.IsInterface())
 }
 
+/**
+	数组类返回元素类型的类对象，比如int[]返回int.class，非数组类返回null
+ */
+// public native Class<?> getComponentType();
+// ()Ljava/lang/Class;
+func getComponentType(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	class := this.Extra().(*heap.Class)
+
+	stack := frame.OperandStack()
+	if class.IsArray() {
+		stack.PushRef(class.ComponentClass().JClass())
+	} else {
+		stack.PushRef(nil)
+	}
+}
+
+// private native Class<?> getDeclaringClass0();
+// ()Ljava/lang/Class;
+func getDeclaringClass0(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	class := this.Extra().(*heap.Class)
+
+	frame.OperandStack().PushRef(jClassOrNil(class.DeclaringClass()))
+}
+
+/**
+	JDK里是java代码，要经过getEnclosingMethod0等一串调用，这里直接用class文件里的属性
+ */
+// public Class<?> getEnclosingClass();
+// ()Ljava/lang/Class;
+func getEnclosingClass(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	class := this.Extra().(*heap.Class)
+
+	frame.OperandStack().PushRef(jClassOrNil(class.EnclosingClass()))
+}
+
+/**
+	相对路径的资源名要加上类所在的包，以/开头的是类路径上的绝对路径
+ */
+// public InputStream getResourceAsStream(String name);
+// (Ljava/lang/String;)Ljava/io/InputStream;
+func classGetResourceAsStream(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	class := vars.GetThis().Extra().(*heap.Class)
+	nameObj := vars.GetRef(1)
+	if nameObj == nil {
+		frame.ThrowException("java/lang/NullPointerException", "")
+		return
+	}
+
+	name := resolveResourceName(class, heap.GoString(nameObj))
+	frame.OperandStack().PushRef(openResource(class.Loader(), name))
+}
+
+func resolveResourceName(class *heap.Class, name string) string {
+	if strings.HasPrefix(name, "/") {
+		return name[1:]
+	}
+	//数组类用元素类型所在的包
+	className := strings.TrimLeft(class.Name(), "[")
+	className = strings.TrimPrefix(className, "L")
+	if i := strings.LastIndex(className, "/"); i >= 0 {
+		return className[:i + 1] + name
+	}
+	return name
+}
+
+/**
+	Constructor对象没有调用构造函数，直接给字段赋值
+	slot 是构造函数在Class.Methods()里的下标，以后实现newInstance时可以用它找到方法
+ */
+// private native Constructor<T>[] getDeclaredConstructors0(boolean publicOnly);
+// (Z)[Ljava/lang/reflect/Constructor;
+func getDeclaredConstructors0(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	class := vars.GetThis().Extra().(*heap.Class)
+	publicOnly := vars.GetInt(1) == 1
+
+	loader := frame.Method().Class().Loader()
+	constructorClass := loader.LoadClass("java/lang/reflect/Constructor")
+
+	var constructors []*heap.Object
+	for slot, method := range class.Methods() {
+		if !method.IsConstructor() || (publicOnly && !method.IsPublic()) {
+			continue
+		}
+		constructor := constructorClass.NewObject()
+		constructor.SetRefVar("clazz", "Ljava/lang/Class;", class.JClass())
+		constructor.SetIntVar("slot", "I", int32(slot))
+		constructor.SetIntVar("modifiers", "I", int32(method.AccessFlags()))
+		constructor.SetRefVar("parameterTypes", "[Ljava/lang/Class;", toClassArr(loader, method.ParameterTypes()))
+		constructor.SetRefVar("exceptionTypes", "[Ljava/lang/Class;", toClassArr(loader, method.ExceptionTypes()))
+		constructors = append(constructors, constructor)
+	}
+
+	arr := constructorClass.ArrayClass().NewArray(uint(len(constructors)))
+	copy(arr.Refs(), constructors)
+	frame.OperandStack().PushRef(arr)
+}
+
+/**
+	按 名字 + 参数类型 找方法：参数类型拼成描述符的参数部分，再和方法描述符的前缀比较。
+	返回值不参与匹配，同名同参数的只有桥接方法，这时优先返回非桥接的那个
+ */
+// public Method getDeclaredMethod(String name, Class<?>... parameterTypes);
+// (Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;
+func classGetDeclaredMethod(frame *chapter4_rtdt.Frame) {
+	class, name, paramTypes, ok := methodQuery(frame)
+	if !ok {
+		return
+	}
+	method := findMethodByParams(class.Methods(), name, paramsDescriptor(paramTypes), false)
+	pushReflectMethod(frame, class, method, name, paramTypes)
+}
+
+/**
+	getMethod只找public方法：类自己的、继承的和接口里的
+ */
+// public Method getMethod(String name, Class<?>... parameterTypes);
+// (Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;
+func classGetMethod(frame *chapter4_rtdt.Frame) {
+	class, name, paramTypes, ok := methodQuery(frame)
+	if !ok {
+		return
+	}
+	descriptor := paramsDescriptor(paramTypes)
+	method := findMethodByParams(class.AllMethods(), name, descriptor, true)
+	if method == nil {
+		method = findInterfaceMethodByParams(class, name, descriptor)
+	}
+	pushReflectMethod(frame, class, method, name, paramTypes)
+}
+
+/**
+	name或者parameterTypes里有null时抛出NullPointerException，返回false
+ */
+func methodQuery(frame *chapter4_rtdt.Frame) (*heap.Class, string, []*heap.Class, bool) {
+	vars := frame.LocalVars()
+	class := vars.GetThis().Extra().(*heap.Class)
+	nameObj := vars.GetRef(1)
+	if nameObj == nil {
+		frame.ThrowException("java/lang/NullPointerException", "")
+		return nil, "", nil, false
+	}
+
+	//parameterTypes 为null相当于没有参数
+	var paramTypes []*heap.Class
+	if jParamTypes := vars.GetRef(2); jParamTypes != nil {
+		for _, jClass := range jParamTypes.Refs() {
+			if jClass == nil {
+				frame.ThrowException("java/lang/NullPointerException", "")
+				return nil, "", nil, false
+			}
+			paramTypes = append(paramTypes, jClass.Extra().(*heap.Class))
+		}
+	}
+	return class, heap.GoString(nameObj), paramTypes, true
+}
+
+// (int, java.lang.String) -> (ILjava/lang/String;)
+func paramsDescriptor(paramTypes []*heap.Class) string {
+	descriptor := "("
+	for _, paramType := range paramTypes {
+		descriptor += paramType.Descriptor()
+	}
+	return descriptor + ")"
+}
+
+func findMethodByParams(methods []*heap.Method, name, paramsDescriptor string, publicOnly bool) *heap.Method {
+	var found *heap.Method
+	for _, method := range methods {
+		if method.Name() != name || !strings.HasPrefix(method.Descriptor(), paramsDescriptor) ||
+			(publicOnly && !method.IsPublic()) {
+			continue
+		}
+		if found == nil || found.IsBridge() {
+			found = method
+		}
+	}
+	return found
+}
+
+/**
+	AllMethods()里只有接口的default方法，抽象的接口方法要单独找
+ */
+func findInterfaceMethodByParams(class *heap.Class, name, paramsDescriptor string) *heap.Method {
+	for c := class; c != nil; c = c.SuperClass() {
+		for _, iface := range c.Interfaces() {
+			if method := findMethodByParams(iface.Methods(), name, paramsDescriptor, true); method != nil {
+				return method
+			}
+			if method := findInterfaceMethodByParams(iface, name, paramsDescriptor); method != nil {
+				return method
+			}
+		}
+	}
+	return nil
+}
+
+/**
+	和Constructor一样，Method对象也是直接给字段赋值，slot是方法在声明它的类的Methods()里的下标
+ */
+func pushReflectMethod(frame *chapter4_rtdt.Frame, class *heap.Class, method *heap.Method, name string, paramTypes []*heap.Class) {
+	if method == nil || method.IsConstructor() || method.IsClinit() {
+		//和JDK的信息格式一样，比如 java.lang.String.foo(int, java.lang.String)
+		paramNames := make([]string, len(paramTypes))
+		for i, paramType := range paramTypes {
+			paramNames[i] = paramType.JavaName()
+		}
+		frame.ThrowException("java/lang/NoSuchMethodException",
+			class.JavaName() + "." + name + "(" + strings.Join(paramNames, ", ") + ")")
+		return
+	}
+
+	loader := frame.Method().Class().Loader()
+	declaringClass := method.Class()
+	slot := 0
+	for i, m := range declaringClass.Methods() {
+		if m == method {
+			slot = i
+		}
+	}
+
+	jMethod := loader.LoadClass("java/lang/reflect/Method").NewObject()
+	jMethod.SetRefVar("clazz", "Ljava/lang/Class;", declaringClass.JClass())
+	jMethod.SetIntVar("slot", "I", int32(slot))
+	jMethod.SetRefVar("name", "Ljava/lang/String;", heap.JString(loader, method.Name()))
+	jMethod.SetRefVar("returnType", "Ljava/lang/Class;", method.ReturnType().JClass())
+	jMethod.SetRefVar("parameterTypes", "[Ljava/lang/Class;", toClassArr(loader, method.ParameterTypes()))
+	jMethod.SetRefVar("exceptionTypes", "[Ljava/lang/Class;", toClassArr(loader, method.ExceptionTypes()))
+	jMethod.SetIntVar("modifiers", "I", int32(method.AccessFlags()))
+	frame.OperandStack().PushRef(jMethod)
+}
+
+func toClassArr(loader *heap.ClassLoader, classes []*heap.Class) *heap.Object {
+	arr := loader.LoadClass("java/lang/Class").ArrayClass().NewArray(uint(len(classes)))
+	refs := arr.Refs()
+	for i, class := range classes {
+		refs[i] = class.JClass()
+	}
+	return arr
+}
+
+func jClassOrNil(class *heap.Class) *heap.Object {
+	if class == nil {
+		return nil
+	}
+	return class.JClass()
+}
+
 // public native boolean isPrimitive();
 // ()Z
 //func isPrimitive(frame *chapter4_rtdt.Frame) {
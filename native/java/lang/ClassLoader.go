@@ -0,0 +1,70 @@
+package lang
+
+import (
+	"GoVM/native"
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+)
+
+const jlClassLoader = "java/lang/ClassLoader"
+
+/**
+	JDK里这些方法要经过 getResource -> URL -> openStream，依赖一整套URL和文件系统的类
+	这里直接用虚拟机的类路径读出资源，包装成ByteArrayInputStream
+ */
+func init() {
+	native.RegisterIntrinsic(jlClassLoader, "getResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;", classLoaderGetResourceAsStream)
+	native.RegisterIntrinsic(jlClassLoader, "getSystemResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;", getSystemResourceAsStream)
+}
+
+// public InputStream getResourceAsStream(String name);
+// (Ljava/lang/String;)Ljava/io/InputStream;
+func classLoaderGetResourceAsStream(frame *chapter4_rtdt.Frame) {
+	nameObj := frame.LocalVars().GetRef(1)
+	pushResourceAsStream(frame, nameObj)
+}
+
+// public static InputStream getSystemResourceAsStream(String name);
+// (Ljava/lang/String;)Ljava/io/InputStream;
+func getSystemResourceAsStream(frame *chapter4_rtdt.Frame) {
+	nameObj := frame.LocalVars().GetRef(0)
+	pushResourceAsStream(frame, nameObj)
+}
+
+func pushResourceAsStream(frame *chapter4_rtdt.Frame, nameObj *heap.Object) {
+	if nameObj == nil {
+		frame.ThrowException("java/lang/NullPointerException", "")
+		return
+	}
+	loader := frame.Method().Class().Loader()
+	frame.OperandStack().PushRef(openResource(loader, heap.GoString(nameObj)))
+}
+
+/**
+	找不到资源时返回null，和JDK一样
+ */
+func openResource(loader *heap.ClassLoader, name string) *heap.Object {
+	data, err := loader.ReadResource(name)
+	if err != nil {
+		return nil
+	}
+	return newByteArrayInputStream(loader, data)
+}
+
+/**
+	没有调用构造函数，直接按 ByteArrayInputStream(byte[] buf) 的逻辑给字段赋值
+ */
+func newByteArrayInputStream(loader *heap.ClassLoader, data []byte) *heap.Object {
+	buf := loader.LoadClass("[B").NewArray(uint(len(data)))
+	bytes := buf.Bytes()
+	for i, b := range data {
+		bytes[i] = int8(b)
+	}
+
+	stream := loader.LoadClass("java/io/ByteArrayInputStream").NewObject()
+	stream.SetRefVar("buf", "[B", buf)
+	stream.SetIntVar("pos", "I", 0)
+	stream.SetIntVar("mark", "I", 0)
+	stream.SetIntVar("count", "I", int32(len(data)))
+	return stream
+}
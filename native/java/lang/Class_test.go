@@ -0,0 +1,433 @@
+package lang_test
+
+import (
+	"GoVM/chapter6-obj/heap"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"GoVM/native/java/lang"
+	"testing"
+)
+
+const (
+	findMethodDescriptor = "(Ljava/lang/Class;Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;"
+	resourceDescriptor   = "(Ljava/lang/Class;Ljava/lang/String;)Ljava/io/InputStream;"
+)
+
+/**
+	class Overloads { public int f(int x); public String f(String s); }
+	以及转调 Class.getDeclaredMethod、getMethod、getResourceAsStream 的静态方法
+ */
+func newReflectionVM(t *testing.T) *jvmtest.VM {
+	overloads := New("lang/Overloads", "java/lang/Object").DefaultConstructor()
+	overloads.Method(ACC_PUBLIC, "f", "(I)I").Code(1, 2).Op(ILOAD_1).Op(IRETURN)
+	overloads.Method(ACC_PUBLIC, "f", "(Ljava/lang/String;)Ljava/lang/String;").Code(1, 2).Op(ALOAD_1).Op(ARETURN)
+
+	class := New("lang/Reflection", "java/lang/Object")
+	for _, name := range []string{"getDeclaredMethod", "getMethod"} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, findMethodDescriptor).Code(3, 3).
+			Op(ALOAD_0).Op(ALOAD_1).Op(ALOAD_2).
+			Invokevirtual("java/lang/Class", name, "(Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;").
+			Op(ARETURN)
+	}
+	class.Method(ACC_PUBLIC|ACC_STATIC, "getResourceAsStream", resourceDescriptor).Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).
+		Invokevirtual("java/lang/Class", "getResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;").Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "getSystemResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;").Code(1, 1).
+		Op(ALOAD_0).
+		Invokestatic("java/lang/ClassLoader", "getSystemResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;").
+		Op(ARETURN)
+	return jvmtest.New(t, overloads, class)
+}
+
+func classArray(vm *jvmtest.VM, names ...string) *heap.Object {
+	arr := vm.Class("[Ljava/lang/Class;").NewArray(uint(len(names)))
+	for i, name := range names {
+		if name != "" {
+			arr.Refs()[i] = vm.Class(name).JClass()
+		}
+	}
+	return arr
+}
+
+func reflectedClass(method *heap.Object, field string) string {
+	return method.GetRefVar(field, "Ljava/lang/Class;").Extra().(*heap.Class).Name()
+}
+
+func TestGetDeclaredMethodPicksOverload(t *testing.T) {
+	vm := newReflectionVM(t)
+	jClass := vm.Class("lang/Overloads").JClass()
+
+	for _, test := range []struct{ param, returnType string }{
+		{"int", "int"},
+		{"java/lang/String", "java/lang/String"},
+	} {
+		method := vm.Call("lang/Reflection", "getDeclaredMethod", findMethodDescriptor,
+			jClass, "f", classArray(vm, test.param)).Ref()
+		if got := heap.GoString(method.GetRefVar("name", "Ljava/lang/String;")); got != "f" {
+			t.Errorf("name = %q", got)
+		}
+		if got := reflectedClass(method, "returnType"); got != test.returnType {
+			t.Errorf("f(%s) returns %s, want %s", test.param, got, test.returnType)
+		}
+		params := method.GetRefVar("parameterTypes", "[Ljava/lang/Class;").Refs()
+		if len(params) != 1 || params[0].Extra().(*heap.Class).Name() != test.param {
+			t.Errorf("f(%s) has %d parameters", test.param, len(params))
+		}
+	}
+
+	msg := vm.Call("lang/Reflection", "getDeclaredMethod", findMethodDescriptor,
+		jClass, "f", classArray(vm, "long")).Throws("java/lang/NoSuchMethodException")
+	if msg != "lang.Overloads.f(long)" {
+		t.Errorf("message = %q", msg)
+	}
+	//继承的方法不是declared的
+	vm.Call("lang/Reflection", "getDeclaredMethod", findMethodDescriptor, jClass, "hashCode", nil).
+		Throws("java/lang/NoSuchMethodException")
+}
+
+func TestGetMethodFindsInheritedPublicMethod(t *testing.T) {
+	vm := newReflectionVM(t)
+	jClass := vm.Class("lang/Overloads").JClass()
+
+	method := vm.Call("lang/Reflection", "getMethod", findMethodDescriptor, jClass, "hashCode", nil).Ref()
+	if got := reflectedClass(method, "clazz"); got != "java/lang/Object" {
+		t.Errorf("hashCode declared in %s", got)
+	}
+	//clone是protected的
+	vm.Call("lang/Reflection", "getMethod", findMethodDescriptor, jClass, "clone", nil).
+		Throws("java/lang/NoSuchMethodException")
+}
+
+func TestReflectionNullArgumentsThrowNPE(t *testing.T) {
+	vm := newReflectionVM(t)
+	jClass := vm.Class("lang/Overloads").JClass()
+
+	vm.Call("lang/Reflection", "getDeclaredMethod", findMethodDescriptor, jClass, nil, nil).
+		Throws("java/lang/NullPointerException")
+	vm.Call("lang/Reflection", "getMethod", findMethodDescriptor, jClass, "f", classArray(vm, "")).
+		Throws("java/lang/NullPointerException")
+	vm.Call("lang/Reflection", "getResourceAsStream", resourceDescriptor, jClass, nil).
+		Throws("java/lang/NullPointerException")
+	vm.Call("lang/Reflection", "getSystemResourceAsStream", "(Ljava/lang/String;)Ljava/io/InputStream;", nil).
+		Throws("java/lang/NullPointerException")
+}
+
+/**
+	class Box implements Comparable<Box> {
+		public int compareTo(Box other) { return 0; }
+		public int compareTo(Object other) { return compareTo((Box) other); }  //javac生成的桥接方法
+		public static int sum(int... xs) { return xs.length; }
+	}
+ */
+func newBridgeVM(t *testing.T) *jvmtest.VM {
+	box := New("lang/Box", "java/lang/Object", "java/lang/Comparable").DefaultConstructor()
+	box.Method(ACC_PUBLIC, "compareTo", "(Llang/Box;)I").Code(1, 2).Op(ICONST_0).Op(IRETURN)
+	box.Method(ACC_PUBLIC|ACC_BRIDGE|ACC_SYNTHETIC, "compareTo", "(Ljava/lang/Object;)I").Code(2, 2).
+		Op(ALOAD_0).Op(ALOAD_1).Checkcast("lang/Box").Invokevirtual("lang/Box", "compareTo", "(Llang/Box;)I").
+		Op(IRETURN)
+	box.Method(ACC_PUBLIC|ACC_STATIC|ACC_VARARGS, "sum", "([I)I").Code(1, 1).
+		Op(ALOAD_0).Op(ARRAYLENGTH).Op(IRETURN)
+
+	class := New("lang/Flags", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "getDeclaredMethod", findMethodDescriptor).Code(3, 3).
+		Op(ALOAD_0).Op(ALOAD_1).Op(ALOAD_2).
+		Invokevirtual("java/lang/Class", "getDeclaredMethod", "(Ljava/lang/String;[Ljava/lang/Class;)Ljava/lang/reflect/Method;").
+		Op(ARETURN)
+	for _, flag := range []string{"isBridge", "isVarArgs", "isSynthetic"} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, flag, "(Ljava/lang/reflect/Method;)Z").Code(1, 1).
+			Op(ALOAD_0).Invokevirtual("java/lang/reflect/Method", flag, "()Z").Op(IRETURN)
+	}
+	return jvmtest.New(t, box, class)
+}
+
+func TestReflectionReportsBridgeAndVarargs(t *testing.T) {
+	vm := newBridgeVM(t)
+	box := vm.Class("lang/Box")
+
+	var bridges, varargs []string
+	for _, method := range box.Methods() {
+		if method.IsBridge() {
+			bridges = append(bridges, method.Name() + method.Descriptor())
+		}
+		if method.IsVarargs() {
+			varargs = append(varargs, method.Name() + method.Descriptor())
+		}
+	}
+	if len(bridges) != 1 || bridges[0] != "compareTo(Ljava/lang/Object;)I" {
+		t.Errorf("bridge methods = %v", bridges)
+	}
+	if len(varargs) != 1 || varargs[0] != "sum([I)I" {
+		t.Errorf("varargs methods = %v", varargs)
+	}
+
+	for _, test := range []struct {
+		name, param                string
+		bridge, varArgs, synthetic bool
+	}{
+		{"compareTo", "lang/Box", false, false, false},
+		{"compareTo", "java/lang/Object", true, false, true},
+		{"sum", "[I", false, true, false},
+	} {
+		jMethod := vm.Call("lang/Flags", "getDeclaredMethod", findMethodDescriptor,
+			box.JClass(), test.name, classArray(vm, test.param)).Ref()
+		for _, flag := range []struct {
+			method string
+			want   bool
+		}{{"isBridge", test.bridge}, {"isVarArgs", test.varArgs}, {"isSynthetic", test.synthetic}} {
+			got := vm.Call("lang/Flags", flag.method, "(Ljava/lang/reflect/Method;)Z", jMethod).Bool()
+			if got != flag.want {
+				t.Errorf("%s(%s).%s() = %v, want %v", test.name, test.param, flag.method, got, flag.want)
+			}
+		}
+	}
+}
+
+func TestGetComponentType(t *testing.T) {
+	class := New("lang/Components", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "of", "(Ljava/lang/Class;)Ljava/lang/Class;").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual("java/lang/Class", "getComponentType", "()Ljava/lang/Class;").Op(ARETURN)
+	vm := jvmtest.New(t, class)
+
+	for _, test := range []struct{ array, component string }{
+		{"[I", "int"},
+		{"[Ljava/lang/String;", "java/lang/String"},
+		{"[[Ljava/lang/String;", "[Ljava/lang/String;"},
+		{"java/lang/String", ""},
+		{"int", ""},
+	} {
+		got := vm.Call("lang/Components", "of", "(Ljava/lang/Class;)Ljava/lang/Class;", vm.Class(test.array).JClass()).Ref()
+		if test.component == "" {
+			if got != nil {
+				t.Errorf("%s.getComponentType() = %s, want null", test.array, got.Extra().(*heap.Class).Name())
+			}
+			continue
+		}
+		//基本类型也是同一个类对象：int[].class.getComponentType() == int.class
+		if want := vm.Class(test.component).JClass(); got != want {
+			t.Errorf("%s.getComponentType() = %v, want the %s class object", test.array, got, test.component)
+		}
+	}
+}
+
+/**
+	javac把int.class编译成getstatic Integer.TYPE
+		static Class Integer() { return Integer.TYPE; }
+ */
+func TestWrapperTypeIsPrimitiveClass(t *testing.T) {
+	wrappers := map[string]string{"Boolean": "boolean", "Character": "char", "Byte": "byte", "Short": "short",
+		"Integer": "int", "Long": "long", "Float": "float", "Double": "double"}
+	class := New("lang/Types", "java/lang/Object")
+	for wrapper := range wrappers {
+		class.Method(ACC_PUBLIC|ACC_STATIC, wrapper, "()Ljava/lang/Class;").Code(1, 0).
+			Getstatic("java/lang/"+wrapper, "TYPE", "Ljava/lang/Class;").Op(ARETURN)
+	}
+	vm := jvmtest.New(t, class)
+
+	for wrapper, primitive := range wrappers {
+		got := vm.Call("lang/Types", wrapper, "()Ljava/lang/Class;").Ref()
+		if want := vm.Class(primitive).JClass(); got == nil || got != want {
+			t.Errorf("%s.TYPE = %v, want the %s class object %v", wrapper, got, primitive, want)
+		}
+		if got == vm.Class("java/lang/" + wrapper).JClass() {
+			t.Errorf("%s.TYPE is %s.class", wrapper, wrapper)
+		}
+	}
+}
+
+func u2s(values ...uint16) []byte {
+	var b []byte
+	for _, v := range values {
+		b = append(b, byte(v >> 8), byte(v))
+	}
+	return b
+}
+
+/**
+	class Outer {
+		class Inner {}                                   //成员类：只有InnerClasses属性
+		Object make() { return new Object() {}; }        //匿名类 Outer$1：EnclosingMethod属性，InnerClasses里外部类为0
+	}
+ */
+func TestGetEnclosingClass(t *testing.T) {
+	outer := New("lang/Outer", "java/lang/Object").DefaultConstructor()
+	inner := New("lang/Outer$Inner", "java/lang/Object")
+	inner.Attribute("InnerClasses", u2s(1,
+		inner.ClassInfo("lang/Outer$Inner"), inner.ClassInfo("lang/Outer"), inner.Utf8("Inner"), ACC_PUBLIC))
+	anonymous := New("lang/Outer$1", "java/lang/Object")
+	anonymous.Attribute("EnclosingMethod", u2s(
+		anonymous.ClassInfo("lang/Outer"), anonymous.NameAndType("make", "()Ljava/lang/Object;")))
+	anonymous.Attribute("InnerClasses", u2s(1, anonymous.ClassInfo("lang/Outer$1"), 0, 0, 0))
+
+	class := New("lang/Nesting", "java/lang/Object")
+	for _, name := range []string{"getEnclosingClass", "getDeclaringClass"} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(Ljava/lang/Class;)Ljava/lang/Class;").Code(1, 1).
+			Op(ALOAD_0).Invokevirtual("java/lang/Class", name, "()Ljava/lang/Class;").Op(ARETURN)
+	}
+	vm := jvmtest.New(t, outer, inner, anonymous, class)
+
+	for _, test := range []struct{ class, enclosing, declaring string }{
+		{"lang/Outer$Inner", "lang/Outer", "lang/Outer"},
+		//匿名类不是成员，没有声明它的类
+		{"lang/Outer$1", "lang/Outer", ""},
+		{"lang/Outer", "", ""},
+	} {
+		jClass := vm.Class(test.class).JClass()
+		for _, method := range []struct{ name, want string }{
+			{"getEnclosingClass", test.enclosing}, {"getDeclaringClass", test.declaring},
+		} {
+			got := vm.Call("lang/Nesting", method.name, "(Ljava/lang/Class;)Ljava/lang/Class;", jClass).Ref()
+			var want *heap.Object
+			if method.want != "" {
+				want = vm.Class(method.want).JClass()
+			}
+			if got != want {
+				t.Errorf("%s.%s() = %v, want %s", test.class, method.name, got, method.want)
+			}
+		}
+	}
+}
+
+/**
+	javac编译assert语句的样子：
+	class Asserts {
+		static final boolean $assertionsDisabled = !Asserts.class.desiredAssertionStatus();
+		static void check(int x) { assert x > 0; }
+		static boolean status(Class<?> c) { return c.desiredAssertionStatus(); }
+	}
+ */
+func newAssertVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Asserts", "java/lang/Object")
+	class.Field(ACC_STATIC|ACC_FINAL|ACC_SYNTHETIC, "$assertionsDisabled", "Z")
+	class.Method(ACC_STATIC, "<clinit>", "()V").Code(1, 0).
+		LdcClass("lang/Asserts").Invokevirtual("java/lang/Class", "desiredAssertionStatus", "()Z").
+		Branch(IFNE, "enabled").Op(ICONST_1).Branch(GOTO, "store").
+		Label("enabled").Op(ICONST_0).
+		Label("store").Putstatic("lang/Asserts", "$assertionsDisabled", "Z").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "check", "(I)V").Code(2, 1).
+		Getstatic("lang/Asserts", "$assertionsDisabled", "Z").Branch(IFNE, "ok").
+		Op(ILOAD_0).Branch(IFGT, "ok").
+		New("java/lang/AssertionError").Op(DUP).
+		Invokespecial("java/lang/AssertionError", "<init>", "()V").Op(ATHROW).
+		Label("ok").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "status", "(Ljava/lang/Class;)Z").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual("java/lang/Class", "desiredAssertionStatus", "()Z").Op(IRETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestAssertFiresOnlyUnderEa(t *testing.T) {
+	vm := newAssertVM(t)
+	//默认关闭：条件不成立也不抛异常
+	if thrown := vm.Call("lang/Asserts", "check", "(I)V", int32(-1)).Thrown; thrown != nil {
+		t.Fatalf("assert threw %s with assertions disabled", thrown.Class().Name())
+	}
+
+	lang.SetAssertionsEnabled(true)
+	t.Cleanup(func() { lang.SetAssertionsEnabled(false) })
+	//$assertionsDisabled在<clinit>里初始化，要换一个虚拟机重新加载类
+	vm = newAssertVM(t)
+	if thrown := vm.Call("lang/Asserts", "check", "(I)V", int32(1)).Thrown; thrown != nil {
+		t.Fatalf("assert 1 > 0 threw %s", thrown.Class().Name())
+	}
+	vm.Call("lang/Asserts", "check", "(I)V", int32(-1)).Throws("java/lang/AssertionError")
+
+	//-ea 不打开JDK自己的类里的断言
+	status := func(className string) bool {
+		return vm.Call("lang/Asserts", "status", "(Ljava/lang/Class;)Z", vm.Class(className).JClass()).Bool()
+	}
+	if !status("lang/Asserts") || status("java/lang/String") {
+		t.Errorf("desiredAssertionStatus under -ea: lang/Asserts %v, java/lang/String %v",
+			status("lang/Asserts"), status("java/lang/String"))
+	}
+}
+
+/**
+	class文件本身也是类路径上的资源，读出来的应该是整个class文件
+ */
+func TestGetResourceAsStreamReadsClasspathFile(t *testing.T) {
+	vm := newReflectionVM(t)
+	jClass := vm.Class("lang/Overloads").JClass()
+	//相对路径加上类所在的包，和绝对路径是同一个文件
+	for _, name := range []string{"Overloads.class", "/lang/Overloads.class"} {
+		stream := vm.Call("lang/Reflection", "getResourceAsStream", resourceDescriptor, jClass, name).Ref()
+		if stream == nil || stream.Class().Name() != "java/io/ByteArrayInputStream" {
+			t.Fatalf("getResourceAsStream(%s) = %v", name, stream)
+		}
+		buf := stream.GetRefVar("buf", "[B").Bytes()
+		if count := stream.GetIntVar("count", "I"); len(buf) < 4 || int(count) != len(buf) {
+			t.Fatalf("%s: stream has %d bytes, count %d", name, len(buf), count)
+		}
+		if magic := uint32(uint8(buf[0])) << 24 | uint32(uint8(buf[1])) << 16 | uint32(uint8(buf[2])) << 8 | uint32(uint8(buf[3])); magic != 0xCAFEBABE {
+			t.Errorf("%s starts with %#x, want the class file magic", name, magic)
+		}
+	}
+	if stream := vm.Call("lang/Reflection", "getResourceAsStream", resourceDescriptor, jClass, "missing.txt").Ref(); stream != nil {
+		t.Error("missing resource did not return null")
+	}
+}
+
+/**
+	class Ctors {
+		public Ctors() {}
+		public Ctors(int n, String s) throws Exception {}
+		private Ctors(long l) {}
+	}
+ */
+func TestGetDeclaredConstructors(t *testing.T) {
+	ctors := New("lang/Ctors", "java/lang/Object").DefaultConstructor()
+	ctors.Method(ACC_PUBLIC, "<init>", "(ILjava/lang/String;)V").
+		Attribute("Exceptions", u2s(1, ctors.ClassInfo("java/lang/Exception"))).Code(1, 3).
+		Op(ALOAD_0).Invokespecial("java/lang/Object", "<init>", "()V").Op(RETURN)
+	ctors.Method(ACC_PRIVATE, "<init>", "(J)V").Code(1, 3).
+		Op(ALOAD_0).Invokespecial("java/lang/Object", "<init>", "()V").Op(RETURN)
+	class := New("lang/Constructors", "java/lang/Object")
+	for _, name := range []string{"getDeclaredConstructors", "getConstructors"} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(Ljava/lang/Class;)[Ljava/lang/reflect/Constructor;").Code(1, 1).
+			Op(ALOAD_0).Invokevirtual("java/lang/Class", name, "()[Ljava/lang/reflect/Constructor;").Op(ARETURN)
+	}
+	class.Method(ACC_PUBLIC|ACC_STATIC, "parameterCount", "(Ljava/lang/reflect/Constructor;)I").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual("java/lang/reflect/Constructor", "getParameterCount", "()I").Op(IRETURN)
+	vm := jvmtest.New(t, ctors, class)
+	jClass := vm.Class("lang/Ctors").JClass()
+
+	constructors := func(method string) map[string]*heap.Object {
+		arr := vm.Call("lang/Constructors", method, "(Ljava/lang/Class;)[Ljava/lang/reflect/Constructor;", jClass).Ref()
+		byParams := map[string]*heap.Object{}
+		for _, constructor := range arr.Refs() {
+			if constructor.GetRefVar("clazz", "Ljava/lang/Class;") != jClass {
+				t.Errorf("%s: constructor of another class", method)
+			}
+			var params string
+			for _, param := range constructor.GetRefVar("parameterTypes", "[Ljava/lang/Class;").Refs() {
+				params += param.Extra().(*heap.Class).Name() + ";"
+			}
+			byParams[params] = constructor
+		}
+		return byParams
+	}
+	declared := constructors("getDeclaredConstructors")
+	if len(declared) != 3 {
+		t.Fatalf("getDeclaredConstructors() returned %d constructors, want 3", len(declared))
+	}
+	public := constructors("getConstructors")
+	if _, ok := public["long;"]; len(public) != 2 || ok {
+		t.Errorf("getConstructors() returned %d constructors, want the 2 public ones", len(public))
+	}
+
+	twoArgs := declared["int;java/lang/String;"]
+	if twoArgs == nil {
+		t.Fatalf("no (int, String) constructor among %v", declared)
+	}
+	if got := vm.Call("lang/Constructors", "parameterCount", "(Ljava/lang/reflect/Constructor;)I", twoArgs).Int(); got != 2 {
+		t.Errorf("getParameterCount() = %d, want 2", got)
+	}
+	if got := twoArgs.GetIntVar("modifiers", "I"); got != ACC_PUBLIC {
+		t.Errorf("modifiers = %#x, want public", got)
+	}
+	exceptions := twoArgs.GetRefVar("exceptionTypes", "[Ljava/lang/Class;").Refs()
+	if len(exceptions) != 1 || exceptions[0] != vm.Class("java/lang/Exception").JClass() {
+		t.Errorf("exceptionTypes = %v, want [Exception]", exceptions)
+	}
+	if got := declared["long;"].GetIntVar("modifiers", "I"); got != ACC_PRIVATE {
+		t.Errorf("private constructor modifiers = %#x", got)
+	}
+}
@@ -11,6 +11,9 @@ const jlDouble = "java/lang/Double"
 func init() {
 	native.Register(jlDouble, "doubleToRawLongBits", "(D)J", doubleToRawLongBits)
 	native.Register(jlDouble, "longBitsToDouble", "(J)D", longBitsToDouble)
+	native.RegisterIntrinsic(jlDouble, "doubleToLongBits", "(D)J", doubleToLongBits)
+	native.RegisterIntrinsic(jlDouble, "isNaN", "(D)Z", doubleIsNaN)
+	native.RegisterIntrinsic(jlDouble, "isInfinite", "(D)Z", doubleIsInfinite)
 }
 
 // public static native long doubleToRawLongBits(double value);
@@ -21,10 +24,38 @@ func doubleToRawLongBits(frame *chapter4_rtdt.Frame) {
 	frame.OperandStack().PushLong(int64(bits))
 }
 
+/**
+	和doubleToRawLongBits不同，所有的NaN都转换成同一个位模式 0x7ff8000000000000。
+	不能用math.NaN()来比较或者生成，go的NaN是0x7ff8000000000001
+ */
+// public static long doubleToLongBits(double value);
+// (D)J
+func doubleToLongBits(frame *chapter4_rtdt.Frame) {
+	value := frame.LocalVars().GetDouble(0)
+	bits := uint64(0x7ff8000000000000)
+	if !math.IsNaN(value) {
+		bits = math.Float64bits(value)
+	}
+	frame.OperandStack().PushLong(int64(bits))
+}
+
 // public static native double longBitsToDouble(long bits);
 // (J)D
 func longBitsToDouble(frame *chapter4_rtdt.Frame) {
 	bits := frame.LocalVars().GetLong(0)
 	value := math.Float64frombits(uint64(bits))
 	frame.OperandStack().PushDouble(value)
-}
\ No newline at end of file
+}
+// public static boolean isNaN(double v);
+// (D)Z
+func doubleIsNaN(frame *chapter4_rtdt.Frame) {
+	v := frame.LocalVars().GetDouble(0)
+	frame.OperandStack().PushBoolean(math.IsNaN(v))
+}
+
+// public static boolean isInfinite(double v);
+// (D)Z
+func doubleIsInfinite(frame *chapter4_rtdt.Frame) {
+	v := frame.LocalVars().GetDouble(0)
+	frame.OperandStack().PushBoolean(math.IsInf(v, 0))
+}
@@ -0,0 +1,69 @@
+package lang_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"math"
+	"testing"
+)
+
+/**
+	转调Double和Float的四个取位模式的方法
+ */
+func newBitsOfVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/BitsOf", "java/lang/Object")
+	for _, m := range []struct {
+		owner, name, descriptor string
+		load, ret               byte
+	}{
+		{"java/lang/Double", "doubleToLongBits", "(D)J", DLOAD_0, LRETURN},
+		{"java/lang/Double", "doubleToRawLongBits", "(D)J", DLOAD_0, LRETURN},
+		{"java/lang/Float", "floatToIntBits", "(F)I", FLOAD_0, IRETURN},
+		{"java/lang/Float", "floatToRawIntBits", "(F)I", FLOAD_0, IRETURN},
+	} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, m.name, m.descriptor).Code(2, 2).
+			Op(m.load).Invokestatic(m.owner, m.name, m.descriptor).Op(m.ret)
+	}
+	return jvmtest.New(t, class)
+}
+
+func TestDoubleToLongBitsCanonicalizesNaN(t *testing.T) {
+	vm := newBitsOfVM(t)
+	const canonical = 0x7ff8000000000000
+	for _, bits := range []uint64{0x7ff0000000000001, 0xfff8000000000000, 0x7fffffffffffffff, canonical} {
+		nan := math.Float64frombits(bits)
+		if got := uint64(vm.Call("lang/BitsOf", "doubleToLongBits", "(D)J", nan).Long()); got != canonical {
+			t.Errorf("doubleToLongBits(%#x) = %#x, want %#x", bits, got, uint64(canonical))
+		}
+		if got := uint64(vm.Call("lang/BitsOf", "doubleToRawLongBits", "(D)J", nan).Long()); got != bits {
+			t.Errorf("doubleToRawLongBits(%#x) = %#x", bits, got)
+		}
+	}
+	//不是NaN的值两个方法结果一样，-0.0也不会变成0.0
+	for _, d := range []float64{1.5, math.Copysign(0, -1), math.Inf(-1)} {
+		want := int64(math.Float64bits(d))
+		if got := vm.Call("lang/BitsOf", "doubleToLongBits", "(D)J", d).Long(); got != want {
+			t.Errorf("doubleToLongBits(%v) = %#x, want %#x", d, got, want)
+		}
+	}
+}
+
+func TestFloatToIntBitsCanonicalizesNaN(t *testing.T) {
+	vm := newBitsOfVM(t)
+	const canonical = 0x7fc00000
+	for _, bits := range []uint32{0x7f800001, 0xffc00000, 0x7fffffff, canonical} {
+		nan := math.Float32frombits(bits)
+		if got := uint32(vm.Call("lang/BitsOf", "floatToIntBits", "(F)I", nan).Int()); got != canonical {
+			t.Errorf("floatToIntBits(%#x) = %#x, want %#x", bits, got, uint32(canonical))
+		}
+		if got := uint32(vm.Call("lang/BitsOf", "floatToRawIntBits", "(F)I", nan).Int()); got != bits {
+			t.Errorf("floatToRawIntBits(%#x) = %#x", bits, got)
+		}
+	}
+	for _, f := range []float32{-2.25, float32(math.Copysign(0, -1)), float32(math.Inf(1))} {
+		want := int32(math.Float32bits(f))
+		if got := vm.Call("lang/BitsOf", "floatToIntBits", "(F)I", f).Int(); got != want {
+			t.Errorf("floatToIntBits(%v) = %#x, want %#x", f, got, want)
+		}
+	}
+}
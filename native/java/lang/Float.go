@@ -11,6 +11,9 @@ const jlFloat = "java/lang/Float"
 func init() {
 	native.Register(jlFloat, "floatToRawIntBits", "(F)I", floatToRawIntBits)
 	native.Register(jlFloat, "intBitsToFloat", "(I)F", intBitsToFloat)
+	native.RegisterIntrinsic(jlFloat, "floatToIntBits", "(F)I", floatToIntBits)
+	native.RegisterIntrinsic(jlFloat, "isNaN", "(F)Z", floatIsNaN)
+	native.RegisterIntrinsic(jlFloat, "isInfinite", "(F)Z", floatIsInfinite)
 }
 
 // public static native int floatToRawIntBits(float value);
@@ -21,6 +24,20 @@ func floatToRawIntBits(frame *chapter4_rtdt.Frame) {
 	frame.OperandStack().PushInt(int32(bits))
 }
 
+/**
+	所有的NaN都转换成 0x7fc00000，和Double.doubleToLongBits一样
+ */
+// public static int floatToIntBits(float value);
+// (F)I
+func floatToIntBits(frame *chapter4_rtdt.Frame) {
+	value := frame.LocalVars().GetFloat(0)
+	bits := uint32(0x7fc00000)
+	if value == value {
+		bits = math.Float32bits(value)
+	}
+	frame.OperandStack().PushInt(int32(bits))
+}
+
 // public static native float intBitsToFloat(int bits);
 // (I)F
 func intBitsToFloat(frame *chapter4_rtdt.Frame) {
@@ -28,3 +45,17 @@ func intBitsToFloat(frame *chapter4_rtdt.Frame) {
 	value := math.Float32frombits(uint32(bits))
 	frame.OperandStack().PushFloat(value)
 }
+
+// public static boolean isNaN(float v);
+// (F)Z
+func floatIsNaN(frame *chapter4_rtdt.Frame) {
+	v := frame.LocalVars().GetFloat(0)
+	frame.OperandStack().PushBoolean(v != v)
+}
+
+// public static boolean isInfinite(float v);
+// (F)Z
+func floatIsInfinite(frame *chapter4_rtdt.Frame) {
+	v := frame.LocalVars().GetFloat(0)
+	frame.OperandStack().PushBoolean(math.IsInf(float64(v), 0))
+}
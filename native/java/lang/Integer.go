@@ -0,0 +1,75 @@
+package lang
+
+import (
+	"GoVM/native"
+	"GoVM/chapter4-rtdt"
+	"math/bits"
+)
+
+const jlInteger = "java/lang/Integer"
+
+/**
+	这些方法在JDK里都是java代码实现的，这里用math/bits替代
+ */
+func init() {
+	native.RegisterIntrinsic(jlInteger, "bitCount", "(I)I", integerBitCount)
+	native.RegisterIntrinsic(jlInteger, "numberOfLeadingZeros", "(I)I", integerNumberOfLeadingZeros)
+	native.RegisterIntrinsic(jlInteger, "numberOfTrailingZeros", "(I)I", integerNumberOfTrailingZeros)
+	native.RegisterIntrinsic(jlInteger, "highestOneBit", "(I)I", integerHighestOneBit)
+	native.RegisterIntrinsic(jlInteger, "lowestOneBit", "(I)I", integerLowestOneBit)
+	native.RegisterIntrinsic(jlInteger, "reverse", "(I)I", integerReverse)
+	native.RegisterIntrinsic(jlInteger, "reverseBytes", "(I)I", integerReverseBytes)
+}
+
+// public static int bitCount(int i);
+// (I)I
+func integerBitCount(frame *chapter4_rtdt.Frame) {
+	i := uint32(frame.LocalVars().GetInt(0))
+	frame.OperandStack().PushInt(int32(bits.OnesCount32(i)))
+}
+
+// public static int numberOfLeadingZeros(int i);
+// (I)I
+func integerNumberOfLeadingZeros(frame *chapter4_rtdt.Frame) {
+	i := uint32(frame.LocalVars().GetInt(0))
+	frame.OperandStack().PushInt(int32(bits.LeadingZeros32(i)))
+}
+
+// public static int numberOfTrailingZeros(int i);
+// (I)I
+func integerNumberOfTrailingZeros(frame *chapter4_rtdt.Frame) {
+	i := uint32(frame.LocalVars().GetInt(0))
+	frame.OperandStack().PushInt(int32(bits.TrailingZeros32(i)))
+}
+
+// public static int highestOneBit(int i);
+// (I)I
+func integerHighestOneBit(frame *chapter4_rtdt.Frame) {
+	i := uint32(frame.LocalVars().GetInt(0))
+	if i == 0 {
+		frame.OperandStack().PushInt(0)
+		return
+	}
+	frame.OperandStack().PushInt(int32(uint32(1) << uint(31 - bits.LeadingZeros32(i))))
+}
+
+// public static int lowestOneBit(int i);
+// (I)I
+func integerLowestOneBit(frame *chapter4_rtdt.Frame) {
+	i := frame.LocalVars().GetInt(0)
+	frame.OperandStack().PushInt(i & -i)
+}
+
+// public static int reverse(int i);
+// (I)I
+func integerReverse(frame *chapter4_rtdt.Frame) {
+	i := uint32(frame.LocalVars().GetInt(0))
+	frame.OperandStack().PushInt(int32(bits.Reverse32(i)))
+}
+
+// public static int reverseBytes(int i);
+// (I)I
+func integerReverseBytes(frame *chapter4_rtdt.Frame) {
+	i := uint32(frame.LocalVars().GetInt(0))
+	frame.OperandStack().PushInt(int32(bits.ReverseBytes32(i)))
+}
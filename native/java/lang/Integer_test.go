@@ -0,0 +1,98 @@
+package lang_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"math"
+	"testing"
+)
+
+var bitMethods = []string{"bitCount", "numberOfLeadingZeros", "numberOfTrailingZeros",
+	"highestOneBit", "lowestOneBit", "reverse", "reverseBytes"}
+
+/**
+	转调Integer和Long位运算方法的包装方法，Long的返回类型按JDK来：前三个返回int，其余返回long
+ */
+func newBitsVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Bits", "java/lang/Object")
+	for i, name := range bitMethods {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(I)I").Code(1, 1).
+			Op(ILOAD_0).Invokestatic("java/lang/Integer", name, "(I)I").Op(IRETURN)
+		descriptor, ret := "(J)J", byte(LRETURN)
+		if i < 3 {
+			descriptor, ret = "(J)I", IRETURN
+		}
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, descriptor).Code(2, 2).
+			Op(LLOAD_0).Invokestatic("java/lang/Long", name, descriptor).Op(ret)
+	}
+	return jvmtest.New(t, class)
+}
+
+func TestIntegerBits(t *testing.T) {
+	vm := newBitsVM(t)
+	for _, test := range []struct {
+		name    string
+		i, want int32
+	}{
+		{"bitCount", 0xFF, 8},
+		{"bitCount", -1, 32},
+		{"bitCount", 0, 0},
+		{"numberOfLeadingZeros", 1, 31},
+		{"numberOfLeadingZeros", -1, 0},
+		{"numberOfLeadingZeros", 0, 32},
+		{"numberOfTrailingZeros", 8, 3},
+		{"numberOfTrailingZeros", 0, 32},
+		{"highestOneBit", 0x123, 0x100},
+		{"highestOneBit", -1, math.MinInt32},
+		{"highestOneBit", 0, 0},
+		{"lowestOneBit", 12, 4},
+		{"lowestOneBit", 0, 0},
+		{"reverse", 1, math.MinInt32},
+		{"reverse", 0, 0},
+		{"reverseBytes", 0x01020304, 0x04030201},
+		{"reverseBytes", 0, 0},
+	} {
+		if got := vm.Call("lang/Bits", test.name, "(I)I", test.i).Int(); got != test.want {
+			t.Errorf("Integer.%s(%#x) = %d, want %d", test.name, test.i, got, test.want)
+		}
+	}
+}
+
+func TestLongBits(t *testing.T) {
+	vm := newBitsVM(t)
+	for _, test := range []struct {
+		name string
+		l    int64
+		want int32
+	}{
+		{"bitCount", 0xFF, 8},
+		{"bitCount", -1, 64},
+		{"bitCount", 0, 0},
+		{"numberOfLeadingZeros", 1, 63},
+		{"numberOfLeadingZeros", 0, 64},
+		{"numberOfTrailingZeros", 1 << 40, 40},
+		{"numberOfTrailingZeros", 0, 64},
+	} {
+		if got := vm.Call("lang/Bits", test.name, "(J)I", test.l).Int(); got != test.want {
+			t.Errorf("Long.%s(%#x) = %d, want %d", test.name, test.l, got, test.want)
+		}
+	}
+	for _, test := range []struct {
+		name    string
+		l, want int64
+	}{
+		{"highestOneBit", 0x1234, 0x1000},
+		{"highestOneBit", -1, math.MinInt64},
+		{"highestOneBit", 0, 0},
+		{"lowestOneBit", 12 << 33, 4 << 33},
+		{"lowestOneBit", 0, 0},
+		{"reverse", 1, math.MinInt64},
+		{"reverse", 0, 0},
+		{"reverseBytes", 0x0102030405060708, 0x0807060504030201},
+		{"reverseBytes", 0, 0},
+	} {
+		if got := vm.Call("lang/Bits", test.name, "(J)J", test.l).Long(); got != test.want {
+			t.Errorf("Long.%s(%#x) = %d, want %d", test.name, test.l, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package lang
+
+import (
+	"GoVM/native"
+	"GoVM/chapter4-rtdt"
+	"math/bits"
+)
+
+const jlLong = "java/lang/Long"
+
+/**
+	和Integer一样，用math/bits替代JDK里的java实现
+ */
+func init() {
+	native.RegisterIntrinsic(jlLong, "bitCount", "(J)I", longBitCount)
+	native.RegisterIntrinsic(jlLong, "numberOfLeadingZeros", "(J)I", longNumberOfLeadingZeros)
+	native.RegisterIntrinsic(jlLong, "numberOfTrailingZeros", "(J)I", longNumberOfTrailingZeros)
+	native.RegisterIntrinsic(jlLong, "highestOneBit", "(J)J", longHighestOneBit)
+	native.RegisterIntrinsic(jlLong, "lowestOneBit", "(J)J", longLowestOneBit)
+	native.RegisterIntrinsic(jlLong, "reverse", "(J)J", longReverse)
+	native.RegisterIntrinsic(jlLong, "reverseBytes", "(J)J", longReverseBytes)
+}
+
+// public static int bitCount(long i);
+// (J)I
+func longBitCount(frame *chapter4_rtdt.Frame) {
+	i := uint64(frame.LocalVars().GetLong(0))
+	frame.OperandStack().PushInt(int32(bits.OnesCount64(i)))
+}
+
+// public static int numberOfLeadingZeros(long i);
+// (J)I
+func longNumberOfLeadingZeros(frame *chapter4_rtdt.Frame) {
+	i := uint64(frame.LocalVars().GetLong(0))
+	frame.OperandStack().PushInt(int32(bits.LeadingZeros64(i)))
+}
+
+// public static int numberOfTrailingZeros(long i);
+// (J)I
+func longNumberOfTrailingZeros(frame *chapter4_rtdt.Frame) {
+	i := uint64(frame.LocalVars().GetLong(0))
+	frame.OperandStack().PushInt(int32(bits.TrailingZeros64(i)))
+}
+
+// public static long highestOneBit(long i);
+// (J)J
+func longHighestOneBit(frame *chapter4_rtdt.Frame) {
+	i := uint64(frame.LocalVars().GetLong(0))
+	if i == 0 {
+		frame.OperandStack().PushLong(0)
+		return
+	}
+	frame.OperandStack().PushLong(int64(uint64(1) << uint(63 - bits.LeadingZeros64(i))))
+}
+
+// public static long lowestOneBit(long i);
+// (J)J
+func longLowestOneBit(frame *chapter4_rtdt.Frame) {
+	i := frame.LocalVars().GetLong(0)
+	frame.OperandStack().PushLong(i & -i)
+}
+
+// public static long reverse(long i);
+// (J)J
+func longReverse(frame *chapter4_rtdt.Frame) {
+	i := uint64(frame.LocalVars().GetLong(0))
+	frame.OperandStack().PushLong(int64(bits.Reverse64(i)))
+}
+
+// public static long reverseBytes(long i);
+// (J)J
+func longReverseBytes(frame *chapter4_rtdt.Frame) {
+	i := uint64(frame.LocalVars().GetLong(0))
+	frame.OperandStack().PushLong(int64(bits.ReverseBytes64(i)))
+}
@@ -0,0 +1,96 @@
+package lang
+
+import (
+	"GoVM/native"
+	"GoVM/chapter4-rtdt"
+	"math"
+)
+
+const jlMath = "java/lang/Math"
+
+/**
+	floor ceil rint 在JDK里转调StrictMath的本地方法，round 是java代码，这里都直接用math包实现
+ */
+func init() {
+	native.RegisterIntrinsic(jlMath, "floor", "(D)D", mathFloor)
+	native.RegisterIntrinsic(jlMath, "ceil", "(D)D", mathCeil)
+	native.RegisterIntrinsic(jlMath, "rint", "(D)D", mathRint)
+	native.RegisterIntrinsic(jlMath, "round", "(D)J", mathRound)
+	native.RegisterIntrinsic(jlMath, "round", "(F)I", mathRoundFloat)
+}
+
+// public static double floor(double a);
+// (D)D
+func mathFloor(frame *chapter4_rtdt.Frame) {
+	a := frame.LocalVars().GetDouble(0)
+	frame.OperandStack().PushDouble(math.Floor(a))
+}
+
+// public static double ceil(double a);
+// (D)D
+func mathCeil(frame *chapter4_rtdt.Frame) {
+	a := frame.LocalVars().GetDouble(0)
+	frame.OperandStack().PushDouble(math.Ceil(a))
+}
+
+// public static double rint(double a);
+// (D)D
+func mathRint(frame *chapter4_rtdt.Frame) {
+	a := frame.LocalVars().GetDouble(0)
+	frame.OperandStack().PushDouble(math.RoundToEven(a))
+}
+
+// public static long round(double a);
+// (D)J
+func mathRound(frame *chapter4_rtdt.Frame) {
+	a := frame.LocalVars().GetDouble(0)
+	r := javaRound(a)
+	var result int64
+	switch {
+	case math.IsNaN(r):
+		result = 0
+	case r >= math.MaxInt64:
+		result = math.MaxInt64
+	case r <= math.MinInt64:
+		result = math.MinInt64
+	default:
+		result = int64(r)
+	}
+	frame.OperandStack().PushLong(result)
+}
+
+// public static int round(float a);
+// (F)I
+func mathRoundFloat(frame *chapter4_rtdt.Frame) {
+	a := frame.LocalVars().GetFloat(0)
+	r := javaRound(float64(a))
+	var result int32
+	switch {
+	case math.IsNaN(r):
+		result = 0
+	case r >= math.MaxInt32:
+		result = math.MaxInt32
+	case r <= math.MinInt32:
+		result = math.MinInt32
+	default:
+		result = int32(r)
+	}
+	frame.OperandStack().PushInt(result)
+}
+
+/**
+	java的round是四舍五入，.5 时向正无穷取整：round(-0.5) = 0，round(2.5) = 3
+	和 math.Round（.5 时远离0）不一样。也不能直接算 floor(a + 0.5)：
+	a = 0.49999999999999994 时 a + 0.5 会被舍入成1。
+	先取floor，再用精确的差值判断要不要加1
+ */
+func javaRound(a float64) float64 {
+	if math.IsNaN(a) || math.IsInf(a, 0) {
+		return a
+	}
+	f := math.Floor(a)
+	if a - f >= 0.5 {
+		f++
+	}
+	return f
+}
@@ -0,0 +1,112 @@
+package lang_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"math"
+	"testing"
+)
+
+/**
+	转调Math、Float、Double静态方法的包装方法，以及 isNaN(0.0/0.0)
+ */
+func newNumbersVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Numbers", "java/lang/Object")
+	for _, m := range []struct {
+		owner, name, descriptor string
+		load, ret               byte
+	}{
+		{"java/lang/Math", "round", "(D)J", DLOAD_0, LRETURN},
+		{"java/lang/Math", "round", "(F)I", FLOAD_0, IRETURN},
+		{"java/lang/Math", "floor", "(D)D", DLOAD_0, DRETURN},
+		{"java/lang/Math", "ceil", "(D)D", DLOAD_0, DRETURN},
+		{"java/lang/Math", "rint", "(D)D", DLOAD_0, DRETURN},
+		{"java/lang/Double", "isInfinite", "(D)Z", DLOAD_0, IRETURN},
+		{"java/lang/Float", "isInfinite", "(F)Z", FLOAD_0, IRETURN},
+	} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, m.name, m.descriptor).Code(2, 2).
+			Op(m.load).Invokestatic(m.owner, m.name, m.descriptor).Op(m.ret)
+	}
+	class.Method(ACC_PUBLIC|ACC_STATIC, "doubleNaN", "()Z").Code(4, 0).
+		Op(DCONST_0).Op(DCONST_0).Op(DDIV).Invokestatic("java/lang/Double", "isNaN", "(D)Z").Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "floatNaN", "()Z").Code(2, 0).
+		Op(FCONST_0).Op(FCONST_0).Op(FDIV).Invokestatic("java/lang/Float", "isNaN", "(F)Z").Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "doubleOneIsNaN", "()Z").Code(2, 0).
+		Op(DCONST_1).Invokestatic("java/lang/Double", "isNaN", "(D)Z").Op(IRETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestMathRound(t *testing.T) {
+	vm := newNumbersVM(t)
+	for _, test := range []struct {
+		a    float64
+		want int64
+	}{
+		{-0.5, 0},
+		{2.5, 3},
+		{-2.5, -2},
+		{0.49999999999999994, 0},
+		{math.NaN(), 0},
+		{math.Inf(1), math.MaxInt64},
+		{-1e300, math.MinInt64},
+	} {
+		if got := vm.Call("lang/Numbers", "round", "(D)J", test.a).Long(); got != test.want {
+			t.Errorf("round(%v) = %d, want %d", test.a, got, test.want)
+		}
+	}
+	for _, test := range []struct {
+		a    float32
+		want int32
+	}{
+		{-0.5, 0},
+		{2.5, 3},
+		{-1.5, -1},
+		{float32(math.Inf(-1)), math.MinInt32},
+	} {
+		if got := vm.Call("lang/Numbers", "round", "(F)I", test.a).Int(); got != test.want {
+			t.Errorf("round(%vf) = %d, want %d", test.a, got, test.want)
+		}
+	}
+}
+
+func TestMathFloorCeilRint(t *testing.T) {
+	vm := newNumbersVM(t)
+	for _, test := range []struct {
+		name    string
+		a, want float64
+	}{
+		{"floor", -1.5, -2},
+		{"floor", 1.5, 1},
+		{"ceil", -1.5, -1},
+		{"ceil", 1.2, 2},
+		{"rint", 2.5, 2},
+		{"rint", 3.5, 4},
+		{"rint", -2.5, -2},
+	} {
+		if got := vm.Call("lang/Numbers", test.name, "(D)D", test.a).Double(); got != test.want {
+			t.Errorf("%s(%v) = %v, want %v", test.name, test.a, got, test.want)
+		}
+	}
+}
+
+func TestIsNaNAndIsInfinite(t *testing.T) {
+	vm := newNumbersVM(t)
+	if !vm.Call("lang/Numbers", "doubleNaN", "()Z").Bool() {
+		t.Error("Double.isNaN(0.0/0.0) = false")
+	}
+	if !vm.Call("lang/Numbers", "floatNaN", "()Z").Bool() {
+		t.Error("Float.isNaN(0.0f/0.0f) = false")
+	}
+	if vm.Call("lang/Numbers", "doubleOneIsNaN", "()Z").Bool() {
+		t.Error("Double.isNaN(1.0) = true")
+	}
+	if !vm.Call("lang/Numbers", "isInfinite", "(D)Z", math.Inf(-1)).Bool() {
+		t.Error("Double.isInfinite(-Infinity) = false")
+	}
+	if vm.Call("lang/Numbers", "isInfinite", "(D)Z", math.NaN()).Bool() {
+		t.Error("Double.isInfinite(NaN) = true")
+	}
+	if !vm.Call("lang/Numbers", "isInfinite", "(F)Z", float32(math.Inf(1))).Bool() {
+		t.Error("Float.isInfinite(Infinity) = false")
+	}
+}
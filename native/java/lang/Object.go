@@ -3,7 +3,7 @@ package lang
 import (
 	"GoVM/native"
 	"GoVM/chapter4-rtdt"
-	"unsafe"
+	"time"
 )
 
 const jlObject = "java/lang/Object"
@@ -12,6 +12,9 @@ func init() {
 	native.Register(jlObject, "getClass", "()Ljava/lang/Class;", getClass)
 	native.Register(jlObject, "hashCode", "()I", hashCode)
 	native.Register(jlObject, "clone", "()Ljava/lang/Object;", clone)
+	native.Register(jlObject, "wait", "(J)V", wait)
+	native.Register(jlObject, "notify", "()V", notify)
+	native.Register(jlObject, "notifyAll", "()V", notifyAll)
 }
 
 //public final native Class<?> getClass
@@ -21,12 +24,17 @@ func getClass(frame *chapter4_rtdt.Frame) {
 	frame.OperandStack().PushRef(class)
 }
 
+/**
+	Object.toString()没有做成intrinsic，直接解释执行JDK里的
+		getClass().getName() + "@" + Integer.toHexString(hashCode())
+	因为hashCode()是虚方法调用，子类只覆盖了hashCode时，toString的结果也要跟着变
+	没有覆盖hashCode的类最终会调用到这里，得到的就是identity hash
+ */
 // public native int hashCode();
 // ()I
 func hashCode(frame *chapter4_rtdt.Frame) {
 	this := frame.LocalVars().GetThis()
-	hash := int32(uintptr(unsafe.Pointer(this)))
-	frame.OperandStack().PushInt(hash)
+	frame.OperandStack().PushInt(this.IdentityHashCode())
 }
 
 // protected native Object clone() throws CloneNotSupportedException;
@@ -34,10 +42,60 @@ func hashCode(frame *chapter4_rtdt.Frame) {
 func clone(frame *chapter4_rtdt.Frame) {
 	this := frame.LocalVars().GetThis()
 
+	//和HotSpot一样，异常信息是类名
 	clonable := this.Class().Loader().LoadClass("java/lang/Cloneable")
-	if !this.Class().IsImplements(clonable) {
-		panic("java.lang.CloneNotSupportException")
+	if !clonable.IsAssignableFrom(this.Class()) {
+		frame.ThrowException("java/lang/CloneNotSupportedException", this.Class().JavaName())
+		return
 	}
 
 	frame.OperandStack().PushRef(this.Clone())
-}
\ No newline at end of file
+}
+/**
+	虚拟机里只有一个java线程，不会有别的线程来notify，所以 wait(timeout) 就是睡timeout毫秒，
+	Thread.join(millis) 靠它等够时间。wait(0) 会永远等下去，和Go的 "all goroutines are asleep" 一样是死锁，
+	抛出InternalError，java代码可以catch到
+	wait/notify/notifyAll 都要求当前线程持有this的锁（synchronized块或者synchronized方法）
+ */
+// public final native void wait(long timeout) throws InterruptedException;
+// (J)V
+func wait(frame *chapter4_rtdt.Frame) {
+	if !checkMonitorOwner(frame) {
+		return
+	}
+	timeout := frame.LocalVars().GetLong(1)
+	if timeout < 0 {
+		frame.ThrowException("java/lang/IllegalArgumentException", "timeout value is negative")
+		return
+	}
+	if timeout == 0 {
+		frame.ThrowException("java/lang/InternalError",
+			"deadlock: Object.wait() on thread " + frame.Thread().Name() + ", no other thread can notify it")
+		return
+	}
+	time.Sleep(time.Duration(timeout) * time.Millisecond)
+}
+
+// public final native void notify();
+// ()V
+func notify(frame *chapter4_rtdt.Frame) {
+	//没有在等待的线程
+	checkMonitorOwner(frame)
+}
+
+// public final native void notifyAll();
+// ()V
+func notifyAll(frame *chapter4_rtdt.Frame) {
+	checkMonitorOwner(frame)
+}
+
+/**
+	只有一个线程，持有锁就是this的监视器进入过。没有持有时抛出IllegalMonitorStateException，返回false
+ */
+func checkMonitorOwner(frame *chapter4_rtdt.Frame) bool {
+	if frame.LocalVars().GetThis().MonitorCount() == 0 {
+		frame.ThrowException("java/lang/IllegalMonitorStateException", "current thread is not owner")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+package lang_test
+
+import (
+	"GoVM/chapter6-obj/heap"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var objectString = regexp.MustCompile(`^lang\.Plain@([0-9a-f]+)\n$`)
+
+/**
+	class Plain {}
+	class Hashed { public int hashCode() { return 0xcafe; } }
+	static void print(Object o) { System.out.println(o); }
+	static int hashCode(Object o) { return o.hashCode(); }
+ */
+func newPrintVM(t *testing.T) *jvmtest.VM {
+	plain := New("lang/Plain", "java/lang/Object").DefaultConstructor()
+	hashed := New("lang/Hashed", "java/lang/Object").DefaultConstructor()
+	hashed.Method(ACC_PUBLIC, "hashCode", "()I").Code(1, 1).Iconst(0xcafe).Op(IRETURN)
+
+	class := New("lang/Print", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "print", "(Ljava/lang/Object;)V").Code(2, 1).
+		Getstatic("java/lang/System", "out", "Ljava/io/PrintStream;").Op(ALOAD_0).
+		Invokevirtual("java/io/PrintStream", "println", "(Ljava/lang/Object;)V").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "hashCode", "(Ljava/lang/Object;)I").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual("java/lang/Object", "hashCode", "()I").Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "identityHashCode", "(Ljava/lang/Object;)I").Code(1, 1).
+		Op(ALOAD_0).Invokestatic("java/lang/System", "identityHashCode", "(Ljava/lang/Object;)I").Op(IRETURN)
+	return jvmtest.New(t, plain, hashed, class)
+}
+
+func TestPrintlnOfPlainObject(t *testing.T) {
+	vm := newPrintVM(t)
+	obj := vm.Class("lang/Plain").NewObject()
+	vm.Call("lang/Print", "print", "(Ljava/lang/Object;)V", obj)
+
+	match := objectString.FindStringSubmatch(vm.Out.String())
+	if match == nil {
+		t.Fatalf("println printed %q, want lang.Plain@<hex>", vm.Out.String())
+	}
+	hash, _ := strconv.ParseUint(match[1], 16, 32)
+	if want := vm.Call("lang/Print", "identityHashCode", "(Ljava/lang/Object;)I", obj).Int(); int32(hash) != want {
+		t.Errorf("hash in %q is %#x, identityHashCode is %#x", vm.Out.String(), hash, want)
+	}
+}
+
+func TestHashCodeIsTheIdentityHashCode(t *testing.T) {
+	vm := newPrintVM(t)
+	for _, className := range []string{"lang/Plain", "lang/Hashed"} {
+		obj := vm.Class(className).NewObject()
+		identity := vm.Call("lang/Print", "identityHashCode", "(Ljava/lang/Object;)I", obj).Int()
+		if identity != obj.IdentityHashCode() || vm.Call("lang/Print", "identityHashCode", "(Ljava/lang/Object;)I", obj).Int() != identity {
+			t.Errorf("%s: identityHashCode is not stable", className)
+		}
+		//覆盖了hashCode的类，identityHashCode还是原来的
+		hash := vm.Call("lang/Print", "hashCode", "(Ljava/lang/Object;)I", obj).Int()
+		if className == "lang/Plain" && hash != identity {
+			t.Errorf("Object.hashCode() = %#x, identityHashCode = %#x", hash, identity)
+		}
+		if className == "lang/Hashed" && (hash != 0xcafe || identity == 0xcafe) {
+			t.Errorf("Hashed: hashCode() = %#x, identityHashCode = %#x", hash, identity)
+		}
+	}
+}
+
+func TestObjectToStringUsesOverriddenHashCode(t *testing.T) {
+	vm := newPrintVM(t)
+	vm.Call("lang/Print", "print", "(Ljava/lang/Object;)V", vm.Class("lang/Hashed").NewObject())
+	if got := vm.Out.String(); got != "lang.Hashed@cafe\n" {
+		t.Errorf("println printed %q, want \"lang.Hashed@cafe\\n\"", got)
+	}
+}
+
+/**
+	class Copyable implements Cloneable {
+		int n; Object ref;
+		static Object copy(Copyable c) throws CloneNotSupportedException { return c.clone(); }
+	}
+	class Sealed {
+		static Object copy(Sealed s) { try { return s.clone(); } catch (CloneNotSupportedException e) { return e; } }
+	}
+	static Object copyInts(int[] a) { return a.clone(); }
+ */
+func newCloneVM(t *testing.T) *jvmtest.VM {
+	copyable := New("lang/Copyable", "java/lang/Object", "java/lang/Cloneable").DefaultConstructor()
+	copyable.Field(0, "n", "I")
+	copyable.Field(0, "ref", "Ljava/lang/Object;")
+	copyable.Method(ACC_PUBLIC|ACC_STATIC, "copy", "(Llang/Copyable;)Ljava/lang/Object;").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual("lang/Copyable", "clone", "()Ljava/lang/Object;").Op(ARETURN)
+	sealed := New("lang/Sealed", "java/lang/Object").DefaultConstructor()
+	sealed.Method(ACC_PUBLIC|ACC_STATIC, "copy", "(Llang/Sealed;)Ljava/lang/Object;").Code(1, 1).
+		Label("start").
+		Op(ALOAD_0).Invokevirtual("lang/Sealed", "clone", "()Ljava/lang/Object;").
+		Label("end").
+		Op(ARETURN).
+		Label("handler").
+		Op(ARETURN).
+		Catch("start", "end", "handler", "java/lang/CloneNotSupportedException")
+	arrays := New("lang/Arrays", "java/lang/Object")
+	arrays.Method(ACC_PUBLIC|ACC_STATIC, "copyInts", "([I)Ljava/lang/Object;").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual("[I", "clone", "()Ljava/lang/Object;").Op(ARETURN)
+	return jvmtest.New(t, copyable, sealed, arrays)
+}
+
+func TestCloneCopiesCloneableObjects(t *testing.T) {
+	vm := newCloneVM(t)
+	original := vm.Class("lang/Copyable").NewObject()
+	original.SetIntVar("n", "I", 42)
+	shared := vm.String("shared")
+	original.SetRefVar("ref", "Ljava/lang/Object;", shared)
+
+	copied := vm.Call("lang/Copyable", "copy", "(Llang/Copyable;)Ljava/lang/Object;", original).Ref()
+	if copied == nil || copied == original || copied.Class() != original.Class() {
+		t.Fatalf("clone returned %v, want a new lang/Copyable", copied)
+	}
+	//浅拷贝：字段的值一样，引用的还是同一个对象；之后改原对象不影响拷贝
+	if copied.GetIntVar("n", "I") != 42 || copied.GetRefVar("ref", "Ljava/lang/Object;") != shared {
+		t.Error("clone did not copy the fields")
+	}
+	original.SetIntVar("n", "I", 7)
+	if copied.GetIntVar("n", "I") != 42 {
+		t.Error("clone shares its fields with the original")
+	}
+
+	ints := vm.Class("[I").NewArray(3)
+	copy(ints.Ints(), []int32{1, 2, 3})
+	copiedInts := vm.Call("lang/Arrays", "copyInts", "([I)Ljava/lang/Object;", ints).Ref()
+	if copiedInts == ints || len(copiedInts.Ints()) != 3 || copiedInts.Ints()[2] != 3 {
+		t.Fatalf("int[].clone() = %v", copiedInts)
+	}
+	ints.Ints()[2] = 30
+	if copiedInts.Ints()[2] != 3 {
+		t.Error("cloned array shares its elements with the original")
+	}
+}
+
+func TestCloneOfNonCloneableThrows(t *testing.T) {
+	vm := newCloneVM(t)
+	ex := vm.Call("lang/Sealed", "copy", "(Llang/Sealed;)Ljava/lang/Object;", vm.Class("lang/Sealed").NewObject()).Ref()
+	if ex == nil || ex.Class().Name() != "java/lang/CloneNotSupportedException" {
+		t.Fatalf("Sealed.copy() returned %v, want the caught CloneNotSupportedException", ex)
+	}
+	if msg := heap.GoString(ex.GetRefVar("detailMessage", "Ljava/lang/String;")); msg != "lang.Sealed" {
+		t.Errorf("message = %q, want \"lang.Sealed\"", msg)
+	}
+}
@@ -0,0 +1,51 @@
+package lang
+
+import (
+	"GoVM/native"
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+	"runtime"
+)
+
+const jlRuntime = "java/lang/Runtime"
+
+func init() {
+	native.Register(jlRuntime, "availableProcessors", "()I", availableProcessors)
+	native.Register(jlRuntime, "freeMemory", "()J", freeMemory)
+	native.Register(jlRuntime, "totalMemory", "()J", totalMemory)
+	native.Register(jlRuntime, "maxMemory", "()J", maxMemory)
+	native.Register(jlRuntime, "gc", "()V", gc)
+}
+
+// public native int availableProcessors();
+// ()I
+func availableProcessors(frame *chapter4_rtdt.Frame) {
+	frame.OperandStack().PushInt(int32(runtime.NumCPU()))
+}
+
+// public native long freeMemory();
+// ()J
+func freeMemory(frame *chapter4_rtdt.Frame) {
+	frame.OperandStack().PushLong(heap.GetHeap().FreeMemory())
+}
+
+// public native long totalMemory();
+// ()J
+func totalMemory(frame *chapter4_rtdt.Frame) {
+	frame.OperandStack().PushLong(heap.GetHeap().TotalMemory())
+}
+
+// public native long maxMemory();
+// ()J
+func maxMemory(frame *chapter4_rtdt.Frame) {
+	frame.OperandStack().PushLong(heap.GetHeap().MaxMemory())
+}
+
+/**
+	本地方法的栈帧还在线程栈上，这里不直接GC，让解释器在下一条指令之前做
+ */
+// public native void gc();
+// ()V
+func gc(frame *chapter4_rtdt.Frame) {
+	heap.GetHeap().RequestGC()
+}
@@ -4,16 +4,159 @@ import (
 	"GoVM/native"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
+	"fmt"
+	"unicode"
+	"unicode/utf16"
 )
 
 const jlString = "java/lang/String"
 
 func init() {
 	native.Register(jlString, "intern", "()Ljava/lang/String;", intern)
+	native.RegisterIntrinsic(jlString, "length", "()I", stringLength)
+	native.RegisterIntrinsic(jlString, "charAt", "(I)C", stringCharAt)
+	native.RegisterIntrinsic(jlString, "substring", "(I)Ljava/lang/String;", stringSubstring)
+	native.RegisterIntrinsic(jlString, "substring", "(II)Ljava/lang/String;", stringSubstring2)
+	native.RegisterIntrinsic(jlString, "indexOf", "(I)I", stringIndexOf)
+	native.RegisterIntrinsic(jlString, "indexOf", "(II)I", stringIndexOf2)
 }
 
 func intern(frame *chapter4_rtdt.Frame) {
 	this := frame.LocalVars().GetThis()
 	interned := heap.InternString(this)
 	frame.OperandStack().PushRef(interned)
+}
+
+/**
+	java字符串的内容存放在value字段的char[]中，是utf16编码的
+ */
+func stringChars(jStr *heap.Object) []uint16 {
+	return jStr.GetRefVar("value", "[C").Chars()
+}
+
+// public int length();
+// ()I
+func stringLength(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	frame.OperandStack().PushInt(int32(len(stringChars(this))))
+}
+
+// public char charAt(int index);
+// (I)C
+func stringCharAt(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	chars := stringChars(vars.GetThis())
+	index := vars.GetInt(1)
+
+	if index < 0 || int(index) >= len(chars) {
+		throwStringIndexError(frame, index)
+		return
+	}
+	frame.OperandStack().PushInt(int32(chars[index]))
+}
+
+// public String substring(int beginIndex);
+// (I)Ljava/lang/String;
+func stringSubstring(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	beginIndex := vars.GetInt(1)
+
+	pushSubstring(frame, this, beginIndex, int32(len(stringChars(this))))
+}
+
+// public String substring(int beginIndex, int endIndex);
+// (II)Ljava/lang/String;
+func stringSubstring2(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	beginIndex := vars.GetInt(1)
+	endIndex := vars.GetInt(2)
+
+	pushSubstring(frame, this, beginIndex, endIndex)
+}
+
+func pushSubstring(frame *chapter4_rtdt.Frame, this *heap.Object, beginIndex, endIndex int32) {
+	chars := stringChars(this)
+	if beginIndex < 0 {
+		throwStringIndexError(frame, beginIndex)
+		return
+	}
+	if int(endIndex) > len(chars) {
+		throwStringIndexError(frame, endIndex)
+		return
+	}
+	if beginIndex > endIndex {
+		throwStringIndexError(frame, endIndex - beginIndex)
+		return
+	}
+
+	//和JDK一样，截取整个字符串时直接返回自己
+	if beginIndex == 0 && int(endIndex) == len(chars) {
+		frame.OperandStack().PushRef(this)
+		return
+	}
+
+	subChars := make([]uint16, endIndex - beginIndex)
+	copy(subChars, chars[beginIndex:endIndex])
+	loader := frame.Method().Class().Loader()
+	frame.OperandStack().PushRef(heap.NewJString(loader, subChars))
+}
+
+/**
+	和JDK 8的信息一样：String index out of range: <index>
+ */
+func throwStringIndexError(frame *chapter4_rtdt.Frame, index int32) {
+	frame.ThrowException("java/lang/StringIndexOutOfBoundsException",
+		fmt.Sprintf("String index out of range: %d", index))
+}
+
+// public int indexOf(int ch);
+// (I)I
+func stringIndexOf(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	chars := stringChars(vars.GetThis())
+	ch := vars.GetInt(1)
+
+	frame.OperandStack().PushInt(indexOfCodePoint(chars, ch, 0))
+}
+
+// public int indexOf(int ch, int fromIndex);
+// (II)I
+func stringIndexOf2(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	chars := stringChars(vars.GetThis())
+	ch := vars.GetInt(1)
+	fromIndex := vars.GetInt(2)
+
+	frame.OperandStack().PushInt(indexOfCodePoint(chars, ch, fromIndex))
+}
+
+/**
+	ch是一个unicode码点，超出BMP的字符在utf16中占两个char（代理对），要成对匹配
+ */
+func indexOfCodePoint(chars []uint16, ch, fromIndex int32) int32 {
+	if fromIndex < 0 {
+		fromIndex = 0
+	}
+	if ch < 0 || ch > unicode.MaxRune {
+		return -1
+	}
+
+	if ch < 0x10000 {
+		for i := int(fromIndex); i < len(chars); i++ {
+			if int32(chars[i]) == ch {
+				return int32(i)
+			}
+		}
+		return -1
+	}
+
+	hi, lo := utf16.EncodeRune(rune(ch))
+	for i := int(fromIndex); i < len(chars) - 1; i++ {
+		if rune(chars[i]) == hi && rune(chars[i + 1]) == lo {
+			return int32(i)
+		}
+	}
+	return -1
 }
\ No newline at end of file
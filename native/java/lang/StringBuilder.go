@@ -0,0 +1,149 @@
+package lang
+
+import (
+	"GoVM/native"
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+	"unicode/utf16"
+)
+
+const jlAbstractStringBuilder = "java/lang/AbstractStringBuilder"
+
+/**
+	StringBuilder和StringBuffer的这些方法都是转调父类AbstractStringBuilder的，
+	所以只需要替换AbstractStringBuilder里的实现。状态就是value（char[]）和count两个字段
+ */
+func init() {
+	native.RegisterIntrinsic(jlAbstractStringBuilder, "length", "()I", builderLength)
+	native.RegisterIntrinsic(jlAbstractStringBuilder, "setLength", "(I)V", builderSetLength)
+	native.RegisterIntrinsic(jlAbstractStringBuilder, "insert", "(ILjava/lang/String;)Ljava/lang/AbstractStringBuilder;", builderInsert)
+	native.RegisterIntrinsic(jlAbstractStringBuilder, "deleteCharAt", "(I)Ljava/lang/AbstractStringBuilder;", builderDeleteCharAt)
+	native.RegisterIntrinsic(jlAbstractStringBuilder, "reverse", "()Ljava/lang/AbstractStringBuilder;", builderReverse)
+}
+
+func builderChars(this *heap.Object) []uint16 {
+	return this.GetRefVar("value", "[C").Chars()
+}
+
+func builderCount(this *heap.Object) int32 {
+	return this.GetIntVar("count", "I")
+}
+
+/**
+	容量不够时和JDK一样按 (容量 + 1) * 2 扩容，还不够就直接用需要的大小
+ */
+func ensureBuilderCapacity(this *heap.Object, minCapacity int32) []uint16 {
+	chars := builderChars(this)
+	if int(minCapacity) <= len(chars) {
+		return chars
+	}
+	newCapacity := int32(len(chars)) * 2 + 2
+	if newCapacity < minCapacity {
+		newCapacity = minCapacity
+	}
+	loader := this.Class().Loader()
+	newValue := loader.LoadClass("[C").NewArray(uint(newCapacity))
+	copy(newValue.Chars(), chars)
+	this.SetRefVar("value", "[C", newValue)
+	return newValue.Chars()
+}
+
+// public int length();
+// ()I
+func builderLength(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	frame.OperandStack().PushInt(builderCount(this))
+}
+
+// public void setLength(int newLength);
+// (I)V
+func builderSetLength(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	newLength := vars.GetInt(1)
+	if newLength < 0 {
+		throwStringIndexError(frame, newLength)
+		return
+	}
+
+	chars := ensureBuilderCapacity(this, newLength)
+	//变长时，多出来的部分填'\u0000'
+	for i := builderCount(this); i < newLength; i++ {
+		chars[i] = 0
+	}
+	this.SetIntVar("count", "I", newLength)
+}
+
+// public AbstractStringBuilder insert(int offset, String str);
+// (ILjava/lang/String;)Ljava/lang/AbstractStringBuilder;
+func builderInsert(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	offset := vars.GetInt(1)
+	count := builderCount(this)
+	if offset < 0 || offset > count {
+		throwStringIndexError(frame, offset)
+		return
+	}
+
+	var str []uint16
+	if jStr := vars.GetRef(2); jStr != nil {
+		str = stringChars(jStr)
+	} else {
+		str = utf16.Encode([]rune("null"))
+	}
+
+	length := int32(len(str))
+	chars := ensureBuilderCapacity(this, count + length)
+	copy(chars[offset + length:count + length], chars[offset:count])
+	copy(chars[offset:], str)
+	this.SetIntVar("count", "I", count + length)
+	frame.OperandStack().PushRef(this)
+}
+
+// public AbstractStringBuilder deleteCharAt(int index);
+// (I)Ljava/lang/AbstractStringBuilder;
+func builderDeleteCharAt(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	index := vars.GetInt(1)
+	count := builderCount(this)
+	if index < 0 || index >= count {
+		throwStringIndexError(frame, index)
+		return
+	}
+
+	chars := builderChars(this)
+	copy(chars[index:count - 1], chars[index + 1:count])
+	this.SetIntVar("count", "I", count - 1)
+	frame.OperandStack().PushRef(this)
+}
+
+/**
+	先逐个char反转，再把被反转成 低代理 高代理 的代理对换回来，这样代理对不会被拆开
+ */
+// public AbstractStringBuilder reverse();
+// ()Ljava/lang/AbstractStringBuilder;
+func builderReverse(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	chars := builderChars(this)[:builderCount(this)]
+
+	for i, j := 0, len(chars) - 1; i < j; i, j = i + 1, j - 1 {
+		chars[i], chars[j] = chars[j], chars[i]
+	}
+	for i := 0; i < len(chars) - 1; i++ {
+		if isLowSurrogate(chars[i]) && isHighSurrogate(chars[i + 1]) {
+			chars[i], chars[i + 1] = chars[i + 1], chars[i]
+			i++
+		}
+	}
+	frame.OperandStack().PushRef(this)
+}
+
+func isHighSurrogate(c uint16) bool {
+	return c >= 0xd800 && c <= 0xdbff
+}
+
+func isLowSurrogate(c uint16) bool {
+	return c >= 0xdc00 && c <= 0xdfff
+}
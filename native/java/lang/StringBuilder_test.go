@@ -0,0 +1,101 @@
+package lang_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+const jlSB = "java/lang/StringBuilder"
+
+/**
+	static String m(String s, ...) { return new StringBuilder(s).m(...).toString(); }
+ */
+func newBuilderVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Builders", "java/lang/Object")
+	newBuilder := func(code *Code) *Code {
+		return code.New(jlSB).Op(DUP).Op(ALOAD_0).Invokespecial(jlSB, "<init>", "(Ljava/lang/String;)V")
+	}
+	toString := func(code *Code) {
+		code.Invokevirtual(jlSB, "toString", "()Ljava/lang/String;").Op(ARETURN)
+	}
+
+	toString(newBuilder(class.Method(ACC_PUBLIC|ACC_STATIC, "insert", "(Ljava/lang/String;ILjava/lang/String;)Ljava/lang/String;").Code(5, 3)).
+		Op(ILOAD_1).Op(ALOAD_2).Invokevirtual(jlSB, "insert", "(ILjava/lang/String;)Ljava/lang/StringBuilder;"))
+	toString(newBuilder(class.Method(ACC_PUBLIC|ACC_STATIC, "deleteCharAt", "(Ljava/lang/String;I)Ljava/lang/String;").Code(4, 2)).
+		Op(ILOAD_1).Invokevirtual(jlSB, "deleteCharAt", "(I)Ljava/lang/StringBuilder;"))
+	toString(newBuilder(class.Method(ACC_PUBLIC|ACC_STATIC, "reverse", "(Ljava/lang/String;)Ljava/lang/String;").Code(3, 1)).
+		Invokevirtual(jlSB, "reverse", "()Ljava/lang/StringBuilder;"))
+	//StringBuilder sb = new StringBuilder(s); sb.setLength(n); return sb.toString();
+	code := newBuilder(class.Method(ACC_PUBLIC|ACC_STATIC, "setLength", "(Ljava/lang/String;I)Ljava/lang/String;").Code(4, 2)).
+		Op(DUP).Op(ILOAD_1).Invokevirtual(jlSB, "setLength", "(I)V")
+	toString(code)
+	return jvmtest.New(t, class)
+}
+
+func TestStringBuilderInsert(t *testing.T) {
+	vm := newBuilderVM(t)
+	desc := "(Ljava/lang/String;ILjava/lang/String;)Ljava/lang/String;"
+	for _, test := range []struct {
+		s      string
+		offset int
+		str    interface{}
+		want   string
+	}{
+		{"held", 2, "llo wor", "hello world"},
+		{"abc", 0, "x", "xabc"},
+		{"abc", 3, "x", "abcx"},
+		{"ab", 1, nil, "anullb"},
+		//超过初始容量（长度 + 16），要扩容
+		{"ab", 1, "0123456789abcdefghijklmnopqrstuvwxyz", "a0123456789abcdefghijklmnopqrstuvwxyzb"},
+	} {
+		if got := vm.Call("lang/Builders", "insert", desc, test.s, test.offset, test.str).String(); got != test.want {
+			t.Errorf("insert(%q, %d, %v) = %q, want %q", test.s, test.offset, test.str, got, test.want)
+		}
+	}
+	msg := vm.Call("lang/Builders", "insert", desc, "abc", 4, "x").Throws("java/lang/StringIndexOutOfBoundsException")
+	if msg != "String index out of range: 4" {
+		t.Errorf("insert at 4 message = %q", msg)
+	}
+}
+
+func TestStringBuilderDeleteCharAt(t *testing.T) {
+	vm := newBuilderVM(t)
+	desc := "(Ljava/lang/String;I)Ljava/lang/String;"
+	if got := vm.Call("lang/Builders", "deleteCharAt", desc, "abcd", 1).String(); got != "acd" {
+		t.Errorf(`deleteCharAt("abcd", 1) = %q`, got)
+	}
+	if got := vm.Call("lang/Builders", "deleteCharAt", desc, "abcd", 3).String(); got != "abc" {
+		t.Errorf(`deleteCharAt("abcd", 3) = %q`, got)
+	}
+	for _, index := range []int{4, -1} {
+		vm.Call("lang/Builders", "deleteCharAt", desc, "abcd", index).Throws("java/lang/StringIndexOutOfBoundsException")
+	}
+}
+
+func TestStringBuilderReverseKeepsSurrogatePairs(t *testing.T) {
+	vm := newBuilderVM(t)
+	desc := "(Ljava/lang/String;)Ljava/lang/String;"
+	for _, test := range []struct{ s, want string }{
+		{"abc", "cba"},
+		{"a\U0001F600b", "b\U0001F600a"},
+		{"\U0001F600\U0001F601", "\U0001F601\U0001F600"},
+		{"", ""},
+	} {
+		if got := vm.Call("lang/Builders", "reverse", desc, test.s).String(); got != test.want {
+			t.Errorf("reverse(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestStringBuilderSetLength(t *testing.T) {
+	vm := newBuilderVM(t)
+	desc := "(Ljava/lang/String;I)Ljava/lang/String;"
+	if got := vm.Call("lang/Builders", "setLength", desc, "hello", 2).String(); got != "he" {
+		t.Errorf(`setLength("hello", 2) = %q`, got)
+	}
+	if got := vm.Call("lang/Builders", "setLength", desc, "ab", 4).String(); got != "ab\x00\x00" {
+		t.Errorf(`setLength("ab", 4) = %q`, got)
+	}
+	vm.Call("lang/Builders", "setLength", desc, "ab", -1).Throws("java/lang/StringIndexOutOfBoundsException")
+}
@@ -0,0 +1,94 @@
+package lang_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"strconv"
+	"testing"
+)
+
+/**
+	每个静态方法把第一个参数当成this，转调String的同名方法
+ */
+func newStringVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Strings", "java/lang/Object")
+	for _, m := range []struct{ name, descriptor string }{
+		{"charAt", "(I)C"},
+		{"substring", "(I)Ljava/lang/String;"},
+		{"substring", "(II)Ljava/lang/String;"},
+		{"indexOf", "(I)I"},
+		{"indexOf", "(II)I"},
+	} {
+		static := "(Ljava/lang/String;" + m.descriptor[1:]
+		code := class.Method(ACC_PUBLIC|ACC_STATIC, m.name, static).Code(3, 3).Op(ALOAD_0)
+		for i := 1; i <= ArgSlotCount(m.descriptor); i++ {
+			code.Op(ILOAD, byte(i))
+		}
+		code.Invokevirtual("java/lang/String", m.name, m.descriptor)
+		if m.descriptor[len(m.descriptor)-1] == ';' {
+			code.Op(ARETURN)
+		} else {
+			code.Op(IRETURN)
+		}
+	}
+	return jvmtest.New(t, class)
+}
+
+func TestStringCharAt(t *testing.T) {
+	vm := newStringVM(t)
+	if got := vm.Call("lang/Strings", "charAt", "(Ljava/lang/String;I)C", "abc", 1).Int(); got != 'b' {
+		t.Errorf(`"abc".charAt(1) = %q`, rune(got))
+	}
+	for _, index := range []int{3, -1} {
+		msg := vm.Call("lang/Strings", "charAt", "(Ljava/lang/String;I)C", "abc", index).
+			Throws("java/lang/StringIndexOutOfBoundsException")
+		if want := "String index out of range: " + strconv.Itoa(index); msg != want {
+			t.Errorf("charAt(%d) message = %q, want %q", index, msg, want)
+		}
+	}
+}
+
+func TestStringSubstring(t *testing.T) {
+	vm := newStringVM(t)
+	if got := vm.Call("lang/Strings", "substring", "(Ljava/lang/String;II)Ljava/lang/String;", "hello", 1, 4).String(); got != "ell" {
+		t.Errorf(`"hello".substring(1, 4) = %q`, got)
+	}
+	if got := vm.Call("lang/Strings", "substring", "(Ljava/lang/String;I)Ljava/lang/String;", "hello", 2).String(); got != "llo" {
+		t.Errorf(`"hello".substring(2) = %q`, got)
+	}
+	for _, test := range []struct {
+		begin, end int
+		want       string
+	}{
+		{-1, 2, "String index out of range: -1"},
+		{0, 6, "String index out of range: 6"},
+		{3, 1, "String index out of range: -2"},
+	} {
+		msg := vm.Call("lang/Strings", "substring", "(Ljava/lang/String;II)Ljava/lang/String;", "hello", test.begin, test.end).
+			Throws("java/lang/StringIndexOutOfBoundsException")
+		if msg != test.want {
+			t.Errorf("substring(%d, %d) message = %q, want %q", test.begin, test.end, msg, test.want)
+		}
+	}
+}
+
+func TestStringIndexOf(t *testing.T) {
+	vm := newStringVM(t)
+	for _, test := range []struct {
+		s    string
+		ch   int
+		want int32
+	}{
+		{"hello", 'l', 2},
+		{"hello", 'z', -1},
+		{"a\U0001F600b", 0x1F600, 1},
+		{"a\U0001F600b", 'b', 3},
+	} {
+		if got := vm.Call("lang/Strings", "indexOf", "(Ljava/lang/String;I)I", test.s, test.ch).Int(); got != test.want {
+			t.Errorf("%q.indexOf(%q) = %d, want %d", test.s, rune(test.ch), got, test.want)
+		}
+	}
+	if got := vm.Call("lang/Strings", "indexOf", "(Ljava/lang/String;II)I", "hello", 'l', 3).Int(); got != 3 {
+		t.Errorf(`"hello".indexOf('l', 3) = %d, want 3`, got)
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"GoVM/native"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
+	"os"
+	"time"
 )
 
 const jlSystem = "java/lang/System"
 
 func init() {
 	native.Register(jlSystem, "arraycopy", "(Ljava/lang/Object;ILjava/lang/Object;II)V", arraycopy)
-	//native.Register(jlSystem, "setIn0", "(Ljava/io/InputStream;)V", setIn0)
+	native.Register(jlSystem, "setIn0", "(Ljava/io/InputStream;)V", setIn0)
 	native.Register(jlSystem, "setOut0", "(Ljava/io/PrintStream;)V", setOut0)
-	//native.Register(jlSystem, "setErr0", "(Ljava/io/PrintStream;)V", setErr0)
+	native.Register(jlSystem, "setErr0", "(Ljava/io/PrintStream;)V", setErr0)
+	native.Register(jlSystem, "identityHashCode", "(Ljava/lang/Object;)I", identityHashCode)
+	native.Register(jlSystem, "currentTimeMillis", "()J", currentTimeMillis)
+	native.RegisterIntrinsic(jlSystem, "getenv", "(Ljava/lang/String;)Ljava/lang/String;", getenv)
 }
 
 func arraycopy(frame *chapter4_rtdt.Frame) {
@@ -21,48 +26,165 @@ func arraycopy(frame *chapter4_rtdt.Frame) {
 	//source
 	src := vars.GetRef(0)
 	if src == nil {
-		panic("java.lang.NullPointerException")
+		frame.ThrowException("java/lang/NullPointerException", "")
+		return
 	}
 	srcPos := vars.GetInt(1)
 
 	//destination
 	dest := vars.GetRef(2)
 	if dest == nil {
-		panic("java.lang.NullPointerException")
+		frame.ThrowException("java/lang/NullPointerException", "")
+		return
 	}
 	destPos := vars.GetInt(3)
 
 	//data length
 	length := vars.GetInt(4)
 
-	//源数组和目标数组必须兼容，否则不能拷贝
-	if !checkArrayCopy(src, dest) {
-		panic("java.lang.ArrayStoreException")
+	//源数组和目标数组必须兼容，否则不能拷贝。要在移动任何元素之前检查
+	if msg := checkArrayCopy(src, dest); msg != "" {
+		frame.ThrowException("java/lang/ArrayStoreException", msg)
+		return
 	}
 
-	if srcPos < 0 || destPos < 0 || length < 0 || srcPos + length > src.ArrayLength() || destPos + length > dest.ArrayLength() {
-		panic("java.lang.IndexOutOfBoundsException")
+	//srcPos + length 可能超出int32的范围，比如 arraycopy(a, 1, b, 0, Integer.MAX_VALUE)，所以用减法比较
+	if srcPos < 0 || destPos < 0 || length < 0 || length > src.ArrayLength() - srcPos || length > dest.ArrayLength() - destPos {
+		frame.ThrowException("java/lang/ArrayIndexOutOfBoundsException", "")
+		return
 	}
 
-	heap.ArrayCopy(src, dest, srcPos, destPos, length)
+	srcComponent := src.Class().ComponentClass()
+	destComponent := dest.Class().ComponentClass()
+	if srcComponent.IsPrimitive() || destComponent.IsAssignableFrom(srcComponent) {
+		heap.ArrayCopy(src, dest, srcPos, destPos, length)
+	} else {
+		if msg := checkedRefArrayCopy(src, dest, srcPos, destPos, length); msg != "" {
+			frame.ThrowException("java/lang/ArrayStoreException", msg)
+		}
+	}
+}
+
+/**
+	比如Object[]拷贝到String[]，只能一个一个元素检查类型
+	和HotSpot一样，遇到不兼容的元素时，前面的元素已经拷贝过去了，返回ArrayStoreException的信息
+ */
+func checkedRefArrayCopy(src, dest *heap.Object, srcPos, destPos, length int32) string {
+	srcRefs := src.Refs()
+	destRefs := dest.Refs()
+	destComponent := dest.Class().ComponentClass()
+	for i := int32(0); i < length; i++ {
+		ref := srcRefs[srcPos + i]
+		if ref != nil && !ref.IsInstanceOf(destComponent) {
+			return "arraycopy: element type mismatch: can not cast one of the elements of " +
+				src.Class().JavaName() + " to the type of the destination array, " + destComponent.JavaName()
+		}
+		destRefs[destPos + i] = ref
+		dest.WriteBarrier(ref)
+	}
+	return ""
+}
+
+/**
+	System.in/out/err都是static final字段，java代码没法直接赋值，
+	System.setIn/setOut/setErr 通过这几个本地方法直接改写静态变量
+ */
+// private static native void setIn0(InputStream in);
+// (Ljava/io/InputStream;)V
+func setIn0(frame *chapter4_rtdt.Frame) {
+	in := frame.LocalVars().GetRef(0)
+	sysClass := frame.Method().Class()
+	sysClass.SetRefVar("in", "Ljava/io/InputStream;", in)
 }
 
+// private static native void setOut0(PrintStream out);
+// (Ljava/io/PrintStream;)V
 func setOut0(frame *chapter4_rtdt.Frame) {
 	out := frame.LocalVars().GetRef(0)
 	sysClass := frame.Method().Class()
 	sysClass.SetRefVar("out", "Ljava/io/PrintStream;", out)
 }
 
-func checkArrayCopy(src, dest *heap.Object) bool {
+// private static native void setErr0(PrintStream err);
+// (Ljava/io/PrintStream;)V
+func setErr0(frame *chapter4_rtdt.Frame) {
+	err := frame.LocalVars().GetRef(0)
+	sysClass := frame.Method().Class()
+	sysClass.SetRefVar("err", "Ljava/io/PrintStream;", err)
+}
+
+/**
+	子类覆盖了hashCode也不影响，null返回0
+ */
+// public static native int identityHashCode(Object x);
+// (Ljava/lang/Object;)I
+func identityHashCode(frame *chapter4_rtdt.Frame) {
+	hash := int32(0)
+	if obj := frame.LocalVars().GetRef(0); obj != nil {
+		hash = obj.IdentityHashCode()
+	}
+	frame.OperandStack().PushInt(hash)
+}
+
+// public static native long currentTimeMillis();
+// ()J
+func currentTimeMillis(frame *chapter4_rtdt.Frame) {
+	frame.OperandStack().PushLong(time.Now().UnixNano() / int64(time.Millisecond))
+}
+
+/**
+	JDK里是java代码，最后会走到ProcessEnvironment，这里直接读进程的环境变量
+	变量不存在时返回null。无参数的 getenv()Ljava/util/Map; 暂时没有实现
+ */
+// public static String getenv(String name);
+// (Ljava/lang/String;)Ljava/lang/String;
+func getenv(frame *chapter4_rtdt.Frame) {
+	nameObj := frame.LocalVars().GetRef(0)
+	if nameObj == nil {
+		frame.ThrowException("java/lang/NullPointerException", "")
+		return
+	}
+
+	value, ok := os.LookupEnv(heap.GoString(nameObj))
+	if !ok {
+		frame.OperandStack().PushRef(nil)
+		return
+	}
+	loader := frame.Method().Class().Loader()
+	frame.OperandStack().PushRef(heap.JString(loader, value))
+}
+
+/**
+	返回空字符串表示可以拷贝，否则返回ArrayStoreException的信息
+	两个都是引用类型的数组时，这里不检查元素类型
+ */
+func checkArrayCopy(src, dest *heap.Object) string {
 	srcClass := src.Class()
 	destClass := dest.Class()
 
-	if !srcClass.IsArray() || !destClass.IsArray() {
-		return false
+	if !srcClass.IsArray() {
+		return "arraycopy: source type " + srcClass.JavaName() + " is not an array"
+	}
+	if !destClass.IsArray() {
+		return "arraycopy: destination type " + destClass.JavaName() + " is not an array"
 	}
 
 	if srcClass.ComponentClass().IsPrimitive() || destClass.ComponentClass().IsPrimitive() {
-		return srcClass == destClass
+		if srcClass != destClass {
+			return "arraycopy: type mismatch: can not copy " + arrayTypeName(srcClass) +
+				" into " + arrayTypeName(destClass)
+		}
+	}
+	return ""
+}
+
+/**
+	int[] long[] 这样的名字，引用类型的数组统一叫 object array[]
+ */
+func arrayTypeName(arrClass *heap.Class) string {
+	component := arrClass.ComponentClass()
+	if component.IsPrimitive() {
+		return component.JavaName() + "[]"
 	}
-	return true
+	return "object array[]"
 }
\ No newline at end of file
@@ -0,0 +1,185 @@
+package lang_test
+
+import (
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"bytes"
+	"testing"
+)
+
+const arraycopyDescriptor = "(Ljava/lang/Object;ILjava/lang/Object;II)V"
+
+/**
+	static void copy(Object src, int srcPos, Object dest, int destPos, int length) { System.arraycopy(...); }
+ */
+func newArraycopyVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Copy", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "copy", arraycopyDescriptor).Code(5, 5).
+		Op(ALOAD_0).Op(ILOAD_1).Op(ALOAD_2).Op(ILOAD_3).Op(ILOAD, 4).
+		Invokestatic("java/lang/System", "arraycopy", arraycopyDescriptor).Op(RETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestArraycopyRejectsIncompatibleArrays(t *testing.T) {
+	vm := newArraycopyVM(t)
+	ints := vm.Class("[I").NewArray(3)
+	ints.Ints()[0] = 7
+	longs := vm.Class("[J").NewArray(3)
+	objects := vm.Class("[Ljava/lang/Object;").NewArray(1)
+
+	for _, test := range []struct {
+		src, dest *heap.Object
+		want      string
+	}{
+		{ints, longs, "arraycopy: type mismatch: can not copy int[] into long[]"},
+		{ints, objects, "arraycopy: type mismatch: can not copy int[] into object array[]"},
+		{objects, vm.String("x"), "arraycopy: destination type java.lang.String is not an array"},
+		{vm.String("x"), objects, "arraycopy: source type java.lang.String is not an array"},
+	} {
+		msg := vm.Call("lang/Copy", "copy", arraycopyDescriptor, test.src, 0, test.dest, 0, 1).
+			Throws("java/lang/ArrayStoreException")
+		if msg != test.want {
+			t.Errorf("message = %q, want %q", msg, test.want)
+		}
+	}
+	if longs.Longs()[0] != 0 {
+		t.Errorf("rejected copy wrote %d into the destination", longs.Longs()[0])
+	}
+}
+
+func TestArraycopyBoundsAndNulls(t *testing.T) {
+	vm := newArraycopyVM(t)
+	src := vm.Class("[I").NewArray(4)
+	copy(src.Ints(), []int32{1, 2, 3, 4})
+	dest := vm.Class("[I").NewArray(4)
+
+	vm.Call("lang/Copy", "copy", arraycopyDescriptor, src, 1, dest, 0, 3)
+	if got := dest.Ints(); got[0] != 2 || got[1] != 3 || got[2] != 4 || got[3] != 0 {
+		t.Errorf("dest = %v, want [2 3 4 0]", got)
+	}
+
+	//srcPos + length 溢出int32也要判断成越界
+	const maxInt = int32(^uint32(0) >> 1)
+	vm.Call("lang/Copy", "copy", arraycopyDescriptor, src, 1, dest, 0, maxInt).
+		Throws("java/lang/ArrayIndexOutOfBoundsException")
+	vm.Call("lang/Copy", "copy", arraycopyDescriptor, src, 0, dest, 2, 3).
+		Throws("java/lang/ArrayIndexOutOfBoundsException")
+	vm.Call("lang/Copy", "copy", arraycopyDescriptor, nil, 0, dest, 0, 1).
+		Throws("java/lang/NullPointerException")
+	vm.Call("lang/Copy", "copy", arraycopyDescriptor, src, 0, nil, 0, 1).
+		Throws("java/lang/NullPointerException")
+}
+
+/**
+	本地方法抛出的异常由调用它的java代码捕获
+		static Throwable guarded(Object src, int length) {
+			try { System.arraycopy(src, 0, new int[2], 0, length); }
+			catch (IndexOutOfBoundsException e) { return e; }
+			return null;
+		}
+ */
+func TestNativeExceptionIsCaughtByJava(t *testing.T) {
+	class := New("lang/Guarded", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "guarded", "(Ljava/lang/Object;I)Ljava/lang/Throwable;").Code(5, 2).
+		Label("start").
+		Op(ALOAD_0).Op(ICONST_0).Op(ICONST_2).Op(NEWARRAY, 10).Op(ICONST_0).Op(ILOAD_1).
+		Invokestatic("java/lang/System", "arraycopy", arraycopyDescriptor).
+		Label("end").
+		Op(ACONST_NULL).Op(ARETURN).
+		Label("handler").
+		Op(ARETURN).
+		Catch("start", "end", "handler", "java/lang/IndexOutOfBoundsException")
+	vm := jvmtest.New(t, class)
+	src := vm.Class("[I").NewArray(4)
+
+	if ex := vm.Call("lang/Guarded", "guarded", "(Ljava/lang/Object;I)Ljava/lang/Throwable;", src, int32(2)).Ref(); ex != nil {
+		t.Fatalf("copying in bounds threw %s", ex.Class().Name())
+	}
+	ex := vm.Call("lang/Guarded", "guarded", "(Ljava/lang/Object;I)Ljava/lang/Throwable;", src, int32(3)).Ref()
+	if ex == nil || ex.Class().Name() != "java/lang/ArrayIndexOutOfBoundsException" {
+		t.Fatalf("guarded(src, 3) returned %v, want the caught ArrayIndexOutOfBoundsException", ex)
+	}
+	//异常对象上记录了抛出时的栈，里面有调用arraycopy的java方法
+	trace, _ := ex.Extra().([]*chapter4_rtdt.StackTraceElement)
+	found := false
+	for _, element := range trace {
+		found = found || element.MethodName() == "guarded"
+	}
+	if !found {
+		t.Errorf("stack trace %v does not contain the calling method", trace)
+	}
+}
+
+/**
+	static String getenv(String name) { return System.getenv(name); }
+ */
+func newGetenvVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Env", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "getenv", "(Ljava/lang/String;)Ljava/lang/String;").Code(1, 1).
+		Op(ALOAD_0).Invokestatic("java/lang/System", "getenv", "(Ljava/lang/String;)Ljava/lang/String;").
+		Op(ARETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestGetenv(t *testing.T) {
+	vm := newGetenvVM(t)
+	t.Setenv("GOVM_TEST_VAR", "value from t.Setenv")
+	t.Setenv("GOVM_TEST_EMPTY", "")
+
+	const descriptor = "(Ljava/lang/String;)Ljava/lang/String;"
+	if got := vm.Call("lang/Env", "getenv", descriptor, "GOVM_TEST_VAR").String(); got != "value from t.Setenv" {
+		t.Errorf("getenv(GOVM_TEST_VAR) = %q", got)
+	}
+	//设置成空字符串和没有设置不一样
+	if got := vm.Call("lang/Env", "getenv", descriptor, "GOVM_TEST_EMPTY").Ref(); got == nil || heap.GoString(got) != "" {
+		t.Errorf("getenv(GOVM_TEST_EMPTY) = %v, want \"\"", got)
+	}
+	if got := vm.Call("lang/Env", "getenv", descriptor, "GOVM_TEST_UNSET").Ref(); got != nil {
+		t.Errorf("getenv of an unset variable = %q, want null", heap.GoString(got))
+	}
+	vm.Call("lang/Env", "getenv", descriptor, nil).Throws("java/lang/NullPointerException")
+}
+
+/**
+	static void redirect(PrintStream out, PrintStream err, InputStream in) {
+		System.setOut(out); System.setErr(err); System.setIn(in);
+		System.out.println("to out"); System.err.println("to err");
+	}
+	static InputStream in() { return System.in; }
+ */
+func newRedirectVM(t *testing.T) *jvmtest.VM {
+	const ps, is = "Ljava/io/PrintStream;", "Ljava/io/InputStream;"
+	class := New("lang/Redirect", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "redirect", "("+ps+ps+is+")V").Code(2, 3).
+		Op(ALOAD_0).Invokestatic("java/lang/System", "setOut", "("+ps+")V").
+		Op(ALOAD_1).Invokestatic("java/lang/System", "setErr", "("+ps+")V").
+		Op(ALOAD_2).Invokestatic("java/lang/System", "setIn", "("+is+")V").
+		Getstatic("java/lang/System", "out", ps).LdcString("to out").
+		Invokevirtual("java/io/PrintStream", "println", "(Ljava/lang/String;)V").
+		Getstatic("java/lang/System", "err", ps).LdcString("to err").
+		Invokevirtual("java/io/PrintStream", "println", "(Ljava/lang/String;)V").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "in", "()"+is).Code(1, 0).
+		Getstatic("java/lang/System", "in", is).Op(ARETURN)
+	return jvmtest.New(t, class)
+}
+
+func TestSetOutRedirectsPrintln(t *testing.T) {
+	vm := newRedirectVM(t)
+	var out, err bytes.Buffer
+	in := vm.Class("java/io/ByteArrayInputStream").NewObject()
+
+	vm.Call("lang/Redirect", "redirect", "(Ljava/io/PrintStream;Ljava/io/PrintStream;Ljava/io/InputStream;)V",
+		vm.NewPrintStream(&out), vm.NewPrintStream(&err), in)
+	if out.String() != "to out\n" || err.String() != "to err\n" {
+		t.Errorf("new streams got out %q, err %q", out.String(), err.String())
+	}
+	if vm.Out.Len() != 0 || vm.Err.Len() != 0 {
+		t.Errorf("old streams still got out %q, err %q", vm.Out.String(), vm.Err.String())
+	}
+	//in、out、err都是final字段，setIn0也要能改
+	if got := vm.Call("lang/Redirect", "in", "()Ljava/io/InputStream;").Ref(); got != in {
+		t.Error("System.in was not replaced by setIn")
+	}
+}
@@ -0,0 +1,54 @@
+package lang
+
+import (
+	"GoVM/native"
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+)
+
+const jlThread = "java/lang/Thread"
+
+/**
+	join(J)V、join()V 和 setDaemon(Z)V 在JDK里都是Java代码，只依赖 isAlive 和 Object.wait(J)V（见Object.go）：
+	join 在 while(isAlive()) 里调用wait，setDaemon 在线程存活时抛IllegalThreadStateException
+	所以这里只需要实现 isAlive，已经结束（或者没有启动）的线程 join 会立即返回
+ */
+func init() {
+	native.Register(jlThread, "currentThread", "()Ljava/lang/Thread;", currentThread)
+	native.Register(jlThread, "isAlive", "()Z", isAlive)
+}
+
+// public static native Thread currentThread();
+// ()Ljava/lang/Thread;
+func currentThread(frame *chapter4_rtdt.Frame) {
+	thread := frame.Thread()
+	jThread := thread.JThread()
+	if jThread == nil {
+		jThread = newMainThreadObject(frame)
+		thread.SetJThread(jThread)
+	}
+	frame.OperandStack().PushRef(jThread)
+}
+
+/**
+	主线程的java.lang.Thread对象，只设置name、priority，以及extra里的Go线程
+	setName/getName 是java代码，直接读写name字段，没有调用构造函数，所以group为null
+ */
+func newMainThreadObject(frame *chapter4_rtdt.Frame) *heap.Object {
+	threadClass := frame.Method().Class()
+	jThread := threadClass.NewObject()
+	jThread.SetRefVar("name", "[C", heap.JChars(threadClass.Loader(), "main"))
+	jThread.SetIntVar("priority", "I", 5)
+	jThread.SetExtra(frame.Thread())
+	return jThread
+}
+
+// public final native boolean isAlive();
+// ()Z
+func isAlive(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	//extra里挂着对应的Go线程、并且它的栈还没有清空（run()还没有返回）时才算存活。
+	//虚拟机目前不能启动新线程（没有 start0），没有挂上Go线程的Thread对象都返回false
+	thread, attached := this.Extra().(*chapter4_rtdt.Thread)
+	frame.OperandStack().PushBoolean(attached && !thread.IsStackEmpty())
+}
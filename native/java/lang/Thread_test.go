@@ -0,0 +1,231 @@
+package lang_test
+
+import (
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter5-instructions"
+	"GoVM/chapter6-obj/heap"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const jlThread = "java/lang/Thread"
+
+/**
+	调用Thread对象实例方法的静态方法，以及 currentThread().isAlive()
+ */
+func newThreadVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Threads", "java/lang/Object")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "alive", "(Ljava/lang/Thread;)Z").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual(jlThread, "isAlive", "()Z").Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "currentAlive", "()Z").Code(1, 0).
+		Invokestatic(jlThread, "currentThread", "()Ljava/lang/Thread;").
+		Invokevirtual(jlThread, "isAlive", "()Z").Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "join", "(Ljava/lang/Thread;)V").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual(jlThread, "join", "()V").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "join", "(Ljava/lang/Thread;J)V").Code(3, 3).
+		Op(ALOAD_0).Op(LLOAD_1).Invokevirtual(jlThread, "join", "(J)V").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "setDaemon", "(Ljava/lang/Thread;Z)Z").Code(2, 2).
+		Op(ALOAD_0).Op(ILOAD_1).Invokevirtual(jlThread, "setDaemon", "(Z)V").
+		Op(ALOAD_0).Invokevirtual(jlThread, "isDaemon", "()Z").Op(IRETURN)
+	//synchronized (o) { o.wait(timeout); }，异常退出时不释放锁，每次用新的对象
+	class.Method(ACC_PUBLIC|ACC_STATIC, "wait", "(Ljava/lang/Object;J)V").Code(3, 3).
+		Op(ALOAD_0).Op(MONITORENTER).
+		Op(ALOAD_0).Op(LLOAD_1).Invokevirtual("java/lang/Object", "wait", "(J)V").
+		Op(ALOAD_0).Op(MONITOREXIT).Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "unownedWait", "(Ljava/lang/Object;)V").Code(3, 1).
+		Op(ALOAD_0).Lconst(1).Invokevirtual("java/lang/Object", "wait", "(J)V").Op(RETURN)
+	for _, name := range []string{"notify", "notifyAll"} {
+		class.Method(ACC_PUBLIC|ACC_STATIC, name, "(Ljava/lang/Object;)V").Code(1, 1).
+			Op(ALOAD_0).Invokevirtual("java/lang/Object", name, "()V").Op(RETURN)
+	}
+	class.Method(ACC_PUBLIC|ACC_STATIC, "renameAndDie", "()V").Code(2, 0).
+		Invokestatic(jlThread, "currentThread", "()Ljava/lang/Thread;").
+		LdcString("worker").Invokevirtual(jlThread, "setName", "(Ljava/lang/String;)V").
+		Invokestatic("lang/Threads", "die", "()V").Op(RETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "die", "()V").Code(3, 0).
+		New("java/lang/RuntimeException").Op(DUP).LdcString("boom").
+		Invokespecial("java/lang/RuntimeException", "<init>", "(Ljava/lang/String;)V").Op(ATHROW)
+	return jvmtest.New(t, class)
+}
+
+/**
+	虚拟机不能启动线程，测试直接给Thread对象挂上一个Go线程，栈上放一帧当作正在执行的run()
+ */
+func startThread(vm *jvmtest.VM) (*heap.Object, *chapter4_rtdt.Thread) {
+	class := vm.Class(jlThread)
+	jThread := class.NewObject()
+	thread := chapter4_rtdt.NewThread()
+	thread.SetJThread(jThread)
+	thread.PushFrame(thread.NewFrame(class.GetInstanceMethod("run", "()V")))
+	jThread.SetExtra(thread)
+	return jThread, thread
+}
+
+func TestThreadIsAliveUntilRunReturns(t *testing.T) {
+	vm := newThreadVM(t)
+	if !vm.Call("lang/Threads", "currentAlive", "()Z").Bool() {
+		t.Error("currentThread().isAlive() = false")
+	}
+	if vm.Call("lang/Threads", "alive", "(Ljava/lang/Thread;)Z", vm.Class(jlThread).NewObject()).Bool() {
+		t.Error("a thread that was never started is alive")
+	}
+
+	jThread, thread := startThread(vm)
+	if !vm.Call("lang/Threads", "alive", "(Ljava/lang/Thread;)Z", jThread).Bool() {
+		t.Fatal("running thread is not alive")
+	}
+	thread.PopFrame()
+	if vm.Call("lang/Threads", "alive", "(Ljava/lang/Thread;)Z", jThread).Bool() {
+		t.Error("thread is still alive after run() returned")
+	}
+}
+
+func TestThreadJoin(t *testing.T) {
+	vm := newThreadVM(t)
+	jThread, thread := startThread(vm)
+
+	//没有别的线程能让它结束，限时的join等够时间就返回
+	const millis = 30
+	start := time.Now()
+	vm.Call("lang/Threads", "join", "(Ljava/lang/Thread;J)V", jThread, int64(millis))
+	if elapsed := time.Since(start); elapsed < millis * time.Millisecond {
+		t.Errorf("join(%d) returned after %v", millis, elapsed)
+	}
+	msg := vm.Call("lang/Threads", "join", "(Ljava/lang/Thread;J)V", jThread, int64(-1)).
+		Throws("java/lang/IllegalArgumentException")
+	if msg != "timeout value is negative" {
+		t.Errorf("message = %q", msg)
+	}
+
+	//已经结束的线程，两种join都立即返回
+	thread.PopFrame()
+	start = time.Now()
+	vm.Call("lang/Threads", "join", "(Ljava/lang/Thread;)V", jThread)
+	vm.Call("lang/Threads", "join", "(Ljava/lang/Thread;J)V", jThread, int64(10000))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("join on a finished thread took %v", elapsed)
+	}
+}
+
+func TestThreadSetDaemon(t *testing.T) {
+	vm := newThreadVM(t)
+	jThread, thread := startThread(vm)
+
+	vm.Call("lang/Threads", "setDaemon", "(Ljava/lang/Thread;Z)Z", jThread, true).
+		Throws("java/lang/IllegalThreadStateException")
+	thread.PopFrame()
+	if !vm.Call("lang/Threads", "setDaemon", "(Ljava/lang/Thread;Z)Z", jThread, true).Bool() {
+		t.Error("isDaemon() = false after setDaemon(true)")
+	}
+}
+
+func TestObjectWait(t *testing.T) {
+	vm := newThreadVM(t)
+	object := vm.Class("java/lang/Object")
+
+	start := time.Now()
+	vm.Call("lang/Threads", "wait", "(Ljava/lang/Object;J)V", object.NewObject(), int64(20))
+	if elapsed := time.Since(start); elapsed < 20 * time.Millisecond {
+		t.Errorf("wait(20) returned after %v", elapsed)
+	}
+	vm.Call("lang/Threads", "wait", "(Ljava/lang/Object;J)V", object.NewObject(), int64(-5)).
+		Throws("java/lang/IllegalArgumentException")
+
+	//没有别的线程来notify，wait(0)是死锁
+	msg := vm.Call("lang/Threads", "wait", "(Ljava/lang/Object;J)V", object.NewObject(), int64(0)).
+		Throws("java/lang/InternalError")
+	if !strings.HasPrefix(msg, "deadlock:") {
+		t.Errorf("wait(0) threw %q, want a deadlock error", msg)
+	}
+}
+
+func TestWaitAndNotifyRequireTheMonitor(t *testing.T) {
+	vm := newThreadVM(t)
+	obj := vm.Class("java/lang/Object").NewObject()
+	for _, name := range []string{"unownedWait", "notify", "notifyAll"} {
+		msg := vm.Call("lang/Threads", name, "(Ljava/lang/Object;)V", obj).
+			Throws("java/lang/IllegalMonitorStateException")
+		if msg != "current thread is not owner" {
+			t.Errorf("%s threw %q", name, msg)
+		}
+	}
+}
+
+/**
+	class Locked {
+		synchronized void hold() { wait(1); }
+		synchronized void deadlock() { wait(0); }
+		static synchronized void holdClass() { Locked.class.notify(); }
+	}
+ */
+func TestSynchronizedMethodHoldsTheMonitor(t *testing.T) {
+	locked := New("lang/Locked", "java/lang/Object").DefaultConstructor()
+	locked.Method(ACC_PUBLIC|ACC_SYNCHRONIZED, "hold", "()V").Code(3, 1).
+		Op(ALOAD_0).Lconst(1).Invokevirtual("java/lang/Object", "wait", "(J)V").Op(RETURN)
+	locked.Method(ACC_PUBLIC|ACC_SYNCHRONIZED, "deadlock", "()V").Code(3, 1).
+		Op(ALOAD_0).Lconst(0).Invokevirtual("java/lang/Object", "wait", "(J)V").Op(RETURN)
+	locked.Method(ACC_PUBLIC|ACC_STATIC|ACC_SYNCHRONIZED, "holdClass", "()V").Code(1, 0).
+		LdcClass("lang/Locked").Invokevirtual("java/lang/Object", "notify", "()V").Op(RETURN)
+	for _, name := range []string{"hold", "deadlock"} {
+		locked.Method(ACC_PUBLIC|ACC_STATIC, name, "(Llang/Locked;)V").Code(1, 1).
+			Op(ALOAD_0).Invokevirtual("lang/Locked", name, "()V").Op(RETURN)
+	}
+	vm := jvmtest.New(t, locked)
+	obj := vm.Class("lang/Locked").NewObject()
+
+	vm.Call("lang/Locked", "hold", "(Llang/Locked;)V", obj)
+	if obj.MonitorCount() != 0 {
+		t.Errorf("monitor count = %d after a synchronized method returned", obj.MonitorCount())
+	}
+	//因为异常退出也要释放锁
+	vm.Call("lang/Locked", "deadlock", "(Llang/Locked;)V", obj).Throws("java/lang/InternalError")
+	if obj.MonitorCount() != 0 {
+		t.Errorf("monitor count = %d after a synchronized method threw", obj.MonitorCount())
+	}
+	vm.Call("lang/Locked", "holdClass", "()V")
+	if jClass := vm.Class("lang/Locked").JClass(); jClass.MonitorCount() != 0 {
+		t.Errorf("class monitor count = %d", jClass.MonitorCount())
+	}
+}
+
+/**
+	不经过jvmtest（它在最外层有一个捕获所有异常的帧），让异常一直传到线程外面，返回打印到标准错误的内容
+ */
+func runUncaught(t *testing.T, vm *jvmtest.VM, thread *chapter4_rtdt.Thread, method string) string {
+	stderr, err := ioutil.TempFile(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(saved *os.File) { os.Stderr = saved }(os.Stderr)
+	os.Stderr = stderr
+
+	thread.PushFrame(thread.NewFrame(vm.Class("lang/Threads").GetStaticMethod(method, "()V")))
+	chapter5_instructions.Interpret(thread, false)
+	out, err := ioutil.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestUncaughtExceptionPrintsThreadName(t *testing.T) {
+	vm := newThreadVM(t)
+	//还没有调用过currentThread()的线程是主线程
+	out := runUncaught(t, vm, chapter4_rtdt.NewThread(), "die")
+	if !strings.HasPrefix(out, `Exception in thread "main" java.lang.RuntimeException: boom`) {
+		t.Errorf("uncaught exception printed %q", out)
+	}
+
+	out = runUncaught(t, vm, chapter4_rtdt.NewThread(), "renameAndDie")
+	if !strings.HasPrefix(out, `Exception in thread "worker" java.lang.RuntimeException: boom`) {
+		t.Errorf("uncaught exception printed %q", out)
+	}
+	if !strings.Contains(out, "\tat lang.Threads.die") {
+		t.Errorf("stack trace is missing from %q", out)
+	}
+}
@@ -4,25 +4,14 @@ import (
 	"GoVM/native"
 	"GoVM/chapter4-rtdt"
 	"GoVM/chapter6-obj/heap"
-	"fmt"
 )
 
 const jlThrowable = "java/lang/Throwable"
 
 func init() {
 	native.Register(jlThrowable, "fillInStackTrace", "(I)Ljava/lang/Throwable;", fillInStackTrace)
-}
-
-type StackTraceElement struct {
-	fileName   string
-	className  string
-	methodName string
-	lineNumber int
-}
-
-func (self *StackTraceElement) String() string {
-	return fmt.Sprintf("%s.%s(%s:%d)",
-		self.className, self.methodName, self.fileName, self.lineNumber)
+	native.Register(jlThrowable, "getStackTraceDepth", "()I", getStackTraceDepth)
+	native.Register(jlThrowable, "getStackTraceElement", "(I)Ljava/lang/StackTraceElement;", getStackTraceElement)
 }
 
 func fillInStackTrace(frame *chapter4_rtdt.Frame) {
@@ -37,27 +26,9 @@ func fillInStackTrace(frame *chapter4_rtdt.Frame) {
 	由于栈顶两帧正在执行fillInStackTrace(int)和fillInStackTrace()方法，所以需要跳过这两帧
 	这两帧下面的几帧正在执行异常类的构造函数，所以也要跳过，具体跳过多少帧要看异常类的层次
  */
-func createStackTraceElements(obj *heap.Object, thread *chapter4_rtdt.Thread) []*StackTraceElement {
+func createStackTraceElements(obj *heap.Object, thread *chapter4_rtdt.Thread) []*chapter4_rtdt.StackTraceElement {
 	skip := distanceToObject(obj.Class()) + 2
-	frames := thread.GetFrames()[skip:]
-
-	stes := make([]*StackTraceElement, len(frames))
-	for i, frame := range frames {
-		stes[i] = createStackTraceElement(frame)
-	}
-	return stes
-}
-
-func createStackTraceElement(frame *chapter4_rtdt.Frame) *StackTraceElement {
-	method := frame.Method()
-	class := method.Class()
-
-	return &StackTraceElement{
-		fileName:	class.SourceFile(),
-		className:	class.JavaName(),
-		methodName:	method.Name(),
-		lineNumber:	method.GetLineNumber(frame.NextPC() - 1),
-	}
+	return thread.StackTrace(skip)
 }
 
 func distanceToObject(class *heap.Class) int {
@@ -66,4 +37,44 @@ func distanceToObject(class *heap.Class) int {
 		distance++
 	}
 	return distance
+}
+
+// native int getStackTraceDepth();
+// ()I
+func getStackTraceDepth(frame *chapter4_rtdt.Frame) {
+	this := frame.LocalVars().GetThis()
+	stes, _ := this.Extra().([]*chapter4_rtdt.StackTraceElement)
+	frame.OperandStack().PushInt(int32(len(stes)))
+}
+
+/**
+	Throwable.getStackTrace()会按需调用这个方法，把fillInStackTrace时记录下来的栈信息
+	转换成java.lang.StackTraceElement对象
+ */
+// native StackTraceElement getStackTraceElement(int index);
+// (I)Ljava/lang/StackTraceElement;
+func getStackTraceElement(frame *chapter4_rtdt.Frame) {
+	vars := frame.LocalVars()
+	this := vars.GetThis()
+	index := vars.GetInt(1)
+
+	stes, _ := this.Extra().([]*chapter4_rtdt.StackTraceElement)
+	if index < 0 || int(index) >= len(stes) {
+		frame.ThrowException("java/lang/IndexOutOfBoundsException", "")
+		return
+	}
+
+	loader := frame.Method().Class().Loader()
+	steObj := createStackTraceElementObject(loader, stes[index])
+	frame.OperandStack().PushRef(steObj)
+}
+
+func createStackTraceElementObject(loader *heap.ClassLoader, ste *chapter4_rtdt.StackTraceElement) *heap.Object {
+	steClass := loader.LoadClass("java/lang/StackTraceElement")
+	steObj := steClass.NewObject()
+	steObj.SetRefVar("declaringClass", "Ljava/lang/String;", heap.JString(loader, ste.ClassName()))
+	steObj.SetRefVar("methodName", "Ljava/lang/String;", heap.JString(loader, ste.MethodName()))
+	steObj.SetRefVar("fileName", "Ljava/lang/String;", heap.JString(loader, ste.FileName()))
+	steObj.SetIntVar("lineNumber", "I", int32(ste.LineNumber()))
+	return steObj
 }
\ No newline at end of file
@@ -0,0 +1,59 @@
+package lang_test
+
+import (
+	"GoVM/chapter6-obj/heap"
+	. "GoVM/internal/classgen"
+	"GoVM/internal/jvmtest"
+	"testing"
+)
+
+/**
+	Trace.java：
+	 7  static StackTraceElement[] inner() { return new Exception().getStackTrace(); }
+	12  static StackTraceElement[] outer() { return inner(); }
+ */
+func newTraceVM(t *testing.T) *jvmtest.VM {
+	class := New("lang/Trace", "java/lang/Object").SourceFile("Trace.java")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "inner", "()[Ljava/lang/StackTraceElement;").Code(2, 0).
+		Line(7).New("java/lang/Exception").Op(DUP).Invokespecial("java/lang/Exception", "<init>", "()V").
+		Invokevirtual("java/lang/Throwable", "getStackTrace", "()[Ljava/lang/StackTraceElement;").Op(ARETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "outer", "()[Ljava/lang/StackTraceElement;").Code(1, 0).
+		Line(12).Invokestatic("lang/Trace", "inner", "()[Ljava/lang/StackTraceElement;").Op(ARETURN)
+	return jvmtest.New(t, class)
+}
+
+type steFields struct {
+	className, methodName, fileName string
+	lineNumber                      int32
+}
+
+func TestGetStackTrace(t *testing.T) {
+	vm := newTraceVM(t)
+	trace := vm.Call("lang/Trace", "outer", "()[Ljava/lang/StackTraceElement;").Ref().Refs()
+
+	//构造方法和fillInStackTrace的栈帧不算，最后一帧是测试工具的调用方
+	if len(trace) != 3 {
+		t.Fatalf("getStackTrace().length = %d, want 3", len(trace))
+	}
+	for i, want := range []steFields{
+		{"lang.Trace", "inner", "Trace.java", 7},
+		{"lang.Trace", "outer", "Trace.java", 12},
+	} {
+		ste := trace[i]
+		if ste.Class().Name() != "java/lang/StackTraceElement" {
+			t.Fatalf("element %d is a %s", i, ste.Class().Name())
+		}
+		got := steFields{
+			heap.GoString(ste.GetRefVar("declaringClass", "Ljava/lang/String;")),
+			heap.GoString(ste.GetRefVar("methodName", "Ljava/lang/String;")),
+			heap.GoString(ste.GetRefVar("fileName", "Ljava/lang/String;")),
+			ste.GetIntVar("lineNumber", "I"),
+		}
+		if got != want {
+			t.Errorf("element %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if name := heap.GoString(trace[2].GetRefVar("methodName", "Ljava/lang/String;")); name != "call" {
+		t.Errorf("last element is in %s, want the caller", name)
+	}
+}
@@ -1,11 +1,24 @@
 package native
 
-import "GoVM/chapter4-rtdt"
+import (
+	"GoVM/chapter4-rtdt"
+	"GoVM/chapter6-obj/heap"
+)
 
 type NativeMethod func(frame *chapter4_rtdt.Frame)
 
 var registry = map[string]NativeMethod{}
 
+/**
+	intrinsic：本来有字节码的java方法，改用go实现
+	比如Integer.bitCount，go的math/bits一条语句就够了，没必要逐条解释执行
+ */
+var intrinsics = map[string]bool{}
+
+func init() {
+	heap.SetIntrinsicChecker(isIntrinsic)
+}
+
 /**
 	空实现
  */
@@ -17,6 +30,20 @@ func Register(className, methodName, methodDescriptor string, method NativeMetho
 	registry[key] = method
 }
 
+/**
+	注册intrinsic，类加载时这个方法的字节码会被替换成调用本地方法的字节码
+ */
+func RegisterIntrinsic(className, methodName, methodDescriptor string, method NativeMethod) {
+	Register(className, methodName, methodDescriptor, method)
+	key := className + "~" + methodName + "~" + methodDescriptor
+	intrinsics[key] = true
+}
+
+func isIntrinsic(className, methodName, methodDescriptor string) bool {
+	key := className + "~" + methodName + "~" + methodDescriptor
+	return intrinsics[key]
+}
+
 func FindNativeMethod(className, methodName, methodDescriptor string) NativeMethod {
 	key := className + "~" + methodName + "~" + methodDescriptor
 	if method, ok := registry[key]; ok {
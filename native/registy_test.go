@@ -0,0 +1,66 @@
+package native_test
+
+import (
+	. "GoVM/internal/classgen"
+	"GoVM/chapter4-rtdt"
+	"GoVM/internal/jvmtest"
+	"GoVM/native"
+	"testing"
+)
+
+/**
+	class Natives {
+		static native int twice(int x);          //测试里注册
+		static native void missing();            //没有注册
+		static int callTwice(int x) { return twice(x) + 1; }
+		static Class classOf(Object o) { return o.getClass(); }
+	}
+ */
+func TestRegisteredNativeMethodRunsInsteadOfBytecode(t *testing.T) {
+	native.Register("reg/Natives", "twice", "(I)I", func(frame *chapter4_rtdt.Frame) {
+		frame.OperandStack().PushInt(frame.LocalVars().GetInt(0) * 2)
+	})
+	class := New("reg/Natives", "java/lang/Object")
+	class.Method(ACC_STATIC|ACC_NATIVE, "twice", "(I)I")
+	class.Method(ACC_STATIC|ACC_NATIVE, "missing", "()V")
+	class.Method(ACC_PUBLIC|ACC_STATIC, "callTwice", "(I)I").Code(2, 1).
+		Op(ILOAD_0).Invokestatic("reg/Natives", "twice", "(I)I").Op(ICONST_1).Op(IADD).Op(IRETURN)
+	class.Method(ACC_PUBLIC|ACC_STATIC, "classOf", "(Ljava/lang/Object;)Ljava/lang/Class;").Code(1, 1).
+		Op(ALOAD_0).Invokevirtual("java/lang/Object", "getClass", "()Ljava/lang/Class;").Op(ARETURN)
+	vm := jvmtest.New(t, class)
+
+	if got := vm.Call("reg/Natives", "callTwice", "(I)I", int32(20)).Int(); got != 41 {
+		t.Errorf("callTwice(20) = %d, want 41", got)
+	}
+	var result *jvmtest.Result
+	r := vm.CallPanic(&result, "reg/Natives", "missing", "()V")
+	if want := "java.lang.UnsatisfiedLinkError: reg/Natives.missing--()V"; r != want {
+		t.Errorf("calling an unregistered native method panicked with %v, want %q", r, want)
+	}
+	//Object.getClass本身就是注册的本地方法
+	object := vm.Class("java/lang/String").NewObject()
+	if got := vm.Call("reg/Natives", "classOf", "(Ljava/lang/Object;)Ljava/lang/Class;", object).Ref(); got != vm.Class("java/lang/String").JClass() {
+		t.Errorf("getClass() = %v, want String.class", got)
+	}
+}
+
+func TestFindNativeMethod(t *testing.T) {
+	for _, test := range []struct {
+		className, name, descriptor string
+		found                       bool
+	}{
+		{"java/lang/Object", "getClass", "()Ljava/lang/Class;", true},
+		{"java/lang/Object", "hashCode", "()I", true},
+		//registerNatives不管哪个类都用空实现
+		{"reg/Anything", "registerNatives", "()V", true},
+		//类名、方法名、描述符都要对上
+		{"java/lang/Object", "hashCode", "()J", false},
+		{"java/lang/String", "getClass", "()Ljava/lang/Class;", false},
+		{"reg/Anything", "registerNatives", "(I)V", false},
+	} {
+		if got := native.FindNativeMethod(test.className, test.name, test.descriptor) != nil; got != test.found {
+			t.Errorf("FindNativeMethod(%s, %s, %s) found = %v, want %v",
+				test.className, test.name, test.descriptor, got, test.found)
+		}
+	}
+}